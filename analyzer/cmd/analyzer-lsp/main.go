@@ -0,0 +1,19 @@
+// Command analyzer-lsp is a Language Server Protocol server for expression files, speaking JSON-RPC over stdio.
+// Each line or indented block of a file is analyzed as one expression.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"antlr-editor/analyzer/core/app"
+	"antlr-editor/analyzer/lsp"
+)
+
+func main() {
+	server := lsp.NewServer(app.NewApp())
+	if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "analyzer-lsp:", err)
+		os.Exit(1)
+	}
+}
@@ -1,11 +1,14 @@
 package app
 
 import (
-	"sort"
+	"slices"
+	"unicode/utf8"
 
 	"github.com/antlr4-go/antlr/v4"
 
+	"antlr-editor/analyzer/core/app/functions"
 	"antlr-editor/analyzer/core/app/tree"
+	"antlr-editor/analyzer/core/app/typecheck"
 	"antlr-editor/analyzer/core/infrastructure"
 	"antlr-editor/analyzer/core/models"
 	"antlr-editor/analyzer/gen/parser"
@@ -59,34 +62,57 @@ func (r *TokenizeResult) AsMap() map[string]any {
 
 // Analyzer provides expression syntax analysis functionality
 type Analyzer struct {
-	helper *infrastructure.ParserHelper
+	helper   *infrastructure.ParserHelper
+	registry *functions.Registry
+	schema   *models.Schema
+	encoding models.PositionEncoding // Encoding of the positions returned by App, code points if empty
 }
 
 // newAnalyzer creates a new analyzer instance
 func newAnalyzer() *Analyzer {
 	return &Analyzer{
-		helper: infrastructure.NewParserHelper(),
+		helper:   infrastructure.NewParserHelper(),
+		registry: functions.NewBuiltinRegistry(),
 	}
 }
 
-// parseExpression parses the input expression string using ANTLR and returns the parse tree context and any parsing errors
-func (a *Analyzer) parseExpression(expression string) (parser.IExpressionContext, []models.ErrorInfo) {
+// parsedExpression is the result of lexing and parsing an expression once, from which tokens and errors are derived
+type parsedExpression struct {
+	tree   parser.IExpressionContext // Parse tree, nil for an empty expression
+	errors []models.ErrorInfo        // Parse errors
+	tokens []antlr.Token             // Tokens from all channels in input order, ending with EOF
+}
+
+// lexAndParse parses the expression using ANTLR, then lexes the rest of the input the parser did not read,
+// so that the tokens of all channels are collected by the lexer run feeding the parser
+func lexAndParse(helper *infrastructure.ParserHelper, expression string) *parsedExpression {
 	// if expression is empty return nil no error
 	if expression == "" {
-		return nil, nil
+		return &parsedExpression{tokens: lexTokens(helper, expression)}
+	}
+	return parseContext(helper, helper.CreateParser(expression))
+}
+
+// parseTokens parses tokens of the expression lexed earlier, from all channels and ending with EOF, without lexing
+func parseTokens(helper *infrastructure.ParserHelper, expression string, tokens []antlr.Token) *parsedExpression {
+	if expression == "" {
+		return &parsedExpression{tokens: tokens}
 	}
+	return parseContext(helper, helper.CreateParserFromTokens(expression, tokens))
+}
 
+// parseContext parses the expression of the parser context and collects its errors and tokens
+func parseContext(helper *infrastructure.ParserHelper, ctx *infrastructure.ParserContext) *parsedExpression {
 	errors := make([]models.ErrorInfo, 0)
 
 	// Parse the expression
-	ctx := a.helper.CreateParser(expression)
 	errorListener := infrastructure.NewCollectingErrorListener(&errors)
-	a.helper.SetupErrorListeners(ctx, errorListener)
+	helper.SetupErrorListeners(ctx, errorListener)
 
-	result := a.helper.ParseExpression(ctx)
+	result := helper.ParseExpression(ctx)
 
 	// Check if all tokens were consumed
-	if !a.helper.IsAllTokensConsumed(ctx) {
+	if !helper.IsAllTokensConsumed(ctx) {
 		currentToken := ctx.Parser.GetCurrentToken()
 		errors = append(errors, models.ErrorInfo{
 			Message: "Unexpected tokens at end of expression",
@@ -97,13 +123,30 @@ func (a *Analyzer) parseExpression(expression string) (parser.IExpressionContext
 		})
 	}
 
-	return result, errors
+	// The parser reported everything it saw; lexing the remaining input must not add errors
+	if ctx.Lexer != nil {
+		ctx.Lexer.RemoveErrorListeners()
+	}
+	ctx.Stream.Fill()
+
+	return &parsedExpression{tree: result, errors: errors, tokens: ctx.Stream.GetAllTokens()}
+}
+
+// parseExpression parses the input expression string using ANTLR and returns the parse tree context and any parsing errors
+func (a *Analyzer) parseExpression(expression string) (parser.IExpressionContext, []models.ErrorInfo) {
+	parsed := lexAndParse(a.helper, expression)
+	return parsed.tree, parsed.errors
 }
 
 // collectAntlrTokens collects all tokens from the input expression including those in HIDDEN channel (whitespace, comments)
 func (a *Analyzer) collectAntlrTokens(expression string) []antlr.Token {
+	return lexTokens(a.helper, expression)
+}
+
+// lexTokens lexes the expression into tokens from all channels, ending with EOF
+func lexTokens(helper *infrastructure.ParserHelper, expression string) []antlr.Token {
 	// Create a new lexer to collect tokens from all channels
-	lexer := a.helper.CreateLexer(expression)
+	lexer := helper.CreateLexer(expression)
 
 	// Create token stream that includes HIDDEN channel
 	stream := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
@@ -112,52 +155,20 @@ func (a *Analyzer) collectAntlrTokens(expression string) []antlr.Token {
 	return stream.GetAllTokens()
 }
 
-// collectWhiteSpaceTokens adds whitespace tokens from HIDDEN channel
-func (a *Analyzer) collectWhitesSpaceTokens(expression string) []models.TokenInfo {
-	whiteSpaceTokens := make([]models.TokenInfo, 0)
-
-	for _, token := range a.collectAntlrTokens(expression) {
-		// Check if this is a WS token in HIDDEN channel
-		if token.GetChannel() == antlr.LexerHidden {
-			whiteSpaceTokens = append(whiteSpaceTokens, models.TokenInfo{
-				Type:   models.TokenWhitespace,
-				Text:   token.GetText(),
-				Start:  token.GetStart(),
-				End:    token.GetStop() + 1,
-				Line:   token.GetLine(),
-				Column: token.GetColumn(),
-			})
-		}
-	}
-	return whiteSpaceTokens
-}
-
-// collectErrorTokens collects ERROR_CHAR tokens from channel 2
-func (a *Analyzer) collectErrorTokens(expression string) []models.TokenInfo {
-	errorTokens := make([]models.TokenInfo, 0)
-
-	for _, token := range a.collectAntlrTokens(expression) {
-		// Check if this is an ERROR_CHAR token in channel 2
-		if token.GetChannel() == 2 {
-			errorTokens = append(errorTokens, models.TokenInfo{
-				Type:   models.TokenError,
-				Text:   token.GetText(),
-				Start:  token.GetStart(),
-				End:    token.GetStop() + 1,
-				Line:   token.GetLine(),
-				Column: token.GetColumn(),
-			})
+// collectTokens converts ANTLR tokens from all channels, in input order, to tokens of the Tokenize result.
+// Whitespace on the HIDDEN channel and ERROR_CHAR tokens on channel 2 are kept; a column reference
+// becomes its brackets and the name between them.
+func (a *Analyzer) collectTokens(antlrTokens []antlr.Token) []models.TokenInfo {
+	// Each column reference becomes three tokens
+	size := len(antlrTokens)
+	for _, token := range antlrTokens {
+		if token.GetTokenType() == parser.ExpressionLexerCOLUMN_REF {
+			size += 2
 		}
 	}
-	return errorTokens
-}
-
-// collectTokens extracts all tokens from the lexer including whitespace
-func (a *Analyzer) collectTokens(expression string, lexer *parser.ExpressionLexer) []models.TokenInfo {
-	tokens := make([]models.TokenInfo, 0)
+	tokens := make([]models.TokenInfo, 0, size)
 
-	for {
-		token := lexer.NextToken()
+	for _, token := range antlrTokens {
 		if token.GetTokenType() == antlr.TokenEOF {
 			// Add EOF token
 			tokens = append(tokens, models.TokenInfo{
@@ -171,14 +182,15 @@ func (a *Analyzer) collectTokens(expression string, lexer *parser.ExpressionLexe
 			break
 		}
 
-		// Skip tokens from HIDDEN channel and ERROR_CHAR channel (they'll be handled separately)
-		// channel(HIDDEN) = 1, channel(2) is for ERROR_CHAR
-		if token.GetChannel() == antlr.LexerHidden || token.GetChannel() == 2 {
-			continue
+		// channel(HIDDEN) = 1 is for whitespace, channel(2) is for ERROR_CHAR
+		tokenType := a.getTokenType(token.GetTokenType())
+		switch token.GetChannel() {
+		case antlr.LexerHidden:
+			tokenType = models.TokenWhitespace
+		case 2:
+			tokenType = models.TokenError
 		}
 
-		// Determine token type based on token type from lexer
-		tokenType := a.getTokenType(token.GetTokenType())
 		if tokenType == models.TokenColumnReference {
 			leftBracket := models.TokenInfo{
 				Type:   models.TokenLeftBracket,
@@ -202,7 +214,7 @@ func (a *Analyzer) collectTokens(expression string, lexer *parser.ExpressionLexe
 				Start:  token.GetStop(),
 				End:    token.GetStop() + 1,
 				Line:   token.GetLine(),
-				Column: token.GetColumn() + 1 + len(identifier.Text),
+				Column: token.GetColumn() + 1 + utf8.RuneCountInString(identifier.Text),
 			}
 			tokens = append(tokens, leftBracket, identifier, rightBracket)
 		} else {
@@ -218,17 +230,6 @@ func (a *Analyzer) collectTokens(expression string, lexer *parser.ExpressionLexe
 		}
 	}
 
-	// Also collect whitespace tokens from HIDDEN channel
-	tokens = append(tokens, a.collectWhitesSpaceTokens(expression)...)
-
-	// Also collect ERROR_CHAR tokens from channel 2
-	tokens = append(tokens, a.collectErrorTokens(expression)...)
-
-	// Sort tokens by position
-	sort.SliceStable(tokens, func(i, j int) bool {
-		return tokens[i].Start < tokens[j].Start
-	})
-
 	return tokens
 }
 
@@ -268,31 +269,110 @@ func (a *Analyzer) getTokenType(antlrTokenType int) models.TokenType {
 	}
 }
 
-// performSemanticValidation performs semantic validation on the parse tree
-func (a *Analyzer) performSemanticValidation(_ parser.IExpressionContext) []models.ErrorInfo {
-	// TODO after implementing type system. returns nil so far.
-	return nil
+// RegisterFunction makes a custom function available to expressions
+func (a *Analyzer) RegisterFunction(fn *functions.Function) error {
+	return a.registry.Register(fn)
+}
+
+// Functions returns the signatures of all functions that expressions may call, sorted by name
+func (a *Analyzer) Functions() []models.FunctionSignature {
+	registered := a.registry.Functions()
+	signatures := make([]models.FunctionSignature, len(registered))
+	for i, fn := range registered {
+		signatures[i] = fn.FunctionSignature
+	}
+	return signatures
+}
+
+// SetSchema sets the columns expressions may reference. A nil schema disables column checks.
+func (a *Analyzer) SetSchema(schema *models.Schema) {
+	a.schema = schema
+}
+
+// SetPositionEncoding sets the unit in which App counts positions in expressions
+func (a *Analyzer) SetPositionEncoding(encoding models.PositionEncoding) {
+	a.encoding = encoding
+}
+
+// positions returns the map converting the code point positions of the expression to the position encoding,
+// nil if they need no conversion
+func (a *Analyzer) positions(expression string) *models.PositionMap {
+	return models.NewPositionMap(expression, a.encoding)
+}
+
+// checkColumnReferences reports COLUMN_REF tokens that are not part of the schema.
+// Works on tokens rather than the parse tree so that unknown columns are flagged in incomplete expressions too.
+func checkColumnReferences(tokens []antlr.Token, schema *models.Schema) []models.ErrorInfo {
+	errors := make([]models.ErrorInfo, 0)
+	if schema == nil {
+		return errors
+	}
+
+	var names []string
+	for _, token := range tokens {
+		if token.GetTokenType() != parser.ExpressionLexerCOLUMN_REF {
+			continue
+		}
+		text := token.GetText()
+		name := text[1 : len(text)-1]
+		if _, ok := schema.Lookup(name); ok {
+			continue
+		}
+
+		if names == nil {
+			for _, column := range schema.Columns() {
+				names = append(names, column.Name)
+			}
+		}
+		message := "Unknown column: " + text
+		if suggestions := suggestNames(name, names); len(suggestions) > 0 {
+			message += ", did you mean " + formatSuggestions(suggestions) + "?"
+		}
+		errors = append(errors, models.ErrorInfo{
+			Message: message,
+			Line:    token.GetLine(),
+			Column:  token.GetColumn(),
+			Start:   token.GetStart(),
+			End:     token.GetStop() + 1,
+		})
+	}
+	return errors
+}
+
+// performSemanticValidation performs semantic validation on the parse tree with the columns of schema
+// and returns the inferred type of the expression
+func (a *Analyzer) performSemanticValidation(tree parser.IExpressionContext, schema *models.Schema) ([]models.ErrorInfo, models.DataType) {
+	if tree == nil {
+		return nil, models.DataTypeAny
+	}
+
+	visitor := typecheck.NewTypeCheckVisitor(a.registry, schema)
+	dataType := visitor.Visit(tree).(models.DataType)
+	return visitor.Errors(), dataType
 }
 
 // ParseTree creates a hierarchical parse tree from the expression.
 // Returns nil tree for empty expressions.
 // Parse errors result in partial trees that exclude unparseable tokens.
 func (a *Analyzer) ParseTree(expression string) *ParseTreeResult {
-	expressionTree, errors := a.parseExpression(expression)
+	return a.parseTree(expression, lexAndParse(a.helper, expression))
+}
 
+// parseTree builds the ParseTree result from the parsed expression
+func (a *Analyzer) parseTree(expression string, parsed *parsedExpression) *ParseTreeResult {
 	// Build the parse tree structure (even with errors to show partial results)
 	var parseTree *models.ParseTreeNode
 
-	if expressionTree != nil {
+	if parsed.tree != nil {
 		visitor := tree.NewParseTreeVisitor(expression)
-		result := visitor.Visit(expressionTree)
+		result := visitor.Visit(parsed.tree)
 		if node, ok := result.(*models.ParseTreeNode); ok {
 			// Wrap the concrete expression type with a root Expression node
 			parseTree = &models.ParseTreeNode{
 				Type:     models.NodeTypeExpression,
 				Text:     expression,
 				Start:    0,
-				End:      len(expression),
+				End:      len([]rune(expression)),
 				Children: []models.ParseTreeNode{*node},
 			}
 		}
@@ -300,29 +380,63 @@ func (a *Analyzer) ParseTree(expression string) *ParseTreeResult {
 
 	return &ParseTreeResult{
 		Tree:   parseTree,
-		Errors: errors,
+		Errors: slices.Clone(parsed.errors),
 	}
 }
 
-// Lint performs comprehensive linting on the expression, checking for syntax errors, invalid tokens, and semantic issues
-func (a *Analyzer) Lint(expression string) []models.ErrorInfo {
-	tree, errors := a.parseExpression(expression)
+// parseWithSyntaxErrors parses the expression and returns the parse tree together with
+// parse errors and errors for character sequences the lexer could not match
+func (a *Analyzer) parseWithSyntaxErrors(expression string) (parser.IExpressionContext, []models.ErrorInfo) {
+	parsed := lexAndParse(a.helper, expression)
+	return parsed.tree, a.syntaxErrors(parsed)
+}
 
-	errorTokens := a.collectErrorTokens(expression)
-	for _, token := range errorTokens {
+// syntaxErrors returns the parse errors of the parsed expression followed by
+// errors for character sequences the lexer could not match
+func (a *Analyzer) syntaxErrors(parsed *parsedExpression) []models.ErrorInfo {
+	errors := slices.Clone(parsed.errors)
+	for _, token := range parsed.tokens {
+		if token.GetChannel() != 2 {
+			continue
+		}
 		errors = append(errors, models.ErrorInfo{
-			Message: "Invalid character sequence: " + token.Text,
-			Line:    token.Line,
-			Column:  token.Column,
-			Start:   token.Start,
-			End:     token.End,
+			Message: "Invalid character sequence: " + token.GetText(),
+			Line:    token.GetLine(),
+			Column:  token.GetColumn(),
+			Start:   token.GetStart(),
+			End:     token.GetStop() + 1,
 		})
 	}
+	return errors
+}
+
+// Lint performs comprehensive linting on the expression, checking for syntax errors, invalid tokens, and semantic issues
+func (a *Analyzer) Lint(expression string) []models.ErrorInfo {
+	return a.lint(lexAndParse(a.helper, expression))
+}
 
-	errors = append(errors, a.performSemanticValidation(tree)...)
+// lint computes the Lint errors of the parsed expression
+func (a *Analyzer) lint(parsed *parsedExpression) []models.ErrorInfo {
+	errors, _ := a.lintWithSchema(parsed, a.schema)
 	return errors
 }
 
+// lintWithSchema computes the Lint errors of the parsed expression with the columns of schema instead of the
+// analyzer's, and the inferred type of the expression, any if it has syntax errors
+func (a *Analyzer) lintWithSchema(parsed *parsedExpression, schema *models.Schema) ([]models.ErrorInfo, models.DataType) {
+	errors := a.syntaxErrors(parsed)
+
+	syntaxErrorCount := len(errors)
+	errors = append(errors, checkColumnReferences(parsed.tokens, schema)...)
+
+	// Type errors on a partially parsed tree are mostly noise, so only check syntactically valid expressions
+	if syntaxErrorCount > 0 {
+		return errors, models.DataTypeAny
+	}
+	semanticErrors, dataType := a.performSemanticValidation(parsed.tree, schema)
+	return append(errors, semanticErrors...), dataType
+}
+
 // Tokenize performs detailed token analysis of the given expression string.
 // Returns all tokens from all channels including whitespace and error tokens that don't match any lexer rules.
 // The Errors field contains only parse errors, not lexical error tokens (which are included in Tokens).
@@ -337,12 +451,8 @@ func (a *Analyzer) Tokenize(expression string) *TokenizeResult {
 		}
 	}
 
-	// First, collect all tokens from the lexer (including whitespace)
-	lexer := a.helper.CreateLexer(expression)
-	tokens := a.collectTokens(expression, lexer)
-
 	return &TokenizeResult{
-		Tokens: tokens,
+		Tokens: a.collectTokens(a.collectAntlrTokens(expression)),
 		Errors: errors,
 	}
 }
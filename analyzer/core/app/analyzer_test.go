@@ -63,7 +63,11 @@ func TestAnalyzer_Validate(t *testing.T) {
 		{"function with string argument", "UPPER('hello')", true},
 		{"function with two arguments", "MAX([score1], [score2])", true},
 		{"function with three arguments", "CONCAT('Hello', ' ', 'World')", true},
-		{"function with three columns", "ADD([a], [b], [c])", true},
+		{"function with three columns", "COALESCE([a], [b], [c])", true},
+		{"unknown function", "ADD([a], [b], [c])", false},
+		{"too few arguments", "UPPER()", false},
+		{"too many arguments", "ROUND(1, 2, 3)", false},
+		{"wrong argument type", "UPPER(42)", false},
 		{"nested functions", "SUM(MAX([a], [b]))", true},
 		{"multiple nested functions", "CONCAT(UPPER([first]), LOWER([last]))", true},
 
@@ -1475,7 +1479,7 @@ func TestAnalyzer_LintAdvanced(t *testing.T) {
 			shouldFail bool
 			reason     string
 		}{
-			{"SUM()", false, "SUM with no args should be valid"},
+			{"COUNT()", false, "COUNT with no args should be valid"},
 			{"SUM(,)", true, "Empty argument should fail"},
 			{"SUM([a],)", true, "Trailing comma should fail"},
 			{"SUM(,[a])", true, "Leading comma should fail"},
@@ -1528,6 +1532,290 @@ func TestAnalyzer_LintAdvanced(t *testing.T) {
 	})
 }
 
+func TestAnalyzer_Lint_TypeErrors(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   []models.ErrorInfo
+	}{
+		// Well-typed expressions
+		{"arithmetic on numbers", "1 + 2 * 3 ^ 4 / 5", nil},
+		{"logical on comparisons", "1 < 2 && 'a' == 'b' || [c]", nil},
+		{"string ordering", "'a' < 'b'", nil},
+		{"boolean equality", "true == false", nil},
+		{"columns are compatible with every type", "[name] * 3 > [limit] && [flag]", nil},
+		{"negated number", "-(1 + 2)", nil},
+
+		// Mismatches point at the offending operand
+		{
+			"string in multiplication", `"abc" * 3`,
+			[]models.ErrorInfo{{Message: "Operator '*' expects number operands, got string", Line: 1, Column: 0, Start: 0, End: 5}},
+		},
+		{
+			"number in logical AND", "[a] && 5",
+			[]models.ErrorInfo{{Message: "Operator '&&' expects boolean operands, got number", Line: 1, Column: 7, Start: 7, End: 8}},
+		},
+		{
+			"boolean in addition", "1 + (2 > 1)",
+			[]models.ErrorInfo{{Message: "Operator '+' expects number operands, got boolean", Line: 1, Column: 4, Start: 4, End: 11}},
+		},
+		{
+			"negated string", "-'abc'",
+			[]models.ErrorInfo{{Message: "Operator '-' expects number operands, got string", Line: 1, Column: 1, Start: 1, End: 6}},
+		},
+		{
+			"comparing number with string", "1 == 'one'",
+			[]models.ErrorInfo{{Message: "Cannot compare number with string using '=='", Line: 1, Column: 5, Start: 5, End: 10}},
+		},
+		{
+			"ordering booleans", "true < 1",
+			[]models.ErrorInfo{{Message: "Operator '<' cannot be applied to boolean operands", Line: 1, Column: 0, Start: 0, End: 4}},
+		},
+		{
+			"comparison result used as number", "([a] > 1) ^ 2",
+			[]models.ErrorInfo{{Message: "Operator '^' expects number operands, got boolean", Line: 1, Column: 0, Start: 0, End: 9}},
+		},
+		{
+			"errors inside function arguments", "MAX('a' / 2, 1)",
+			[]models.ErrorInfo{{Message: "Operator '/' expects number operands, got string", Line: 1, Column: 4, Start: 4, End: 7}},
+		},
+		{
+			"multiple mismatches", "'a' - true",
+			[]models.ErrorInfo{
+				{Message: "Operator '-' expects number operands, got string", Line: 1, Column: 0, Start: 0, End: 3},
+				{Message: "Operator '-' expects number operands, got boolean", Line: 1, Column: 6, Start: 6, End: 10},
+			},
+		},
+		{
+			"multiline position", "1 +\n  'x'",
+			[]models.ErrorInfo{{Message: "Operator '+' expects number operands, got string", Line: 2, Column: 2, Start: 6, End: 9}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errors := analyzer.Lint(tc.expression)
+			if len(tc.expected) == 0 {
+				if len(errors) != 0 {
+					t.Errorf("Expected no errors for '%s', got %v", tc.expression, errors)
+				}
+				return
+			}
+			if !reflect.DeepEqual(errors, tc.expected) {
+				t.Errorf("Lint(%q) = %v, want %v", tc.expression, errors, tc.expected)
+			}
+		})
+	}
+
+	t.Run("type errors are not reported for syntactically invalid expressions", func(t *testing.T) {
+		errors := analyzer.Lint("'a' * 2 +")
+		for _, err := range errors {
+			if err.Message == "Operator '*' expects number operands, got string" {
+				t.Errorf("Unexpected type error on invalid expression: %v", errors)
+			}
+		}
+	})
+}
+
+func TestAnalyzer_Lint_FunctionErrors(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   []models.ErrorInfo
+	}{
+		// Valid calls
+		{"exact arity", "SUBSTRING('hello', 1, 2)", nil},
+		{"optional parameter omitted", "ROUND(3.14)", nil},
+		{"optional parameter given", "ROUND(3.14, 1)", nil},
+		{"variadic tail", "CONCAT('a', 'b', 'c', [d])", nil},
+		{"no arguments", "NOW()", nil},
+		{"return type flows into operators", "LENGTH([name]) + YEAR(NOW()) > 2000", nil},
+		{"any typed parameters", "IF([a] > 1, 'x', 2)", nil},
+
+		// Unknown functions are reported on the whole call
+		{
+			"unknown function", "FOO(1, 2)",
+			[]models.ErrorInfo{{Message: "Unknown function: FOO", Line: 1, Column: 0, Start: 0, End: 9}},
+		},
+		{
+			"unknown nested function", "UPPER(BAR())",
+			[]models.ErrorInfo{{Message: "Unknown function: BAR", Line: 1, Column: 6, Start: 6, End: 11}},
+		},
+
+		// Arity errors are reported on the argument list, or the call when it has no arguments
+		{
+			"missing argument", "UPPER()",
+			[]models.ErrorInfo{{Message: "Function UPPER expects 1 argument, got 0", Line: 1, Column: 0, Start: 0, End: 7}},
+		},
+		{
+			"too many arguments", "UPPER('a', 'b')",
+			[]models.ErrorInfo{{Message: "Function UPPER expects 1 argument, got 2", Line: 1, Column: 6, Start: 6, End: 14}},
+		},
+		{
+			"outside optional range", "ROUND(1, 2, 3)",
+			[]models.ErrorInfo{{Message: "Function ROUND expects 1 to 2 arguments, got 3", Line: 1, Column: 6, Start: 6, End: 13}},
+		},
+		{
+			"variadic minimum", "SUM()",
+			[]models.ErrorInfo{{Message: "Function SUM expects at least 1 argument, got 0", Line: 1, Column: 0, Start: 0, End: 5}},
+		},
+		{
+			"unexpected argument", "NOW(1)",
+			[]models.ErrorInfo{{Message: "Function NOW expects 0 arguments, got 1", Line: 1, Column: 4, Start: 4, End: 5}},
+		},
+
+		// Argument type errors are reported on the argument
+		{
+			"wrong argument type", "UPPER(42)",
+			[]models.ErrorInfo{{Message: "Argument 'text' of UPPER expects string, got number", Line: 1, Column: 6, Start: 6, End: 8}},
+		},
+		{
+			"wrong variadic argument type", "SUM(1, 'two', 3)",
+			[]models.ErrorInfo{{Message: "Argument 'number' of SUM expects number, got string", Line: 1, Column: 7, Start: 7, End: 12}},
+		},
+		{
+			"wrong return type used as argument", "ABS(UPPER('a'))",
+			[]models.ErrorInfo{{Message: "Argument 'number' of ABS expects number, got string", Line: 1, Column: 4, Start: 4, End: 14}},
+		},
+		{
+			"non-boolean condition", "IF(1, 2, 3)",
+			[]models.ErrorInfo{{Message: "Argument 'condition' of IF expects boolean, got number", Line: 1, Column: 3, Start: 3, End: 4}},
+		},
+		{
+			"return type mismatch in operator", "UPPER('a') * 2",
+			[]models.ErrorInfo{{Message: "Operator '*' expects number operands, got string", Line: 1, Column: 0, Start: 0, End: 10}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errors := analyzer.Lint(tc.expression)
+			if len(tc.expected) == 0 {
+				if len(errors) != 0 {
+					t.Errorf("Expected no errors for '%s', got %v", tc.expression, errors)
+				}
+				return
+			}
+			if !reflect.DeepEqual(errors, tc.expected) {
+				t.Errorf("Lint(%q) = %v, want %v", tc.expression, errors, tc.expected)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_Lint_Schema(t *testing.T) {
+	analyzer := newAnalyzer()
+	analyzer.SetSchema(models.NewSchema([]models.Column{
+		{Name: "price", Type: models.DataTypeNumber, Description: "Unit price"},
+		{Name: "prices", Type: models.DataTypeNumber},
+		{Name: "quantity", Type: models.DataTypeNumber},
+		{Name: "name", Type: models.DataTypeString},
+		{Name: "active", Type: models.DataTypeBoolean},
+		{Name: "notes"},
+	}))
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   []models.ErrorInfo
+	}{
+		// Known columns
+		{"known columns", "[price] * [quantity] > 100 && [active]", nil},
+		{"untyped column accepts any use", "[notes] * 2 > 1 && UPPER([notes]) == 'X'", nil},
+		{"column types flow into functions", "LENGTH(TRIM([name])) > 3", nil},
+
+		// Unknown columns
+		{
+			"unknown column with suggestion", "[pricee] * 2",
+			[]models.ErrorInfo{{Message: "Unknown column: [pricee], did you mean [price] or [prices]?", Line: 1, Column: 0, Start: 0, End: 8}},
+		},
+		{
+			"suggestion ignores case", "[Quantity] + 1",
+			[]models.ErrorInfo{{Message: "Unknown column: [Quantity], did you mean [quantity]?", Line: 1, Column: 0, Start: 0, End: 10}},
+		},
+		{
+			"unknown column without suggestion", "[discount] + 1",
+			[]models.ErrorInfo{{Message: "Unknown column: [discount]", Line: 1, Column: 0, Start: 0, End: 10}},
+		},
+		{
+			"unknown column in incomplete expression", "[nmae] +",
+			[]models.ErrorInfo{
+				{Message: "mismatched input '<EOF>' expecting {'-', '(', BOOLEAN_LITERAL, FLOAT_LITERAL, INTEGER_LITERAL, STRING_LITERAL, FUNCTION_NAME, COLUMN_REF}", Line: 1, Column: 8, Start: 8, End: 8},
+				{Message: "Unknown column: [nmae], did you mean [name]?", Line: 1, Column: 0, Start: 0, End: 6},
+			},
+		},
+
+		// Declared column types are used by the type checker
+		{
+			"string column in arithmetic", "[name] * 2",
+			[]models.ErrorInfo{{Message: "Operator '*' expects number operands, got string", Line: 1, Column: 0, Start: 0, End: 6}},
+		},
+		{
+			"number column as condition", "IF([price], 1, 2)",
+			[]models.ErrorInfo{{Message: "Argument 'condition' of IF expects boolean, got number", Line: 1, Column: 3, Start: 3, End: 10}},
+		},
+		{
+			"unknown column and type error", "[price] == 'x' && [flag]",
+			[]models.ErrorInfo{
+				{Message: "Unknown column: [flag]", Line: 1, Column: 18, Start: 18, End: 24},
+				{Message: "Cannot compare number with string using '=='", Line: 1, Column: 11, Start: 11, End: 14},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errors := analyzer.Lint(tc.expression)
+			if len(tc.expected) == 0 {
+				if len(errors) != 0 {
+					t.Errorf("Expected no errors for '%s', got %v", tc.expression, errors)
+				}
+				return
+			}
+			if !reflect.DeepEqual(errors, tc.expected) {
+				t.Errorf("Lint(%q) = %v, want %v", tc.expression, errors, tc.expected)
+			}
+		})
+	}
+
+	t.Run("nil schema accepts any column", func(t *testing.T) {
+		analyzer := newAnalyzer()
+		analyzer.SetSchema(nil)
+		if errors := analyzer.Lint("[anything] + 1"); len(errors) != 0 {
+			t.Errorf("Expected no errors without schema, got %v", errors)
+		}
+	})
+}
+
+func TestSuggestNames(t *testing.T) {
+	candidates := []string{"price", "prices", "quantity", "name", "amount"}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"single typo", "quantiy", []string{"quantity"}},
+		{"closest first", "pricee", []string{"price", "prices"}},
+		{"case insensitive", "NAME", []string{"name"}},
+		{"too far", "total", []string{}},
+		{"short names allow one edit", "nam", []string{"name"}},
+		{"transposition counts as one edit", "nmae", []string{"name"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := suggestNames(tc.input, candidates); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("suggestNames(%q) = %v, want %v", tc.input, got, tc.expected)
+			}
+		})
+	}
+}
+
 // Helper functions for tests
 func getTreeDepth(node *models.ParseTreeNode) int {
 	if node == nil || len(node.Children) == 0 {
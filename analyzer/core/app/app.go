@@ -1,7 +1,11 @@
 package app
 
 import (
+	"fmt"
+
 	"antlr-editor/analyzer/core/app/formatter"
+	"antlr-editor/analyzer/core/app/functions"
+	"antlr-editor/analyzer/core/app/sqlgen"
 	"antlr-editor/analyzer/core/models"
 )
 
@@ -20,19 +24,113 @@ func NewApp() *App {
 
 // ParseTree builds a hierarchical parse tree from the expression
 func (app *App) ParseTree(expression string) *ParseTreeResult {
-	return app.analyzer.ParseTree(expression)
+	result := app.analyzer.ParseTree(expression)
+	positions := app.analyzer.positions(expression)
+	positions.ParseTree(result.Tree)
+	positions.Errors(result.Errors)
+	return result
+}
+
+// ParseAST parses the expression into a typed abstract syntax tree; print it back with models.Print
+func (app *App) ParseAST(expression string) *ASTResult {
+	result := app.analyzer.ParseAST(expression)
+	positions := app.analyzer.positions(expression)
+	positions.AST(result.AST)
+	positions.Errors(result.Errors)
+	return result
+}
+
+// ParseCST parses the expression into a lossless concrete syntax tree whose String method returns exactly the expression
+func (app *App) ParseCST(expression string) *CSTResult {
+	result := app.analyzer.ParseCST(expression)
+	positions := app.analyzer.positions(expression)
+	positions.CST(result.Tree)
+	positions.Errors(result.Errors)
+	return result
+}
+
+// Rewrite parses the expression, transforms its abstract syntax tree with models.Rewrite and formats the result.
+// The callback can replace, wrap or delete nodes; see models.Rewriter. The layout of the input is not kept:
+// the result is printed with the minimal parentheses and formatted with the default options.
+func (app *App) Rewrite(expression string, rewrite models.Rewriter) *RewriteResult {
+	if expression == "" {
+		return rewriteFailure("Empty expression")
+	}
+	parsed := app.analyzer.ParseAST(expression)
+	if len(parsed.Errors) > 0 {
+		return &RewriteResult{Errors: app.analyzer.positions(expression).Errors(parsed.Errors)}
+	}
+
+	rewritten := models.Rewrite(parsed.AST, rewrite)
+	if rewritten == nil {
+		return rewriteFailure("Rewrite deleted the entire expression")
+	}
+	source, err := app.formatter.FormatAST(rewritten)
+	if err != nil {
+		return rewriteFailure("Rewritten expression cannot be printed: " + err.Error())
+	}
+	return &RewriteResult{Expression: source, Errors: []models.ErrorInfo{}}
+}
+
+// SourceFromJSON regenerates canonical expression source from a JSON tree, such as one edited by a visual tool.
+// The document is a parse tree as serialized by ParseTreeNode.AsMap or an abstract syntax tree as serialized
+// by Expr.AsMap, optionally wrapped in its ParseTreeResult or ASTResult. The tree is validated, printed with
+// the minimal parentheses and formatted with the default options; the layout of the original source is not kept.
+// A malformed tree yields an error naming the path of the offending node, e.g. "tree.children[0]".
+// Positions in the tree are read in the position encoding of the App, as ParseTree writes them.
+func (app *App) SourceFromJSON(data []byte) *TranspileResult {
+	expr, err := decodeTreeDocument(data, app.analyzer.encoding)
+	if err != nil {
+		return sourceFailure("Invalid tree: " + err.Error())
+	}
+	source, err := app.formatter.FormatAST(expr)
+	if err != nil {
+		return sourceFailure("Tree cannot be printed: " + err.Error())
+	}
+	return &TranspileResult{Code: source, Errors: []models.ErrorInfo{}}
+}
+
+// RenameColumn renames the references to a column, keeping the layout of the expression and string literals unchanged
+func (app *App) RenameColumn(expression, oldName, newName string) *RenameResult {
+	result := app.analyzer.RenameColumn(expression, oldName, newName)
+	positions := app.analyzer.positions(expression)
+	for i, edit := range result.Edits {
+		result.Edits[i] = positions.Edit(edit)
+	}
+	positions.Errors(result.Errors)
+	return result
+}
+
+// References returns each distinct column and function the expression uses with all their occurrences,
+// and whether the expression parsed completely
+func (app *App) References(expression string) *ReferencesResult {
+	result := app.analyzer.References(expression)
+	positions := app.analyzer.positions(expression)
+	for _, references := range [][]models.Reference{result.Columns, result.Functions} {
+		for _, reference := range references {
+			for i, span := range reference.Occurrences {
+				reference.Occurrences[i] = positions.Span(span)
+			}
+		}
+	}
+	positions.Errors(result.Errors)
+	return result
 }
 
 // Lint performs comprehensive linting on the expression, checking for syntax errors, invalid tokens, and semantic issues
 func (app *App) Lint(expression string) []models.ErrorInfo {
-	return app.analyzer.Lint(expression)
+	return app.analyzer.positions(expression).Errors(app.analyzer.Lint(expression))
 }
 
 // Tokenize performs detailed token analysis of the given expression string.
 // Returns all tokens from all channels including whitespace and error tokens that don't match any lexer rules.
 // The Errors field contains only parse errors, not lexical error tokens (which are included in Tokens).
 func (app *App) Tokenize(expression string) *TokenizeResult {
-	return app.analyzer.Tokenize(expression)
+	result := app.analyzer.Tokenize(expression)
+	positions := app.analyzer.positions(expression)
+	positions.Tokens(result.Tokens)
+	positions.Errors(result.Errors)
+	return result
 }
 
 // Validate checks if the given expression string has valid syntax.
@@ -41,6 +139,76 @@ func (app *App) Validate(expression string) bool {
 	return app.analyzer.Validate(expression)
 }
 
+// Evaluate computes the value of the expression for a row of column values keyed by column name
+func (app *App) Evaluate(expression string, row map[string]any) *EvaluateResult {
+	result := app.analyzer.Evaluate(expression, row)
+	app.analyzer.positions(expression).Errors(result.Errors)
+	return result
+}
+
+// Compile parses and compiles the expression once for evaluation across many rows
+func (app *App) Compile(expression string) (*CompiledExpression, []models.ErrorInfo) {
+	compiled, errors := app.analyzer.Compile(expression)
+	positions := app.analyzer.positions(expression)
+	if compiled != nil {
+		compiled.positions = positions
+	}
+	return compiled, positions.Errors(errors)
+}
+
+// ToSQL translates the expression to a SQL expression in the given dialect, such as sqlgen.SQLite
+func (app *App) ToSQL(expression string, dialect *sqlgen.Dialect) *TranspileResult {
+	result := app.analyzer.ToSQL(expression, dialect)
+	app.analyzer.positions(expression).Errors(result.Errors)
+	return result
+}
+
+// ToJavaScript translates the expression to the source of a JavaScript function `(row) => value`
+func (app *App) ToJavaScript(expression string) *TranspileResult {
+	result := app.analyzer.ToJavaScript(expression)
+	app.analyzer.positions(expression).Errors(result.Errors)
+	return result
+}
+
+// ToPandas translates the expression to a vectorized pandas expression over a DataFrame named df
+func (app *App) ToPandas(expression string) *TranspileResult {
+	result := app.analyzer.ToPandas(expression)
+	app.analyzer.positions(expression).Errors(result.Errors)
+	return result
+}
+
+// RegisterFunction registers a custom function on this App.
+// Lint and Validate then check calls against its signature, and Evaluate calls its implementation;
+// a function without an implementation can be validated but not evaluated.
+// Built-in functions cannot be replaced; registering a custom function twice replaces it. The App keeps a copy,
+// so the definition may be registered in other Apps or changed afterwards.
+func (app *App) RegisterFunction(fn *functions.Function) error {
+	return app.analyzer.RegisterFunction(fn)
+}
+
+// Functions returns the signatures and documentation of all callable functions, sorted by name
+func (app *App) Functions() []models.FunctionSignature {
+	return app.analyzer.Functions()
+}
+
+// SetPositionEncoding sets the unit in which positions in expressions are counted, both in results such as
+// tokens, errors, trees and edits, and in cursor offsets passed to Complete, SignatureHelp and NodeAt.
+// Positions are code points by default; an empty encoding restores the default.
+func (app *App) SetPositionEncoding(encoding models.PositionEncoding) error {
+	if encoding != "" && !encoding.IsValid() {
+		return fmt.Errorf("unknown position encoding %q, expected %q, %q or %q",
+			encoding, models.PositionEncodingRune, models.PositionEncodingUTF16, models.PositionEncodingByte)
+	}
+	app.analyzer.SetPositionEncoding(encoding)
+	return nil
+}
+
+// SetSchema sets the columns that expressions may reference.
+// Lint and Validate then report unknown columns and use the declared column types; pass nil to accept any column.
+func (app *App) SetSchema(schema *models.Schema) {
+	app.analyzer.SetSchema(schema)
+}
+
 // Format formats the given expression string using default formatting options
 func (app *App) Format(expression string) string {
 	return app.formatter.Format(expression)
@@ -50,3 +218,31 @@ func (app *App) Format(expression string) string {
 func (app *App) FormatWithOptions(expression string, options *formatter.FormatOptions) string {
 	return NewFormatterWithOptions(options).Format(expression)
 }
+
+// Complete suggests the columns, functions, literals, operators and punctuation the grammar allows at the cursor offset
+func (app *App) Complete(expression string, offset int) *CompletionResult {
+	positions := app.analyzer.positions(expression)
+	result := app.analyzer.Complete(expression, positions.CodePoint(offset))
+	for i := range result.Items {
+		result.Items[i].Edit = positions.Edit(result.Items[i].Edit)
+	}
+	return result
+}
+
+// SignatureHelp returns the signature of the function call enclosing the cursor offset and the argument the cursor is in
+func (app *App) SignatureHelp(expression string, offset int) *SignatureHelpResult {
+	return app.analyzer.SignatureHelp(expression, app.analyzer.positions(expression).CodePoint(offset))
+}
+
+// NodeAt returns the parse tree node at the cursor offset with its ancestors, inferred type and documentation
+func (app *App) NodeAt(expression string, offset int) *NodeAtResult {
+	positions := app.analyzer.positions(expression)
+	result := app.analyzer.NodeAt(expression, positions.CodePoint(offset))
+	if result.Node != nil {
+		*result.Node = positions.Node(*result.Node)
+	}
+	for i, ancestor := range result.Ancestors {
+		result.Ancestors[i] = positions.Node(ancestor)
+	}
+	return result
+}
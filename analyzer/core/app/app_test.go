@@ -0,0 +1,165 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/app/functions"
+	"antlr-editor/analyzer/core/models"
+)
+
+func TestApp_RegisterFunction(t *testing.T) {
+	app := NewApp()
+	rates := map[string]float64{"EUR": 1.1, "JPY": 0.0067}
+
+	require.NoError(t, app.RegisterFunction(&functions.Function{
+		FunctionSignature: models.FunctionSignature{
+			Name:        "FX_RATE",
+			Description: "Returns the USD exchange rate of a currency.",
+			Parameters:  []models.Parameter{{Name: "currency", Type: models.DataTypeString}},
+			ReturnType:  models.DataTypeNumber,
+		},
+		Impl: func(args functions.Arguments) (models.Value, error) {
+			currency, _ := args.At(0)
+			rate, ok := rates[currency.Str()]
+			if !ok {
+				return models.NullValue(), &functions.ArgumentError{Index: 0, Message: "Unknown currency: " + currency.Str()}
+			}
+			return models.NumberValue(rate), nil
+		},
+	}))
+	require.NoError(t, app.RegisterFunction(&functions.Function{
+		FunctionSignature: models.FunctionSignature{
+			Name:       "FISCAL_QUARTER",
+			Parameters: []models.Parameter{{Name: "date", Type: models.DataTypeDateTime}},
+			ReturnType: models.DataTypeNumber,
+		},
+	}))
+
+	t.Run("calls are validated against the signature", func(t *testing.T) {
+		assert.True(t, app.Validate("[amount] * FX_RATE('EUR') > 100"))
+		assert.Equal(t, []models.ErrorInfo{
+			{Message: "Function FX_RATE expects 1 argument, got 2", Line: 1, Column: 8, Start: 8, End: 20},
+		}, app.Lint("FX_RATE('EUR', 'USD')"))
+		assert.Equal(t, []models.ErrorInfo{
+			{Message: "Argument 'currency' of FX_RATE expects string, got number", Line: 1, Column: 8, Start: 8, End: 9},
+		}, app.Lint("FX_RATE(1)"))
+		assert.Equal(t, []models.ErrorInfo{
+			{Message: "Operator '&&' expects boolean operands, got number", Line: 1, Column: 8, Start: 8, End: 29},
+		}, app.Lint("true && FISCAL_QUARTER(NOW())"))
+	})
+
+	t.Run("implementation is used by Evaluate", func(t *testing.T) {
+		result := app.Evaluate("ROUND([amount] * FX_RATE([currency]), 2)", map[string]any{"amount": 20, "currency": "EUR"})
+		assert.Empty(t, result.Errors)
+		assert.True(t, models.NumberValue(22).Equal(result.Value), "got %v", result.Value)
+	})
+
+	t.Run("implementation errors are located on the argument", func(t *testing.T) {
+		result := app.Evaluate("FX_RATE('XXX')", nil)
+		assert.Equal(t, []models.ErrorInfo{
+			{Message: "Unknown currency: XXX", Line: 1, Column: 8, Start: 8, End: 13},
+		}, result.Errors)
+	})
+
+	t.Run("function without implementation cannot be evaluated", func(t *testing.T) {
+		result := app.Evaluate("FISCAL_QUARTER(NOW())", nil)
+		assert.Equal(t, []models.ErrorInfo{
+			{Message: "Function FISCAL_QUARTER cannot be evaluated", Line: 1, Column: 0, Start: 0, End: 21},
+		}, result.Errors)
+	})
+
+	t.Run("registered functions are listed", func(t *testing.T) {
+		var names []string
+		for _, signature := range app.Functions() {
+			names = append(names, signature.Name)
+		}
+		assert.Contains(t, names, "FX_RATE")
+		assert.Contains(t, names, "FISCAL_QUARTER")
+	})
+
+	t.Run("registrations are per App", func(t *testing.T) {
+		assert.False(t, NewApp().Validate("FX_RATE('EUR')"))
+	})
+}
+
+func TestApp_RegisterFunction_ReturnType(t *testing.T) {
+	app := NewApp()
+	require.NoError(t, app.RegisterFunction(&functions.Function{
+		FunctionSignature: models.FunctionSignature{Name: "BROKEN", ReturnType: models.DataTypeNumber},
+		Impl: func(args functions.Arguments) (models.Value, error) {
+			return models.StringValue("oops"), nil
+		},
+	}))
+
+	result := app.Evaluate("BROKEN()", nil)
+	assert.Equal(t, []models.ErrorInfo{
+		{Message: "Function BROKEN returned string, expected number", Line: 1, Column: 0, Start: 0, End: 8},
+	}, result.Errors)
+}
+
+func TestApp_RegisterFunction_CopiesDefinition(t *testing.T) {
+	fn := &functions.Function{
+		FunctionSignature: models.FunctionSignature{
+			Name:       "SCALE",
+			Parameters: []models.Parameter{{Name: "value"}},
+			Variadic:   &models.Parameter{Name: "factor"},
+		},
+	}
+	first, second := NewApp(), NewApp()
+	require.NoError(t, first.RegisterFunction(fn))
+	require.NoError(t, second.RegisterFunction(fn))
+
+	assert.Empty(t, fn.ReturnType, "defaults are not written into the caller's definition")
+	assert.Empty(t, fn.Parameters[0].Type)
+	assert.Empty(t, fn.Variadic.Type)
+
+	fn.Parameters[0].Type = models.DataTypeString
+	fn.Variadic.Type = models.DataTypeString
+	fn.ReturnType = models.DataTypeString
+	for _, app := range []*App{first, second} {
+		registered, ok := app.analyzer.registry.Lookup("SCALE")
+		require.True(t, ok)
+		assert.Equal(t, models.DataTypeAny, registered.ReturnType, "changes after registration do not reach the registry")
+		assert.Equal(t, models.DataTypeAny, registered.Parameters[0].Type)
+		assert.Equal(t, models.DataTypeAny, registered.Variadic.Type)
+	}
+	firstFn, _ := first.analyzer.registry.Lookup("SCALE")
+	secondFn, _ := second.analyzer.registry.Lookup("SCALE")
+	assert.NotSame(t, firstFn, secondFn, "apps do not share a definition")
+}
+
+func TestApp_RegisterFunction_Invalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		function *functions.Function
+		expected string
+	}{
+		{"nil function", nil, "function name must not be empty"},
+		{"lowercase name", &functions.Function{FunctionSignature: models.FunctionSignature{Name: "fx"}}, "invalid function name"},
+		{"leading digit", &functions.Function{FunctionSignature: models.FunctionSignature{Name: "1X"}}, "invalid function name"},
+		{"built-in", &functions.Function{FunctionSignature: models.FunctionSignature{Name: "UPPER"}}, "cannot be replaced"},
+		{
+			"unknown parameter type",
+			&functions.Function{FunctionSignature: models.FunctionSignature{Name: "F", Parameters: []models.Parameter{{Name: "x", Type: "money"}}}},
+			`unknown type "money"`,
+		},
+		{
+			"required after optional",
+			&functions.Function{FunctionSignature: models.FunctionSignature{Name: "F", Parameters: []models.Parameter{{Name: "a", Optional: true}, {Name: "b"}}}},
+			"follows an optional parameter",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewApp().RegisterFunction(tc.function)
+			require.Error(t, err)
+			assert.True(t, strings.Contains(err.Error(), tc.expected), fmt.Sprintf("error %q does not contain %q", err, tc.expected))
+		})
+	}
+}
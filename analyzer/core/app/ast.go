@@ -0,0 +1,68 @@
+package app
+
+import (
+	"antlr-editor/analyzer/core/app/ast"
+	"antlr-editor/analyzer/core/models"
+)
+
+// ASTResult represents the result of parsing an expression into an abstract syntax tree
+type ASTResult struct {
+	AST    models.Expr        `json:"ast"`    // Root of the tree, nil if the expression is empty or has syntax errors
+	Errors []models.ErrorInfo `json:"errors"` // Syntax errors
+}
+
+// AsMap converts ASTResult to a map for JSON serialization
+func (r *ASTResult) AsMap() map[string]any {
+	var astMap map[string]any
+	if r.AST != nil {
+		astMap = r.AST.AsMap()
+	}
+
+	errors := make([]any, len(r.Errors))
+	for i, err := range r.Errors {
+		errors[i] = err.AsMap()
+	}
+
+	return map[string]any{
+		"ast":    astMap,
+		"errors": errors,
+	}
+}
+
+// ParseAST parses the expression into a typed abstract syntax tree independent of ANTLR.
+// Unlike ParseTree, no tree is built for an expression with syntax errors, so every node of a returned tree
+// is complete. models.Print turns the tree, or one built with the models builder functions, back into source.
+func (a *Analyzer) ParseAST(expression string) *ASTResult {
+	if expression == "" {
+		return &ASTResult{Errors: []models.ErrorInfo{}}
+	}
+
+	tree, errors := a.parseWithSyntaxErrors(expression)
+	if len(errors) > 0 || tree == nil {
+		return &ASTResult{Errors: errors}
+	}
+	return &ASTResult{AST: ast.NewASTVisitor().Build(tree), Errors: []models.ErrorInfo{}}
+}
+
+// RewriteResult represents the result of rewriting an expression
+type RewriteResult struct {
+	Expression string             `json:"expression"` // Formatted rewritten expression, empty if there are errors
+	Errors     []models.ErrorInfo `json:"errors"`     // Syntax errors, or why the rewritten tree has no source form
+}
+
+// AsMap converts RewriteResult to a map for JSON serialization
+func (r *RewriteResult) AsMap() map[string]any {
+	errors := make([]any, len(r.Errors))
+	for i, err := range r.Errors {
+		errors[i] = err.AsMap()
+	}
+	return map[string]any{
+		"expression": r.Expression,
+		"errors":     errors,
+	}
+}
+
+// rewriteFailure creates a RewriteResult for an error that has no position in the expression
+func rewriteFailure(message string) *RewriteResult {
+	return &RewriteResult{Errors: []models.ErrorInfo{{Message: message, Line: 1, Column: 0, Start: 0, End: 0}}}
+}
@@ -0,0 +1,125 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+// Decode validates an abstract syntax tree serialized by Expr.AsMap and decoded from JSON, and rebuilds it.
+// Positions are optional and ignored. Node kinds, literal values, operators and names are checked,
+// so a decoded tree can be printed with models.Print. Errors name the path of the offending node.
+func Decode(node map[string]any) (models.Expr, error) {
+	return decodeNode(node, "ast")
+}
+
+// decodeNode rebuilds the node at path
+func decodeNode(node map[string]any, path string) (models.Expr, error) {
+	kind, ok := node["node"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%s: node must have a string node kind", path)
+	}
+
+	switch kind {
+	case "literal":
+		return decodeLiteral(node, path)
+
+	case "column":
+		name, ok := node["name"].(string)
+		if !ok || !models.IsColumnName(name) {
+			return nil, fmt.Errorf("%s: column name must be a non-empty string without brackets or whitespace", path)
+		}
+		return models.ColumnReference(name), nil
+
+	case "call":
+		name, ok := node["name"].(string)
+		if !ok || !models.IsFunctionName(name) {
+			return nil, fmt.Errorf("%s: function name %s", path, models.FunctionNameRule)
+		}
+		list, ok := node["args"].([]any)
+		if !ok && node["args"] != nil {
+			return nil, fmt.Errorf("%s: args must be an array", path)
+		}
+		args := make([]models.Expr, len(list))
+		for i, item := range list {
+			arg, err := child(item, fmt.Sprintf("%s.args[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			args[i] = arg
+		}
+		return models.FunctionCall(name, args...), nil
+
+	case "unary":
+		if op, _ := node["op"].(string); models.Operator(op) != models.OpSub {
+			return nil, fmt.Errorf("%s: unary operator must be %q, got %q", path, models.OpSub, node["op"])
+		}
+		operand, err := child(node["operand"], path+".operand")
+		if err != nil {
+			return nil, err
+		}
+		return models.Negate(operand), nil
+
+	case "binary":
+		op, _ := node["op"].(string)
+		if models.Operator(op).Precedence() == 0 {
+			return nil, fmt.Errorf("%s: invalid binary operator %q", path, node["op"])
+		}
+		left, err := child(node["left"], path+".left")
+		if err != nil {
+			return nil, err
+		}
+		right, err := child(node["right"], path+".right")
+		if err != nil {
+			return nil, err
+		}
+		return models.Binary(models.Operator(op), left, right), nil
+
+	case "paren":
+		inner, err := child(node["inner"], path+".inner")
+		if err != nil {
+			return nil, err
+		}
+		return &models.ParenExpr{Inner: inner}, nil
+	}
+	return nil, fmt.Errorf("%s: unknown node kind %q", path, kind)
+}
+
+// decodeLiteral rebuilds a literal, checking that its value matches its kind
+func decodeLiteral(node map[string]any, path string) (models.Expr, error) {
+	kind, _ := node["kind"].(string)
+	value := node["value"]
+
+	switch models.LiteralKind(kind) {
+	case models.LiteralNumber:
+		switch number := value.(type) {
+		case json.Number:
+			if n, err := number.Float64(); err == nil {
+				return models.NumberLiteral(n), nil
+			}
+		case float64:
+			return models.NumberLiteral(number), nil
+		}
+	case models.LiteralString:
+		if s, ok := value.(string); ok {
+			return models.StringLiteral(s), nil
+		}
+	case models.LiteralBoolean:
+		if b, ok := value.(bool); ok {
+			return models.BooleanLiteral(b), nil
+		}
+	default:
+		return nil, fmt.Errorf("%s: literal kind must be number, string or boolean, got %q", path, node["kind"])
+	}
+	return nil, fmt.Errorf("%s: invalid %s literal value %v", path, kind, value)
+}
+
+// child rebuilds a required child node
+func child(value any, path string) (models.Expr, error) {
+	node, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: missing node", path)
+	}
+	return decodeNode(node, path)
+}
@@ -0,0 +1,158 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/core/app/eval"
+	"antlr-editor/analyzer/core/infrastructure"
+	"antlr-editor/analyzer/core/models"
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// Visitor builds the abstract syntax tree of a parse tree
+type Visitor struct {
+	*parser.BaseExpressionVisitor
+}
+
+// NewASTVisitor creates a visitor building abstract syntax trees
+func NewASTVisitor() *Visitor {
+	return &Visitor{BaseExpressionVisitor: &parser.BaseExpressionVisitor{}}
+}
+
+// Build returns the abstract syntax tree of a syntactically valid parse tree.
+// Explicit parentheses are kept as ParenExpr nodes, and spans are code point offsets in the input.
+func (v *Visitor) Build(tree parser.IExpressionContext) models.Expr {
+	return v.build(tree)
+}
+
+// Visit visits a parse tree node and returns its AST node
+func (v *Visitor) Visit(tree antlr.ParseTree) any {
+	if tree == nil {
+		return nil
+	}
+	if node, ok := tree.Accept(v).(models.Expr); ok {
+		return node
+	}
+	return nil
+}
+
+// build builds the AST of the given expression, nil for a missing expression
+func (v *Visitor) build(expr parser.IExpressionContext) models.Expr {
+	if expr == nil {
+		return nil
+	}
+	node, _ := v.Visit(expr).(models.Expr)
+	return node
+}
+
+// span returns the span of the given context
+func span(ctx antlr.ParserRuleContext) models.Span {
+	start, stop := infrastructure.ContextTokens(ctx)
+	if start == nil {
+		return models.Span{}
+	}
+	return models.Span{Start: start.GetStart(), End: stop.GetStop() + 1}
+}
+
+// VisitLiteralExpr builds a literal expression
+func (v *Visitor) VisitLiteralExpr(ctx *parser.LiteralExprContext) any {
+	return v.Visit(ctx.Literal())
+}
+
+// VisitLiteral builds a literal with its decoded value
+func (v *Visitor) VisitLiteral(ctx *parser.LiteralContext) any {
+	literal := &models.Literal{Span: span(ctx)}
+	switch {
+	case ctx.STRING_LITERAL() != nil:
+		literal.Kind, literal.Value = models.LiteralString, eval.UnquoteString(ctx.STRING_LITERAL().GetText())
+	case ctx.BOOLEAN_LITERAL() != nil:
+		literal.Kind, literal.Value = models.LiteralBoolean, strings.EqualFold(ctx.GetText(), "true")
+	default:
+		number, err := strconv.ParseFloat(ctx.GetText(), 64)
+		if err != nil {
+			return nil
+		}
+		literal.Kind, literal.Value = models.LiteralNumber, number
+	}
+	return literal
+}
+
+// VisitColumnRefExpr builds a column reference expression
+func (v *Visitor) VisitColumnRefExpr(ctx *parser.ColumnRefExprContext) any {
+	return v.Visit(ctx.ColumnReference())
+}
+
+// VisitColumnReference builds a column reference, stripping the brackets
+func (v *Visitor) VisitColumnReference(ctx *parser.ColumnReferenceContext) any {
+	text := ctx.COLUMN_REF().GetText()
+	return &models.ColumnRef{Span: span(ctx), Name: text[1 : len(text)-1]}
+}
+
+// VisitFunctionCallExpr builds a function call expression
+func (v *Visitor) VisitFunctionCallExpr(ctx *parser.FunctionCallExprContext) any {
+	return v.Visit(ctx.FunctionCall())
+}
+
+// VisitFunctionCall builds a function call
+func (v *Visitor) VisitFunctionCall(ctx *parser.FunctionCallContext) any {
+	call := &models.Call{Span: span(ctx), Name: ctx.FUNCTION_NAME().GetText(), Args: []models.Expr{}}
+	if argList := ctx.ArgumentList(); argList != nil {
+		for _, expr := range argList.AllExpression() {
+			call.Args = append(call.Args, v.build(expr))
+		}
+	}
+	return call
+}
+
+// VisitParenExpr builds a parenthesized expression
+func (v *Visitor) VisitParenExpr(ctx *parser.ParenExprContext) any {
+	return &models.ParenExpr{Span: span(ctx), Inner: v.build(ctx.Expression())}
+}
+
+// VisitUnaryMinusExpr builds a negation
+func (v *Visitor) VisitUnaryMinusExpr(ctx *parser.UnaryMinusExprContext) any {
+	return &models.UnaryExpr{Span: span(ctx), Op: models.OpSub, Operand: v.build(ctx.Expression())}
+}
+
+// VisitPowerExpr builds an exponentiation
+func (v *Visitor) VisitPowerExpr(ctx *parser.PowerExprContext) any {
+	return v.binary(ctx)
+}
+
+// VisitMulDivExpr builds a multiplication/division
+func (v *Visitor) VisitMulDivExpr(ctx *parser.MulDivExprContext) any {
+	return v.binary(ctx)
+}
+
+// VisitAddSubExpr builds an addition/subtraction
+func (v *Visitor) VisitAddSubExpr(ctx *parser.AddSubExprContext) any {
+	return v.binary(ctx)
+}
+
+// VisitComparisonExpr builds a comparison
+func (v *Visitor) VisitComparisonExpr(ctx *parser.ComparisonExprContext) any {
+	return v.binary(ctx)
+}
+
+// VisitAndExpr builds a logical AND
+func (v *Visitor) VisitAndExpr(ctx *parser.AndExprContext) any {
+	return v.binary(ctx)
+}
+
+// VisitOrExpr builds a logical OR
+func (v *Visitor) VisitOrExpr(ctx *parser.OrExprContext) any {
+	return v.binary(ctx)
+}
+
+// binary builds a binary operation from its operator token and both operands
+func (v *Visitor) binary(ctx infrastructure.BinaryExpressionContext) *models.BinaryExpr {
+	return &models.BinaryExpr{
+		Span:  span(ctx),
+		Op:    models.Operator(infrastructure.OperatorText(ctx)),
+		Left:  v.build(ctx.Expression(0)),
+		Right: v.build(ctx.Expression(1)),
+	}
+}
@@ -0,0 +1,489 @@
+package app
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+// stripAST returns a copy of the tree without positions and ParenExpr nodes, so trees can be compared by structure.
+// A negated number is folded into a negative literal, which is how Print writes negative literals.
+func stripAST(expr models.Expr) models.Expr {
+	switch e := expr.(type) {
+	case *models.Literal:
+		return &models.Literal{Kind: e.Kind, Value: e.Value}
+	case *models.ColumnRef:
+		return &models.ColumnRef{Name: e.Name}
+	case *models.Call:
+		args := make([]models.Expr, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = stripAST(arg)
+		}
+		return &models.Call{Name: e.Name, Args: args}
+	case *models.UnaryExpr:
+		operand := stripAST(e.Operand)
+		if literal, ok := operand.(*models.Literal); ok && literal.Kind == models.LiteralNumber {
+			if n, ok := literal.Value.(float64); ok && n > 0 {
+				return models.NumberLiteral(-n)
+			}
+		}
+		return &models.UnaryExpr{Op: e.Op, Operand: operand}
+	case *models.BinaryExpr:
+		return &models.BinaryExpr{Op: e.Op, Left: stripAST(e.Left), Right: stripAST(e.Right)}
+	case *models.ParenExpr:
+		return stripAST(e.Inner)
+	default:
+		return expr
+	}
+}
+
+func TestAnalyzer_ParseAST(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	result := analyzer.ParseAST(`[price] * -2 + UPPER('a\'b')`)
+	require.Empty(t, result.Errors)
+
+	expected := &models.BinaryExpr{
+		Span: models.Span{Start: 0, End: 28},
+		Op:   models.OpAdd,
+		Left: &models.BinaryExpr{
+			Span: models.Span{Start: 0, End: 12},
+			Op:   models.OpMul,
+			Left: &models.ColumnRef{Span: models.Span{Start: 0, End: 7}, Name: "price"},
+			Right: &models.UnaryExpr{
+				Span:    models.Span{Start: 10, End: 12},
+				Op:      models.OpSub,
+				Operand: &models.Literal{Span: models.Span{Start: 11, End: 12}, Kind: models.LiteralNumber, Value: 2.0},
+			},
+		},
+		Right: &models.Call{
+			Span: models.Span{Start: 15, End: 28},
+			Name: "UPPER",
+			Args: []models.Expr{
+				&models.Literal{Span: models.Span{Start: 21, End: 27}, Kind: models.LiteralString, Value: "a'b"},
+			},
+		},
+	}
+	assert.Equal(t, expected, result.AST)
+}
+
+func TestAnalyzer_ParseAST_Nodes(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   models.Expr
+	}{
+		{"boolean", "TRUE", models.BooleanLiteral(true)},
+		{"float", "1.5", models.NumberLiteral(1.5)},
+		{"double-quoted string", `"x\ny"`, models.StringLiteral("x\ny")},
+		{"call without arguments", "NOW()", models.FunctionCall("NOW")},
+		{
+			"power is right-associative", "2 ^ 3 ^ 2",
+			models.Binary(models.OpPow, models.NumberLiteral(2), models.Binary(models.OpPow, models.NumberLiteral(3), models.NumberLiteral(2))),
+		},
+		{
+			"negation binds tighter than power", "-[x] ^ 2",
+			models.Binary(models.OpPow, models.Negate(models.ColumnReference("x")), models.NumberLiteral(2)),
+		},
+		{
+			"logical operators", "[a] || [b] && [c]",
+			models.AnyOf(models.ColumnReference("a"), models.AllOf(models.ColumnReference("b"), models.ColumnReference("c"))),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.ParseAST(tc.expression)
+			require.Empty(t, result.Errors)
+			assert.Equal(t, tc.expected, stripAST(result.AST))
+		})
+	}
+
+	t.Run("parentheses are kept", func(t *testing.T) {
+		result := analyzer.ParseAST("([a])")
+		require.Empty(t, result.Errors)
+		paren, ok := result.AST.(*models.ParenExpr)
+		require.True(t, ok)
+		assert.Equal(t, models.Span{Start: 0, End: 5}, paren.Position())
+		assert.Equal(t, &models.ColumnRef{Span: models.Span{Start: 1, End: 4}, Name: "a"}, paren.Inner)
+	})
+}
+
+func TestAnalyzer_ParseAST_Errors(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	result := analyzer.ParseAST("[a] +")
+	assert.Nil(t, result.AST)
+	assert.NotEmpty(t, result.Errors)
+
+	result = analyzer.ParseAST("[a] # 1")
+	assert.Nil(t, result.AST)
+	assert.NotEmpty(t, result.Errors)
+
+	result = analyzer.ParseAST("")
+	assert.Nil(t, result.AST)
+	assert.Empty(t, result.Errors)
+}
+
+func TestAnalyzer_ParseAST_AsMap(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	result := analyzer.ParseAST("-[a] > 1").AsMap()
+	assert.Equal(t, map[string]any{
+		"ast": map[string]any{
+			"node": "binary",
+			"op":   ">",
+			"left": map[string]any{
+				"node":    "unary",
+				"op":      "-",
+				"operand": map[string]any{"node": "column", "name": "a", "start": 1, "end": 4},
+				"start":   0,
+				"end":     4,
+			},
+			"right": map[string]any{"node": "literal", "kind": "number", "value": 1.0, "start": 7, "end": 8},
+			"start": 0,
+			"end":   8,
+		},
+		"errors": []any{},
+	}, result)
+}
+
+func TestPrint(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	testCases := []struct {
+		name     string
+		expr     models.Expr
+		expected string
+	}{
+		{"column", models.ColumnReference(`unit"qty`), `[unit"qty]`},
+		{"integer", models.NumberLiteral(42), "42"},
+		{"fraction", models.NumberLiteral(0.25), "0.25"},
+		{"large number", models.NumberLiteral(1e21), "1e+21"},
+		{"negative number", models.NumberLiteral(-3), "-3"},
+		{"string escapes", models.StringLiteral("say \"hi\"\\\n"), `"say \"hi\"\\\n"`},
+		{"boolean", models.BooleanLiteral(false), "false"},
+		{
+			"call", models.FunctionCall("ROUND", models.ColumnReference("price"), models.NumberLiteral(2)),
+			"ROUND([price], 2)",
+		},
+		{
+			"tighter operand needs no parentheses",
+			models.Binary(models.OpAdd, models.ColumnReference("a"), models.Binary(models.OpMul, models.ColumnReference("b"), models.ColumnReference("c"))),
+			"[a] + [b] * [c]",
+		},
+		{
+			"looser operand is parenthesized",
+			models.Binary(models.OpMul, models.Binary(models.OpAdd, models.ColumnReference("a"), models.ColumnReference("b")), models.ColumnReference("c")),
+			"([a] + [b]) * [c]",
+		},
+		{
+			"left associativity",
+			models.Binary(models.OpSub, models.ColumnReference("a"), models.Binary(models.OpSub, models.ColumnReference("b"), models.ColumnReference("c"))),
+			"[a] - ([b] - [c])",
+		},
+		{
+			"left chain needs no parentheses",
+			models.Binary(models.OpSub, models.Binary(models.OpSub, models.ColumnReference("a"), models.ColumnReference("b")), models.ColumnReference("c")),
+			"[a] - [b] - [c]",
+		},
+		{
+			"right associativity of power",
+			models.Binary(models.OpPow, models.Binary(models.OpPow, models.ColumnReference("a"), models.ColumnReference("b")), models.ColumnReference("c")),
+			"([a] ^ [b]) ^ [c]",
+		},
+		{
+			"power chain needs no parentheses",
+			models.Binary(models.OpPow, models.ColumnReference("a"), models.Binary(models.OpPow, models.ColumnReference("b"), models.ColumnReference("c"))),
+			"[a] ^ [b] ^ [c]",
+		},
+		{
+			"negated power", models.Negate(models.Binary(models.OpPow, models.ColumnReference("x"), models.NumberLiteral(2))),
+			"-([x] ^ 2)",
+		},
+		{
+			"power of a negation", models.Binary(models.OpPow, models.Negate(models.ColumnReference("x")), models.NumberLiteral(2)),
+			"-[x] ^ 2",
+		},
+		{
+			"negated sum", models.Negate(models.Binary(models.OpAdd, models.ColumnReference("a"), models.NumberLiteral(1))),
+			"-([a] + 1)",
+		},
+		{"double negation", models.Negate(models.Negate(models.ColumnReference("a"))), "--[a]"},
+		{
+			"negative exponent", models.Binary(models.OpPow, models.NumberLiteral(2), models.NumberLiteral(-1)),
+			"2 ^ -1",
+		},
+		{
+			"or inside and",
+			models.AllOf(models.AnyOf(models.ColumnReference("a"), models.ColumnReference("b")), models.ColumnReference("c")),
+			"([a] || [b]) && [c]",
+		},
+		{
+			"comparisons combined",
+			models.AllOf(
+				models.Binary(models.OpGe, models.ColumnReference("price"), models.NumberLiteral(10)),
+				models.Binary(models.OpNeq, models.FunctionCall("UPPER", models.ColumnReference("status")), models.StringLiteral("CLOSED")),
+				models.Binary(models.OpLt, models.ColumnReference("qty"), models.NumberLiteral(5)),
+			),
+			`[price] >= 10 && UPPER([status]) != "CLOSED" && [qty] < 5`,
+		},
+		{
+			"explicit parentheses are kept",
+			&models.ParenExpr{Inner: models.Binary(models.OpMul, models.ColumnReference("a"), models.ColumnReference("b"))},
+			"([a] * [b])",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source, err := models.Print(tc.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, source)
+
+			// The printed source parses back into the same tree
+			result := analyzer.ParseAST(source)
+			require.Empty(t, result.Errors)
+			assert.Equal(t, stripAST(tc.expr), stripAST(result.AST))
+		})
+	}
+}
+
+func TestPrint_RoundTrip(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	expressions := []string{
+		"[a] + [b] * [c] - [d] / 2",
+		"(([a] + [b])) * ([c] - [d])",
+		"2 ^ 3 ^ 2 + (2 ^ 3) ^ 2",
+		"-[x] ^ 2 + -([x] ^ 2) + 2 ^ -[x]",
+		"[a] < [b] == ([c] > [d])",
+		"[a] || [b] && [c] || [d]",
+		"([a] || [b]) && ([c] || [d])",
+		"IF([score] >= 90, 'A', IF([score] >= 80, 'B', 'C'))",
+		`CONCAT('it\'s', "a \"b\"", '\\')`,
+		"ROUND(AVG([a], [b], 3.75), 1) * 1.0e2",
+		"COALESCE([x], NOW())",
+		"true && FALSE || True",
+		"007 + 0.5",
+	}
+
+	for _, expression := range expressions {
+		t.Run(expression, func(t *testing.T) {
+			parsed := analyzer.ParseAST(expression)
+			require.Empty(t, parsed.Errors)
+
+			// Printing keeps explicit parentheses; printing without them inserts only the required ones
+			for _, tree := range []models.Expr{parsed.AST, stripAST(parsed.AST)} {
+				source, err := models.Print(tree)
+				require.NoError(t, err)
+				reparsed := analyzer.ParseAST(source)
+				require.Empty(t, reparsed.Errors, source)
+				assert.Equal(t, stripAST(parsed.AST), stripAST(reparsed.AST), source)
+
+				again, err := models.Print(reparsed.AST)
+				require.NoError(t, err)
+				assert.Equal(t, source, again)
+			}
+		})
+	}
+}
+
+func TestPrint_Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expr     models.Expr
+		expected string
+	}{
+		{"missing operand", models.Binary(models.OpAdd, models.ColumnReference("a"), nil), "missing expression"},
+		{"column with bracket", models.ColumnReference("a]b"), `invalid column name "a]b"`},
+		{"column with space", models.ColumnReference("unit price"), `invalid column name "unit price"`},
+		{"empty column", models.ColumnReference(""), `invalid column name ""`},
+		{"lowercase function", models.FunctionCall("upper", models.ColumnReference("a")), `invalid function name "upper"`},
+		{"unknown operator", models.Binary("%", models.ColumnReference("a"), models.ColumnReference("b")), `invalid binary operator "%"`},
+		{"unknown unary operator", &models.UnaryExpr{Op: "!", Operand: models.ColumnReference("a")}, `invalid unary operator "!"`},
+		{"infinite number", models.NumberLiteral(math.Inf(1)), "number +Inf has no literal form"},
+		{"kind mismatch", &models.Literal{Kind: models.LiteralString, Value: 1.0}, "invalid string literal value 1"},
+		{"nested error", models.FunctionCall("ABS", models.NumberLiteral(math.NaN())), "number NaN has no literal form"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source, err := models.Print(tc.expr)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+			assert.Empty(t, source)
+		})
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	parsed := analyzer.ParseAST("LEN([name]) + [qty] * 2")
+	require.Empty(t, parsed.Errors)
+	original, err := models.Print(parsed.AST)
+	require.NoError(t, err)
+
+	var visited []string
+	rewritten := models.Rewrite(parsed.AST, func(node models.Expr) models.Expr {
+		source, err := models.Print(node)
+		require.NoError(t, err)
+		visited = append(visited, source)
+
+		if column, ok := node.(*models.ColumnRef); ok && column.Name == "qty" {
+			return models.ColumnReference("quantity")
+		}
+		return node
+	})
+
+	// Children are rewritten before their parent, which sees the rewritten children
+	assert.Equal(t, []string{"[name]", "LEN([name])", "[qty]", "2", "[quantity] * 2", "LEN([name]) + [quantity] * 2"}, visited)
+
+	source, err := models.Print(rewritten)
+	require.NoError(t, err)
+	assert.Equal(t, "LEN([name]) + [quantity] * 2", source)
+
+	// The input tree is left unchanged
+	unchanged, err := models.Print(parsed.AST)
+	require.NoError(t, err)
+	assert.Equal(t, original, unchanged)
+}
+
+func TestInspect(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	parsed := analyzer.ParseAST("IF([a] > 1, UPPER([b]), ([c]))")
+	require.Empty(t, parsed.Errors)
+
+	var columns []string
+	models.Inspect(parsed.AST, func(node models.Expr) bool {
+		if column, ok := node.(*models.ColumnRef); ok {
+			columns = append(columns, column.Name)
+		}
+		return true
+	})
+	assert.Equal(t, []string{"a", "b", "c"}, columns)
+
+	var calls []string
+	models.Inspect(parsed.AST, func(node models.Expr) bool {
+		if call, ok := node.(*models.Call); ok {
+			calls = append(calls, call.Name)
+			return false
+		}
+		return true
+	})
+	assert.Equal(t, []string{"IF"}, calls)
+}
+
+func TestApp_Rewrite(t *testing.T) {
+	app := NewApp()
+
+	renameFunction := func(from, to string) models.Rewriter {
+		return func(node models.Expr) models.Expr {
+			if call, ok := node.(*models.Call); ok && call.Name == from {
+				call.Name = to
+			}
+			return node
+		}
+	}
+	coalesceColumns := func(node models.Expr) models.Expr {
+		if _, ok := node.(*models.ColumnRef); ok {
+			return models.FunctionCall("COALESCE", node, models.NumberLiteral(0))
+		}
+		return node
+	}
+	replaceColumn := func(name string, replacement models.Expr) models.Rewriter {
+		return func(node models.Expr) models.Expr {
+			if column, ok := node.(*models.ColumnRef); ok && column.Name == name {
+				return replacement
+			}
+			return node
+		}
+	}
+	deleteColumn := func(name string) models.Rewriter {
+		return func(node models.Expr) models.Expr {
+			if column, ok := node.(*models.ColumnRef); ok && column.Name == name {
+				return nil
+			}
+			return node
+		}
+	}
+	deleteCondition := func(node models.Expr) models.Expr {
+		if binary, ok := node.(*models.BinaryExpr); ok {
+			if column, ok := binary.Left.(*models.ColumnRef); ok && column.Name == "status" {
+				return nil
+			}
+		}
+		return node
+	}
+
+	testCases := []struct {
+		name       string
+		expression string
+		rewrite    models.Rewriter
+		expected   string
+	}{
+		{"swap deprecated function", "LEN([name]) + LEN(UPPER([code]))", renameFunction("LEN", "LENGTH"), "LENGTH([name]) + LENGTH(UPPER([code]))"},
+		{"wrap columns in COALESCE", "[price]*[qty]", coalesceColumns, "COALESCE([price], 0)\n  * COALESCE([qty], 0)"},
+		{
+			"replace column with subexpression", "[total] / 2",
+			replaceColumn("total", models.Binary(models.OpAdd, models.ColumnReference("net"), models.ColumnReference("tax"))),
+			"([net] + [tax]) / 2",
+		},
+		{
+			"replacement binding more tightly", "-[total]",
+			replaceColumn("total", models.Binary(models.OpPow, models.ColumnReference("x"), models.NumberLiteral(2))),
+			"-([x] ^ 2)",
+		},
+		{"delete function argument", "SUM([a], [b], [c])", deleteColumn("b"), "SUM([a], [c])"},
+		{"delete operand", "[a] + [b]", deleteColumn("a"), "[b]"},
+		{
+			"delete condition", `[price] > 10 && [status] == "open" && [qty] < 5`, deleteCondition,
+			"[price] > 10 && [qty] < 5",
+		},
+		{"delete inside parentheses", "([a]) + 1", deleteColumn("a"), "1"},
+		{"unchanged tree is formatted", "(([a]))+1", func(node models.Expr) models.Expr { return node }, "(([a])) + 1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := app.Rewrite(tc.expression, tc.rewrite)
+			require.Empty(t, result.Errors)
+			assert.Equal(t, tc.expected, result.Expression)
+		})
+	}
+}
+
+func TestApp_Rewrite_Errors(t *testing.T) {
+	app := NewApp()
+	keep := func(node models.Expr) models.Expr { return node }
+
+	result := app.Rewrite("[a] +", keep)
+	assert.Empty(t, result.Expression)
+	assert.NotEmpty(t, result.Errors)
+
+	result = app.Rewrite("", keep)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "Empty expression", result.Errors[0].Message)
+
+	result = app.Rewrite("[a]", func(node models.Expr) models.Expr { return nil })
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "Rewrite deleted the entire expression", result.Errors[0].Message)
+
+	result = app.Rewrite("[a] + 1", func(node models.Expr) models.Expr {
+		if _, ok := node.(*models.ColumnRef); ok {
+			return models.ColumnReference("unit price")
+		}
+		return node
+	})
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, `Rewritten expression cannot be printed: invalid column name "unit price": must be non-empty without brackets or whitespace`, result.Errors[0].Message)
+	assert.Equal(t, map[string]any{"expression": "", "errors": []any{result.Errors[0].AsMap()}}, result.AsMap())
+}
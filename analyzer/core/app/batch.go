@@ -0,0 +1,98 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+// RowError is the error that stopped evaluation of one row of a batch
+type RowError struct {
+	Row int `json:"row"` // Row index (0-based)
+	models.ErrorInfo
+}
+
+// BatchResult represents the result of evaluating an expression over columns of values
+type BatchResult struct {
+	Values *models.Vector // One value per row, null where evaluation failed
+	Failed []bool         // Per-row error mask, true where evaluation failed
+	Errors []RowError     // Error of each failed row, in row order
+}
+
+// EvaluateColumns evaluates the expression for every row of the given columns.
+// Each column is a slice accepted by models.NewVector, such as []float64, []string or []bool,
+// and all columns must have the same length. Columns the expression does not reference are
+// only used to determine the number of rows.
+func (c *CompiledExpression) EvaluateColumns(columns map[string]any) (*BatchResult, error) {
+	vectors := make(map[string]*models.Vector, len(columns))
+	rows := 0
+	for _, name := range sortedColumnNames(columns) {
+		vector, err := models.NewVector(columns[name])
+		if err != nil {
+			return nil, fmt.Errorf("column [%s]: %w", name, err)
+		}
+		if len(vectors) == 0 {
+			rows = vector.Len()
+		}
+		vectors[name] = vector
+	}
+	return c.EvaluateVectors(vectors, rows)
+}
+
+// EvaluateVectors evaluates the expression for each of rows rows; every column must have that many rows.
+// A referenced column that is missing fails every row that reads it with an unknown column error.
+func (c *CompiledExpression) EvaluateVectors(columns map[string]*models.Vector, rows int) (*BatchResult, error) {
+	for _, name := range sortedColumnNames(columns) {
+		vector := columns[name]
+		if vector == nil {
+			return nil, fmt.Errorf("column [%s] is nil", name)
+		}
+		if nulls := vector.Nulls(); nulls != nil && len(nulls) != vector.Len() {
+			return nil, fmt.Errorf("column [%s] has a null mask of %d rows, expected %d", name, len(nulls), vector.Len())
+		}
+		if vector.Len() != rows {
+			return nil, fmt.Errorf("column [%s] has %d rows, expected %d", name, vector.Len(), rows)
+		}
+	}
+
+	names := c.program.Columns()
+	inputs := make([]*models.Vector, len(names))
+	present := make([]bool, len(names))
+	for i, name := range names {
+		if vector, ok := columns[name]; ok {
+			inputs[i], present[i] = vector, true
+		}
+	}
+
+	// The slots are reused for every row, so a row costs no allocation beyond what functions allocate
+	slots := make([]models.Value, len(names))
+	values := models.NewVectorBuilder(rows)
+	result := &BatchResult{Failed: make([]bool, rows)}
+	for row := 0; row < rows; row++ {
+		for i, input := range inputs {
+			if input != nil {
+				slots[i] = input.At(row)
+			}
+		}
+		value, err := c.program.EvalPartial(slots, present)
+		if err != nil {
+			result.Failed[row] = true
+			result.Errors = append(result.Errors, RowError{Row: row, ErrorInfo: *c.encodeError(err)})
+			value = models.NullValue()
+		}
+		values.Append(value)
+	}
+	result.Values = values.Build()
+	return result, nil
+}
+
+// sortedColumnNames returns the keys of a column map in a deterministic order
+func sortedColumnNames[T any](columns map[string]T) []string {
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+func TestCompiledExpression_EvaluateColumns(t *testing.T) {
+	analyzer := newAnalyzer()
+	columns := map[string]any{
+		"price":    []float64{10, 2.5, 4},
+		"quantity": []int{3, 0, 2},
+		"name":     []string{"a", "b", "c"},
+		"active":   []bool{true, false, true},
+		"none":     []any{nil, nil, nil},
+	}
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   *models.Vector
+	}{
+		{"number result", "[price] * [quantity]", models.NumberVector([]float64{30, 0, 8}, nil)},
+		{"string result", "UPPER([name])", models.StringVector([]string{"A", "B", "C"}, nil)},
+		{"boolean result", "[active] && [price] > 3", models.BooleanVector([]bool{true, false, true}, nil)},
+		{"constant over all rows", "1 + 1", models.NumberVector([]float64{2, 2, 2}, nil)},
+		{
+			"null rows", "IF([active], [price], [none])",
+			models.NumberVector([]float64{10, 0, 4}, []bool{false, true, false}),
+		},
+		{
+			"leading null rows", "IF([quantity] > 2, [name], [none])",
+			models.StringVector([]string{"a", "", ""}, []bool{false, true, true}),
+		},
+		{
+			"mixed result types", "IF([active], [price], [name])",
+			models.ValueVector([]models.Value{models.NumberValue(10), models.StringValue("b"), models.NumberValue(4)}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			compiled, errors := analyzer.Compile(tc.expression)
+			require.Empty(t, errors)
+			result, err := compiled.EvaluateColumns(columns)
+			require.NoError(t, err)
+			assert.Empty(t, result.Errors)
+			assert.Equal(t, []bool{false, false, false}, result.Failed)
+			assert.Equal(t, tc.expected, result.Values)
+		})
+	}
+}
+
+func TestCompiledExpression_EvaluateColumns_RowErrors(t *testing.T) {
+	analyzer := newAnalyzer()
+	compiled, errors := analyzer.Compile("[total] / [count]")
+	require.Empty(t, errors)
+
+	result, err := compiled.EvaluateColumns(map[string]any{
+		"total": []float64{10, 5, 8, 3},
+		"count": []any{2, 0, nil, 0},
+	})
+	require.NoError(t, err)
+
+	divisionByZero := models.ErrorInfo{Message: "Division by zero", Line: 1, Column: 10, Start: 10, End: 17}
+	assert.Equal(t, []bool{false, true, false, true}, result.Failed)
+	assert.Equal(t, []RowError{{Row: 1, ErrorInfo: divisionByZero}, {Row: 3, ErrorInfo: divisionByZero}}, result.Errors)
+	assert.Equal(t, models.NumberVector([]float64{5, 0, 0, 0}, []bool{false, true, true, true}), result.Values)
+
+	// A missing column fails only the rows that read it
+	compiled, errors = analyzer.Compile("IF([flag], [missing], 0)")
+	require.Empty(t, errors)
+	result, err = compiled.EvaluateColumns(map[string]any{"flag": []bool{false, true}})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, true}, result.Failed)
+	assert.Equal(t, []RowError{
+		{Row: 1, ErrorInfo: models.ErrorInfo{Message: "Unknown column: [missing]", Line: 1, Column: 11, Start: 11, End: 20}},
+	}, result.Errors)
+}
+
+func TestCompiledExpression_EvaluateColumns_InvalidInput(t *testing.T) {
+	analyzer := newAnalyzer()
+	compiled, errors := analyzer.Compile("[a] + [b]")
+	require.Empty(t, errors)
+
+	testCases := []struct {
+		name     string
+		columns  map[string]any
+		expected string
+	}{
+		{"length mismatch", map[string]any{"a": []float64{1, 2}, "b": []float64{1}}, "column [b] has 1 rows, expected 2"},
+		{"unsupported column type", map[string]any{"a": []uint8{1}}, "column [a]: unsupported column type []uint8"},
+		{"unsupported value", map[string]any{"a": []any{1, []int{}}}, "column [a]: row 1: unsupported value type []int"},
+		{
+			"null mask length",
+			map[string]any{"a": models.NumberVector([]float64{1, 2}, []bool{true})},
+			"column [a] has a null mask of 1 rows, expected 2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := compiled.EvaluateColumns(tc.columns)
+			assert.Nil(t, result)
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+
+	result, err := compiled.EvaluateColumns(map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Values.Len())
+
+	result, err = compiled.EvaluateVectors(map[string]*models.Vector{"a": models.NumberVector([]float64{1}, nil)}, 2)
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "column [a] has 1 rows, expected 2")
+}
+
+func TestCompiledExpression_EvaluateVectors_RowCount(t *testing.T) {
+	analyzer := newAnalyzer()
+	compiled, errors := analyzer.Compile("UPPER('x')")
+	require.Empty(t, errors)
+
+	result, err := compiled.EvaluateVectors(nil, 3)
+	require.NoError(t, err)
+	assert.Equal(t, models.StringVector([]string{"X", "X", "X"}, nil), result.Values)
+}
+
+func TestCompiledExpression_EvaluateColumns_MatchesRows(t *testing.T) {
+	analyzer := newAnalyzer()
+	start := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	columns := map[string]any{
+		"amount": []any{12.5, nil, -3, 0, 7},
+		"label":  []string{"x", "", "long label", "y", "z"},
+		"day":    []time.Time{start, start.AddDate(0, 1, 0), start.AddDate(1, 0, 0), start, start},
+	}
+	expression := "IF(LENGTH([label]) > 1, ROUND([amount] / [amount], 1), YEAR([day]) + [amount])"
+	compiled, errors := analyzer.Compile(expression)
+	require.Empty(t, errors)
+
+	result, err := compiled.EvaluateColumns(columns)
+	require.NoError(t, err)
+
+	rowErrors := result.Errors
+	for row := 0; row < result.Values.Len(); row++ {
+		values := map[string]any{}
+		for name, column := range columns {
+			vector, err := models.NewVector(column)
+			require.NoError(t, err)
+			values[name] = vector.At(row)
+		}
+		expected := analyzer.Evaluate(expression, values)
+		if len(expected.Errors) > 0 {
+			require.True(t, result.Failed[row], "row %d should fail", row)
+			require.NotEmpty(t, rowErrors)
+			assert.Equal(t, RowError{Row: row, ErrorInfo: expected.Errors[0]}, rowErrors[0])
+			rowErrors = rowErrors[1:]
+			continue
+		}
+		assert.False(t, result.Failed[row], "row %d should succeed", row)
+		assert.True(t, expected.Value.Equal(result.Values.At(row)), "row %d = %v, want %v", row, result.Values.At(row), expected.Value)
+	}
+	assert.Empty(t, rowErrors)
+}
+
+func BenchmarkEvaluate_CompiledColumns(b *testing.B) {
+	analyzer := newAnalyzer()
+	compiled, _ := analyzer.Compile(benchmarkRowExpression)
+	const rows = 1000
+	price, quantity, discount, status := make([]float64, rows), make([]int, rows), make([]float64, rows), make([]string, rows)
+	for i := 0; i < rows; i++ {
+		row := benchmarkRow(i)
+		price[i], quantity[i], discount[i], status[i] = row["price"].(float64), row["quantity"].(int), row["discount"].(float64), row["status"].(string)
+	}
+	quantities, _ := models.NewVector(quantity)
+	columns := map[string]*models.Vector{
+		"price":    models.NumberVector(price, nil),
+		"quantity": quantities,
+		"discount": models.NumberVector(discount, nil),
+		"status":   models.StringVector(status, nil),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		compiled.EvaluateVectors(columns, rows)
+	}
+}
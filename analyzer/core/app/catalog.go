@@ -0,0 +1,456 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"antlr-editor/analyzer/core/models"
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// CatalogEntry is a named expression defining a derived column
+type CatalogEntry struct {
+	Name       string `json:"name"`       // Name of the derived column, referenced as [name] by other expressions
+	Expression string `json:"expression"` // Expression computing the column
+}
+
+// Catalog holds named expressions whose formulas may reference each other's columns.
+// It is checked with the functions and schema of the App that created it, as they are when Analyze is called.
+type Catalog struct {
+	analyzer *Analyzer
+	entries  []CatalogEntry
+	index    map[string]int
+}
+
+// catalogDocument is the JSON form of a catalog
+type catalogDocument struct {
+	Expressions []CatalogEntry `json:"expressions"`
+}
+
+// NewCatalog creates an empty catalog checked by this App
+func (app *App) NewCatalog() *Catalog {
+	return &Catalog{analyzer: app.analyzer, entries: []CatalogEntry{}, index: make(map[string]int)}
+}
+
+// LoadCatalog creates a catalog from its JSON form {"expressions": [{"name": ..., "expression": ...}]}.
+// An entry with an invalid or duplicate name is an error.
+func (app *App) LoadCatalog(data []byte) (*Catalog, error) {
+	var document catalogDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid catalog: %w", err)
+	}
+
+	catalog := app.NewCatalog()
+	for _, entry := range document.Expressions {
+		if _, ok := catalog.index[entry.Name]; ok {
+			return nil, fmt.Errorf("invalid catalog: duplicate expression name %q", entry.Name)
+		}
+		if err := catalog.Set(entry.Name, entry.Expression); err != nil {
+			return nil, fmt.Errorf("invalid catalog: %w", err)
+		}
+	}
+	return catalog, nil
+}
+
+// MarshalJSON encodes the catalog in the form read by LoadCatalog
+func (c *Catalog) MarshalJSON() ([]byte, error) {
+	return json.Marshal(catalogDocument{Expressions: c.entries})
+}
+
+// Set adds a named expression, or replaces the expression of an existing name keeping its position.
+// The name must be usable as a column reference.
+func (c *Catalog) Set(name, expression string) error {
+	if !models.IsColumnName(name) {
+		return fmt.Errorf("invalid expression name %q: must be non-empty without brackets or whitespace", name)
+	}
+	if i, ok := c.index[name]; ok {
+		c.entries[i].Expression = expression
+		return nil
+	}
+	c.index[name] = len(c.entries)
+	c.entries = append(c.entries, CatalogEntry{Name: name, Expression: expression})
+	return nil
+}
+
+// Remove removes a named expression and reports whether it existed
+func (c *Catalog) Remove(name string) bool {
+	i, ok := c.index[name]
+	if !ok {
+		return false
+	}
+	c.entries = append(c.entries[:i], c.entries[i+1:]...)
+	delete(c.index, name)
+	for j := i; j < len(c.entries); j++ {
+		c.index[c.entries[j].Name] = j
+	}
+	return true
+}
+
+// Lookup returns the named expression
+func (c *Catalog) Lookup(name string) (CatalogEntry, bool) {
+	i, ok := c.index[name]
+	if !ok {
+		return CatalogEntry{}, false
+	}
+	return c.entries[i], true
+}
+
+// Entries returns the named expressions in the order they were added
+func (c *Catalog) Entries() []CatalogEntry {
+	return append([]CatalogEntry(nil), c.entries...)
+}
+
+// CatalogExpressionReport is the analysis of one named expression
+type CatalogExpressionReport struct {
+	Name         string             `json:"name"`
+	Type         models.DataType    `json:"type"`         // Inferred type, any if the expression is invalid or in a cycle
+	Dependencies []string           `json:"dependencies"` // Named expressions it references, in order of first occurrence
+	Columns      []string           `json:"columns"`      // Other columns it references, in order of first occurrence
+	Errors       []models.ErrorInfo `json:"errors"`       // Lint errors, with the other named expressions as known columns
+}
+
+// AsMap converts CatalogExpressionReport to a map for JSON serialization
+func (r *CatalogExpressionReport) AsMap() map[string]any {
+	errors := make([]any, len(r.Errors))
+	for i, err := range r.Errors {
+		errors[i] = err.AsMap()
+	}
+	return map[string]any{
+		"name":         r.Name,
+		"type":         string(r.Type),
+		"dependencies": stringsAsAny(r.Dependencies),
+		"columns":      stringsAsAny(r.Columns),
+		"errors":       errors,
+	}
+}
+
+// MissingDependency is a column referenced by a named expression that is neither another
+// named expression nor a column of the schema
+type MissingDependency struct {
+	Expression string `json:"expression"` // Name of the referencing expression
+	Column     string `json:"column"`     // Missing column
+}
+
+// AsMap converts MissingDependency to a map for JSON serialization
+func (m *MissingDependency) AsMap() map[string]any {
+	return map[string]any{
+		"expression": m.Expression,
+		"column":     m.Column,
+	}
+}
+
+// CatalogReport is the analysis of a catalog
+type CatalogReport struct {
+	Expressions []CatalogExpressionReport `json:"expressions"` // One report per named expression, in catalog order
+	Cycles      [][]string                `json:"cycles"`      // Groups of named expressions that depend on each other
+	Missing     []MissingDependency       `json:"missing"`     // References to unknown columns; only reported with a schema
+	Order       []string                  `json:"order"`       // Evaluation order of the expressions outside of and not depending on cycles
+}
+
+// AsMap converts CatalogReport to a map for JSON serialization
+func (r *CatalogReport) AsMap() map[string]any {
+	expressions := make([]any, len(r.Expressions))
+	for i, expression := range r.Expressions {
+		expressions[i] = expression.AsMap()
+	}
+
+	cycles := make([]any, len(r.Cycles))
+	for i, cycle := range r.Cycles {
+		cycles[i] = stringsAsAny(cycle)
+	}
+
+	missing := make([]any, len(r.Missing))
+	for i, dependency := range r.Missing {
+		missing[i] = dependency.AsMap()
+	}
+
+	return map[string]any{
+		"expressions": expressions,
+		"cycles":      cycles,
+		"missing":     missing,
+		"order":       stringsAsAny(r.Order),
+	}
+}
+
+// stringsAsAny converts a string slice for JSON serialization through syscall/js, which only accepts []any
+func stringsAsAny(values []string) []any {
+	result := make([]any, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
+
+// Analyze validates every named expression and builds the dependency graph from their column references.
+// Expressions are validated in evaluation order so that the inferred type of a named expression is the
+// column type seen by the expressions referencing it. Without a schema, columns that are not named
+// expressions are accepted with type any; with a schema, they must be schema columns and are otherwise
+// reported as missing dependencies.
+func (c *Catalog) Analyze() *CatalogReport {
+	graph := c.dependencyGraph()
+	report := &CatalogReport{
+		Expressions: make([]CatalogExpressionReport, len(c.entries)),
+		Cycles:      [][]string{},
+		Missing:     []MissingDependency{},
+		Order:       []string{},
+	}
+
+	for i, entry := range c.entries {
+		report.Expressions[i] = CatalogExpressionReport{
+			Name:         entry.Name,
+			Type:         models.DataTypeAny,
+			Dependencies: append([]string{}, graph.names(graph.dependencies[i])...),
+			Columns:      append([]string{}, graph.columns[i]...),
+		}
+		if c.analyzer.schema == nil {
+			continue
+		}
+		for _, column := range graph.columns[i] {
+			if _, ok := c.analyzer.schema.Lookup(column); !ok {
+				report.Missing = append(report.Missing, MissingDependency{Expression: entry.Name, Column: column})
+			}
+		}
+	}
+
+	for _, cycle := range graph.cycles() {
+		report.Cycles = append(report.Cycles, graph.names(cycle))
+	}
+	order := graph.order()
+	report.Order = append(report.Order, graph.names(order)...)
+
+	// Check the expressions in evaluation order, then the ones in or depending on cycles, whose type stays any
+	types := make(map[string]models.DataType, len(c.entries))
+	checked := make([]bool, len(c.entries))
+	for _, i := range order {
+		expression := &report.Expressions[i]
+		expression.Type, expression.Errors = c.check(i, graph, types)
+		c.analyzer.positions(c.entries[i].Expression).Errors(expression.Errors)
+		types[expression.Name] = expression.Type
+		checked[i] = true
+	}
+	for i := range c.entries {
+		if !checked[i] {
+			_, report.Expressions[i].Errors = c.check(i, graph, types)
+			c.analyzer.positions(c.entries[i].Expression).Errors(report.Expressions[i].Errors)
+		}
+	}
+	return report
+}
+
+// check lints the expression at index i of the catalog, from its parse in the graph, with the named expressions
+// as known columns, typed as inferred so far, and returns its inferred type. Without a schema the other
+// referenced columns are known columns of type any.
+func (c *Catalog) check(i int, graph *dependencyGraph, types map[string]models.DataType) (models.DataType, []models.ErrorInfo) {
+	if c.entries[i].Expression == "" {
+		return models.DataTypeAny, []models.ErrorInfo{{Message: "Empty expression", Line: 1, Column: 0, Start: 0, End: 0}}
+	}
+
+	var columns []models.Column
+	if c.analyzer.schema != nil {
+		columns = append(columns, c.analyzer.schema.Columns()...)
+	} else {
+		for _, names := range graph.columns {
+			for _, name := range names {
+				columns = append(columns, models.Column{Name: name, Type: models.DataTypeAny})
+			}
+		}
+	}
+	for _, entry := range c.entries {
+		columnType, ok := types[entry.Name]
+		if !ok {
+			columnType = models.DataTypeAny
+		}
+		columns = append(columns, models.Column{Name: entry.Name, Type: columnType})
+	}
+
+	errors, dataType := c.analyzer.lintWithSchema(graph.parsed[i], models.NewSchema(columns))
+	if len(errors) > 0 {
+		return models.DataTypeAny, errors
+	}
+	return dataType, errors
+}
+
+// Impact returns the named expressions that depend on a column, directly or through other named
+// expressions, in evaluation order: the expressions that break if the column is dropped.
+// Expressions in or depending on cycles come last, in catalog order.
+func (c *Catalog) Impact(column string) []string {
+	graph := c.dependencyGraph()
+
+	affected := make([]bool, len(c.entries))
+	var visit func(i int)
+	visit = func(i int) {
+		for _, dependent := range graph.dependents[i] {
+			if !affected[dependent] {
+				affected[dependent] = true
+				visit(dependent)
+			}
+		}
+	}
+	for i, columns := range graph.columns {
+		for _, name := range columns {
+			if name == column && !affected[i] {
+				affected[i] = true
+				visit(i)
+			}
+		}
+	}
+	if i, ok := c.index[column]; ok {
+		visit(i)
+	}
+
+	var impact []int
+	ordered := make([]bool, len(c.entries))
+	for _, i := range graph.order() {
+		ordered[i] = true
+		if affected[i] {
+			impact = append(impact, i)
+		}
+	}
+	for i := range c.entries {
+		if affected[i] && !ordered[i] {
+			impact = append(impact, i)
+		}
+	}
+	return append([]string{}, graph.names(impact)...)
+}
+
+// dependencyGraph links the named expressions, identified by catalog index, to the named expressions they reference
+type dependencyGraph struct {
+	entries      []CatalogEntry
+	dependencies [][]int             // Named expressions referenced by each expression, in order of first occurrence
+	dependents   [][]int             // Expressions referencing each named expression, in catalog order
+	columns      [][]string          // Other columns referenced by each expression, in order of first occurrence
+	parsed       []*parsedExpression // Parse of each expression, shared by its references and its checks
+}
+
+// dependencyGraph parses each expression once and splits the columns it references into named expressions
+// and other columns
+func (c *Catalog) dependencyGraph() *dependencyGraph {
+	graph := &dependencyGraph{
+		entries:      c.entries,
+		dependencies: make([][]int, len(c.entries)),
+		dependents:   make([][]int, len(c.entries)),
+		columns:      make([][]string, len(c.entries)),
+		parsed:       make([]*parsedExpression, len(c.entries)),
+	}
+	for i, entry := range c.entries {
+		graph.parsed[i] = lexAndParse(c.analyzer.helper, entry.Expression)
+		referenced := make(map[string]bool)
+		for _, token := range graph.parsed[i].tokens {
+			if token.GetTokenType() != parser.ExpressionLexerCOLUMN_REF {
+				continue
+			}
+			name := token.GetText()[1 : len(token.GetText())-1]
+			if referenced[name] {
+				continue
+			}
+			referenced[name] = true
+
+			j, ok := c.index[name]
+			if !ok {
+				graph.columns[i] = append(graph.columns[i], name)
+				continue
+			}
+			graph.dependencies[i] = append(graph.dependencies[i], j)
+			graph.dependents[j] = append(graph.dependents[j], i)
+		}
+	}
+	return graph
+}
+
+// names returns the names of the given expressions
+func (g *dependencyGraph) names(indexes []int) []string {
+	names := make([]string, len(indexes))
+	for i, index := range indexes {
+		names[i] = g.entries[index].Name
+	}
+	return names
+}
+
+// cycles returns the groups of expressions that depend on each other: the strongly connected components,
+// found with Tarjan's algorithm, that have more than one expression or an expression referencing itself.
+// Each cycle lists its expressions in catalog order, and cycles are ordered by their first expression.
+func (g *dependencyGraph) cycles() [][]int {
+	n := len(g.entries)
+	index, lowLink := make([]int, n), make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+	var stack []int
+	var cycles [][]int
+	next := 0
+
+	var connect func(v int)
+	connect = func(v int) {
+		index[v], lowLink[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range g.dependencies[v] {
+			if index[w] < 0 {
+				connect(w)
+				lowLink[v] = min(lowLink[v], lowLink[w])
+			} else if onStack[w] {
+				lowLink[v] = min(lowLink[v], index[w])
+			}
+		}
+
+		if lowLink[v] != index[v] {
+			return
+		}
+		var component []int
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		if len(component) > 1 || slices.Contains(g.dependencies[v], v) {
+			slices.Sort(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for v := range n {
+		if index[v] < 0 {
+			connect(v)
+		}
+	}
+	slices.SortFunc(cycles, func(a, b []int) int { return a[0] - b[0] })
+	return cycles
+}
+
+// order returns the expressions that are neither in nor depending on a cycle, each after the expressions
+// it references and otherwise in catalog order
+func (g *dependencyGraph) order() []int {
+	n := len(g.entries)
+	remaining := make([]int, n) // Number of dependencies not yet ordered; references are distinct per expression
+	for i, dependencies := range g.dependencies {
+		remaining[i] = len(dependencies)
+	}
+
+	var ready, order []int
+	for i := range n {
+		if remaining[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		order = append(order, i)
+		for _, dependent := range g.dependents[i] {
+			if remaining[dependent]--; remaining[dependent] == 0 {
+				position, _ := slices.BinarySearch(ready, dependent)
+				ready = slices.Insert(ready, position, dependent)
+			}
+		}
+	}
+	return order
+}
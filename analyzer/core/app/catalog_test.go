@@ -0,0 +1,217 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+// newTestCatalog creates a catalog holding the given name/expression pairs in order
+func newTestCatalog(t *testing.T, app *App, pairs ...string) *Catalog {
+	t.Helper()
+	catalog := app.NewCatalog()
+	for i := 0; i < len(pairs); i += 2 {
+		require.NoError(t, catalog.Set(pairs[i], pairs[i+1]))
+	}
+	return catalog
+}
+
+func TestCatalog_Analyze(t *testing.T) {
+	catalog := newTestCatalog(t, NewApp(),
+		"margin", "[revenue] - [cost]",
+		"revenue", "[price] * [qty]",
+		"cost", "[unit_cost] * [qty]",
+		"margin_pct", "ROUND([margin] / [revenue] * 100, 1)",
+		"label", `CONCAT([name], ": ", UPPER([margin_pct]))`,
+	)
+
+	report := catalog.Analyze()
+	assert.Equal(t, []string{"revenue", "cost", "margin", "margin_pct", "label"}, report.Order)
+	assert.Empty(t, report.Cycles)
+	assert.Empty(t, report.Missing)
+
+	require.Len(t, report.Expressions, 5)
+	margin := report.Expressions[0]
+	assert.Equal(t, "margin", margin.Name)
+	assert.Equal(t, []string{"revenue", "cost"}, margin.Dependencies)
+	assert.Empty(t, margin.Columns)
+	assert.Equal(t, models.DataTypeNumber, margin.Type)
+	assert.Empty(t, margin.Errors)
+
+	revenue := report.Expressions[1]
+	assert.Empty(t, revenue.Dependencies)
+	assert.Equal(t, []string{"price", "qty"}, revenue.Columns)
+
+	// Types inferred for named expressions are used when checking the expressions referencing them
+	label := report.Expressions[4]
+	require.Len(t, label.Errors, 1)
+	assert.Contains(t, label.Errors[0].Message, "UPPER")
+	assert.Equal(t, models.DataTypeAny, label.Type)
+}
+
+func TestCatalog_Cycles(t *testing.T) {
+	catalog := newTestCatalog(t, NewApp(),
+		"a", "[b] + 1",
+		"b", "[c] * 2",
+		"c", "[a] - [base]",
+		"d", "[d] + 1",
+		"e", "[a] + [f]",
+		"f", "[base] / 2",
+	)
+
+	report := catalog.Analyze()
+	assert.Equal(t, [][]string{{"a", "b", "c"}, {"d"}}, report.Cycles)
+	// e depends on a cycle, so only f can be evaluated
+	assert.Equal(t, []string{"f"}, report.Order)
+	for _, expression := range report.Expressions {
+		if expression.Name != "f" {
+			assert.Equal(t, models.DataTypeAny, expression.Type, expression.Name)
+		}
+		assert.Empty(t, expression.Errors, expression.Name)
+	}
+}
+
+func TestCatalog_MissingDependencies(t *testing.T) {
+	app := NewApp()
+	app.SetSchema(models.NewSchema([]models.Column{
+		{Name: "price", Type: models.DataTypeNumber},
+		{Name: "qty", Type: models.DataTypeNumber},
+		{Name: "status", Type: models.DataTypeString},
+	}))
+	catalog := newTestCatalog(t, app,
+		"total", "[price] * [qty]",
+		"net", "[total] - [discount]",
+		"open", `[status] == "open" && [total] > 0`,
+	)
+
+	report := catalog.Analyze()
+	assert.Equal(t, []MissingDependency{{Expression: "net", Column: "discount"}}, report.Missing)
+	assert.Equal(t, []string{"total", "net", "open"}, report.Order)
+
+	net := report.Expressions[1]
+	require.Len(t, net.Errors, 1)
+	assert.Contains(t, net.Errors[0].Message, "Unknown column: [discount]")
+	assert.Equal(t, models.DataTypeBoolean, report.Expressions[2].Type)
+	assert.Empty(t, report.Expressions[2].Errors)
+}
+
+func TestCatalog_InvalidExpressions(t *testing.T) {
+	catalog := newTestCatalog(t, NewApp(),
+		"broken", "[a] +",
+		"empty", "",
+		"typed", `UPPER([broken])`,
+	)
+
+	report := catalog.Analyze()
+	assert.Equal(t, []string{"broken", "empty", "typed"}, report.Order)
+	assert.NotEmpty(t, report.Expressions[0].Errors)
+	require.Len(t, report.Expressions[1].Errors, 1)
+	assert.Equal(t, "Empty expression", report.Expressions[1].Errors[0].Message)
+	// An invalid named expression has type any, so its dependents are not reported for it
+	assert.Empty(t, report.Expressions[2].Errors)
+}
+
+func TestCatalog_Impact(t *testing.T) {
+	catalog := newTestCatalog(t, NewApp(),
+		"margin", "[revenue] - [cost]",
+		"revenue", "[price] * [qty]",
+		"cost", "[unit_cost] * [qty]",
+		"margin_pct", "[margin] / [revenue]",
+		"tax", "[price] * 0.2",
+		"loop", "[loop] + [unit_cost]",
+	)
+
+	testCases := []struct {
+		column   string
+		expected []string
+	}{
+		{"unit_cost", []string{"cost", "margin", "margin_pct", "loop"}},
+		{"qty", []string{"revenue", "cost", "margin", "margin_pct"}},
+		{"price", []string{"revenue", "margin", "margin_pct", "tax"}},
+		{"cost", []string{"margin", "margin_pct"}},
+		{"margin_pct", []string{}},
+		{"unknown", []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.column, func(t *testing.T) {
+			assert.Equal(t, tc.expected, catalog.Impact(tc.column))
+		})
+	}
+}
+
+func TestCatalog_Edit(t *testing.T) {
+	catalog := newTestCatalog(t, NewApp(), "a", "1", "b", "[a] + 1", "c", "[b] * 2")
+
+	require.NoError(t, catalog.Set("a", "[c]"))
+	entry, ok := catalog.Lookup("a")
+	require.True(t, ok)
+	assert.Equal(t, CatalogEntry{Name: "a", Expression: "[c]"}, entry)
+	assert.Equal(t, [][]string{{"a", "b", "c"}}, catalog.Analyze().Cycles)
+
+	assert.True(t, catalog.Remove("b"))
+	assert.False(t, catalog.Remove("b"))
+	assert.Equal(t, []CatalogEntry{{Name: "a", Expression: "[c]"}, {Name: "c", Expression: "[b] * 2"}}, catalog.Entries())
+	report := catalog.Analyze()
+	assert.Empty(t, report.Cycles)
+	assert.Equal(t, []string{"c", "a"}, report.Order)
+
+	assert.EqualError(t, catalog.Set("unit price", "1"), `invalid expression name "unit price": must be non-empty without brackets or whitespace`)
+	_, ok = catalog.Lookup("unit price")
+	assert.False(t, ok)
+}
+
+func TestCatalog_JSON(t *testing.T) {
+	app := NewApp()
+	catalog := newTestCatalog(t, app, "total", "[price] * [qty]", "label", `CONCAT("#", [id])`)
+
+	data, err := json.Marshal(catalog)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"expressions": [
+		{"name": "total", "expression": "[price] * [qty]"},
+		{"name": "label", "expression": "CONCAT(\"#\", [id])"}
+	]}`, string(data))
+
+	loaded, err := app.LoadCatalog(data)
+	require.NoError(t, err)
+	assert.Equal(t, catalog.Entries(), loaded.Entries())
+
+	testCases := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"malformed", `{"expressions": [`, "invalid catalog: unexpected end of JSON input"},
+		{"duplicate name", `{"expressions": [{"name": "a", "expression": "1"}, {"name": "a", "expression": "2"}]}`, `invalid catalog: duplicate expression name "a"`},
+		{"invalid name", `{"expressions": [{"name": "", "expression": "1"}]}`, `invalid catalog: invalid expression name "": must be non-empty without brackets or whitespace`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := app.LoadCatalog([]byte(tc.data))
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
+
+func TestCatalogReport_AsMap(t *testing.T) {
+	app := NewApp()
+	app.SetSchema(models.NewSchema([]models.Column{{Name: "x", Type: models.DataTypeNumber}}))
+	report := newTestCatalog(t, app, "a", "[x] + [b]", "b", "[y]").Analyze()
+
+	result := report.AsMap()
+	assert.Equal(t, []any{"b", "a"}, result["order"])
+	assert.Equal(t, []any{}, result["cycles"])
+	assert.Equal(t, []any{map[string]any{"expression": "b", "column": "y"}}, result["missing"])
+	expressions := result["expressions"].([]any)
+	require.Len(t, expressions, 2)
+	a := expressions[0].(map[string]any)
+	assert.Equal(t, "a", a["name"])
+	assert.Equal(t, "number", a["type"])
+	assert.Equal(t, []any{"b"}, a["dependencies"])
+	assert.Equal(t, []any{"x"}, a["columns"])
+	assert.Equal(t, []any{}, a["errors"])
+}
@@ -0,0 +1,226 @@
+package app
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"antlr-editor/analyzer/core/infrastructure"
+	"antlr-editor/analyzer/core/models"
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// CompletionResult lists the suggestions for a cursor position
+type CompletionResult struct {
+	Items []models.CompletionItem `json:"items"` // Suggestions, best first
+}
+
+// AsMap converts CompletionResult to a map for JSON serialization
+func (r *CompletionResult) AsMap() map[string]any {
+	items := make([]any, len(r.Items))
+	for i, item := range r.Items {
+		items[i] = item.AsMap()
+	}
+	return map[string]any{
+		"items": items,
+	}
+}
+
+// completionSymbol is a token offered as written, such as an operator or parenthesis
+type completionSymbol struct {
+	text   string
+	kind   models.CompletionKind
+	detail string
+}
+
+// completionSymbols maps the token types that are suggested as written to their items
+var completionSymbols = map[int]completionSymbol{
+	parser.ExpressionLexerLPAREN: {"(", models.CompletionPunctuation, "Group an expression"},
+	parser.ExpressionLexerRPAREN: {")", models.CompletionPunctuation, "Close parenthesis"},
+	parser.ExpressionLexerCOMMA:  {",", models.CompletionPunctuation, "Next argument"},
+	parser.ExpressionLexerADD:    {"+", models.CompletionOperator, "Addition"},
+	parser.ExpressionLexerSUB:    {"-", models.CompletionOperator, "Subtraction or negation"},
+	parser.ExpressionLexerMUL:    {"*", models.CompletionOperator, "Multiplication"},
+	parser.ExpressionLexerDIV:    {"/", models.CompletionOperator, "Division"},
+	parser.ExpressionLexerPOW:    {"^", models.CompletionOperator, "Power"},
+	parser.ExpressionLexerLT:     {"<", models.CompletionOperator, "Less than"},
+	parser.ExpressionLexerLE:     {"<=", models.CompletionOperator, "Less than or equal to"},
+	parser.ExpressionLexerGT:     {">", models.CompletionOperator, "Greater than"},
+	parser.ExpressionLexerGE:     {">=", models.CompletionOperator, "Greater than or equal to"},
+	parser.ExpressionLexerEQ:     {"==", models.CompletionOperator, "Equal to"},
+	parser.ExpressionLexerNEQ:    {"!=", models.CompletionOperator, "Not equal to"},
+	parser.ExpressionLexerAND:    {"&&", models.CompletionOperator, "Logical and"},
+	parser.ExpressionLexerOR:     {"||", models.CompletionOperator, "Logical or"},
+}
+
+// completionKindRank orders the kinds of suggestions: operands before the symbols that follow them
+var completionKindRank = map[models.CompletionKind]int{
+	models.CompletionColumn:      0,
+	models.CompletionFunction:    1,
+	models.CompletionLiteral:     2,
+	models.CompletionPunctuation: 3,
+	models.CompletionOperator:    4,
+}
+
+// Complete suggests what may be typed at the cursor offset, a code point position in the expression.
+// The suggestions are the tokens the grammar allows after the text before the cursor: operators after an operand,
+// columns, functions and literals where an operand is expected, and "," or ")" inside argument lists, "," only
+// while the function takes another argument according to the registry, as in signature help.
+// A column name or word being typed at the cursor filters the suggestions and is replaced by them;
+// inside string literals nothing is suggested. Columns come from the schema, or from the expression itself
+// when no schema is set. Items matching the typed text case-sensitively come first, then columns, functions,
+// literals, punctuation and operators.
+func (a *Analyzer) Complete(expression string, offset int) *CompletionResult {
+	result := &CompletionResult{Items: []models.CompletionItem{}}
+	runes := []rune(expression)
+	offset = max(0, min(offset, len(runes)))
+	if insideString(runes[:offset]) {
+		return result
+	}
+
+	// Find the column name or word being typed
+	start, end := offset, offset
+	isColumn := false
+	for start > 0 && !isColumnDelimiter(runes[start-1]) {
+		start--
+	}
+	if start > 0 && runes[start-1] == '[' {
+		start, isColumn = start-1, true
+		for end < len(runes) && !isColumnDelimiter(runes[end]) {
+			end++
+		}
+		if end < len(runes) && runes[end] == ']' {
+			end++
+		}
+	} else {
+		start = offset
+		for start > 0 && isWordRune(runes[start-1]) {
+			start--
+		}
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		if start < offset && unicode.IsDigit(runes[start]) {
+			// A number is being typed: suggest what may follow it
+			start, end = offset, offset
+		}
+	}
+	typed := string(runes[start:offset])
+	if isColumn {
+		typed = string(runes[start+1 : offset])
+	}
+	edit := models.TextEdit{Start: start, End: end}
+
+	add := func(label string, kind models.CompletionKind, detail, documentation, text string) {
+		if !strings.HasPrefix(strings.ToLower(label), strings.ToLower(typed)) {
+			return
+		}
+		item := models.CompletionItem{Label: label, Kind: kind, Detail: detail, Documentation: documentation, Edit: edit}
+		item.Edit.NewText = text
+		result.Items = append(result.Items, item)
+	}
+
+	prefix, expected := a.parsePrefix(string(runes[:edit.Start]))
+	for _, tokenType := range expected {
+		switch tokenType {
+		case parser.ExpressionLexerCOLUMN_REF:
+			for _, column := range a.completionColumns(expression) {
+				add(column.Name, models.CompletionColumn, string(column.Type), column.Description, "["+column.Name+"]")
+			}
+		case parser.ExpressionLexerFUNCTION_NAME:
+			if isColumn {
+				continue
+			}
+			for _, signature := range a.Functions() {
+				add(signature.Name, models.CompletionFunction, signature.Syntax(), signature.Description, signature.Name+"(")
+			}
+		case parser.ExpressionLexerBOOLEAN_LITERAL:
+			if isColumn {
+				continue
+			}
+			add("true", models.CompletionLiteral, string(models.DataTypeBoolean), "", "true")
+			add("false", models.CompletionLiteral, string(models.DataTypeBoolean), "", "false")
+		case parser.ExpressionLexerCOMMA:
+			if typed == "" && !isColumn && a.takesNextArgument(prefix, edit.Start) {
+				symbol := completionSymbols[tokenType]
+				add(symbol.text, symbol.kind, symbol.detail, "", symbol.text)
+			}
+		default:
+			if symbol, ok := completionSymbols[tokenType]; ok && typed == "" && !isColumn {
+				add(symbol.text, symbol.kind, symbol.detail, "", symbol.text)
+			}
+		}
+	}
+
+	sort.SliceStable(result.Items, func(i, j int) bool {
+		left, right := result.Items[i], result.Items[j]
+		leftExact, rightExact := strings.HasPrefix(left.Label, typed), strings.HasPrefix(right.Label, typed)
+		if leftExact != rightExact {
+			return leftExact
+		}
+		return completionKindRank[left.Kind] < completionKindRank[right.Kind]
+	})
+	return result
+}
+
+// parsePrefix parses the text before the cursor once and returns the parse and the token types that may
+// legally follow the text, in ascending order: those the parser's ATN accepts where it reaches the end
+func (a *Analyzer) parsePrefix(text string) (*parsedExpression, []int) {
+	listener := infrastructure.NewExpectedTokensListener()
+	ctx := a.helper.CreateParser(text)
+	a.helper.SetupErrorListeners(ctx, listener)
+	a.helper.SetupExpectedTokens(ctx, listener)
+	tree := a.helper.ParseExpression(ctx)
+	ctx.Stream.Fill()
+	return &parsedExpression{tree: tree, tokens: ctx.Stream.GetAllTokens()}, listener.Expected
+}
+
+// takesNextArgument reports whether the function call enclosing the end of the parsed text, at offset, takes an
+// argument after the one being written; calls of unknown functions take any number
+func (a *Analyzer) takesNextArgument(parsed *parsedExpression, offset int) bool {
+	help := a.signatureHelp(parsed, offset)
+	return help.Signature == nil || help.Signature.ParameterAt(help.ActiveParameter+1) != nil
+}
+
+// completionColumns returns the columns of the schema, or the columns the expression references if there is none,
+// in order of first reference; finding them only needs the tokens of the expression
+func (a *Analyzer) completionColumns(expression string) []models.Column {
+	if a.schema != nil {
+		return a.schema.Columns()
+	}
+	var columns []models.Column
+	seen := make(map[string]bool)
+	for _, token := range a.collectTokens(lexTokens(a.helper, expression)) {
+		if token.Type == models.TokenColumnReference && !seen[token.Text] {
+			seen[token.Text] = true
+			columns = append(columns, models.Column{Name: token.Text, Type: models.DataTypeAny})
+		}
+	}
+	return columns
+}
+
+// insideString reports whether text ends inside a string literal
+func insideString(text []rune) bool {
+	var quote rune
+	for i := 0; i < len(text); i++ {
+		switch r := text[i]; {
+		case quote == 0 && (r == '\'' || r == '"'):
+			quote = r
+		case quote != 0 && r == '\\':
+			i++
+		case r == quote, r == '\n', r == '\r':
+			quote = 0
+		}
+	}
+	return quote != 0
+}
+
+// isColumnDelimiter reports whether r cannot be part of a column name
+func isColumnDelimiter(r rune) bool {
+	return r == '[' || r == ']' || unicode.IsSpace(r)
+}
+
+// isWordRune reports whether r can be part of a function name or boolean literal
+func isWordRune(r rune) bool {
+	return r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+}
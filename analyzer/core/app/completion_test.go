@@ -0,0 +1,191 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/app/functions"
+	"antlr-editor/analyzer/core/models"
+)
+
+var operatorLabels = []string{"+", "-", "*", "/", "^", "<", "<=", ">", ">=", "==", "!=", "||", "&&"}
+
+func completionLabels(result *CompletionResult) []string {
+	labels := make([]string, len(result.Items))
+	for i, item := range result.Items {
+		labels[i] = item.Label
+	}
+	return labels
+}
+
+func TestAnalyzer_Complete(t *testing.T) {
+	analyzer := newAnalyzer()
+	analyzer.SetSchema(models.NewSchema([]models.Column{
+		{Name: "price", Type: models.DataTypeNumber, Description: "Unit price"},
+		{Name: "product", Type: models.DataTypeString},
+		{Name: "qty", Type: models.DataTypeNumber},
+	}))
+
+	testCases := []struct {
+		name       string
+		expression string
+		offset     int
+		contains   []string
+		excludes   []string
+	}{
+		{"empty expression", "", 0, []string{"price", "ROUND", "true", "(", "-"}, []string{"+", ",", ")"}},
+		{"after binary operator", "[price] * ", 10, []string{"qty", "ABS", "false", "("}, []string{"*", ")"}},
+		{"after operand", "[price] ", 8, operatorLabels, []string{"qty", "ROUND", ",", ")", "("}},
+		{"after operand in argument list", "ROUND([price] ", 14, append([]string{",", ")"}, operatorLabels...), []string{"qty", "ROUND"}},
+		{"after open parenthesis of call", "ROUND(", 6, []string{"price", "ROUND", "true", ")"}, []string{",", "*"}},
+		{"after comma", "ROUND([price], ", 15, []string{"qty", "ABS", "("}, []string{",", ")", "*"}},
+		{"inside grouping parentheses", "([price] ", 9, []string{")", "+"}, []string{","}},
+		{"after function name", "ROUND ", 6, []string{"("}, []string{"price", "+"}},
+		{"after complete call", "ROUND([price], 2)", 17, operatorLabels, []string{",", ")"}},
+		{"in middle of expression", "[price] *  + 1", 10, []string{"qty", "ABS"}, []string{"*"}},
+		{"operand after operand", "1 2", 3, []string{}, []string{"+", "price"}},
+		{"typing a number", "[qty] * 10", 10, operatorLabels, []string{"price"}},
+		{"inside string literal", "CONCAT('abc", 11, []string{}, []string{"price", "+"}},
+		{"after string literal", "CONCAT('a b' ", 13, []string{",", ")"}, []string{"price"}},
+		{"after last argument", "UPPER([product] ", 16, append([]string{")"}, operatorLabels...), []string{","}},
+		{"after last optional argument", "ROUND([price], 2 ", 17, []string{")"}, []string{","}},
+		{"after last argument of nested call", "ROUND(ABS([price] ", 18, []string{")"}, []string{","}},
+		{"nested call before argument", "ROUND(ABS([price]) ", 19, []string{",", ")"}, []string{}},
+		{"variadic arguments", "CONCAT('a', 'b', 'c' ", 21, []string{",", ")"}, []string{}},
+		{"unknown function", "FOO([price] ", 12, []string{",", ")"}, []string{}},
+		{"column after bracket", "[price] + [", 11, []string{"price", "product", "qty"}, []string{"ROUND", "("}},
+		{"column where operator expected", "[price] [", 9, []string{}, []string{"price"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			labels := completionLabels(analyzer.Complete(tc.expression, tc.offset))
+			for _, label := range tc.contains {
+				assert.Contains(t, labels, label)
+			}
+			for _, label := range tc.excludes {
+				assert.NotContains(t, labels, label)
+			}
+			if len(tc.contains) == 0 {
+				assert.Empty(t, labels)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_Complete_Filtering(t *testing.T) {
+	analyzer := newAnalyzer()
+	analyzer.SetSchema(models.NewSchema([]models.Column{
+		{Name: "price", Type: models.DataTypeNumber, Description: "Unit price"},
+		{Name: "product", Type: models.DataTypeString},
+		{Name: "qty", Type: models.DataTypeNumber},
+	}))
+
+	t.Run("function prefix", func(t *testing.T) {
+		result := analyzer.Complete("[qty] + RO", 10)
+		require.Equal(t, []string{"ROUND"}, completionLabels(result))
+		item := result.Items[0]
+		assert.Equal(t, models.CompletionFunction, item.Kind)
+		assert.Equal(t, models.TextEdit{Start: 8, End: 10, NewText: "ROUND("}, item.Edit)
+		assert.NotEmpty(t, item.Detail)
+		assert.NotEmpty(t, item.Documentation)
+	})
+
+	t.Run("lowercase word matches functions and literals", func(t *testing.T) {
+		result := analyzer.Complete("tr", 2)
+		assert.Equal(t, []string{"true", "TRIM"}, completionLabels(result), "case-sensitive matches first")
+	})
+
+	t.Run("word under cursor is replaced entirely", func(t *testing.T) {
+		result := analyzer.Complete("ROUxx([price])", 3)
+		require.Equal(t, []string{"ROUND"}, completionLabels(result))
+		assert.Equal(t, models.TextEdit{Start: 0, End: 5, NewText: "ROUND("}, result.Items[0].Edit)
+	})
+
+	t.Run("column prefix", func(t *testing.T) {
+		result := analyzer.Complete("ABS([pr", 7)
+		assert.Equal(t, []string{"price", "product"}, completionLabels(result))
+		assert.Equal(t, models.TextEdit{Start: 4, End: 7, NewText: "[price]"}, result.Items[0].Edit)
+		assert.Equal(t, models.CompletionColumn, result.Items[0].Kind)
+		assert.Equal(t, "number", result.Items[0].Detail)
+		assert.Equal(t, "Unit price", result.Items[0].Documentation)
+	})
+
+	t.Run("closed column reference is replaced with its bracket", func(t *testing.T) {
+		result := analyzer.Complete("[pr] + 1", 3)
+		require.Equal(t, []string{"price", "product"}, completionLabels(result))
+		assert.Equal(t, models.TextEdit{Start: 0, End: 4, NewText: "[price]"}, result.Items[0].Edit)
+	})
+
+	t.Run("operand position ranks columns before functions before literals", func(t *testing.T) {
+		labels := completionLabels(analyzer.Complete("", 0))
+		assert.Equal(t, []string{"price", "product", "qty"}, labels[:3])
+		assert.Equal(t, []string{"true", "false", "(", "-"}, labels[len(labels)-4:])
+	})
+
+	t.Run("argument list ranks separators before operators", func(t *testing.T) {
+		labels := completionLabels(analyzer.Complete("ROUND([price] ", 14))
+		assert.Equal(t, append([]string{")", ","}, operatorLabels...), labels)
+	})
+
+	t.Run("last argument ranks closing parenthesis first", func(t *testing.T) {
+		labels := completionLabels(analyzer.Complete("UPPER([product] ", 16))
+		assert.Equal(t, append([]string{")"}, operatorLabels...), labels)
+	})
+
+	t.Run("offset is clamped", func(t *testing.T) {
+		assert.Equal(t, completionLabels(analyzer.Complete("[qty] ", 6)), completionLabels(analyzer.Complete("[qty] ", 100)))
+		assert.Equal(t, completionLabels(analyzer.Complete("", 0)), completionLabels(analyzer.Complete("[qty]", -1)))
+	})
+}
+
+func TestAnalyzer_Complete_ColumnsWithoutSchema(t *testing.T) {
+	analyzer := newAnalyzer()
+	labels := completionLabels(analyzer.Complete("[b] + [a] * [", 13))
+	assert.Equal(t, []string{"b", "a"}, labels, "columns referenced by the expression are offered")
+}
+
+func TestAnalyzer_Complete_MultiByte(t *testing.T) {
+	analyzer := newAnalyzer()
+	analyzer.SetSchema(models.NewSchema([]models.Column{{Name: "顧客名", Type: models.DataTypeString}}))
+
+	result := analyzer.Complete("CONCAT('😀', [顧", 14)
+	require.Equal(t, []string{"顧客名"}, completionLabels(result))
+	assert.Equal(t, models.TextEdit{Start: 12, End: 14, NewText: "[顧客名]"}, result.Items[0].Edit)
+}
+
+func TestAnalyzer_Complete_CustomFunctions(t *testing.T) {
+	app := NewApp()
+	require.NoError(t, app.RegisterFunction(&functions.Function{
+		FunctionSignature: models.FunctionSignature{Name: "FX_RATE", ReturnType: models.DataTypeNumber},
+	}))
+	assert.Contains(t, completionLabels(app.Complete("FX", 2)), "FX_RATE")
+}
+
+func TestCompletionResult_AsMap(t *testing.T) {
+	result := NewApp().Complete("[a] && [", 8)
+	assert.Equal(t, map[string]any{
+		"items": []any{
+			map[string]any{
+				"label":         "a",
+				"kind":          "column",
+				"detail":        "any",
+				"documentation": "",
+				"edit":          map[string]any{"start": 7, "end": 8, "newText": "[a]"},
+			},
+		},
+	}, result.AsMap())
+}
+
+func BenchmarkAnalyzer_Complete(b *testing.B) {
+	analyzer := newAnalyzer()
+	expr := strings.Repeat("ROUND([price] * 2, 1) + ", 50) + "ROUND([price] "
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		analyzer.Complete(expr, len(expr))
+	}
+}
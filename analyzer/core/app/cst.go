@@ -0,0 +1,44 @@
+package app
+
+import (
+	"antlr-editor/analyzer/core/app/cst"
+	"antlr-editor/analyzer/core/models"
+)
+
+// CSTResult represents the result of parsing an expression into a concrete syntax tree
+type CSTResult struct {
+	Tree   *models.CSTNode    `json:"tree"`   // Root of the tree, never nil
+	Errors []models.ErrorInfo `json:"errors"` // Syntax errors
+}
+
+// AsMap converts CSTResult to a map for JSON serialization
+func (r *CSTResult) AsMap() map[string]any {
+	errors := make([]any, len(r.Errors))
+	for i, err := range r.Errors {
+		errors[i] = err.AsMap()
+	}
+
+	return map[string]any{
+		"tree":   r.Tree.AsMap(),
+		"errors": errors,
+	}
+}
+
+// ParseCST parses the expression into a lossless concrete syntax tree.
+// Unlike ParseTree, every character of the expression is kept: tokens carry the whitespace around them as trivia,
+// and text the parser could not place in the tree, such as invalid characters, is kept as trivia as well,
+// so Tree.String() returns exactly the expression even when it has syntax errors.
+func (a *Analyzer) ParseCST(expression string) *CSTResult {
+	return a.parseCST(lexAndParse(a.helper, expression))
+}
+
+// parseCST builds the ParseCST result from the parsed expression
+func (a *Analyzer) parseCST(parsed *parsedExpression) *CSTResult {
+	errors := a.syntaxErrors(parsed)
+	if errors == nil {
+		errors = []models.ErrorInfo{}
+	}
+
+	builder := cst.NewCSTBuilder(parsed.tokens)
+	return &CSTResult{Tree: builder.Build(parsed.tree), Errors: errors}
+}
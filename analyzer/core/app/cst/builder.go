@@ -0,0 +1,265 @@
+package cst
+
+import (
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/core/models"
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// errorChannel is the lexer channel of ERROR_CHAR tokens
+const errorChannel = 2
+
+// tokenTypes maps lexer token types to the node types of tokens
+var tokenTypes = map[int]models.NodeType{
+	parser.ExpressionLexerADD:             models.NodeTypeAdd,
+	parser.ExpressionLexerSUB:             models.NodeTypeSub,
+	parser.ExpressionLexerMUL:             models.NodeTypeMul,
+	parser.ExpressionLexerDIV:             models.NodeTypeDiv,
+	parser.ExpressionLexerPOW:             models.NodeTypePow,
+	parser.ExpressionLexerLT:              models.NodeTypeLt,
+	parser.ExpressionLexerLE:              models.NodeTypeLe,
+	parser.ExpressionLexerGT:              models.NodeTypeGt,
+	parser.ExpressionLexerGE:              models.NodeTypeGe,
+	parser.ExpressionLexerEQ:              models.NodeTypeEq,
+	parser.ExpressionLexerNEQ:             models.NodeTypeNeq,
+	parser.ExpressionLexerOR:              models.NodeTypeOr,
+	parser.ExpressionLexerAND:             models.NodeTypeAnd,
+	parser.ExpressionLexerLPAREN:          models.NodeTypeLParen,
+	parser.ExpressionLexerRPAREN:          models.NodeTypeRParen,
+	parser.ExpressionLexerLBRACKET:        models.NodeTypeLBracket,
+	parser.ExpressionLexerRBRACKET:        models.NodeTypeRBracket,
+	parser.ExpressionLexerCOMMA:           models.NodeTypeComma,
+	parser.ExpressionLexerBOOLEAN_LITERAL: models.NodeTypeBooleanLiteral,
+	parser.ExpressionLexerFLOAT_LITERAL:   models.NodeTypeFloatLiteral,
+	parser.ExpressionLexerINTEGER_LITERAL: models.NodeTypeIntegerLiteral,
+	parser.ExpressionLexerSTRING_LITERAL:  models.NodeTypeStringLiteral,
+	parser.ExpressionLexerFUNCTION_NAME:   models.NodeTypeFunctionName,
+	parser.ExpressionLexerCOLUMN_REF:      models.NodeTypeColumnRef,
+}
+
+// Builder builds a concrete syntax tree from an ANTLR parse tree and the tokens of all channels
+type Builder struct {
+	tokens []antlr.Token
+	inTree map[int]bool
+	leaves map[int]*models.CSTNode
+}
+
+// NewCSTBuilder creates a builder for the tokens of all channels of the parsed input, ending with EOF
+func NewCSTBuilder(tokens []antlr.Token) *Builder {
+	return &Builder{
+		tokens: tokens,
+		inTree: make(map[int]bool),
+		leaves: make(map[int]*models.CSTNode),
+	}
+}
+
+// Build returns the root of the concrete syntax tree; tree may be nil for an empty expression.
+// The root has the node of the expression, if any token was parsed, followed by an EOF token,
+// and its String method returns exactly the parsed input.
+func (b *Builder) Build(tree antlr.ParseTree) *models.CSTNode {
+	root := &models.CSTNode{Type: models.NodeTypeExpression}
+	if tree != nil {
+		if node, ok := b.buildNode(tree); ok {
+			root.Children = append(root.Children, node)
+		}
+	}
+
+	eof := b.tokens[len(b.tokens)-1]
+	root.Children = append(root.Children, b.newToken(models.NodeTypeEOF, eof))
+	root.Start, root.End = root.Children[0].Start, eof.GetStart()
+
+	// Slices of children are final, so the leaves can be addressed to attach trivia
+	b.inTree[eof.GetTokenIndex()] = true
+	b.indexLeaves(root)
+	b.attachTrivia()
+	return root
+}
+
+// buildNode converts a parse tree node, returning false for nodes without any token of the input
+func (b *Builder) buildNode(tree antlr.Tree) (models.CSTNode, bool) {
+	nodeType, ok := NodeType(tree)
+	if !ok {
+		return models.CSTNode{}, false
+	}
+	if terminal, isTerminal := tree.(antlr.TerminalNode); isTerminal {
+		return b.buildTerminal(terminal.GetSymbol(), nodeType)
+	}
+
+	result := models.CSTNode{Type: nodeType}
+	for _, child := range tree.GetChildren() {
+		if node, ok := b.buildNode(child); ok {
+			result.Children = append(result.Children, node)
+		}
+	}
+	if len(result.Children) == 0 {
+		return models.CSTNode{}, false
+	}
+	result.Start = result.Children[0].Start
+	result.End = result.Children[len(result.Children)-1].End
+	return result, true
+}
+
+// buildTerminal converts a token of the tree; tokens conjured by error recovery are not part of the input
+func (b *Builder) buildTerminal(token antlr.Token, nodeType models.NodeType) (models.CSTNode, bool) {
+	if token.GetTokenIndex() < 0 || token.GetTokenType() == antlr.TokenEOF || b.inTree[token.GetTokenIndex()] {
+		return models.CSTNode{}, false
+	}
+	b.inTree[token.GetTokenIndex()] = true
+	return b.newToken(nodeType, token), true
+}
+
+// newToken creates a token node without trivia
+func (b *Builder) newToken(nodeType models.NodeType, token antlr.Token) models.CSTNode {
+	text := token.GetText()
+	end := token.GetStop() + 1
+	if token.GetTokenType() == antlr.TokenEOF {
+		text, end = "", token.GetStart()
+	}
+	return models.CSTNode{
+		Type:     nodeType,
+		Text:     text,
+		Start:    token.GetStart(),
+		End:      end,
+		Leading:  []models.Trivia{},
+		Trailing: []models.Trivia{},
+		Children: []models.CSTNode{},
+	}
+}
+
+// indexLeaves records the token nodes of the tree by the index of their token in the stream
+func (b *Builder) indexLeaves(root *models.CSTNode) {
+	leaves := root.Tokens()
+	next := 0
+	for _, token := range b.tokens {
+		if next < len(leaves) && b.inTree[token.GetTokenIndex()] {
+			b.leaves[token.GetTokenIndex()] = leaves[next]
+			next++
+		}
+	}
+}
+
+// attachTrivia distributes the tokens that are not part of the tree over the leading and trailing trivia of the tree tokens
+func (b *Builder) attachTrivia() {
+	var previous *models.CSTNode
+	var pending []models.Trivia
+	lineEnded := false
+
+	for _, token := range b.tokens {
+		if leaf, ok := b.leaves[token.GetTokenIndex()]; ok {
+			leaf.Leading = append(leaf.Leading, pending...)
+			pending, previous, lineEnded = nil, leaf, false
+			continue
+		}
+
+		trivia := models.Trivia{
+			Kind:  triviaKind(token),
+			Text:  token.GetText(),
+			Start: token.GetStart(),
+			End:   token.GetStop() + 1,
+		}
+		if previous == nil || lineEnded {
+			pending = append(pending, trivia)
+			continue
+		}
+
+		// The line break ends the trailing trivia of the previous token; whitespace is ASCII,
+		// so byte offsets in its text are code point offsets
+		if index := lineBreakEnd(trivia.Text); index > 0 {
+			lineEnded = true
+			previous.Trailing = append(previous.Trailing, models.Trivia{
+				Kind:  trivia.Kind,
+				Text:  trivia.Text[:index],
+				Start: trivia.Start,
+				End:   trivia.Start + index,
+			})
+			if index < len(trivia.Text) {
+				pending = append(pending, models.Trivia{
+					Kind:  trivia.Kind,
+					Text:  trivia.Text[index:],
+					Start: trivia.Start + index,
+					End:   trivia.End,
+				})
+			}
+			continue
+		}
+		previous.Trailing = append(previous.Trailing, trivia)
+	}
+}
+
+// lineBreakEnd returns the offset just after the first line break of whitespace text, or 0 if there is none
+func lineBreakEnd(text string) int {
+	index := strings.IndexAny(text, "\r\n")
+	if index < 0 {
+		return 0
+	}
+	if strings.HasPrefix(text[index:], "\r\n") {
+		return index + 2
+	}
+	return index + 1
+}
+
+// triviaKind classifies a token that is not part of the tree
+func triviaKind(token antlr.Token) models.TriviaKind {
+	switch token.GetChannel() {
+	case antlr.LexerHidden:
+		return models.TriviaWhitespace
+	case errorChannel:
+		return models.TriviaInvalid
+	default:
+		return models.TriviaSkipped
+	}
+}
+
+// NodeType returns the node type of a parse tree node.
+// Returns false for tokens that have no node type, such as EOF.
+func NodeType(tree antlr.Tree) (models.NodeType, bool) {
+	switch node := tree.(type) {
+	case antlr.ErrorNode:
+		return models.NodeTypeError, true
+	case antlr.TerminalNode:
+		nodeType, ok := tokenTypes[node.GetSymbol().GetTokenType()]
+		return nodeType, ok
+	}
+	return ruleType(tree), true
+}
+
+// ruleType returns the node type of a parser rule context
+func ruleType(tree antlr.Tree) models.NodeType {
+	switch tree.(type) {
+	case *parser.LiteralExprContext:
+		return models.NodeTypeLiteralExpr
+	case *parser.ColumnRefExprContext:
+		return models.NodeTypeColumnRefExpr
+	case *parser.FunctionCallExprContext:
+		return models.NodeTypeFunctionCallExpr
+	case *parser.ParenExprContext:
+		return models.NodeTypeParenExpr
+	case *parser.UnaryMinusExprContext:
+		return models.NodeTypeUnaryMinusExpr
+	case *parser.PowerExprContext:
+		return models.NodeTypePowerExpr
+	case *parser.MulDivExprContext:
+		return models.NodeTypeMulDivExpr
+	case *parser.AddSubExprContext:
+		return models.NodeTypeAddSubExpr
+	case *parser.ComparisonExprContext:
+		return models.NodeTypeComparisonExpr
+	case *parser.AndExprContext:
+		return models.NodeTypeAndExpr
+	case *parser.OrExprContext:
+		return models.NodeTypeOrExpr
+	case *parser.LiteralContext:
+		return models.NodeTypeLiteral
+	case *parser.ColumnReferenceContext:
+		return models.NodeTypeColumnReference
+	case *parser.FunctionCallContext:
+		return models.NodeTypeFunctionCall
+	case *parser.ArgumentListContext:
+		return models.NodeTypeArgumentList
+	default:
+		return models.NodeTypeExpression
+	}
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+func TestAnalyzer_ParseCST_RoundTrip(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	testCases := []struct {
+		name       string
+		expression string
+		valid      bool
+	}{
+		{"empty", "", true},
+		{"whitespace only", " \t\n ", false},
+		{"compact", "[a]+[b]*2", true},
+		{"spaced", "  [a]  +  [b]  ", true},
+		{"multiline", "IF(\n    [qty] > 0,\n    [price],\n    0\n)\n", true},
+		{"windows line breaks", "[a] +\r\n  [b]\r\n", true},
+		{"unicode", `CONCAT("héllo", [名前]) `, true},
+		{"missing operand", "[a] + ", false},
+		{"unclosed call", "SUM(1, 2", false},
+		{"trailing tokens", "1 2 3", false},
+		{"invalid character", "[a] @ [b]", false},
+		{"empty arguments", "MAX(, )", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.ParseCST(tc.expression)
+			require.NotNil(t, result.Tree)
+			assert.Equal(t, tc.expression, result.Tree.String())
+			assert.Equal(t, tc.valid, len(result.Errors) == 0, result.Errors)
+
+			tokens := result.Tree.Tokens()
+			assert.Equal(t, models.NodeTypeEOF, tokens[len(tokens)-1].Type)
+		})
+	}
+}
+
+func TestAnalyzer_ParseCST_Trivia(t *testing.T) {
+	analyzer := newAnalyzer()
+	result := analyzer.ParseCST("  [a] +\n  [b] ")
+	require.Empty(t, result.Errors)
+
+	whitespace := func(text string, start int) models.Trivia {
+		return models.Trivia{Kind: models.TriviaWhitespace, Text: text, Start: start, End: start + len(text)}
+	}
+
+	tokens := result.Tree.Tokens()
+	require.Len(t, tokens, 4)
+
+	// Leading trivia of the first token is the whitespace before it
+	assert.Equal(t, "[a]", tokens[0].Text)
+	assert.Equal(t, []models.Trivia{whitespace("  ", 0)}, tokens[0].Leading)
+	assert.Equal(t, []models.Trivia{whitespace(" ", 5)}, tokens[0].Trailing)
+
+	// Trailing trivia ends with the line break, the indentation of the next line leads the next token
+	assert.Equal(t, models.NodeTypeAdd, tokens[1].Type)
+	assert.Equal(t, []models.Trivia{whitespace("\n", 7)}, tokens[1].Trailing)
+	assert.Equal(t, []models.Trivia{whitespace("  ", 8)}, tokens[2].Leading)
+	assert.Equal(t, 10, tokens[2].Start)
+	assert.Equal(t, 13, tokens[2].End)
+
+	// Whitespace at the end of the input trails the last token
+	assert.Equal(t, []models.Trivia{whitespace(" ", 13)}, tokens[2].Trailing)
+	assert.Equal(t, models.CSTNode{
+		Type: models.NodeTypeEOF, Start: 14, End: 14,
+		Leading: []models.Trivia{}, Trailing: []models.Trivia{}, Children: []models.CSTNode{},
+	}, *tokens[3])
+}
+
+func TestAnalyzer_ParseCST_Structure(t *testing.T) {
+	analyzer := newAnalyzer()
+	result := analyzer.ParseCST("SUM([a], 1) * 2")
+	require.Empty(t, result.Errors)
+
+	root := result.Tree
+	assert.Equal(t, models.NodeTypeExpression, root.Type)
+	assert.Equal(t, 0, root.Start)
+	assert.Equal(t, 15, root.End)
+	require.Len(t, root.Children, 2)
+
+	mulDiv := root.Children[0]
+	assert.Equal(t, models.NodeTypeMulDivExpr, mulDiv.Type)
+	assert.Equal(t, "SUM([a], 1) * 2", mulDiv.String())
+	assert.Equal(t, "", mulDiv.Text)
+	require.Len(t, mulDiv.Children, 3)
+	assert.Equal(t, models.NodeTypeMul, mulDiv.Children[1].Type)
+
+	call := mulDiv.Children[0].Children[0]
+	assert.Equal(t, models.NodeTypeFunctionCall, call.Type)
+	assert.Equal(t, 0, call.Start)
+	assert.Equal(t, 11, call.End)
+
+	var types []models.NodeType
+	for _, child := range call.Children {
+		types = append(types, child.Type)
+	}
+	assert.Equal(t, []models.NodeType{models.NodeTypeFunctionName, models.NodeTypeLParen, models.NodeTypeArgumentList, models.NodeTypeRParen}, types)
+
+	arguments := call.Children[2]
+	assert.Equal(t, "[a], 1", arguments.String())
+	assert.Equal(t, models.NodeTypeColumnRef, arguments.Children[0].Children[0].Children[0].Type)
+}
+
+func TestAnalyzer_ParseCST_UnparsedText(t *testing.T) {
+	analyzer := newAnalyzer()
+	result := analyzer.ParseCST("1 @ 2")
+	require.NotEmpty(t, result.Errors)
+
+	tokens := result.Tree.Tokens()
+	require.Len(t, tokens, 2)
+	assert.Equal(t, "1", tokens[0].Text)
+	assert.Equal(t, []models.Trivia{
+		{Kind: models.TriviaWhitespace, Text: " ", Start: 1, End: 2},
+		{Kind: models.TriviaInvalid, Text: "@", Start: 2, End: 3},
+		{Kind: models.TriviaWhitespace, Text: " ", Start: 3, End: 4},
+		{Kind: models.TriviaSkipped, Text: "2", Start: 4, End: 5},
+	}, tokens[0].Trailing)
+}
+
+func TestCSTResult_AsMap(t *testing.T) {
+	result := NewApp().ParseCST(" 1").AsMap()
+	assert.Equal(t, []any{}, result["errors"])
+
+	tree := result["tree"].(map[string]any)
+	assert.Equal(t, int(models.NodeTypeExpression), tree["type"])
+	children := tree["children"].([]any)
+	require.Len(t, children, 2)
+
+	literal := children[0].(map[string]any)["children"].([]any)[0].(map[string]any)
+	token := literal["children"].([]any)[0].(map[string]any)
+	assert.Equal(t, "1", token["text"])
+	assert.Equal(t, []any{map[string]any{"kind": "whitespace", "text": " ", "start": 0, "end": 1}}, token["leading"])
+	assert.Equal(t, []any{}, token["trailing"])
+	assert.Equal(t, []any{}, token["children"])
+}
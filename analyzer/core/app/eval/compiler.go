@@ -0,0 +1,377 @@
+package eval
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/core/app/functions"
+	"antlr-editor/analyzer/core/infrastructure"
+	"antlr-editor/analyzer/core/models"
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// node evaluates a compiled expression against the column values of one row.
+// present reports which slots hold a value; a nil present means every slot does.
+type node func(slots []models.Value, present []bool) (models.Value, *models.ErrorInfo)
+
+// Program is an expression compiled to a tree of closures.
+// Column references are resolved to slot indices, so evaluating a row needs neither parsing nor map lookups.
+// A Program is immutable and safe for concurrent use.
+type Program struct {
+	root       node
+	columns    []string
+	index      map[string]int
+	references []antlr.ParserRuleContext
+}
+
+// Columns returns the referenced column names; the position of a name is its slot index
+func (p *Program) Columns() []string {
+	return p.columns
+}
+
+// Slot returns the slot index of a column, or false if the expression does not reference it
+func (p *Program) Slot(column string) (int, bool) {
+	i, ok := p.index[column]
+	return i, ok
+}
+
+// ColumnError creates an error located at the first reference to the column in the given slot
+func (p *Program) ColumnError(slot int, message string) *models.ErrorInfo {
+	return errorAt(p.references[slot], message)
+}
+
+// Eval evaluates the program with slots[i] holding the value of Columns()[i].
+// Evaluation stops at the first error, which is located at the offending node as in Visitor.
+func (p *Program) Eval(slots []models.Value) (models.Value, *models.ErrorInfo) {
+	if len(slots) < len(p.columns) {
+		return models.NullValue(), &models.ErrorInfo{
+			Message: fmt.Sprintf("Expected %d column values, got %d", len(p.columns), len(slots)),
+			Line:    -1,
+			Column:  -1,
+			Start:   -1,
+			End:     -1,
+		}
+	}
+	return p.root(slots, nil)
+}
+
+// EvalPartial is like Eval but treats slots whose present flag is false as missing columns
+func (p *Program) EvalPartial(slots []models.Value, present []bool) (models.Value, *models.ErrorInfo) {
+	if len(slots) < len(p.columns) || len(present) < len(p.columns) {
+		return p.Eval(slots)
+	}
+	return p.root(slots, present)
+}
+
+// Compiler turns a parse tree into a Program
+type Compiler struct {
+	*parser.BaseExpressionVisitor
+	registry   *functions.Registry
+	columns    []string
+	index      map[string]int
+	references []antlr.ParserRuleContext
+	errors     []models.ErrorInfo
+}
+
+// Compile compiles a syntactically valid parse tree.
+// Calls to unknown functions are compile errors; everything else is checked when the program runs.
+func Compile(tree parser.IExpressionContext, registry *functions.Registry) (*Program, []models.ErrorInfo) {
+	compiler := &Compiler{
+		BaseExpressionVisitor: &parser.BaseExpressionVisitor{},
+		registry:              registry,
+		index:                 make(map[string]int),
+	}
+	root := compiler.compile(tree)
+	if len(compiler.errors) > 0 {
+		return nil, compiler.errors
+	}
+	return &Program{
+		root:       root,
+		columns:    compiler.columns,
+		index:      compiler.index,
+		references: compiler.references,
+	}, nil
+}
+
+// Visit visits a parse tree node and returns its compiled node
+func (c *Compiler) Visit(tree antlr.ParseTree) any {
+	if tree == nil {
+		return constant(models.NullValue())
+	}
+	if result, ok := tree.Accept(c).(node); ok {
+		return result
+	}
+	return constant(models.NullValue())
+}
+
+// compile compiles the given expression
+func (c *Compiler) compile(expr parser.IExpressionContext) node {
+	if expr == nil {
+		return constant(models.NullValue())
+	}
+	return c.Visit(expr).(node)
+}
+
+// slot returns the slot index of a column, allocating one on first reference
+func (c *Compiler) slot(column string, reference antlr.ParserRuleContext) int {
+	if i, ok := c.index[column]; ok {
+		return i
+	}
+	c.index[column] = len(c.columns)
+	c.columns = append(c.columns, column)
+	c.references = append(c.references, reference)
+	return len(c.columns) - 1
+}
+
+// constant returns a node that always yields value
+func constant(value models.Value) node {
+	return func([]models.Value, []bool) (models.Value, *models.ErrorInfo) {
+		return value, nil
+	}
+}
+
+// failing returns a node that always fails with err
+func failing(err *models.ErrorInfo) node {
+	return func([]models.Value, []bool) (models.Value, *models.ErrorInfo) {
+		return models.NullValue(), err
+	}
+}
+
+// VisitLiteralExpr compiles a literal expression
+func (c *Compiler) VisitLiteralExpr(ctx *parser.LiteralExprContext) any {
+	return c.Visit(ctx.Literal())
+}
+
+// VisitLiteral compiles a literal value to a constant
+func (c *Compiler) VisitLiteral(ctx *parser.LiteralContext) any {
+	switch {
+	case ctx.STRING_LITERAL() != nil:
+		return constant(models.StringValue(UnquoteString(ctx.STRING_LITERAL().GetText())))
+	case ctx.INTEGER_LITERAL() != nil, ctx.FLOAT_LITERAL() != nil:
+		number, err := strconv.ParseFloat(ctx.GetText(), 64)
+		if err != nil {
+			return failing(errorAt(ctx, fmt.Sprintf("Invalid number: %s", ctx.GetText())))
+		}
+		return constant(models.NumberValue(number))
+	case ctx.BOOLEAN_LITERAL() != nil:
+		return constant(models.BooleanValue(strings.EqualFold(ctx.GetText(), "true")))
+	default:
+		return failing(errorAt(ctx, "Invalid literal"))
+	}
+}
+
+// VisitColumnRefExpr compiles a column reference expression
+func (c *Compiler) VisitColumnRefExpr(ctx *parser.ColumnRefExprContext) any {
+	return c.Visit(ctx.ColumnReference())
+}
+
+// VisitColumnReference compiles a column reference to a slot read
+func (c *Compiler) VisitColumnReference(ctx *parser.ColumnReferenceContext) any {
+	text := ctx.COLUMN_REF().GetText()
+	slot := c.slot(text[1:len(text)-1], ctx)
+	missing := errorAt(ctx, "Unknown column: "+text)
+	return node(func(slots []models.Value, present []bool) (models.Value, *models.ErrorInfo) {
+		if present != nil && !present[slot] {
+			return models.NullValue(), missing
+		}
+		return slots[slot], nil
+	})
+}
+
+// VisitFunctionCallExpr compiles a function call expression
+func (c *Compiler) VisitFunctionCallExpr(ctx *parser.FunctionCallExprContext) any {
+	return c.Visit(ctx.FunctionCall())
+}
+
+// VisitFunctionCall resolves the called function and compiles its arguments
+func (c *Compiler) VisitFunctionCall(ctx *parser.FunctionCallContext) any {
+	name := ctx.FUNCTION_NAME().GetText()
+	fn, ok := c.registry.Lookup(name)
+	if !ok {
+		c.errors = append(c.errors, *errorAt(ctx, "Unknown function: "+name))
+		return constant(models.NullValue())
+	}
+
+	var exprs []parser.IExpressionContext
+	if argList := ctx.ArgumentList(); argList != nil {
+		exprs = argList.AllExpression()
+	}
+	args := make([]node, len(exprs))
+	for i, expr := range exprs {
+		args[i] = c.compile(expr)
+	}
+
+	// Rows evaluated one after another reuse the frames of the call, rows evaluated concurrently get their own
+	frames := &sync.Pool{New: func() any { return &callFrame{} }}
+	return node(func(slots []models.Value, present []bool) (models.Value, *models.ErrorInfo) {
+		frame := frames.Get().(*callFrame)
+		frame.args = compiledArguments{args: args, slots: slots, present: present}
+		result, err := frame.Call(fn, &frame.args)
+		argsErr := frame.args.err
+		frame.args = compiledArguments{}
+		frames.Put(frame)
+		if argsErr != nil {
+			return models.NullValue(), argsErr
+		}
+		if err != nil {
+			if argErr, ok := err.(*functions.ArgumentError); ok && argErr.Index >= 0 && argErr.Index < len(exprs) {
+				return models.NullValue(), errorAt(exprs[argErr.Index], argErr.Message)
+			}
+			return models.NullValue(), errorAt(ctx, err.Error())
+		}
+		return result, nil
+	})
+}
+
+// callFrame holds the arguments of one evaluation of a function call
+type callFrame struct {
+	functions.Frame
+	args compiledArguments
+}
+
+// compiledArguments evaluates the compiled arguments of a function call on demand
+type compiledArguments struct {
+	args    []node
+	slots   []models.Value
+	present []bool
+	err     *models.ErrorInfo
+}
+
+func (a *compiledArguments) Len() int {
+	return len(a.args)
+}
+
+func (a *compiledArguments) At(index int) (models.Value, error) {
+	value, err := a.args[index](a.slots, a.present)
+	if err != nil {
+		a.err = err
+		return models.NullValue(), errEvaluationFailed
+	}
+	return value, nil
+}
+
+// VisitParenExpr compiles a parenthesized expression
+func (c *Compiler) VisitParenExpr(ctx *parser.ParenExprContext) any {
+	return c.compile(ctx.Expression())
+}
+
+// VisitUnaryMinusExpr compiles a negation
+func (c *Compiler) VisitUnaryMinusExpr(ctx *parser.UnaryMinusExprContext) any {
+	operandCtx := ctx.Expression()
+	operand := c.compile(operandCtx)
+	return node(func(slots []models.Value, present []bool) (models.Value, *models.ErrorInfo) {
+		value, err := operand(slots, present)
+		if err != nil {
+			return models.NullValue(), err
+		}
+		result, opErr := Negate(value)
+		if opErr != nil {
+			return models.NullValue(), errorAt(operandCtx, opErr.Message)
+		}
+		return result, nil
+	})
+}
+
+// VisitPowerExpr compiles an exponentiation
+func (c *Compiler) VisitPowerExpr(ctx *parser.PowerExprContext) any {
+	return c.compileBinary(ctx, Arithmetic)
+}
+
+// VisitMulDivExpr compiles a multiplication/division
+func (c *Compiler) VisitMulDivExpr(ctx *parser.MulDivExprContext) any {
+	return c.compileBinary(ctx, Arithmetic)
+}
+
+// VisitAddSubExpr compiles an addition/subtraction
+func (c *Compiler) VisitAddSubExpr(ctx *parser.AddSubExprContext) any {
+	return c.compileBinary(ctx, Arithmetic)
+}
+
+// VisitComparisonExpr compiles a comparison
+func (c *Compiler) VisitComparisonExpr(ctx *parser.ComparisonExprContext) any {
+	return c.compileBinary(ctx, Compare)
+}
+
+// VisitAndExpr compiles a logical AND
+func (c *Compiler) VisitAndExpr(ctx *parser.AndExprContext) any {
+	return c.compileLogical(ctx)
+}
+
+// VisitOrExpr compiles a logical OR
+func (c *Compiler) VisitOrExpr(ctx *parser.OrExprContext) any {
+	return c.compileLogical(ctx)
+}
+
+// operatorFailure locates an operator error on the operand it refers to
+func operatorFailure(ctx infrastructure.BinaryExpressionContext, err *OperatorError) *models.ErrorInfo {
+	var target antlr.ParserRuleContext = ctx
+	if err.Operand != OperandNone {
+		if operand := ctx.Expression(err.Operand); operand != nil {
+			target = operand
+		}
+	}
+	return errorAt(target, err.Message)
+}
+
+// compileBinary compiles an operator whose operands are both always evaluated
+func (c *Compiler) compileBinary(ctx infrastructure.BinaryExpressionContext, apply func(string, models.Value, models.Value) (models.Value, *OperatorError)) node {
+	operator := infrastructure.OperatorText(ctx)
+	left, right := c.compile(ctx.Expression(0)), c.compile(ctx.Expression(1))
+
+	// Division by zero is the one runtime error worth precomputing: it may occur on every row
+	divisionByZero := errorAt(ctx, "Division by zero")
+	if rightCtx := ctx.Expression(1); rightCtx != nil {
+		divisionByZero = errorAt(rightCtx, "Division by zero")
+	}
+
+	return func(slots []models.Value, present []bool) (models.Value, *models.ErrorInfo) {
+		leftValue, err := left(slots, present)
+		if err != nil {
+			return models.NullValue(), err
+		}
+		rightValue, err := right(slots, present)
+		if err != nil {
+			return models.NullValue(), err
+		}
+		result, opErr := apply(operator, leftValue, rightValue)
+		if opErr != nil {
+			if opErr.Message == divisionByZero.Message && opErr.Operand == OperandRight {
+				return models.NullValue(), divisionByZero
+			}
+			return models.NullValue(), operatorFailure(ctx, opErr)
+		}
+		return result, nil
+	}
+}
+
+// compileLogical compiles && or ||, skipping the right operand when the left one decides the result
+func (c *Compiler) compileLogical(ctx infrastructure.BinaryExpressionContext) node {
+	operator := infrastructure.OperatorText(ctx)
+	left, right := c.compile(ctx.Expression(0)), c.compile(ctx.Expression(1))
+
+	return func(slots []models.Value, present []bool) (models.Value, *models.ErrorInfo) {
+		leftValue, err := left(slots, present)
+		if err != nil {
+			return models.NullValue(), err
+		}
+		if opErr := LogicalOperand(operator, OperandLeft, leftValue); opErr != nil {
+			return models.NullValue(), operatorFailure(ctx, opErr)
+		}
+		if ShortCircuits(operator, leftValue) {
+			return leftValue, nil
+		}
+
+		rightValue, err := right(slots, present)
+		if err != nil {
+			return models.NullValue(), err
+		}
+		if opErr := LogicalOperand(operator, OperandRight, rightValue); opErr != nil {
+			return models.NullValue(), operatorFailure(ctx, opErr)
+		}
+		return Logical(operator, leftValue, rightValue), nil
+	}
+}
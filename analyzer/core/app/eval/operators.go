@@ -0,0 +1,182 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+// Operand positions an OperatorError refers to
+const (
+	OperandNone  = -1 // The error concerns the whole operation
+	OperandLeft  = 0  // The error concerns the left (or only) operand
+	OperandRight = 1  // The error concerns the right operand
+)
+
+// OperatorError is an error raised while applying an operator, located on one of its operands
+type OperatorError struct {
+	Operand int    // One of OperandNone, OperandLeft or OperandRight
+	Message string // Human readable message
+}
+
+// Error implements the error interface
+func (e *OperatorError) Error() string {
+	return e.Message
+}
+
+// operandTypeError reports an operand of the wrong type using the type checker's wording
+func operandTypeError(operand int, operator string, expected, actual models.DataType) *OperatorError {
+	return &OperatorError{
+		Operand: operand,
+		Message: fmt.Sprintf("Operator '%s' expects %s operands, got %s", operator, expected, actual),
+	}
+}
+
+// Negate applies unary minus. Null stays null.
+func Negate(operand models.Value) (models.Value, *OperatorError) {
+	if operand.IsNull() {
+		return models.NullValue(), nil
+	}
+	if operand.Type() != models.DataTypeNumber {
+		return models.NullValue(), operandTypeError(OperandLeft, "-", models.DataTypeNumber, operand.Type())
+	}
+	return models.NumberValue(-operand.Number()), nil
+}
+
+// Arithmetic applies one of the operators + - * / ^ to two numbers.
+// Non-null operands must be numbers; if either operand is null the result is null.
+func Arithmetic(operator string, left, right models.Value) (models.Value, *OperatorError) {
+	if !left.IsNull() && left.Type() != models.DataTypeNumber {
+		return models.NullValue(), operandTypeError(OperandLeft, operator, models.DataTypeNumber, left.Type())
+	}
+	if !right.IsNull() && right.Type() != models.DataTypeNumber {
+		return models.NullValue(), operandTypeError(OperandRight, operator, models.DataTypeNumber, right.Type())
+	}
+	if left.IsNull() || right.IsNull() {
+		return models.NullValue(), nil
+	}
+
+	a, b := left.Number(), right.Number()
+	var result float64
+	switch operator {
+	case "+":
+		result = a + b
+	case "-":
+		result = a - b
+	case "*":
+		result = a * b
+	case "/":
+		if b == 0 {
+			return models.NullValue(), &OperatorError{Operand: OperandRight, Message: "Division by zero"}
+		}
+		result = a / b
+	case "^":
+		result = math.Pow(a, b)
+	default:
+		return models.NullValue(), &OperatorError{Operand: OperandNone, Message: fmt.Sprintf("Unknown arithmetic operator '%s'", operator)}
+	}
+
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return models.NullValue(), &OperatorError{Operand: OperandNone, Message: fmt.Sprintf("Operator '%s' produced a result that is not a finite number", operator)}
+	}
+	return models.NumberValue(result), nil
+}
+
+// Compare applies one of the operators < <= > >= == != to two values of the same type.
+// Ordering operators reject booleans; if either operand is null the result is null.
+func Compare(operator string, left, right models.Value) (models.Value, *OperatorError) {
+	ordering := operator != "==" && operator != "!="
+	if ordering {
+		if !left.IsNull() && left.Type() == models.DataTypeBoolean {
+			return models.NullValue(), &OperatorError{Operand: OperandLeft, Message: fmt.Sprintf("Operator '%s' cannot be applied to boolean operands", operator)}
+		}
+		if !right.IsNull() && right.Type() == models.DataTypeBoolean {
+			return models.NullValue(), &OperatorError{Operand: OperandRight, Message: fmt.Sprintf("Operator '%s' cannot be applied to boolean operands", operator)}
+		}
+	}
+	if left.IsNull() || right.IsNull() {
+		return models.NullValue(), nil
+	}
+	if left.Type() != right.Type() {
+		return models.NullValue(), &OperatorError{
+			Operand: OperandRight,
+			Message: fmt.Sprintf("Cannot compare %s with %s using '%s'", left.Type(), right.Type(), operator),
+		}
+	}
+
+	var order int
+	switch left.Type() {
+	case models.DataTypeNumber:
+		order = compareNumbers(left.Number(), right.Number())
+	case models.DataTypeString:
+		order = strings.Compare(left.Str(), right.Str())
+	case models.DataTypeDateTime:
+		order = left.DateTime().Compare(right.DateTime())
+	case models.DataTypeBoolean:
+		if left.Boolean() != right.Boolean() {
+			order = 1
+		}
+	}
+
+	switch operator {
+	case "<":
+		return models.BooleanValue(order < 0), nil
+	case "<=":
+		return models.BooleanValue(order <= 0), nil
+	case ">":
+		return models.BooleanValue(order > 0), nil
+	case ">=":
+		return models.BooleanValue(order >= 0), nil
+	case "==":
+		return models.BooleanValue(order == 0), nil
+	case "!=":
+		return models.BooleanValue(order != 0), nil
+	default:
+		return models.NullValue(), &OperatorError{Operand: OperandNone, Message: fmt.Sprintf("Unknown comparison operator '%s'", operator)}
+	}
+}
+
+// LogicalOperand checks that an operand of && or || is a boolean or null
+func LogicalOperand(operator string, operand int, value models.Value) *OperatorError {
+	if value.IsNull() || value.Type() == models.DataTypeBoolean {
+		return nil
+	}
+	return operandTypeError(operand, operator, models.DataTypeBoolean, value.Type())
+}
+
+// ShortCircuits reports whether the left operand alone decides the result of && or ||
+func ShortCircuits(operator string, left models.Value) bool {
+	if left.IsNull() {
+		return false
+	}
+	if operator == "&&" {
+		return !left.Boolean()
+	}
+	return left.Boolean()
+}
+
+// Logical applies && or || using three-valued logic: false && null is false, true || null is true,
+// and any other combination involving null is null
+func Logical(operator string, left, right models.Value) models.Value {
+	decisive := operator == "||"
+	if (!left.IsNull() && left.Boolean() == decisive) || (!right.IsNull() && right.Boolean() == decisive) {
+		return models.BooleanValue(decisive)
+	}
+	if left.IsNull() || right.IsNull() {
+		return models.NullValue()
+	}
+	return models.BooleanValue(!decisive)
+}
+
+func compareNumbers(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
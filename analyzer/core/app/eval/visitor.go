@@ -0,0 +1,312 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/core/app/functions"
+	"antlr-editor/analyzer/core/infrastructure"
+	"antlr-editor/analyzer/core/models"
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// errEvaluationFailed signals that an argument failed to evaluate; the error itself is held by the Visitor
+var errEvaluationFailed = errors.New("evaluation failed")
+
+// Visitor evaluates an expression tree against a row of column values.
+// Evaluation stops at the first error, which is reported with the position of the offending node.
+type Visitor struct {
+	*parser.BaseExpressionVisitor
+	registry *functions.Registry
+	row      map[string]any
+	err      *models.ErrorInfo
+}
+
+// NewEvaluateVisitor creates a new evaluation visitor resolving column references from row
+func NewEvaluateVisitor(registry *functions.Registry, row map[string]any) *Visitor {
+	return &Visitor{
+		BaseExpressionVisitor: &parser.BaseExpressionVisitor{},
+		registry:              registry,
+		row:                   row,
+	}
+}
+
+// Err returns the error that stopped evaluation, or nil if evaluation succeeded
+func (v *Visitor) Err() *models.ErrorInfo {
+	return v.err
+}
+
+// Visit visits a parse tree node and returns its models.Value
+func (v *Visitor) Visit(tree antlr.ParseTree) any {
+	if tree == nil || v.err != nil {
+		return models.NullValue()
+	}
+	if result, ok := tree.Accept(v).(models.Value); ok {
+		return result
+	}
+	return models.NullValue()
+}
+
+// valueOf visits the given expression and returns its value
+func (v *Visitor) valueOf(expr parser.IExpressionContext) models.Value {
+	if expr == nil {
+		return models.NullValue()
+	}
+	return v.Visit(expr).(models.Value)
+}
+
+// fail records the evaluation error located on the span of the given context
+func (v *Visitor) fail(ctx antlr.ParserRuleContext, message string) models.Value {
+	if v.err != nil {
+		return models.NullValue()
+	}
+	v.err = errorAt(ctx, message)
+	return models.NullValue()
+}
+
+// errorAt creates an error located on the span of the given context
+func errorAt(ctx antlr.ParserRuleContext, message string) *models.ErrorInfo {
+	err, ok := infrastructure.ErrorAt(ctx, message)
+	if !ok {
+		err = models.ErrorInfo{Message: message, Line: -1, Column: -1, Start: -1, End: -1}
+	}
+	return &err
+}
+
+// failOperator records an operator error on the operand it refers to
+func (v *Visitor) failOperator(ctx infrastructure.BinaryExpressionContext, err *OperatorError) models.Value {
+	var target antlr.ParserRuleContext = ctx
+	if err.Operand != OperandNone {
+		if operand := ctx.Expression(err.Operand); operand != nil {
+			target = operand
+		}
+	}
+	return v.fail(target, err.Message)
+}
+
+// VisitLiteralExpr evaluates a literal expression
+func (v *Visitor) VisitLiteralExpr(ctx *parser.LiteralExprContext) any {
+	return v.Visit(ctx.Literal())
+}
+
+// VisitLiteral evaluates a literal value
+func (v *Visitor) VisitLiteral(ctx *parser.LiteralContext) any {
+	switch {
+	case ctx.STRING_LITERAL() != nil:
+		return models.StringValue(UnquoteString(ctx.STRING_LITERAL().GetText()))
+	case ctx.INTEGER_LITERAL() != nil, ctx.FLOAT_LITERAL() != nil:
+		number, err := strconv.ParseFloat(ctx.GetText(), 64)
+		if err != nil {
+			return v.fail(ctx, fmt.Sprintf("Invalid number: %s", ctx.GetText()))
+		}
+		return models.NumberValue(number)
+	case ctx.BOOLEAN_LITERAL() != nil:
+		return models.BooleanValue(strings.EqualFold(ctx.GetText(), "true"))
+	default:
+		return v.fail(ctx, "Invalid literal")
+	}
+}
+
+// VisitColumnRefExpr evaluates a column reference expression
+func (v *Visitor) VisitColumnRefExpr(ctx *parser.ColumnRefExprContext) any {
+	return v.Visit(ctx.ColumnReference())
+}
+
+// VisitColumnReference looks up the referenced column in the row
+func (v *Visitor) VisitColumnReference(ctx *parser.ColumnReferenceContext) any {
+	text := ctx.COLUMN_REF().GetText()
+	raw, ok := v.row[text[1:len(text)-1]]
+	if !ok {
+		return v.fail(ctx, "Unknown column: "+text)
+	}
+	value, err := models.NewValue(raw)
+	if err != nil {
+		return v.fail(ctx, fmt.Sprintf("Invalid value for column %s: %s", text, err))
+	}
+	return value
+}
+
+// VisitFunctionCallExpr evaluates a function call expression
+func (v *Visitor) VisitFunctionCallExpr(ctx *parser.FunctionCallExprContext) any {
+	return v.Visit(ctx.FunctionCall())
+}
+
+// VisitFunctionCall evaluates a function call.
+// Argument errors are reported on the offending argument, arity errors on the whole call.
+func (v *Visitor) VisitFunctionCall(ctx *parser.FunctionCallContext) any {
+	name := ctx.FUNCTION_NAME().GetText()
+	fn, ok := v.registry.Lookup(name)
+	if !ok {
+		return v.fail(ctx, "Unknown function: "+name)
+	}
+
+	args := &callArguments{visitor: v}
+	if argList := ctx.ArgumentList(); argList != nil {
+		args.exprs = argList.AllExpression()
+	}
+
+	result, err := functions.Call(fn, args)
+	if v.err != nil {
+		return models.NullValue()
+	}
+	if err != nil {
+		if argErr, ok := err.(*functions.ArgumentError); ok && argErr.Index >= 0 && argErr.Index < len(args.exprs) {
+			return v.fail(args.exprs[argErr.Index], argErr.Message)
+		}
+		return v.fail(ctx, err.Error())
+	}
+	return result
+}
+
+// callArguments evaluates the arguments of a function call on demand
+type callArguments struct {
+	visitor *Visitor
+	exprs   []parser.IExpressionContext
+}
+
+func (a *callArguments) Len() int {
+	return len(a.exprs)
+}
+
+func (a *callArguments) At(index int) (models.Value, error) {
+	value := a.visitor.valueOf(a.exprs[index])
+	if a.visitor.err != nil {
+		return models.NullValue(), errEvaluationFailed
+	}
+	return value, nil
+}
+
+// VisitParenExpr evaluates a parenthesized expression
+func (v *Visitor) VisitParenExpr(ctx *parser.ParenExprContext) any {
+	return v.valueOf(ctx.Expression())
+}
+
+// VisitUnaryMinusExpr evaluates a negation
+func (v *Visitor) VisitUnaryMinusExpr(ctx *parser.UnaryMinusExprContext) any {
+	operand := v.valueOf(ctx.Expression())
+	if v.err != nil {
+		return models.NullValue()
+	}
+	result, err := Negate(operand)
+	if err != nil {
+		return v.fail(ctx.Expression(), err.Message)
+	}
+	return result
+}
+
+// VisitPowerExpr evaluates an exponentiation
+func (v *Visitor) VisitPowerExpr(ctx *parser.PowerExprContext) any {
+	return v.visitArithmetic(ctx)
+}
+
+// VisitMulDivExpr evaluates a multiplication/division
+func (v *Visitor) VisitMulDivExpr(ctx *parser.MulDivExprContext) any {
+	return v.visitArithmetic(ctx)
+}
+
+// VisitAddSubExpr evaluates an addition/subtraction
+func (v *Visitor) VisitAddSubExpr(ctx *parser.AddSubExprContext) any {
+	return v.visitArithmetic(ctx)
+}
+
+// VisitComparisonExpr evaluates a comparison
+func (v *Visitor) VisitComparisonExpr(ctx *parser.ComparisonExprContext) any {
+	left, right := v.valueOf(ctx.Expression(0)), v.valueOf(ctx.Expression(1))
+	if v.err != nil {
+		return models.NullValue()
+	}
+	result, err := Compare(infrastructure.OperatorText(ctx), left, right)
+	if err != nil {
+		return v.failOperator(ctx, err)
+	}
+	return result
+}
+
+// VisitAndExpr evaluates a logical AND
+func (v *Visitor) VisitAndExpr(ctx *parser.AndExprContext) any {
+	return v.visitLogical(ctx)
+}
+
+// VisitOrExpr evaluates a logical OR
+func (v *Visitor) VisitOrExpr(ctx *parser.OrExprContext) any {
+	return v.visitLogical(ctx)
+}
+
+// visitArithmetic evaluates both operands and applies an arithmetic operator
+func (v *Visitor) visitArithmetic(ctx infrastructure.BinaryExpressionContext) models.Value {
+	left, right := v.valueOf(ctx.Expression(0)), v.valueOf(ctx.Expression(1))
+	if v.err != nil {
+		return models.NullValue()
+	}
+	result, err := Arithmetic(infrastructure.OperatorText(ctx), left, right)
+	if err != nil {
+		return v.failOperator(ctx, err)
+	}
+	return result
+}
+
+// visitLogical evaluates a logical operator, skipping the right operand when the left one decides the result
+func (v *Visitor) visitLogical(ctx infrastructure.BinaryExpressionContext) models.Value {
+	operator := infrastructure.OperatorText(ctx)
+
+	left := v.valueOf(ctx.Expression(0))
+	if v.err != nil {
+		return models.NullValue()
+	}
+	if err := LogicalOperand(operator, OperandLeft, left); err != nil {
+		return v.failOperator(ctx, err)
+	}
+	if ShortCircuits(operator, left) {
+		return left
+	}
+
+	right := v.valueOf(ctx.Expression(1))
+	if v.err != nil {
+		return models.NullValue()
+	}
+	if err := LogicalOperand(operator, OperandRight, right); err != nil {
+		return v.failOperator(ctx, err)
+	}
+	return Logical(operator, left, right)
+}
+
+// UnquoteString strips the quotes of a STRING_LITERAL token and resolves its backslash escapes.
+// \n, \r and \t denote control characters; any other escaped character stands for itself.
+func UnquoteString(literal string) string {
+	if len(literal) < 2 {
+		return literal
+	}
+	body := literal[1 : len(literal)-1]
+	if !strings.Contains(body, `\`) {
+		return body
+	}
+
+	var builder strings.Builder
+	escaped := false
+	for _, r := range body {
+		if !escaped {
+			if r == '\\' {
+				escaped = true
+			} else {
+				builder.WriteRune(r)
+			}
+			continue
+		}
+		escaped = false
+		switch r {
+		case 'n':
+			builder.WriteRune('\n')
+		case 'r':
+			builder.WriteRune('\r')
+		case 't':
+			builder.WriteRune('\t')
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
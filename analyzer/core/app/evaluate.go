@@ -0,0 +1,131 @@
+package app
+
+import (
+	"fmt"
+
+	"antlr-editor/analyzer/core/app/eval"
+	"antlr-editor/analyzer/core/models"
+)
+
+// EvaluateResult represents the result of evaluating an expression
+type EvaluateResult struct {
+	Value  models.Value       `json:"value"`  // Resulting value, null if evaluation failed
+	Errors []models.ErrorInfo `json:"errors"` // Syntax errors, or the single error that stopped evaluation
+}
+
+// AsMap converts EvaluateResult to a map for JSON serialization
+func (r *EvaluateResult) AsMap() map[string]any {
+	errors := make([]any, len(r.Errors))
+	for i, err := range r.Errors {
+		errors[i] = err.AsMap()
+	}
+	return map[string]any{
+		"value":  r.Value.AsMap(),
+		"errors": errors,
+	}
+}
+
+// Evaluate computes the value of the expression for a single row.
+// Row values may be nil, bool, string, any integer or float kind, time.Time or models.Value.
+// Syntax errors are reported without evaluating; runtime errors such as a missing column,
+// a type mismatch or a division by zero stop evaluation and are reported at the offending node.
+func (a *Analyzer) Evaluate(expression string, row map[string]any) *EvaluateResult {
+	if expression == "" {
+		return &EvaluateResult{
+			Value:  models.NullValue(),
+			Errors: []models.ErrorInfo{{Message: "Empty expression", Line: 1, Column: 0, Start: 0, End: 0}},
+		}
+	}
+
+	tree, errors := a.parseWithSyntaxErrors(expression)
+	if len(errors) > 0 || tree == nil {
+		return &EvaluateResult{Value: models.NullValue(), Errors: errors}
+	}
+
+	visitor := eval.NewEvaluateVisitor(a.registry, row)
+	value := visitor.Visit(tree).(models.Value)
+	if err := visitor.Err(); err != nil {
+		return &EvaluateResult{Value: models.NullValue(), Errors: []models.ErrorInfo{*err}}
+	}
+	return &EvaluateResult{Value: value, Errors: []models.ErrorInfo{}}
+}
+
+// CompiledExpression is an expression parsed and compiled once, to be evaluated for many rows.
+// It is safe for concurrent use. Functions are resolved at compile time, so registering
+// a function afterwards does not affect an existing CompiledExpression.
+type CompiledExpression struct {
+	program   *eval.Program
+	positions *models.PositionMap // Converts the positions of runtime errors to the position encoding of the App
+}
+
+// Compile parses the expression and compiles it for repeated evaluation.
+// Syntax errors and calls to unknown functions are reported instead of a CompiledExpression.
+func (a *Analyzer) Compile(expression string) (*CompiledExpression, []models.ErrorInfo) {
+	if expression == "" {
+		return nil, []models.ErrorInfo{{Message: "Empty expression", Line: 1, Column: 0, Start: 0, End: 0}}
+	}
+
+	tree, errors := a.parseWithSyntaxErrors(expression)
+	if len(errors) > 0 || tree == nil {
+		return nil, errors
+	}
+
+	program, errors := eval.Compile(tree, a.registry)
+	if len(errors) > 0 {
+		return nil, errors
+	}
+	return &CompiledExpression{program: program}, nil
+}
+
+// Columns returns the columns referenced by the expression, in slot order
+func (c *CompiledExpression) Columns() []string {
+	return c.program.Columns()
+}
+
+// Slot returns the slot index of a column, or false if the expression does not reference it
+func (c *CompiledExpression) Slot(column string) (int, bool) {
+	return c.program.Slot(column)
+}
+
+// Eval evaluates the expression with slots[i] holding the value of Columns()[i].
+// This is the fast path: it neither parses nor looks up columns by name.
+func (c *CompiledExpression) Eval(slots []models.Value) (models.Value, *models.ErrorInfo) {
+	value, err := c.program.Eval(slots)
+	return value, c.encodeError(err)
+}
+
+// encodeError converts the positions of a runtime error, if any, to the position encoding
+func (c *CompiledExpression) encodeError(err *models.ErrorInfo) *models.ErrorInfo {
+	if err == nil || c.positions == nil {
+		return err
+	}
+	encoded := c.positions.Error(*err)
+	return &encoded
+}
+
+// Evaluate evaluates the expression for a row of column values keyed by column name,
+// with the same results as Analyzer.Evaluate except that an invalid column value is
+// reported even if the branch referencing it would not have been evaluated
+func (c *CompiledExpression) Evaluate(row map[string]any) *EvaluateResult {
+	columns := c.program.Columns()
+	slots := make([]models.Value, len(columns))
+	present := make([]bool, len(columns))
+	for i, column := range columns {
+		raw, ok := row[column]
+		if !ok {
+			continue
+		}
+		value, err := models.NewValue(raw)
+		if err != nil {
+			message := fmt.Sprintf("Invalid value for column [%s]: %s", column, err.Error())
+			return &EvaluateResult{Value: models.NullValue(), Errors: []models.ErrorInfo{*c.encodeError(c.program.ColumnError(i, message))}}
+		}
+		slots[i], present[i] = value, true
+	}
+
+	value, err := c.program.EvalPartial(slots, present)
+	if err != nil {
+		return &EvaluateResult{Value: models.NullValue(), Errors: []models.ErrorInfo{*c.encodeError(err)}}
+	}
+	return &EvaluateResult{Value: value, Errors: []models.ErrorInfo{}}
+}
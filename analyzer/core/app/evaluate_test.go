@@ -0,0 +1,558 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+func TestAnalyzer_Evaluate(t *testing.T) {
+	analyzer := newAnalyzer()
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	row := map[string]any{
+		"price":    19.5,
+		"quantity": 4,
+		"name":     "Widget",
+		"active":   true,
+		"discount": nil,
+		"created":  created,
+		"updated":  created.Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   models.Value
+	}{
+		// Literals
+		{"integer", "42", models.NumberValue(42)},
+		{"float", "3.25", models.NumberValue(3.25)},
+		{"exponent", "1.5e2", models.NumberValue(150)},
+		{"single quoted string", "'hello'", models.StringValue("hello")},
+		{"double quoted string", `"hello"`, models.StringValue("hello")},
+		{"string escapes", `'it\'s\n\\'`, models.StringValue("it's\n\\")},
+		{"boolean", "TRUE", models.BooleanValue(true)},
+
+		// Arithmetic and precedence
+		{"precedence", "1 + 2 * 3", models.NumberValue(7)},
+		{"parentheses", "(1 + 2) * 3", models.NumberValue(9)},
+		{"left associative subtraction", "10 - 4 - 3", models.NumberValue(3)},
+		{"left associative division", "16 / 4 / 2", models.NumberValue(2)},
+		{"right associative power", "2 ^ 3 ^ 2", models.NumberValue(512)},
+		{"power binds tighter than multiplication", "2 * 3 ^ 2", models.NumberValue(18)},
+		{"unary minus", "-3 + 5", models.NumberValue(2)},
+		{"unary minus on power base", "-2 ^ 2", models.NumberValue(4)},
+		{"double negation", "--4", models.NumberValue(4)},
+
+		// Columns
+		{"number column", "[price] * [quantity]", models.NumberValue(78)},
+		{"string column", "[name]", models.StringValue("Widget")},
+		{"null column", "[discount]", models.NullValue()},
+		{"null propagates through arithmetic", "[price] - [discount]", models.NullValue()},
+
+		// Comparisons
+		{"number comparison", "[price] * [quantity] > 50", models.BooleanValue(true)},
+		{"string comparison", "[name] < 'Zebra'", models.BooleanValue(true)},
+		{"string equality", "[name] == 'Widget'", models.BooleanValue(true)},
+		{"boolean inequality", "[active] != false", models.BooleanValue(true)},
+		{"datetime comparison", "[created] < [updated]", models.BooleanValue(true)},
+		{"comparison with null", "[discount] == 0", models.NullValue()},
+		{"comparison binds looser than arithmetic", "1 + 1 == 2", models.BooleanValue(true)},
+
+		// Logical operators
+		{"and binds tighter than or", "true || false && false", models.BooleanValue(true)},
+		{"and", "[active] && [price] > 10", models.BooleanValue(true)},
+		{"or", "false || [quantity] == 4", models.BooleanValue(true)},
+		{"false and null", "false && [discount] > 0", models.BooleanValue(false)},
+		{"true or null", "true || [discount] > 0", models.BooleanValue(true)},
+		{"true and null", "true && [discount] > 0", models.NullValue()},
+		{"short circuit skips errors", "false && 1 / 0 > 0", models.BooleanValue(false)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.Evaluate(tc.expression, row)
+			assert.Empty(t, result.Errors)
+			assert.True(t, tc.expected.Equal(result.Value), "Evaluate(%q) = %v, want %v", tc.expression, result.Value, tc.expected)
+		})
+	}
+}
+
+func TestAnalyzer_Evaluate_Errors(t *testing.T) {
+	analyzer := newAnalyzer()
+	row := map[string]any{
+		"price": 10,
+		"name":  "Widget",
+		"tags":  []string{"a"},
+	}
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   models.ErrorInfo
+	}{
+		{
+			"empty expression", "",
+			models.ErrorInfo{Message: "Empty expression", Line: 1, Column: 0, Start: 0, End: 0},
+		},
+		{
+			"syntax error", "1 +",
+			models.ErrorInfo{Message: "mismatched input '<EOF>' expecting {'-', '(', BOOLEAN_LITERAL, FLOAT_LITERAL, INTEGER_LITERAL, STRING_LITERAL, FUNCTION_NAME, COLUMN_REF}", Line: 1, Column: 3, Start: 3, End: 3},
+		},
+		{
+			"missing column", "[price] + [tax]",
+			models.ErrorInfo{Message: "Unknown column: [tax]", Line: 1, Column: 10, Start: 10, End: 15},
+		},
+		{
+			"unsupported column value", "[tags]",
+			models.ErrorInfo{Message: "Invalid value for column [tags]: unsupported value type []string", Line: 1, Column: 0, Start: 0, End: 6},
+		},
+		{
+			"division by zero", "[price] / (5 - 5)",
+			models.ErrorInfo{Message: "Division by zero", Line: 1, Column: 10, Start: 10, End: 17},
+		},
+		{
+			"operand type mismatch", "1 + [name] * 2",
+			models.ErrorInfo{Message: "Operator '*' expects number operands, got string", Line: 1, Column: 4, Start: 4, End: 10},
+		},
+		{
+			"comparing different types", "[price] == '10'",
+			models.ErrorInfo{Message: "Cannot compare number with string using '=='", Line: 1, Column: 11, Start: 11, End: 15},
+		},
+		{
+			"non-boolean logical operand", "[price] || true",
+			models.ErrorInfo{Message: "Operator '||' expects boolean operands, got number", Line: 1, Column: 0, Start: 0, End: 7},
+		},
+		{
+			"negated string", "-[name]",
+			models.ErrorInfo{Message: "Operator '-' expects number operands, got string", Line: 1, Column: 1, Start: 1, End: 7},
+		},
+		{
+			"non-finite result", "(0 - 8) ^ 0.5",
+			models.ErrorInfo{Message: "Operator '^' produced a result that is not a finite number", Line: 1, Column: 0, Start: 0, End: 13},
+		},
+		{
+			"unknown function", "FOO(1)",
+			models.ErrorInfo{Message: "Unknown function: FOO", Line: 1, Column: 0, Start: 0, End: 6},
+		},
+		{
+			"multiline position", "1 +\n  [missing]",
+			models.ErrorInfo{Message: "Unknown column: [missing]", Line: 2, Column: 2, Start: 6, End: 15},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.Evaluate(tc.expression, row)
+			assert.True(t, result.Value.IsNull())
+			assert.Equal(t, []models.ErrorInfo{tc.expected}, result.Errors)
+		})
+	}
+}
+
+func TestAnalyzer_Evaluate_Functions(t *testing.T) {
+	analyzer := newAnalyzer()
+	created := time.Date(2024, 3, 15, 18, 30, 0, 0, time.UTC)
+	row := map[string]any{
+		"name":     "  Widget  ",
+		"city":     "Zürich",
+		"price":    19.456,
+		"zero":     0,
+		"missing":  nil,
+		"created":  created,
+		"fallback": "n/a",
+	}
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   models.Value
+	}{
+		// String functions
+		{"UPPER", "UPPER('hello')", models.StringValue("HELLO")},
+		{"LOWER", "LOWER('HeLLo')", models.StringValue("hello")},
+		{"TRIM", "TRIM([name])", models.StringValue("Widget")},
+		{"LENGTH counts characters", "LENGTH([city])", models.NumberValue(6)},
+		{"LEN", "LEN('')", models.NumberValue(0)},
+		{"CONCAT", "CONCAT('Hello', ' ', 'World')", models.StringValue("Hello World")},
+		{"CONCAT single", "CONCAT('a')", models.StringValue("a")},
+		{"SUBSTRING zero-based", "SUBSTRING('hello', 2, 3)", models.StringValue("llo")},
+		{"SUBSTRING from start", "SUBSTRING('hello', 0, 2)", models.StringValue("he")},
+		{"SUBSTRING clamps length", "SUBSTRING('hello', 3, 10)", models.StringValue("lo")},
+		{"SUBSTRING at end", "SUBSTRING('hello', 5, 1)", models.StringValue("")},
+		{"SUBSTRING multibyte", "SUBSTRING([city], 1, 2)", models.StringValue("ür")},
+		{"REPLACE", "REPLACE('hello', 'l', 'r')", models.StringValue("herro")},
+
+		// Math functions
+		{"ROUND", "ROUND(3.14159, 2)", models.NumberValue(3.14)},
+		{"ROUND default decimals", "ROUND([price])", models.NumberValue(19)},
+		{"ROUND half away from zero", "ROUND(-2.5)", models.NumberValue(-3)},
+		{"ROUND negative decimals", "ROUND(1234, -2)", models.NumberValue(1200)},
+		{"FLOOR", "FLOOR(3.7)", models.NumberValue(3)},
+		{"CEIL", "CEIL(3.2)", models.NumberValue(4)},
+		{"ABS", "ABS(-5)", models.NumberValue(5)},
+
+		// Aggregate functions
+		{"MIN numbers", "MIN(10, 20, 5)", models.NumberValue(5)},
+		{"MAX numbers", "MAX(10, 20, 5)", models.NumberValue(20)},
+		{"MIN strings", "MIN('pear', 'apple')", models.StringValue("apple")},
+		{"MAX ignores null", "MAX([missing], 3)", models.NumberValue(3)},
+		{"MIN of nulls", "MIN([missing])", models.NullValue()},
+		{"SUM", "SUM(10, 20, 30)", models.NumberValue(60)},
+		{"SUM ignores null", "SUM(1, [missing], 2)", models.NumberValue(3)},
+		{"AVG", "AVG(1, 2, 3, 6)", models.NumberValue(3)},
+		{"AVG ignores null", "AVG([missing], 4)", models.NumberValue(4)},
+		{"COUNT", "COUNT([name], [missing], 1)", models.NumberValue(2)},
+		{"COUNT no arguments", "COUNT()", models.NumberValue(0)},
+
+		// Conditional functions
+		{"IF true", "IF(1 < 2, 'yes', 'no')", models.StringValue("yes")},
+		{"IF false", "IF(1 > 2, 'yes', 'no')", models.StringValue("no")},
+		{"IF null condition", "IF([missing] > 1, 'yes', 'no')", models.StringValue("no")},
+		{"IF only evaluates selected branch", "IF([zero] == 0, 0, 1 / [zero])", models.NumberValue(0)},
+		{"COALESCE", "COALESCE([missing], [fallback], 'default')", models.StringValue("n/a")},
+		{"COALESCE skips later arguments", "COALESCE(1, 1 / [zero])", models.NumberValue(1)},
+		{"COALESCE all null", "COALESCE([missing])", models.NullValue()},
+
+		// Datetime functions
+		{"DATE", "DATE([created])", models.DateTimeValue(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC))},
+		{"YEAR", "YEAR([created])", models.NumberValue(2024)},
+		{"MONTH", "MONTH([created])", models.NumberValue(3)},
+		{"DAY", "DAY([created])", models.NumberValue(15)},
+		{"NOW is after a past date", "NOW() > [created]", models.BooleanValue(true)},
+
+		// Null propagation and composition
+		{"null argument yields null", "UPPER([missing])", models.NullValue()},
+		{"nested calls", "LENGTH(CONCAT(UPPER('ab'), LOWER('CD')))", models.NumberValue(4)},
+		{"call in arithmetic", "ABS(-2) ^ 3 + 1", models.NumberValue(9)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.Evaluate(tc.expression, row)
+			assert.Empty(t, result.Errors)
+			assert.True(t, tc.expected.Equal(result.Value), "Evaluate(%q) = %v, want %v", tc.expression, result.Value, tc.expected)
+		})
+	}
+}
+
+func TestAnalyzer_Evaluate_FunctionErrors(t *testing.T) {
+	analyzer := newAnalyzer()
+	row := map[string]any{"name": "Widget", "zero": 0}
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   models.ErrorInfo
+	}{
+		{
+			"wrong argument type", "UPPER(42)",
+			models.ErrorInfo{Message: "Argument 'text' of UPPER expects string, got number", Line: 1, Column: 6, Start: 6, End: 8},
+		},
+		{
+			"wrong variadic argument type", "CONCAT('a', [zero])",
+			models.ErrorInfo{Message: "Argument 'text' of CONCAT expects string, got number", Line: 1, Column: 12, Start: 12, End: 18},
+		},
+		{
+			"too few arguments", "SUBSTRING('abc', 1)",
+			models.ErrorInfo{Message: "Function SUBSTRING expects 3 arguments, got 2", Line: 1, Column: 0, Start: 0, End: 19},
+		},
+		{
+			"start out of range", "SUBSTRING([name], 7, 1)",
+			models.ErrorInfo{Message: "Argument 'start' of SUBSTRING is out of range: 7 is not between 0 and 6", Line: 1, Column: 18, Start: 18, End: 19},
+		},
+		{
+			"negative start", "SUBSTRING([name], -1, 1)",
+			models.ErrorInfo{Message: "Argument 'start' of SUBSTRING is out of range: -1 is not between 0 and 6", Line: 1, Column: 18, Start: 18, End: 20},
+		},
+		{
+			"negative length", "SUBSTRING([name], 1, 0 - 2)",
+			models.ErrorInfo{Message: "Argument 'length' of SUBSTRING must not be negative, got -2", Line: 1, Column: 21, Start: 21, End: 26},
+		},
+		{
+			"fractional index", "SUBSTRING([name], 1.5, 2)",
+			models.ErrorInfo{Message: "Argument 'start' of SUBSTRING must be an integer, got 1.5", Line: 1, Column: 18, Start: 18, End: 21},
+		},
+		{
+			"empty search", "REPLACE([name], '', 'x')",
+			models.ErrorInfo{Message: "Argument 'search' of REPLACE must not be empty", Line: 1, Column: 16, Start: 16, End: 18},
+		},
+		{
+			"decimals out of range", "ROUND(1, 16)",
+			models.ErrorInfo{Message: "Argument 'decimals' of ROUND is out of range: 16 is not between -15 and 15", Line: 1, Column: 9, Start: 9, End: 11},
+		},
+		{
+			"mixed types in MAX", "MAX(1, 'a')",
+			models.ErrorInfo{Message: "Function MAX cannot compare number with string", Line: 1, Column: 7, Start: 7, End: 10},
+		},
+		{
+			"booleans in MIN", "MIN(true, false)",
+			models.ErrorInfo{Message: "Function MIN cannot compare boolean values", Line: 1, Column: 4, Start: 4, End: 8},
+		},
+		{
+			"non-boolean condition", "IF([name], 1, 2)",
+			models.ErrorInfo{Message: "Argument 'condition' of IF expects boolean, got string", Line: 1, Column: 3, Start: 3, End: 9},
+		},
+		{
+			"error inside argument", "ABS(1 / [zero])",
+			models.ErrorInfo{Message: "Division by zero", Line: 1, Column: 8, Start: 8, End: 14},
+		},
+		{
+			"error in selected branch", "IF(true, 1 / [zero], 0)",
+			models.ErrorInfo{Message: "Division by zero", Line: 1, Column: 13, Start: 13, End: 19},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.Evaluate(tc.expression, row)
+			assert.True(t, result.Value.IsNull())
+			assert.Equal(t, []models.ErrorInfo{tc.expected}, result.Errors)
+		})
+	}
+}
+
+func TestApp_Functions(t *testing.T) {
+	signatures := NewApp().Functions()
+
+	names := make([]string, len(signatures))
+	for i, signature := range signatures {
+		names[i] = signature.Name
+	}
+	assert.IsIncreasing(t, names)
+	assert.Contains(t, names, "SUBSTRING")
+
+	for _, signature := range signatures {
+		if signature.Name == "ROUND" {
+			assert.Equal(t, "ROUND(number, decimals?)", signature.Syntax())
+			assert.Equal(t, models.DataTypeNumber, signature.ReturnType)
+		}
+	}
+}
+
+func TestEvaluateResult_AsMap(t *testing.T) {
+	analyzer := newAnalyzer()
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	result := analyzer.Evaluate("[created]", map[string]any{"created": created})
+	assert.Equal(t, map[string]any{
+		"value":  map[string]any{"type": "datetime", "value": "2024-03-01T12:00:00Z"},
+		"errors": []any{},
+	}, result.AsMap())
+
+	result = analyzer.Evaluate("[missing]", nil)
+	assert.Equal(t, map[string]any{
+		"value": map[string]any{"type": "null", "value": nil},
+		"errors": []any{
+			map[string]any{"message": "Unknown column: [missing]", "line": 1, "column": 0, "start": 0, "end": 9},
+		},
+	}, result.AsMap())
+}
+
+func TestAnalyzer_Compile(t *testing.T) {
+	analyzer := newAnalyzer()
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	row := map[string]any{
+		"price":    19.5,
+		"quantity": 4,
+		"name":     "  Widget  ",
+		"active":   true,
+		"discount": nil,
+		"created":  created,
+	}
+
+	// A compiled expression must behave exactly like the tree-walking evaluator
+	expressions := []string{
+		"1 + 2 * 3",
+		"2 ^ 3 ^ 2",
+		"-[price] + [quantity]",
+		"[price] * [quantity] > 50 && [active]",
+		"[price] - [discount]",
+		"false && [discount] > 0",
+		"true && [discount] > 0",
+		"[discount] || true",
+		"UPPER(TRIM([name])) == 'WIDGET'",
+		"IF([active], [price], 1 / 0)",
+		"IF(false, 1 / 0, COALESCE([discount], [price]))",
+		"ROUND([price] / 3, 2)",
+		"YEAR([created]) + MONTH([created])",
+		"[price] / ([quantity] - 4)",
+		"[price] + [tax]",
+		"1 +\n  [missing]",
+		"[name] * 2",
+		"[price] == 'x'",
+		"SUBSTRING([name], 50)",
+		"ROUND([price], 1.5)",
+		"(0 - 8) ^ 0.5",
+	}
+
+	for _, expression := range expressions {
+		t.Run(expression, func(t *testing.T) {
+			compiled, errors := analyzer.Compile(expression)
+			require.Empty(t, errors)
+			expected := analyzer.Evaluate(expression, row)
+			actual := compiled.Evaluate(row)
+			assert.Equal(t, expected.Errors, actual.Errors)
+			assert.True(t, expected.Value.Equal(actual.Value), "compiled %q = %v, want %v", expression, actual.Value, expected.Value)
+		})
+	}
+}
+
+func TestAnalyzer_Compile_Errors(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   []models.ErrorInfo
+	}{
+		{
+			"empty expression", "",
+			[]models.ErrorInfo{{Message: "Empty expression", Line: 1, Column: 0, Start: 0, End: 0}},
+		},
+		{
+			"syntax error", "1 +",
+			[]models.ErrorInfo{{Message: "mismatched input '<EOF>' expecting {'-', '(', BOOLEAN_LITERAL, FLOAT_LITERAL, INTEGER_LITERAL, STRING_LITERAL, FUNCTION_NAME, COLUMN_REF}", Line: 1, Column: 3, Start: 3, End: 3}},
+		},
+		{
+			"unknown functions", "FOO(1) + BAR()",
+			[]models.ErrorInfo{
+				{Message: "Unknown function: FOO", Line: 1, Column: 0, Start: 0, End: 6},
+				{Message: "Unknown function: BAR", Line: 1, Column: 9, Start: 9, End: 14},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			compiled, errors := analyzer.Compile(tc.expression)
+			assert.Nil(t, compiled)
+			assert.Equal(t, tc.expected, errors)
+		})
+	}
+}
+
+func TestCompiledExpression_Eval(t *testing.T) {
+	analyzer := newAnalyzer()
+	compiled, errors := analyzer.Compile("[price] * [quantity] + [price]")
+	require.Empty(t, errors)
+
+	assert.Equal(t, []string{"price", "quantity"}, compiled.Columns())
+	slot, ok := compiled.Slot("quantity")
+	assert.True(t, ok)
+	assert.Equal(t, 1, slot)
+	_, ok = compiled.Slot("tax")
+	assert.False(t, ok)
+
+	value, err := compiled.Eval([]models.Value{models.NumberValue(2), models.NumberValue(5)})
+	assert.Nil(t, err)
+	assert.True(t, models.NumberValue(12).Equal(value))
+
+	_, err = compiled.Eval([]models.Value{models.NumberValue(2)})
+	require.NotNil(t, err)
+	assert.Equal(t, "Expected 2 column values, got 1", err.Message)
+
+	invalid := compiled.Evaluate(map[string]any{"price": []int{1}, "quantity": 1})
+	assert.Equal(t, []models.ErrorInfo{
+		{Message: "Invalid value for column [price]: unsupported value type []int", Line: 1, Column: 0, Start: 0, End: 7},
+	}, invalid.Errors)
+}
+
+func TestCompiledExpression_Concurrent(t *testing.T) {
+	analyzer := newAnalyzer()
+	compiled, errors := analyzer.Compile("IF([n] > 0, CONCAT('n=', [label]), 'none')")
+	require.Empty(t, errors)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				label := fmt.Sprintf("%d-%d", worker, i)
+				value, err := compiled.Eval([]models.Value{models.NumberValue(float64(i)), models.StringValue(label)})
+				if !assert.Nil(t, err) {
+					return
+				}
+				expected := "none"
+				if i > 0 {
+					expected = "n=" + label
+				}
+				assert.Equal(t, expected, value.Str())
+			}
+		}(worker)
+	}
+	wg.Wait()
+}
+
+func TestCompiledExpression_Eval_Allocations(t *testing.T) {
+	compiled, errs := newAnalyzer().Compile(benchmarkRowExpression)
+	require.Empty(t, errs)
+	slots := make([]models.Value, len(compiled.Columns()))
+	for i, column := range compiled.Columns() {
+		slots[i], _ = models.NewValue(benchmarkRow(6)[column])
+	}
+
+	var result models.Value
+	allocs := testing.AllocsPerRun(100, func() {
+		result, _ = compiled.Eval(slots)
+	})
+	assert.Equal(t, models.BooleanValue(true), result)
+	assert.Zero(t, allocs, "evaluating lazy and eager function calls allocates nothing per row")
+}
+
+const benchmarkRowExpression = "IF([quantity] > 0, ROUND([price] * [quantity] * (1 - [discount]), 2), 0) > 100 && [status] == 'active'"
+
+func benchmarkRow(i int) map[string]any {
+	return map[string]any{
+		"price":    19.5 + float64(i%10),
+		"quantity": i % 7,
+		"discount": 0.1,
+		"status":   "active",
+	}
+}
+
+// Baseline: parse and evaluate the expression for every row
+func BenchmarkEvaluate_ParsePerRow(b *testing.B) {
+	analyzer := newAnalyzer()
+	row := benchmarkRow(3)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		analyzer.Evaluate(benchmarkRowExpression, row)
+	}
+}
+
+func BenchmarkEvaluate_CompiledRow(b *testing.B) {
+	analyzer := newAnalyzer()
+	compiled, _ := analyzer.Compile(benchmarkRowExpression)
+	row := benchmarkRow(3)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		compiled.Evaluate(row)
+	}
+}
+
+func BenchmarkEvaluate_CompiledSlots(b *testing.B) {
+	analyzer := newAnalyzer()
+	compiled, _ := analyzer.Compile(benchmarkRowExpression)
+	slots := make([]models.Value, len(compiled.Columns()))
+	for i, column := range compiled.Columns() {
+		slots[i], _ = models.NewValue(benchmarkRow(3)[column])
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		compiled.Eval(slots)
+	}
+}
@@ -31,17 +31,12 @@ func (f *Formatter) Format(expression string) string {
 		return ""
 	}
 
-	// Parse the expression
-	ctx := f.helper.CreateParser(expression)
-
-	errors := make([]models.ErrorInfo, 0)
-	errorListener := infrastructure.NewCollectingErrorListener(&errors)
-	f.helper.SetupErrorListeners(ctx, errorListener)
-
-	tree := f.helper.ParseExpression(ctx)
+	return f.format(expression, lexAndParse(f.helper, expression))
+}
 
-	if hasError := (len(errors) > 0 || !f.helper.IsAllTokensConsumed(ctx)); hasError {
-		// If there are parsing errors, return the original expression
+// format formats the parsed expression, or returns the expression unchanged if it has parse errors
+func (f *Formatter) format(expression string, parsed *parsedExpression) string {
+	if len(parsed.errors) > 0 || parsed.tree == nil {
 		return expression
 	}
 
@@ -49,7 +44,16 @@ func (f *Formatter) Format(expression string) string {
 	visitor := formatter.NewFormatterVisitor(f.options)
 
 	// Visit the parse tree to generate formatted output
-	visitor.Visit(tree)
+	visitor.Visit(parsed.tree)
 
 	return visitor.Finalize()
 }
+
+// FormatAST prints an abstract syntax tree, such as one produced by models.Rewrite, and formats the source
+func (f *Formatter) FormatAST(expr models.Expr) (string, error) {
+	source, err := models.Print(expr)
+	if err != nil {
+		return "", err
+	}
+	return f.Format(source), nil
+}
@@ -0,0 +1,161 @@
+package functions
+
+import (
+	"fmt"
+	"strings"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+func aggregateFunctions() []*Function {
+	return []*Function{
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "MIN",
+				Description: "Returns the minimum value among the provided arguments.",
+				Examples:    []string{"MIN(10, 20, 5) → 5"},
+				Parameters:  []models.Parameter{param("value1", models.DataTypeAny, "First value")},
+				Variadic:    variadic("value", models.DataTypeAny, "Further values"),
+				ReturnType:  models.DataTypeAny,
+			},
+			NullAware: true,
+			ValueImpl: extremum("MIN", -1),
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "MAX",
+				Description: "Returns the maximum value among the provided arguments.",
+				Examples:    []string{"MAX(10, 20, 5) → 20"},
+				Parameters:  []models.Parameter{param("value1", models.DataTypeAny, "First value")},
+				Variadic:    variadic("value", models.DataTypeAny, "Further values"),
+				ReturnType:  models.DataTypeAny,
+			},
+			NullAware: true,
+			ValueImpl: extremum("MAX", 1),
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "SUM",
+				Description: "Returns the sum of all provided arguments.",
+				Examples:    []string{"SUM(10, 20, 30) → 60"},
+				Parameters:  []models.Parameter{param("number1", models.DataTypeNumber, "First number")},
+				Variadic:    variadic("number", models.DataTypeNumber, "Further numbers"),
+				ReturnType:  models.DataTypeNumber,
+			},
+			NullAware: true,
+			ValueImpl: sum,
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "AVG",
+				Description: "Returns the average of the provided numeric values.",
+				Examples:    []string{"AVG(1, 2, 3) → 2"},
+				Parameters:  []models.Parameter{param("number1", models.DataTypeNumber, "First number")},
+				Variadic:    variadic("number", models.DataTypeNumber, "Further numbers"),
+				ReturnType:  models.DataTypeNumber,
+			},
+			NullAware: true,
+			ValueImpl: avg,
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "COUNT",
+				Description: "Counts the number of non-null values.",
+				Examples:    []string{"COUNT([field1], [field2]) → count of non-null values"},
+				Variadic:    variadic("value", models.DataTypeAny, "Values to count"),
+				ReturnType:  models.DataTypeNumber,
+			},
+			NullAware: true,
+			ValueImpl: count,
+		},
+	}
+}
+
+// extremum returns the smallest (direction -1) or largest (direction 1) non-null argument.
+// All non-null arguments must be numbers, strings or datetimes of the same type; null if every argument is null.
+func extremum(name string, direction int) ValueImplementation {
+	return func(args []models.Value) (models.Value, error) {
+		result := models.NullValue()
+		for i, value := range args {
+			if value.IsNull() {
+				continue
+			}
+			if value.Type() == models.DataTypeBoolean {
+				return models.NullValue(), argumentError(i, "Function %s cannot compare boolean values", name)
+			}
+			if result.IsNull() {
+				result = value
+				continue
+			}
+			if value.Type() != result.Type() {
+				return models.NullValue(), argumentError(i, "Function %s cannot compare %s with %s", name, result.Type(), value.Type())
+			}
+			if compareValues(value, result)*direction > 0 {
+				result = value
+			}
+		}
+		return result, nil
+	}
+}
+
+// sum adds the non-null arguments; null if every argument is null
+func sum(args []models.Value) (models.Value, error) {
+	total, count := 0.0, 0
+	for _, value := range args {
+		if !value.IsNull() {
+			total += value.Number()
+			count++
+		}
+	}
+	if count == 0 {
+		return models.NullValue(), nil
+	}
+	return models.NumberValue(total), nil
+}
+
+// avg averages the non-null arguments; null if every argument is null
+func avg(args []models.Value) (models.Value, error) {
+	total, count := 0.0, 0
+	for _, value := range args {
+		if !value.IsNull() {
+			total += value.Number()
+			count++
+		}
+	}
+	if count == 0 {
+		return models.NullValue(), nil
+	}
+	return models.NumberValue(total / float64(count)), nil
+}
+
+// count counts the non-null arguments
+func count(args []models.Value) (models.Value, error) {
+	result := 0
+	for _, value := range args {
+		if !value.IsNull() {
+			result++
+		}
+	}
+	return models.NumberValue(float64(result)), nil
+}
+
+// compareValues orders two non-null values of the same number, string or datetime type
+func compareValues(a, b models.Value) int {
+	switch a.Type() {
+	case models.DataTypeNumber:
+		switch {
+		case a.Number() < b.Number():
+			return -1
+		case a.Number() > b.Number():
+			return 1
+		default:
+			return 0
+		}
+	case models.DataTypeString:
+		return strings.Compare(a.Str(), b.Str())
+	case models.DataTypeDateTime:
+		return a.DateTime().Compare(b.DateTime())
+	default:
+		panic(fmt.Sprintf("cannot order %s values", a.Type()))
+	}
+}
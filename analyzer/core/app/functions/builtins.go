@@ -0,0 +1,44 @@
+package functions
+
+import (
+	"math"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+// builtins returns the built-in function catalogue.
+// The registry is the single source of function documentation for the analyzer, the editor and the language server.
+// Implementations receive arguments already checked against their parameter types.
+func builtins() []*Function {
+	var result []*Function
+	result = append(result, stringFunctions()...)
+	result = append(result, mathFunctions()...)
+	result = append(result, aggregateFunctions()...)
+	result = append(result, conditionalFunctions()...)
+	result = append(result, dateTimeFunctions()...)
+	return result
+}
+
+// param creates a required parameter
+func param(name string, dataType models.DataType, description string) models.Parameter {
+	return models.Parameter{Name: name, Type: dataType, Description: description}
+}
+
+// optionalParam creates an optional parameter
+func optionalParam(name string, dataType models.DataType, description string) models.Parameter {
+	return models.Parameter{Name: name, Type: dataType, Optional: true, Description: description}
+}
+
+// variadic creates a repeated tail parameter
+func variadic(name string, dataType models.DataType, description string) *models.Parameter {
+	return &models.Parameter{Name: name, Type: dataType, Description: description}
+}
+
+// integerArgument returns the argument at index as an int, failing if it has a fractional part
+func integerArgument(args []models.Value, index int, function, name string) (int, error) {
+	number := args[index].Number()
+	if number != math.Trunc(number) || math.Abs(number) > math.MaxInt32 {
+		return 0, argumentError(index, "Argument '%s' of %s must be an integer, got %v", name, function, number)
+	}
+	return int(number), nil
+}
@@ -0,0 +1,184 @@
+package functions
+
+import (
+	"fmt"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+// Implementation computes the result of a function call
+type Implementation func(args Arguments) (models.Value, error)
+
+// ValueImplementation computes the result of a call from its evaluated arguments.
+// The slice is only valid during the call and must not be retained.
+type ValueImplementation func(args []models.Value) (models.Value, error)
+
+// Arguments gives an implementation access to the arguments of a call.
+// Arguments of lazy functions are evaluated on first access, so unused arguments are never evaluated.
+type Arguments interface {
+	// Len returns the number of arguments
+	Len() int
+	// At evaluates and returns the argument at the given index
+	At(index int) (models.Value, error)
+}
+
+// ArgumentError is an error raised by a function call, located on one of its arguments
+type ArgumentError struct {
+	Index   int    // Index of the offending argument, -1 if the error concerns the whole call
+	Message string // Human readable message
+}
+
+// Error implements the error interface
+func (e *ArgumentError) Error() string {
+	return e.Message
+}
+
+// argumentError creates an ArgumentError for the argument at index
+func argumentError(index int, format string, args ...any) *ArgumentError {
+	return &ArgumentError{Index: index, Message: fmt.Sprintf(format, args...)}
+}
+
+// ArityMessage returns a message describing an argument count mismatch, or an empty string if the count is accepted
+func ArityMessage(signature *models.FunctionSignature, count int) string {
+	minArgs, maxArgs := signature.MinArgs(), signature.MaxArgs()
+	if count >= minArgs && (maxArgs < 0 || count <= maxArgs) {
+		return ""
+	}
+	switch {
+	case maxArgs < 0:
+		return fmt.Sprintf("Function %s expects at least %d %s, got %d", signature.Name, minArgs, pluralize("argument", minArgs), count)
+	case minArgs == maxArgs:
+		return fmt.Sprintf("Function %s expects %d %s, got %d", signature.Name, minArgs, pluralize("argument", minArgs), count)
+	default:
+		return fmt.Sprintf("Function %s expects %d to %d arguments, got %d", signature.Name, minArgs, maxArgs, count)
+	}
+}
+
+// ArgumentTypeMessage returns a message describing an argument of the wrong type
+func ArgumentTypeMessage(signature *models.FunctionSignature, param *models.Parameter, actual models.DataType) string {
+	return fmt.Sprintf("Argument '%s' of %s expects %s, got %s", param.Name, signature.Name, param.Type, actual)
+}
+
+// pluralize returns the plural form of word unless count is exactly one
+func pluralize(word string, count int) string {
+	if count == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// Frame holds the arguments of a function call, so that a call site calling through the same frame again and
+// again allocates nothing per call. A Frame is not safe for concurrent use.
+type Frame struct {
+	checked checkedArguments
+	values  []models.Value
+}
+
+// Call invokes the function with the given arguments.
+// The argument count and the types of non-null arguments are checked against the signature.
+// Unless the function is lazy or null aware, all arguments are evaluated first and a null argument yields null.
+func Call(fn *Function, args Arguments) (models.Value, error) {
+	var frame Frame
+	return frame.Call(fn, args)
+}
+
+// Call is like the Call function, keeping the evaluated arguments in the frame
+func (f *Frame) Call(fn *Function, args Arguments) (models.Value, error) {
+	if message := ArityMessage(&fn.FunctionSignature, args.Len()); message != "" {
+		return models.NullValue(), &ArgumentError{Index: -1, Message: message}
+	}
+	if fn.Impl == nil && fn.ValueImpl == nil {
+		return models.NullValue(), &ArgumentError{Index: -1, Message: fmt.Sprintf("Function %s cannot be evaluated", fn.Name)}
+	}
+
+	if fn.Lazy {
+		f.checked = checkedArguments{fn: fn, args: args}
+		result, err := fn.Impl(&f.checked)
+		f.checked = checkedArguments{}
+		return checkResult(fn, result, err)
+	}
+
+	values := f.values[:0]
+	hasNull := false
+	for i := 0; i < args.Len(); i++ {
+		value, err := args.At(i)
+		if err == nil {
+			err = checkArgument(fn, i, value)
+		}
+		if err != nil {
+			return models.NullValue(), err
+		}
+		values = append(values, value)
+		hasNull = hasNull || value.IsNull()
+	}
+	f.values = values
+	if hasNull && !fn.NullAware {
+		return models.NullValue(), nil
+	}
+	if fn.ValueImpl != nil {
+		result, err := fn.ValueImpl(values)
+		return checkResult(fn, result, err)
+	}
+	result, err := fn.Impl(evaluatedArguments(values))
+	return checkResult(fn, result, err)
+}
+
+// checkResult checks that a non-null result matches the declared return type
+func checkResult(fn *Function, result models.Value, err error) (models.Value, error) {
+	if err != nil {
+		return models.NullValue(), err
+	}
+	if !result.IsNull() && !result.Type().IsAssignableTo(fn.ReturnType) {
+		return models.NullValue(), &ArgumentError{
+			Index:   -1,
+			Message: fmt.Sprintf("Function %s returned %s, expected %s", fn.Name, result.Type(), fn.ReturnType),
+		}
+	}
+	return result, nil
+}
+
+// checkedArguments checks the type of every argument against its parameter when it is accessed
+type checkedArguments struct {
+	fn   *Function
+	args Arguments
+}
+
+func (a *checkedArguments) Len() int {
+	return a.args.Len()
+}
+
+func (a *checkedArguments) At(index int) (models.Value, error) {
+	value, err := a.args.At(index)
+	if err == nil {
+		err = checkArgument(a.fn, index, value)
+	}
+	if err != nil {
+		return models.NullValue(), err
+	}
+	return value, nil
+}
+
+// checkArgument checks a non-null argument against the type of the parameter receiving it
+func checkArgument(fn *Function, index int, value models.Value) error {
+	param := fn.ParameterAt(index)
+	if param != nil && !value.IsNull() && !value.Type().IsAssignableTo(param.Type) {
+		return &ArgumentError{Index: index, Message: ArgumentTypeMessage(&fn.FunctionSignature, param, value.Type())}
+	}
+	return nil
+}
+
+// evaluatedArguments are arguments that have already been evaluated
+type evaluatedArguments []models.Value
+
+func (a evaluatedArguments) Len() int {
+	return len(a)
+}
+
+func (a evaluatedArguments) At(index int) (models.Value, error) {
+	return a[index], nil
+}
+
+// Values wraps already evaluated values as Arguments
+func Values(values ...models.Value) Arguments {
+	return evaluatedArguments(values)
+}
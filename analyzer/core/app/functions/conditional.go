@@ -0,0 +1,60 @@
+package functions
+
+import (
+	"antlr-editor/analyzer/core/models"
+)
+
+func conditionalFunctions() []*Function {
+	return []*Function{
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "IF",
+				Description: "Returns one value if a condition is true and another value if it is false.",
+				Examples:    []string{`IF([score] > 80, "Pass", "Fail")`},
+				Parameters: []models.Parameter{
+					param("condition", models.DataTypeBoolean, "Condition to test"),
+					param("true_value", models.DataTypeAny, "Value returned when the condition is true"),
+					param("false_value", models.DataTypeAny, "Value returned when the condition is false"),
+				},
+				ReturnType: models.DataTypeAny,
+			},
+			Lazy: true,
+			Impl: ifFunction,
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "COALESCE",
+				Description: "Returns the first non-null value from the provided arguments.",
+				Examples:    []string{`COALESCE([field1], [field2], "default") → first non-null value`},
+				Parameters:  []models.Parameter{param("value1", models.DataTypeAny, "First value")},
+				Variadic:    variadic("value", models.DataTypeAny, "Fallback values"),
+				ReturnType:  models.DataTypeAny,
+			},
+			Lazy: true,
+			Impl: coalesce,
+		},
+	}
+}
+
+// ifFunction evaluates only the branch selected by the condition; a null condition selects false_value
+func ifFunction(args Arguments) (models.Value, error) {
+	condition, err := args.At(0)
+	if err != nil {
+		return models.NullValue(), err
+	}
+	if !condition.IsNull() && condition.Boolean() {
+		return args.At(1)
+	}
+	return args.At(2)
+}
+
+// coalesce evaluates arguments from left to right until one is not null
+func coalesce(args Arguments) (models.Value, error) {
+	for i := 0; i < args.Len(); i++ {
+		value, err := args.At(i)
+		if err != nil || !value.IsNull() {
+			return value, err
+		}
+	}
+	return models.NullValue(), nil
+}
@@ -0,0 +1,81 @@
+package functions
+
+import (
+	"time"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+func dateTimeFunctions() []*Function {
+	return []*Function{
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "NOW",
+				Description: "Returns the current date and time.",
+				Examples:    []string{"NOW() → current timestamp"},
+				ReturnType:  models.DataTypeDateTime,
+			},
+			ValueImpl: now,
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "DATE",
+				Description: "Extracts the date part from a datetime value.",
+				Examples:    []string{"DATE(NOW()) → current date"},
+				Parameters:  []models.Parameter{param("datetime", models.DataTypeDateTime, "Datetime value")},
+				ReturnType:  models.DataTypeDateTime,
+			},
+			ValueImpl: date,
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "YEAR",
+				Description: "Extracts the year from a datetime value.",
+				Examples:    []string{"YEAR(NOW()) → 2024"},
+				Parameters:  []models.Parameter{param("datetime", models.DataTypeDateTime, "Datetime value")},
+				ReturnType:  models.DataTypeNumber,
+			},
+			ValueImpl: dateTimePart(func(t time.Time) int { return t.Year() }),
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "MONTH",
+				Description: "Extracts the month (1-12) from a datetime value.",
+				Examples:    []string{"MONTH(NOW()) → current month"},
+				Parameters:  []models.Parameter{param("datetime", models.DataTypeDateTime, "Datetime value")},
+				ReturnType:  models.DataTypeNumber,
+			},
+			ValueImpl: dateTimePart(func(t time.Time) int { return int(t.Month()) }),
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "DAY",
+				Description: "Extracts the day of month from a datetime value.",
+				Examples:    []string{"DAY(NOW()) → current day"},
+				Parameters:  []models.Parameter{param("datetime", models.DataTypeDateTime, "Datetime value")},
+				ReturnType:  models.DataTypeNumber,
+			},
+			ValueImpl: dateTimePart(func(t time.Time) int { return t.Day() }),
+		},
+	}
+}
+
+// clock returns the current time; replaced in tests
+var clock = time.Now
+
+func now(_ []models.Value) (models.Value, error) {
+	return models.DateTimeValue(clock()), nil
+}
+
+// date truncates a datetime to midnight in its own time zone
+func date(args []models.Value) (models.Value, error) {
+	t := args[0].DateTime()
+	return models.DateTimeValue(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())), nil
+}
+
+// dateTimePart adapts the extraction of a datetime component to a ValueImplementation
+func dateTimePart(part func(time.Time) int) ValueImplementation {
+	return func(args []models.Value) (models.Value, error) {
+		return models.NumberValue(float64(part(args[0].DateTime()))), nil
+	}
+}
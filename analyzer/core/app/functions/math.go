@@ -0,0 +1,83 @@
+package functions
+
+import (
+	"math"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+func mathFunctions() []*Function {
+	return []*Function{
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "ROUND",
+				Description: "Rounds a number to a specified number of decimal places.",
+				Examples:    []string{"ROUND(3.14159, 2) → 3.14"},
+				Parameters: []models.Parameter{
+					param("number", models.DataTypeNumber, "Number to round"),
+					optionalParam("decimals", models.DataTypeNumber, "Number of decimal places, 0 if omitted"),
+				},
+				ReturnType: models.DataTypeNumber,
+			},
+			ValueImpl: round,
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "FLOOR",
+				Description: "Rounds down to the nearest integer.",
+				Examples:    []string{"FLOOR(3.7) → 3"},
+				Parameters:  []models.Parameter{param("number", models.DataTypeNumber, "Number to round down")},
+				ReturnType:  models.DataTypeNumber,
+			},
+			ValueImpl: numberFunction(math.Floor),
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "CEIL",
+				Description: "Rounds up to the nearest integer.",
+				Examples:    []string{"CEIL(3.2) → 4"},
+				Parameters:  []models.Parameter{param("number", models.DataTypeNumber, "Number to round up")},
+				ReturnType:  models.DataTypeNumber,
+			},
+			ValueImpl: numberFunction(math.Ceil),
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "ABS",
+				Description: "Returns the absolute (positive) value of a number.",
+				Examples:    []string{"ABS(-5) → 5"},
+				Parameters:  []models.Parameter{param("number", models.DataTypeNumber, "Number")},
+				ReturnType:  models.DataTypeNumber,
+			},
+			ValueImpl: numberFunction(math.Abs),
+		},
+	}
+}
+
+// maxRoundDecimals bounds the decimals of ROUND to what a float64 can represent
+const maxRoundDecimals = 15
+
+// numberFunction adapts a numeric transformation to a ValueImplementation
+func numberFunction(transform func(float64) float64) ValueImplementation {
+	return func(args []models.Value) (models.Value, error) {
+		return models.NumberValue(transform(args[0].Number())), nil
+	}
+}
+
+// round rounds half away from zero; negative decimals round to tens, hundreds and so on
+func round(args []models.Value) (models.Value, error) {
+	number := args[0]
+	decimals := 0
+	if len(args) > 1 {
+		var err error
+		if decimals, err = integerArgument(args, 1, "ROUND", "decimals"); err != nil {
+			return models.NullValue(), err
+		}
+		if decimals < -maxRoundDecimals || decimals > maxRoundDecimals {
+			return models.NullValue(), argumentError(1, "Argument 'decimals' of ROUND is out of range: %d is not between %d and %d", decimals, -maxRoundDecimals, maxRoundDecimals)
+		}
+	}
+
+	scale := math.Pow(10, float64(decimals))
+	return models.NumberValue(math.Round(number.Number()*scale) / scale), nil
+}
@@ -0,0 +1,141 @@
+package functions
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+// Function is a function known to the analyzer, together with its implementation
+type Function struct {
+	models.FunctionSignature
+	Impl      Implementation      // Computes the result; nil with ValueImpl if the function can only be type checked
+	ValueImpl ValueImplementation // Computes the result from the evaluated arguments instead of Impl; not for lazy functions
+	Lazy      bool                // Arguments are evaluated on demand by Impl, which then also handles null arguments
+	NullAware bool                // The implementation receives null arguments instead of the call returning null
+}
+
+// Registry holds the functions that may be called from expressions
+type Registry struct {
+	functions map[string]*Function
+	builtin   map[string]bool
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		functions: make(map[string]*Function),
+		builtin:   make(map[string]bool),
+	}
+}
+
+// NewBuiltinRegistry creates a registry populated with the built-in functions
+func NewBuiltinRegistry() *Registry {
+	registry := NewRegistry()
+	for _, fn := range builtins() {
+		if err := registry.Register(fn); err != nil {
+			panic(err)
+		}
+		registry.builtin[fn.Name] = true
+	}
+	return registry
+}
+
+// Register adds a copy of a function to the registry, replacing any function of the same name.
+// Built-in functions cannot be replaced.
+func (r *Registry) Register(fn *Function) error {
+	if fn == nil || fn.Name == "" {
+		return fmt.Errorf("function name must not be empty")
+	}
+	if !models.IsFunctionName(fn.Name) {
+		return fmt.Errorf("invalid function name %q: %s", fn.Name, models.FunctionNameRule)
+	}
+	if r.builtin[fn.Name] {
+		return fmt.Errorf("function %s is a built-in function and cannot be replaced", fn.Name)
+	}
+
+	params := fn.Parameters
+	if fn.Variadic != nil {
+		params = append(params[:len(params):len(params)], *fn.Variadic)
+	}
+	for _, param := range params {
+		if param.Name == "" {
+			return fmt.Errorf("function %s: parameter names must not be empty", fn.Name)
+		}
+		if param.Type != "" && !param.Type.IsValid() {
+			return fmt.Errorf("function %s: parameter %q has unknown type %q", fn.Name, param.Name, param.Type)
+		}
+	}
+	if fn.ReturnType != "" && !fn.ReturnType.IsValid() {
+		return fmt.Errorf("function %s: unknown return type %q", fn.Name, fn.ReturnType)
+	}
+
+	seenOptional := false
+	for _, param := range fn.Parameters {
+		if seenOptional && !param.Optional {
+			return fmt.Errorf("function %s: required parameter %q follows an optional parameter", fn.Name, param.Name)
+		}
+		seenOptional = seenOptional || param.Optional
+	}
+	if seenOptional && fn.Variadic != nil {
+		return fmt.Errorf("function %s: variadic parameters cannot follow optional parameters", fn.Name)
+	}
+
+	if fn.Lazy && fn.ValueImpl != nil {
+		return fmt.Errorf("function %s: lazy functions evaluate their arguments through Impl", fn.Name)
+	}
+
+	fn = fn.clone()
+	if fn.ReturnType == "" {
+		fn.ReturnType = models.DataTypeAny
+	}
+	for i := range fn.Parameters {
+		if fn.Parameters[i].Type == "" {
+			fn.Parameters[i].Type = models.DataTypeAny
+		}
+	}
+	if fn.Variadic != nil && fn.Variadic.Type == "" {
+		fn.Variadic.Type = models.DataTypeAny
+	}
+
+	r.functions[fn.Name] = fn
+	return nil
+}
+
+// clone copies the function so that the registry does not share its definition with the caller,
+// who may register it in other registries or change it afterwards
+func (fn *Function) clone() *Function {
+	copied := *fn
+	copied.Examples = slices.Clone(fn.Examples)
+	copied.Parameters = slices.Clone(fn.Parameters)
+	if fn.Variadic != nil {
+		variadic := *fn.Variadic
+		copied.Variadic = &variadic
+	}
+	return &copied
+}
+
+// Lookup returns the function with the given name
+func (r *Registry) Lookup(name string) (*Function, bool) {
+	fn, ok := r.functions[name]
+	return fn, ok
+}
+
+// Functions returns all registered functions sorted by name
+func (r *Registry) Functions() []*Function {
+	result := make([]*Function, 0, len(r.functions))
+	for _, fn := range r.functions {
+		result = append(result, fn)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// IsBuiltin reports whether name refers to a built-in function
+func (r *Registry) IsBuiltin(name string) bool {
+	return r.builtin[name]
+}
@@ -0,0 +1,156 @@
+package functions
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+func stringFunctions() []*Function {
+	return []*Function{
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "UPPER",
+				Description: "Converts text to uppercase letters.",
+				Examples:    []string{`UPPER("hello") → "HELLO"`},
+				Parameters:  []models.Parameter{param("text", models.DataTypeString, "Text to convert")},
+				ReturnType:  models.DataTypeString,
+			},
+			ValueImpl: stringFunction(strings.ToUpper),
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "LOWER",
+				Description: "Converts text to lowercase letters.",
+				Examples:    []string{`LOWER("HELLO") → "hello"`},
+				Parameters:  []models.Parameter{param("text", models.DataTypeString, "Text to convert")},
+				ReturnType:  models.DataTypeString,
+			},
+			ValueImpl: stringFunction(strings.ToLower),
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "TRIM",
+				Description: "Removes leading and trailing whitespace from text.",
+				Examples:    []string{`TRIM("  hello  ") → "hello"`},
+				Parameters:  []models.Parameter{param("text", models.DataTypeString, "Text to trim")},
+				ReturnType:  models.DataTypeString,
+			},
+			ValueImpl: stringFunction(strings.TrimSpace),
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "LENGTH",
+				Description: "Returns the length of a string.",
+				Examples:    []string{`LENGTH("hello") → 5`},
+				Parameters:  []models.Parameter{param("text", models.DataTypeString, "Text to measure")},
+				ReturnType:  models.DataTypeNumber,
+			},
+			ValueImpl: length,
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "LEN",
+				Description: "Returns the length of a string.",
+				Examples:    []string{`LEN("hello") → 5`},
+				Parameters:  []models.Parameter{param("text", models.DataTypeString, "Text to measure")},
+				ReturnType:  models.DataTypeNumber,
+			},
+			ValueImpl: length,
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "CONCAT",
+				Description: "Concatenates multiple text values into a single string.",
+				Examples:    []string{`CONCAT("Hello", " ", "World") → "Hello World"`},
+				Parameters:  []models.Parameter{param("text1", models.DataTypeString, "First text")},
+				Variadic:    variadic("text", models.DataTypeString, "Further texts to append"),
+				ReturnType:  models.DataTypeString,
+			},
+			ValueImpl: concat,
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "SUBSTRING",
+				Description: "Extracts a substring from text.",
+				Examples:    []string{`SUBSTRING("hello", 2, 3) → "llo"`},
+				Parameters: []models.Parameter{
+					param("text", models.DataTypeString, "Source text"),
+					param("start", models.DataTypeNumber, "Zero-based start position"),
+					param("length", models.DataTypeNumber, "Number of characters to extract"),
+				},
+				ReturnType: models.DataTypeString,
+			},
+			ValueImpl: substring,
+		},
+		{
+			FunctionSignature: models.FunctionSignature{
+				Name:        "REPLACE",
+				Description: "Replaces occurrences of a search string with a replacement string.",
+				Examples:    []string{`REPLACE("hello", "l", "r") → "herro"`},
+				Parameters: []models.Parameter{
+					param("text", models.DataTypeString, "Source text"),
+					param("search", models.DataTypeString, "Text to search for"),
+					param("replace", models.DataTypeString, "Replacement text"),
+				},
+				ReturnType: models.DataTypeString,
+			},
+			ValueImpl: replace,
+		},
+	}
+}
+
+// stringFunction adapts a string transformation to a ValueImplementation
+func stringFunction(transform func(string) string) ValueImplementation {
+	return func(args []models.Value) (models.Value, error) {
+		return models.StringValue(transform(args[0].Str())), nil
+	}
+}
+
+// length counts the characters (not bytes) of a string
+func length(args []models.Value) (models.Value, error) {
+	return models.NumberValue(float64(utf8.RuneCountInString(args[0].Str()))), nil
+}
+
+func concat(args []models.Value) (models.Value, error) {
+	var builder strings.Builder
+	for _, text := range args {
+		builder.WriteString(text.Str())
+	}
+	return models.StringValue(builder.String()), nil
+}
+
+// substring extracts length characters starting at the zero-based start position.
+// The length is cut short at the end of the text; a start beyond the end is an error.
+func substring(args []models.Value) (models.Value, error) {
+	runes := []rune(args[0].Str())
+
+	start, err := integerArgument(args, 1, "SUBSTRING", "start")
+	if err != nil {
+		return models.NullValue(), err
+	}
+	if start < 0 || start > len(runes) {
+		return models.NullValue(), argumentError(1, "Argument 'start' of SUBSTRING is out of range: %d is not between 0 and %d", start, len(runes))
+	}
+
+	count, err := integerArgument(args, 2, "SUBSTRING", "length")
+	if err != nil {
+		return models.NullValue(), err
+	}
+	if count < 0 {
+		return models.NullValue(), argumentError(2, "Argument 'length' of SUBSTRING must not be negative, got %d", count)
+	}
+
+	end := min(start+count, len(runes))
+	return models.StringValue(string(runes[start:end])), nil
+}
+
+// replace replaces every occurrence of search in text
+func replace(args []models.Value) (models.Value, error) {
+	text, search, replacement := args[0], args[1], args[2]
+	if search.Str() == "" {
+		return models.NullValue(), argumentError(1, "Argument 'search' of REPLACE must not be empty")
+	}
+	return models.StringValue(strings.ReplaceAll(text.Str(), search.Str(), replacement.Str())), nil
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/core/app/cst"
+	"antlr-editor/analyzer/core/app/typecheck"
+	"antlr-editor/analyzer/core/models"
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// NodeAtResult describes the parse tree node at a cursor position and the hover content for it
+type NodeAtResult struct {
+	Node      *models.NodeInfo          `json:"node"`      // Innermost node containing the position, nil outside the expression
+	Ancestors []models.NodeInfo         `json:"ancestors"` // Nodes enclosing Node, innermost first, ending with the root Expression
+	Type      models.DataType           `json:"type"`      // Inferred type of the innermost expression containing Node, empty if Node is nil
+	Function  *models.FunctionSignature `json:"function"`  // Signature and documentation if Node is the name of a known function
+	Column    *models.Column            `json:"column"`    // Schema column if Node references a column of the schema
+}
+
+// AsMap converts NodeAtResult to a map for JSON serialization
+func (r *NodeAtResult) AsMap() map[string]any {
+	var node, function, column any
+	if r.Node != nil {
+		node = r.Node.AsMap()
+	}
+	if r.Function != nil {
+		function = r.Function.AsMap()
+	}
+	if r.Column != nil {
+		column = r.Column.AsMap()
+	}
+
+	ancestors := make([]any, len(r.Ancestors))
+	for i, ancestor := range r.Ancestors {
+		ancestors[i] = ancestor.AsMap()
+	}
+
+	return map[string]any{
+		"node":      node,
+		"ancestors": ancestors,
+		"type":      string(r.Type),
+		"function":  function,
+		"column":    column,
+	}
+}
+
+// NodeAt finds the innermost parse tree node containing the character at offset, a code point position in the
+// expression, and the nodes enclosing it up to a root Expression node spanning the whole expression. Unlike
+// ParseTree, operators, parentheses and other tokens are nodes too, so the node under the cursor is usually a token;
+// whitespace belongs to the innermost node around it. Partially parsed expressions are supported.
+// The result also holds what a hover shows: the type inferred for the innermost expression containing the node,
+// and the documentation of the function or schema column the node names.
+func (a *Analyzer) NodeAt(expression string, offset int) *NodeAtResult {
+	result := &NodeAtResult{Ancestors: []models.NodeInfo{}}
+	runes := []rune(expression)
+	if offset < 0 || offset >= len(runes) {
+		return result
+	}
+
+	tree, _ := a.parseExpression(expression)
+	var path []antlr.Tree
+	if tree != nil {
+		path = pathToOffset(tree, offset)
+	}
+
+	// Innermost first, with the root wrapping the whole expression as in ParseTree
+	nodes := []models.NodeInfo{{Type: models.NodeTypeExpression, Text: expression, Start: 0, End: len(runes)}}
+	for _, node := range path {
+		nodeType, _ := cst.NodeType(node)
+		start, end, _ := nodeSpan(node)
+		nodes = append([]models.NodeInfo{{Type: nodeType, Text: string(runes[start:end]), Start: start, End: end}}, nodes...)
+	}
+	result.Node, result.Ancestors = &nodes[0], nodes[1:]
+
+	// The root node stands for the whole tree
+	var expr antlr.ParseTree = tree
+	for i := len(path) - 1; i >= 0; i-- {
+		if ctx, ok := path[i].(parser.IExpressionContext); ok {
+			expr = ctx
+			break
+		}
+	}
+	result.Type = models.DataTypeAny
+	if expr != nil {
+		result.Type = typecheck.NewTypeCheckVisitor(a.registry, a.schema).Visit(expr).(models.DataType)
+	}
+
+	if len(path) == 0 {
+		return result
+	}
+	terminal, ok := path[len(path)-1].(antlr.TerminalNode)
+	if !ok {
+		return result
+	}
+	switch text := terminal.GetText(); terminal.GetSymbol().GetTokenType() {
+	case parser.ExpressionLexerFUNCTION_NAME:
+		if fn, ok := a.registry.Lookup(text); ok {
+			signature := fn.FunctionSignature
+			result.Function = &signature
+		}
+	case parser.ExpressionLexerCOLUMN_REF:
+		if a.schema != nil {
+			if column, ok := a.schema.Lookup(text[1 : len(text)-1]); ok {
+				result.Column = column
+			}
+		}
+	}
+	return result
+}
+
+// pathToOffset returns the nodes from tree down to the innermost node containing offset,
+// or nil if tree does not contain it
+func pathToOffset(tree antlr.Tree, offset int) []antlr.Tree {
+	if _, ok := cst.NodeType(tree); !ok {
+		return nil
+	}
+	start, end, ok := nodeSpan(tree)
+	if !ok || offset < start || offset >= end {
+		return nil
+	}
+	for _, child := range tree.GetChildren() {
+		if path := pathToOffset(child, offset); path != nil {
+			return append([]antlr.Tree{tree}, path...)
+		}
+	}
+	return []antlr.Tree{tree}
+}
+
+// nodeSpan returns the code point span of a parse tree node.
+// Returns false for tokens conjured by error recovery and rules that matched no token.
+func nodeSpan(tree antlr.Tree) (int, int, bool) {
+	switch node := tree.(type) {
+	case antlr.TerminalNode:
+		token := node.GetSymbol()
+		return token.GetStart(), token.GetStop() + 1, token.GetTokenIndex() >= 0 && token.GetStop() >= token.GetStart()
+	case antlr.ParserRuleContext:
+		start, stop := node.GetStart(), node.GetStop()
+		if start == nil || stop == nil || stop.GetTokenIndex() < start.GetTokenIndex() {
+			return 0, 0, false
+		}
+		return start.GetStart(), stop.GetStop() + 1, true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+func nodeTypes(result *NodeAtResult) []models.NodeType {
+	types := []models.NodeType{result.Node.Type}
+	for _, ancestor := range result.Ancestors {
+		types = append(types, ancestor.Type)
+	}
+	return types
+}
+
+func TestAnalyzer_NodeAt(t *testing.T) {
+	analyzer := newAnalyzer()
+	analyzer.SetSchema(models.NewSchema([]models.Column{
+		{Name: "price", Type: models.DataTypeNumber, Description: "Unit price"},
+		{Name: "name", Type: models.DataTypeString},
+	}))
+
+	testCases := []struct {
+		name       string
+		expression string
+		offset     int
+		node       models.NodeInfo
+		types      []models.NodeType
+		dataType   models.DataType
+	}{
+		{
+			"column reference", "[price] * 2", 3,
+			models.NodeInfo{Type: models.NodeTypeColumnRef, Text: "[price]", Start: 0, End: 7},
+			[]models.NodeType{models.NodeTypeColumnRef, models.NodeTypeColumnReference, models.NodeTypeColumnRefExpr, models.NodeTypeMulDivExpr, models.NodeTypeExpression},
+			models.DataTypeNumber,
+		},
+		{
+			"operator", "[price] * 2", 8,
+			models.NodeInfo{Type: models.NodeTypeMul, Text: "*", Start: 8, End: 9},
+			[]models.NodeType{models.NodeTypeMul, models.NodeTypeMulDivExpr, models.NodeTypeExpression},
+			models.DataTypeNumber,
+		},
+		{
+			"whitespace belongs to the enclosing node", "[price] * 2", 7,
+			models.NodeInfo{Type: models.NodeTypeMulDivExpr, Text: "[price] * 2", Start: 0, End: 11},
+			[]models.NodeType{models.NodeTypeMulDivExpr, models.NodeTypeExpression},
+			models.DataTypeNumber,
+		},
+		{
+			"function name", "UPPER([name]) == 'A'", 2,
+			models.NodeInfo{Type: models.NodeTypeFunctionName, Text: "UPPER", Start: 0, End: 5},
+			[]models.NodeType{models.NodeTypeFunctionName, models.NodeTypeFunctionCall, models.NodeTypeFunctionCallExpr, models.NodeTypeComparisonExpr, models.NodeTypeExpression},
+			models.DataTypeString,
+		},
+		{
+			"literal in argument list", "ROUND([price], 2)", 15,
+			models.NodeInfo{Type: models.NodeTypeIntegerLiteral, Text: "2", Start: 15, End: 16},
+			[]models.NodeType{models.NodeTypeIntegerLiteral, models.NodeTypeLiteral, models.NodeTypeLiteralExpr, models.NodeTypeArgumentList, models.NodeTypeFunctionCall, models.NodeTypeFunctionCallExpr, models.NodeTypeExpression},
+			models.DataTypeNumber,
+		},
+		{
+			"parenthesis", "([price] > 1)", 0,
+			models.NodeInfo{Type: models.NodeTypeLParen, Text: "(", Start: 0, End: 1},
+			[]models.NodeType{models.NodeTypeLParen, models.NodeTypeParenExpr, models.NodeTypeExpression},
+			models.DataTypeBoolean,
+		},
+		{
+			"trailing whitespace", "[price] ", 7,
+			models.NodeInfo{Type: models.NodeTypeExpression, Text: "[price] ", Start: 0, End: 8},
+			[]models.NodeType{models.NodeTypeExpression},
+			models.DataTypeNumber,
+		},
+		{
+			"incomplete expression", "[name] + ", 3,
+			models.NodeInfo{Type: models.NodeTypeColumnRef, Text: "[name]", Start: 0, End: 6},
+			[]models.NodeType{models.NodeTypeColumnRef, models.NodeTypeColumnReference, models.NodeTypeColumnRefExpr, models.NodeTypeAddSubExpr, models.NodeTypeExpression},
+			models.DataTypeString,
+		},
+		{
+			"multi-byte characters", "CONCAT('😀', [name])", 12,
+			models.NodeInfo{Type: models.NodeTypeColumnRef, Text: "[name]", Start: 12, End: 18},
+			[]models.NodeType{models.NodeTypeColumnRef, models.NodeTypeColumnReference, models.NodeTypeColumnRefExpr, models.NodeTypeArgumentList, models.NodeTypeFunctionCall, models.NodeTypeFunctionCallExpr, models.NodeTypeExpression},
+			models.DataTypeString,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.NodeAt(tc.expression, tc.offset)
+			require.NotNil(t, result.Node)
+			assert.Equal(t, tc.node, *result.Node)
+			assert.Equal(t, tc.types, nodeTypes(result))
+			assert.Equal(t, tc.dataType, result.Type)
+		})
+	}
+}
+
+func TestAnalyzer_NodeAt_Hover(t *testing.T) {
+	analyzer := newAnalyzer()
+	analyzer.SetSchema(models.NewSchema([]models.Column{
+		{Name: "price", Type: models.DataTypeNumber, Description: "Unit price"},
+	}))
+
+	t.Run("function", func(t *testing.T) {
+		result := analyzer.NodeAt("ROUND([price], 2)", 1)
+		require.NotNil(t, result.Function)
+		assert.Equal(t, "ROUND", result.Function.Name)
+		assert.NotEmpty(t, result.Function.Description)
+		assert.Nil(t, result.Column)
+	})
+
+	t.Run("column", func(t *testing.T) {
+		result := analyzer.NodeAt("ROUND([price], 2)", 8)
+		require.NotNil(t, result.Column)
+		assert.Equal(t, "Unit price", result.Column.Description)
+		assert.Nil(t, result.Function)
+	})
+
+	t.Run("unknown names", func(t *testing.T) {
+		result := analyzer.NodeAt("FOO([qty])", 1)
+		assert.Nil(t, result.Function)
+		assert.Equal(t, models.DataTypeAny, result.Type)
+
+		result = analyzer.NodeAt("FOO([qty])", 5)
+		assert.Nil(t, result.Column)
+		assert.Equal(t, models.DataTypeAny, result.Type)
+	})
+
+	t.Run("outside the expression", func(t *testing.T) {
+		for _, offset := range []int{-1, 7} {
+			result := analyzer.NodeAt("[price]", offset)
+			assert.Nil(t, result.Node)
+			assert.Empty(t, result.Ancestors)
+			assert.Empty(t, result.Type)
+		}
+		assert.Nil(t, analyzer.NodeAt("", 0).Node)
+	})
+}
+
+func TestNodeAtResult_AsMap(t *testing.T) {
+	result := NewApp().NodeAt("-[a]", 0)
+	assert.Equal(t, map[string]any{
+		"node": map[string]any{"type": int(models.NodeTypeSub), "text": "-", "start": 0, "end": 1},
+		"ancestors": []any{
+			map[string]any{"type": int(models.NodeTypeUnaryMinusExpr), "text": "-[a]", "start": 0, "end": 4},
+			map[string]any{"type": int(models.NodeTypeExpression), "text": "-[a]", "start": 0, "end": 4},
+		},
+		"type":     "number",
+		"function": nil,
+		"column":   nil,
+	}, result.AsMap())
+}
@@ -0,0 +1,251 @@
+package jsgen
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Runtime helpers of the generated code. Each helper is a JavaScript expression bound to a
+// constant named after its key; only the helpers an expression needs are emitted.
+// Error messages match those of the evaluator so that both report failures the same way.
+var helpers = map[string]string{
+	"$type": `(v) => v === null ? "null" : v instanceof Date ? "datetime" : typeof v`,
+
+	"$col": `(row, name) => {
+  const v = row[name];
+  if (v === undefined) {
+    if (!(name in row)) throw new Error("Unknown column: [" + name + "]");
+    return null;
+  }
+  if (v === null || typeof v === "number" || typeof v === "string" || typeof v === "boolean" || v instanceof Date) return v;
+  throw new Error("Invalid value for column [" + name + "]: unsupported value type " + typeof v);
+}`,
+
+	"$neg": `(a) => {
+  if (a === null) return null;
+  if (typeof a !== "number") throw new Error("Operator '-' expects number operands, got " + $type(a));
+  return -a;
+}`,
+
+	"$arith": `(op, a, b) => {
+  if (a !== null && typeof a !== "number") throw new Error("Operator '" + op + "' expects number operands, got " + $type(a));
+  if (b !== null && typeof b !== "number") throw new Error("Operator '" + op + "' expects number operands, got " + $type(b));
+  if (a === null || b === null) return null;
+  let r;
+  switch (op) {
+    case "+": r = a + b; break;
+    case "-": r = a - b; break;
+    case "*": r = a * b; break;
+    case "/":
+      if (b === 0) throw new Error("Division by zero");
+      r = a / b;
+      break;
+    case "^": r = Math.pow(a, b); break;
+  }
+  if (!Number.isFinite(r)) throw new Error("Operator '" + op + "' produced a result that is not a finite number");
+  return r;
+}`,
+
+	"$order": `(a, b) => {
+  if (a instanceof Date) return Math.sign(a.getTime() - b.getTime());
+  return a < b ? -1 : a > b ? 1 : 0;
+}`,
+
+	"$compare": `(op, a, b) => {
+  if (op !== "==" && op !== "!=") {
+    if (typeof a === "boolean" || typeof b === "boolean") throw new Error("Operator '" + op + "' cannot be applied to boolean operands");
+  }
+  if (a === null || b === null) return null;
+  if ($type(a) !== $type(b)) throw new Error("Cannot compare " + $type(a) + " with " + $type(b) + " using '" + op + "'");
+  const o = $order(a, b);
+  switch (op) {
+    case "<": return o < 0;
+    case "<=": return o <= 0;
+    case ">": return o > 0;
+    case ">=": return o >= 0;
+    case "==": return o === 0;
+    case "!=": return o !== 0;
+  }
+}`,
+
+	"$boolean": `(op, v) => {
+  if (v !== null && typeof v !== "boolean") throw new Error("Operator '" + op + "' expects boolean operands, got " + $type(v));
+  return v;
+}`,
+
+	// The right operand is a thunk so that it is only evaluated when the left operand does not decide the result
+	"$logical": `(op, a, b) => {
+  const decisive = op === "||";
+  if ($boolean(op, a) === decisive) return a;
+  const r = $boolean(op, b());
+  if (r === decisive) return r;
+  return a === null || r === null ? null : !decisive;
+}`,
+
+	"$arg": `(fn, param, type, v) => {
+  if (v !== null && type !== "any" && $type(v) !== type) throw new Error("Argument '" + param + "' of " + fn + " expects " + type + ", got " + $type(v));
+  return v;
+}`,
+
+	"$check": `(fn, params, args) => {
+  for (let i = 0; i < args.length; i++) {
+    const [param, type] = params[Math.min(i, params.length - 1)];
+    $arg(fn, param, type, args[i]);
+  }
+}`,
+
+	// $strict wraps a function that returns null as soon as one of its arguments is null
+	"$strict": `(fn, params, impl) => (...args) => {
+  $check(fn, params, args);
+  return args.includes(null) ? null : impl(...args);
+}`,
+
+	// $nullAware wraps a function that handles null arguments itself
+	"$nullAware": `(fn, params, impl) => (...args) => {
+  $check(fn, params, args);
+  return impl(...args);
+}`,
+
+	"$integer": `(fn, param, v) => {
+  if (!Number.isInteger(v) || Math.abs(v) > 2147483647) throw new Error("Argument '" + param + "' of " + fn + " must be an integer, got " + v);
+  return v;
+}`,
+
+	"$extremum": `(fn, direction) => (...values) => {
+  let result = null;
+  for (const v of values) {
+    if (v === null) continue;
+    if (typeof v === "boolean") throw new Error("Function " + fn + " cannot compare boolean values");
+    if (result === null) {
+      result = v;
+      continue;
+    }
+    if ($type(v) !== $type(result)) throw new Error("Function " + fn + " cannot compare " + $type(result) + " with " + $type(v));
+    if ($order(v, result) * direction > 0) result = v;
+  }
+  return result;
+}`,
+
+	// IF and COALESCE evaluate their arguments lazily, so they are generated inline rather than wrapped
+	"$if": `(condition) => $arg("IF", "condition", "boolean", condition) === true`,
+
+	"$coalesce": `(...args) => {
+  for (const arg of args) {
+    const v = arg();
+    if (v !== null) return v;
+  }
+  return null;
+}`,
+}
+
+// Implementations of the built-in functions, called with arguments already checked against their
+// parameter types. Strings are measured and sliced in characters, and datetimes are handled in UTC.
+var builtins = map[string]string{
+	"UPPER":  `(text) => text.toUpperCase()`,
+	"LOWER":  `(text) => text.toLowerCase()`,
+	"TRIM":   `(text) => text.trim()`,
+	"LENGTH": `(text) => [...text].length`,
+	"LEN":    `(text) => [...text].length`,
+	"CONCAT": `(...texts) => texts.join("")`,
+	"SUBSTRING": `(text, start, length) => {
+  const chars = [...text];
+  $integer("SUBSTRING", "start", start);
+  if (start < 0 || start > chars.length) throw new Error("Argument 'start' of SUBSTRING is out of range: " + start + " is not between 0 and " + chars.length);
+  $integer("SUBSTRING", "length", length);
+  if (length < 0) throw new Error("Argument 'length' of SUBSTRING must not be negative, got " + length);
+  return chars.slice(start, start + length).join("");
+}`,
+	"REPLACE": `(text, search, replacement) => {
+  if (search === "") throw new Error("Argument 'search' of REPLACE must not be empty");
+  return text.split(search).join(replacement);
+}`,
+	"ROUND": `(number, decimals = 0) => {
+  $integer("ROUND", "decimals", decimals);
+  if (decimals < -15 || decimals > 15) throw new Error("Argument 'decimals' of ROUND is out of range: " + decimals + " is not between -15 and 15");
+  const scale = Math.pow(10, decimals);
+  return Math.sign(number) * Math.round(Math.abs(number) * scale) / scale;
+}`,
+	"FLOOR": `Math.floor`,
+	"CEIL":  `Math.ceil`,
+	"ABS":   `Math.abs`,
+	"MIN":   `$extremum("MIN", -1)`,
+	"MAX":   `$extremum("MAX", 1)`,
+	"SUM": `(...numbers) => {
+  const present = numbers.filter((n) => n !== null);
+  return present.length === 0 ? null : present.reduce((total, n) => total + n, 0);
+}`,
+	"AVG": `(...numbers) => {
+  const present = numbers.filter((n) => n !== null);
+  return present.length === 0 ? null : present.reduce((total, n) => total + n, 0) / present.length;
+}`,
+	"COUNT": `(...values) => values.filter((v) => v !== null).length`,
+	"NOW":   `() => new Date()`,
+	"DATE": `(datetime) => {
+  const day = new Date(datetime.getTime());
+  day.setUTCHours(0, 0, 0, 0);
+  return day;
+}`,
+	"YEAR":  `(datetime) => datetime.getUTCFullYear()`,
+	"MONTH": `(datetime) => datetime.getUTCMonth() + 1`,
+	"DAY":   `(datetime) => datetime.getUTCDate()`,
+}
+
+// helperReference matches the helpers a piece of JavaScript refers to
+var helperReference = regexp.MustCompile(`\$[A-Za-z_][A-Za-z0-9_]*`)
+
+// runtime collects the helpers needed by the generated code, in an order where every helper
+// is defined after the helpers it calls while being defined
+type runtime struct {
+	definitions map[string]string // Helpers that may be emitted, by name
+	emitted     map[string]bool
+	lines       []string
+}
+
+func newRuntime() *runtime {
+	return &runtime{definitions: make(map[string]string, len(helpers)), emitted: make(map[string]bool)}
+}
+
+// use adds the named helper and the helpers it refers to
+func (r *runtime) use(name string) {
+	if r.emitted[name] {
+		return
+	}
+	source, ok := r.definitions[name]
+	if !ok {
+		if source, ok = helpers[name]; !ok {
+			return
+		}
+	}
+	r.emitted[name] = true
+	for _, reference := range helperReference.FindAllString(source, -1) {
+		if reference != name {
+			r.use(reference)
+		}
+	}
+	r.lines = append(r.lines, "const "+name+" = "+source+";")
+}
+
+// define makes a helper available under name without emitting it
+func (r *runtime) define(name, source string) {
+	r.definitions[name] = source
+}
+
+// wrap returns the code of a function evaluating body, preceded by the helpers it uses
+func (r *runtime) wrap(body string) string {
+	function := "(row) => " + body
+	if len(r.lines) == 0 {
+		return function
+	}
+
+	var builder strings.Builder
+	builder.WriteString("(() => {\n  \"use strict\";\n")
+	for _, line := range r.lines {
+		builder.WriteString("  ")
+		builder.WriteString(strings.ReplaceAll(line, "\n", "\n  "))
+		builder.WriteString("\n")
+	}
+	builder.WriteString("  return ")
+	builder.WriteString(function)
+	builder.WriteString(";\n})()")
+	return builder.String()
+}
@@ -0,0 +1,237 @@
+package jsgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/core/app/eval"
+	"antlr-editor/analyzer/core/app/functions"
+	"antlr-editor/analyzer/core/infrastructure"
+	"antlr-editor/analyzer/core/models"
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// Visitor generates JavaScript from a parse tree.
+// Every operator becomes a call to a runtime helper implementing the evaluator's null handling and
+// type checks, so grouping, including the right associativity of ^, follows the parse tree exactly.
+type Visitor struct {
+	*parser.BaseExpressionVisitor
+	registry *functions.Registry
+	runtime  *runtime
+	errors   []models.ErrorInfo
+}
+
+// NewJSVisitor creates a visitor generating JavaScript for calls to the functions of registry.
+// Only built-in functions can be generated; calling a custom function is an error.
+func NewJSVisitor(registry *functions.Registry) *Visitor {
+	return &Visitor{
+		BaseExpressionVisitor: &parser.BaseExpressionVisitor{},
+		registry:              registry,
+		runtime:               newRuntime(),
+	}
+}
+
+// Generate returns the source of a JavaScript function `(row) => value` for a syntactically valid parse tree,
+// or the constructs that cannot be expressed in JavaScript.
+// The source is a self-contained expression: helpers it needs are defined in a closure around the function.
+// The row maps column names to numbers, strings, booleans, Dates or null; the function returns a value of
+// the same kinds and throws an Error with the evaluator's message when evaluation fails.
+func (v *Visitor) Generate(tree parser.IExpressionContext) (string, []models.ErrorInfo) {
+	body := v.generate(tree)
+	if len(v.errors) > 0 {
+		return "", v.errors
+	}
+	return v.runtime.wrap(body), nil
+}
+
+// Visit visits a parse tree node and returns its JavaScript
+func (v *Visitor) Visit(tree antlr.ParseTree) any {
+	if tree == nil {
+		return "null"
+	}
+	if result, ok := tree.Accept(v).(string); ok {
+		return result
+	}
+	return "null"
+}
+
+// generate generates the JavaScript of the given expression
+func (v *Visitor) generate(expr parser.IExpressionContext) string {
+	if expr == nil {
+		return "null"
+	}
+	return v.Visit(expr).(string)
+}
+
+// helper returns the name of a runtime helper, adding it to the generated code
+func (v *Visitor) helper(name string) string {
+	v.runtime.use(name)
+	return name
+}
+
+// addError records an error located on the span of the given context
+func (v *Visitor) addError(ctx antlr.ParserRuleContext, message string) {
+	if err, ok := infrastructure.ErrorAt(ctx, message); ok {
+		v.errors = append(v.errors, err)
+	}
+}
+
+// VisitLiteralExpr generates a literal expression
+func (v *Visitor) VisitLiteralExpr(ctx *parser.LiteralExprContext) any {
+	return v.Visit(ctx.Literal())
+}
+
+// VisitLiteral generates a literal value
+func (v *Visitor) VisitLiteral(ctx *parser.LiteralContext) any {
+	switch {
+	case ctx.STRING_LITERAL() != nil:
+		return quote(eval.UnquoteString(ctx.STRING_LITERAL().GetText()))
+	case ctx.BOOLEAN_LITERAL() != nil:
+		return strconv.FormatBool(strings.EqualFold(ctx.GetText(), "true"))
+	default:
+		number, err := strconv.ParseFloat(ctx.GetText(), 64)
+		if err != nil {
+			v.addError(ctx, fmt.Sprintf("Invalid number: %s", ctx.GetText()))
+			return "null"
+		}
+		return strconv.FormatFloat(number, 'g', -1, 64)
+	}
+}
+
+// VisitColumnRefExpr generates a column reference expression
+func (v *Visitor) VisitColumnRefExpr(ctx *parser.ColumnRefExprContext) any {
+	return v.Visit(ctx.ColumnReference())
+}
+
+// VisitColumnReference generates a lookup of the column in the row
+func (v *Visitor) VisitColumnReference(ctx *parser.ColumnReferenceContext) any {
+	text := ctx.COLUMN_REF().GetText()
+	return v.helper("$col") + "(row, " + quote(text[1:len(text)-1]) + ")"
+}
+
+// VisitFunctionCallExpr generates a function call expression
+func (v *Visitor) VisitFunctionCallExpr(ctx *parser.FunctionCallExprContext) any {
+	return v.Visit(ctx.FunctionCall())
+}
+
+// VisitFunctionCall generates a call to the runtime implementation of a built-in function
+func (v *Visitor) VisitFunctionCall(ctx *parser.FunctionCallContext) any {
+	name := ctx.FUNCTION_NAME().GetText()
+
+	var args []string
+	if argList := ctx.ArgumentList(); argList != nil {
+		for _, expr := range argList.AllExpression() {
+			args = append(args, v.generate(expr))
+		}
+	}
+
+	switch name {
+	case "IF":
+		return "(" + v.helper("$if") + "(" + args[0] + ") ? " + args[1] + " : " + args[2] + ")"
+	case "COALESCE":
+		thunks := make([]string, len(args))
+		for i, arg := range args {
+			thunks[i] = "() => " + arg
+		}
+		return v.helper("$coalesce") + "(" + strings.Join(thunks, ", ") + ")"
+	}
+
+	fn, ok := v.registry.Lookup(name)
+	impl, implemented := builtins[name]
+	if !ok || !implemented || !v.registry.IsBuiltin(name) {
+		v.addError(ctx, fmt.Sprintf("Function %s cannot be translated to JavaScript", name))
+		return "null"
+	}
+
+	wrapper := "$strict"
+	if fn.NullAware {
+		wrapper = "$nullAware"
+	}
+	helper := "$" + name
+	v.runtime.define(helper, wrapper+"("+quote(name)+", "+parameterTypes(&fn.FunctionSignature)+", "+impl+")")
+	return v.helper(helper) + "(" + strings.Join(args, ", ") + ")"
+}
+
+// VisitParenExpr generates a parenthesized expression; the helper calls already group their operands
+func (v *Visitor) VisitParenExpr(ctx *parser.ParenExprContext) any {
+	return v.generate(ctx.Expression())
+}
+
+// VisitUnaryMinusExpr generates a negation; negated number literals are folded
+func (v *Visitor) VisitUnaryMinusExpr(ctx *parser.UnaryMinusExprContext) any {
+	operand := v.generate(ctx.Expression())
+	if literal, ok := ctx.Expression().(*parser.LiteralExprContext); ok && literal.Literal().STRING_LITERAL() == nil && literal.Literal().BOOLEAN_LITERAL() == nil {
+		return "-" + operand
+	}
+	return v.helper("$neg") + "(" + operand + ")"
+}
+
+// VisitPowerExpr generates an exponentiation
+func (v *Visitor) VisitPowerExpr(ctx *parser.PowerExprContext) any {
+	return v.binary("$arith", ctx)
+}
+
+// VisitMulDivExpr generates a multiplication/division
+func (v *Visitor) VisitMulDivExpr(ctx *parser.MulDivExprContext) any {
+	return v.binary("$arith", ctx)
+}
+
+// VisitAddSubExpr generates an addition/subtraction
+func (v *Visitor) VisitAddSubExpr(ctx *parser.AddSubExprContext) any {
+	return v.binary("$arith", ctx)
+}
+
+// VisitComparisonExpr generates a comparison
+func (v *Visitor) VisitComparisonExpr(ctx *parser.ComparisonExprContext) any {
+	return v.binary("$compare", ctx)
+}
+
+// VisitAndExpr generates a logical AND
+func (v *Visitor) VisitAndExpr(ctx *parser.AndExprContext) any {
+	return v.logical(ctx)
+}
+
+// VisitOrExpr generates a logical OR
+func (v *Visitor) VisitOrExpr(ctx *parser.OrExprContext) any {
+	return v.logical(ctx)
+}
+
+// binary generates a call of helper with the operator and both operands
+func (v *Visitor) binary(helper string, ctx infrastructure.BinaryExpressionContext) string {
+	left, right := v.generate(ctx.Expression(0)), v.generate(ctx.Expression(1))
+	return v.helper(helper) + "(" + quote(infrastructure.OperatorText(ctx)) + ", " + left + ", " + right + ")"
+}
+
+// logical generates a logical operator whose right operand is only evaluated when needed
+func (v *Visitor) logical(ctx infrastructure.BinaryExpressionContext) string {
+	left, right := v.generate(ctx.Expression(0)), v.generate(ctx.Expression(1))
+	return v.helper("$logical") + "(" + quote(infrastructure.OperatorText(ctx)) + ", " + left + ", () => " + right + ")"
+}
+
+// parameterTypes renders the names and types of the parameters of a function as a JavaScript array;
+// the last entry applies to any further argument
+func parameterTypes(signature *models.FunctionSignature) string {
+	params := signature.Parameters
+	if signature.Variadic != nil {
+		params = append(params[:len(params):len(params)], *signature.Variadic)
+	}
+	entries := make([]string, len(params))
+	for i, param := range params {
+		entries[i] = "[" + quote(param.Name) + ", " + quote(string(param.Type)) + "]"
+	}
+	return "[" + strings.Join(entries, ", ") + "]"
+}
+
+// quote renders s as a JavaScript string literal
+func quote(s string) string {
+	var builder strings.Builder
+	encoder := json.NewEncoder(&builder)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	// The encoder also escapes the line and paragraph separators, which older engines reject in string literals
+	return strings.TrimSuffix(builder.String(), "\n")
+}
@@ -0,0 +1,217 @@
+package pandasgen
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Argument is a translated function argument
+type Argument struct {
+	Code       string // Translated argument
+	Series     bool   // Whether Code evaluates to a Series rather than a scalar
+	precedence int    // Precedence of the outermost operator of Code
+}
+
+// Operand returns the argument in a form that can be used as an operand of any operator or as the target of an attribute
+func (a Argument) Operand() string {
+	if a.precedence == precedencePrimary {
+		return a.Code
+	}
+	return "(" + a.Code + ")"
+}
+
+// AsSeries returns the argument as a Series operand, broadcasting a scalar over the rows of the frame
+func (a Argument) AsSeries() string {
+	if a.Series {
+		return a.Operand()
+	}
+	return "pd.Series(" + a.Code + ", index=" + frame + ".index)"
+}
+
+// translator renders a function call from its translated arguments, or returns an error
+// if the call cannot be expressed with pandas
+type translator func(name string, args []Argument) (Argument, error)
+
+// functions maps the built-in functions to their pandas equivalents.
+// Functions missing from the table, such as custom functions, cannot be translated.
+var functions = map[string]translator{
+	"UPPER":     stringMethod("upper()"),
+	"LOWER":     stringMethod("lower()"),
+	"TRIM":      stringMethod("strip()"),
+	"LENGTH":    stringMethod("len()"),
+	"LEN":       stringMethod("len()"),
+	"CONCAT":    concat,
+	"SUBSTRING": substring,
+	"REPLACE":   replace,
+	"ROUND":     round,
+	"FLOOR":     numpyFunction("np.floor"),
+	"CEIL":      numpyFunction("np.ceil"),
+	"ABS":       numpyFunction("np.abs"),
+	"MIN":       extremum("np.fmin"),
+	"MAX":       extremum("np.fmax"),
+	"SUM":       rowReduction("sum(axis=1, min_count=1)"),
+	"AVG":       rowReduction("mean(axis=1)"),
+	"COUNT":     rowReduction("count(axis=1)"),
+	"IF":        ifThenElse,
+	"COALESCE":  coalesce,
+	"NOW":       now,
+	"DATE":      datetimeAccessor("normalize()"),
+	"YEAR":      datetimeAccessor("year"),
+	"MONTH":     datetimeAccessor("month"),
+	"DAY":       datetimeAccessor("day"),
+}
+
+// series creates a translated Series expression
+func series(code string) Argument {
+	return Argument{Code: code, Series: true, precedence: precedencePrimary}
+}
+
+// anySeries reports whether one of the arguments is a Series
+func anySeries(args []Argument) bool {
+	for _, arg := range args {
+		if arg.Series {
+			return true
+		}
+	}
+	return false
+}
+
+// constantInteger returns the value of an argument that is a non-negative integer literal
+func constantInteger(arg Argument) (int, bool) {
+	n, err := strconv.Atoi(arg.Code)
+	return n, err == nil && n >= 0
+}
+
+// notConstant reports an argument that pandas requires to be a constant
+func notConstant(name, param string) error {
+	return fmt.Errorf("Argument '%s' of %s must be a constant to be translated to pandas", param, name)
+}
+
+func stringMethod(method string) translator {
+	return func(_ string, args []Argument) (Argument, error) {
+		return series(args[0].AsSeries() + ".str." + method), nil
+	}
+}
+
+func numpyFunction(function string) translator {
+	return func(_ string, args []Argument) (Argument, error) {
+		return Argument{Code: function + "(" + args[0].Code + ")", Series: args[0].Series, precedence: precedencePrimary}, nil
+	}
+}
+
+func datetimeAccessor(attribute string) translator {
+	return func(_ string, args []Argument) (Argument, error) {
+		return series(args[0].AsSeries() + ".dt." + attribute), nil
+	}
+}
+
+// concat adds the texts, which concatenates Series element-wise and yields NaN where one of them is missing
+func concat(_ string, args []Argument) (Argument, error) {
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	operands := make([]string, len(args))
+	for i, arg := range args {
+		operands[i] = arg.Operand()
+	}
+	return Argument{Code: "(" + strings.Join(operands, " + ") + ")", Series: anySeries(args), precedence: precedencePrimary}, nil
+}
+
+// substring slices characters with a zero-based start like SUBSTRING; the bounds must be constants
+func substring(name string, args []Argument) (Argument, error) {
+	start, ok := constantInteger(args[1])
+	if !ok {
+		return Argument{}, notConstant(name, "start")
+	}
+	length, ok := constantInteger(args[2])
+	if !ok {
+		return Argument{}, notConstant(name, "length")
+	}
+	return series(fmt.Sprintf("%s.str.slice(%d, %d)", args[0].AsSeries(), start, start+length)), nil
+}
+
+func replace(name string, args []Argument) (Argument, error) {
+	if args[1].Series {
+		return Argument{}, notConstant(name, "search")
+	}
+	if args[2].Series {
+		return Argument{}, notConstant(name, "replace")
+	}
+	return series(args[0].AsSeries() + ".str.replace(" + args[1].Code + ", " + args[2].Code + ", regex=False)"), nil
+}
+
+// round rounds half away from zero like ROUND; np.round rounds half to even
+func round(name string, args []Argument) (Argument, error) {
+	number := args[0]
+	scale := ""
+	if len(args) > 1 {
+		decimals, err := strconv.Atoi(args[1].Code)
+		if err != nil {
+			return Argument{}, notConstant(name, "decimals")
+		}
+		if decimals != 0 {
+			scale = strconv.FormatFloat(math.Pow(10, float64(decimals)), 'g', -1, 64)
+		}
+	}
+
+	magnitude := "np.abs(" + number.Code + ")"
+	if scale != "" {
+		magnitude += " * " + scale
+	}
+	code := "np.sign(" + number.Code + ") * np.floor(" + magnitude + " + 0.5)"
+	if scale != "" {
+		code += " / " + scale
+	}
+	return Argument{Code: "(" + code + ")", Series: number.Series, precedence: precedencePrimary}, nil
+}
+
+// extremum combines the arguments pairwise with a NumPy function that ignores NaN
+func extremum(function string) translator {
+	return func(_ string, args []Argument) (Argument, error) {
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = Argument{
+				Code:       function + "(" + result.Code + ", " + arg.Code + ")",
+				Series:     result.Series || arg.Series,
+				precedence: precedencePrimary,
+			}
+		}
+		return result, nil
+	}
+}
+
+// rowReduction reduces the arguments of each row, skipping NaN
+func rowReduction(reduction string) translator {
+	return func(_ string, args []Argument) (Argument, error) {
+		if len(args) == 0 {
+			return series("pd.Series(0, index=" + frame + ".index)"), nil
+		}
+		columns := make([]string, len(args))
+		for i, arg := range args {
+			columns[i] = arg.AsSeries()
+		}
+		return series("pd.concat([" + strings.Join(columns, ", ") + "], axis=1)." + reduction), nil
+	}
+}
+
+// ifThenElse selects the false value where the condition is false or missing, like IF
+func ifThenElse(_ string, args []Argument) (Argument, error) {
+	return series(args[1].AsSeries() + ".where(" + args[0].AsSeries() + " == True, " + args[2].Code + ")"), nil
+}
+
+func coalesce(_ string, args []Argument) (Argument, error) {
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	code := args[0].AsSeries()
+	for _, arg := range args[1:] {
+		code += ".fillna(" + arg.Code + ")"
+	}
+	return series(code), nil
+}
+
+func now(_ string, _ []Argument) (Argument, error) {
+	return Argument{Code: "pd.Timestamp.now()", precedence: precedencePrimary}, nil
+}
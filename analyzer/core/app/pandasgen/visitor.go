@@ -0,0 +1,220 @@
+package pandasgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/core/app/eval"
+	"antlr-editor/analyzer/core/infrastructure"
+	"antlr-editor/analyzer/core/models"
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// frame is the name of the DataFrame the generated code reads columns from
+const frame = "df"
+
+// Operator precedence of generated Python, from loosest to tightest binding.
+// Unlike && and || in expressions, & and | bind more tightly than comparisons in Python.
+const (
+	precedenceComparison = iota + 1
+	precedenceOr
+	precedenceAnd
+	precedenceAdditive
+	precedenceMultiplicative
+	precedenceUnary
+	precedencePower
+	precedencePrimary
+)
+
+// Visitor generates a vectorized pandas expression from a parse tree
+type Visitor struct {
+	*parser.BaseExpressionVisitor
+	errors []models.ErrorInfo
+}
+
+// NewPandasVisitor creates a visitor generating pandas expressions
+func NewPandasVisitor() *Visitor {
+	return &Visitor{BaseExpressionVisitor: &parser.BaseExpressionVisitor{}}
+}
+
+// Generate returns the pandas expression for a syntactically valid parse tree,
+// or the constructs that cannot be expressed with pandas.
+// The expression reads columns from a DataFrame named df and uses the modules pandas as pd and numpy as np.
+func (v *Visitor) Generate(tree parser.IExpressionContext) (string, []models.ErrorInfo) {
+	code := v.generate(tree).Code
+	if len(v.errors) > 0 {
+		return "", v.errors
+	}
+	return code, nil
+}
+
+// Visit visits a parse tree node and returns its translation
+func (v *Visitor) Visit(tree antlr.ParseTree) any {
+	if tree == nil {
+		return none()
+	}
+	if result, ok := tree.Accept(v).(Argument); ok {
+		return result
+	}
+	return none()
+}
+
+// generate generates the pandas code of the given expression
+func (v *Visitor) generate(expr parser.IExpressionContext) Argument {
+	if expr == nil {
+		return none()
+	}
+	return v.Visit(expr).(Argument)
+}
+
+// none is the translation of a missing or failed expression
+func none() Argument {
+	return Argument{Code: "None", precedence: precedencePrimary}
+}
+
+// addError records an error located on the span of the given context
+func (v *Visitor) addError(ctx antlr.ParserRuleContext, message string) {
+	if err, ok := infrastructure.ErrorAt(ctx, message); ok {
+		v.errors = append(v.errors, err)
+	}
+}
+
+// VisitLiteralExpr generates a literal expression
+func (v *Visitor) VisitLiteralExpr(ctx *parser.LiteralExprContext) any {
+	return v.Visit(ctx.Literal())
+}
+
+// VisitLiteral generates a literal value.
+// Go quoted strings are valid Python string literals, and numbers are normalized since Python rejects leading zeros.
+func (v *Visitor) VisitLiteral(ctx *parser.LiteralContext) any {
+	switch {
+	case ctx.STRING_LITERAL() != nil:
+		return Argument{Code: strconv.Quote(eval.UnquoteString(ctx.STRING_LITERAL().GetText())), precedence: precedencePrimary}
+	case ctx.BOOLEAN_LITERAL() != nil:
+		if strings.EqualFold(ctx.GetText(), "true") {
+			return Argument{Code: "True", precedence: precedencePrimary}
+		}
+		return Argument{Code: "False", precedence: precedencePrimary}
+	default:
+		number, err := strconv.ParseFloat(ctx.GetText(), 64)
+		if err != nil {
+			v.addError(ctx, fmt.Sprintf("Invalid number: %s", ctx.GetText()))
+			return none()
+		}
+		return Argument{Code: strconv.FormatFloat(number, 'g', -1, 64), precedence: precedencePrimary}
+	}
+}
+
+// VisitColumnRefExpr generates a column reference expression
+func (v *Visitor) VisitColumnRefExpr(ctx *parser.ColumnRefExprContext) any {
+	return v.Visit(ctx.ColumnReference())
+}
+
+// VisitColumnReference generates a column of the frame
+func (v *Visitor) VisitColumnReference(ctx *parser.ColumnReferenceContext) any {
+	text := ctx.COLUMN_REF().GetText()
+	return series(frame + "[" + strconv.Quote(text[1:len(text)-1]) + "]")
+}
+
+// VisitFunctionCallExpr generates a function call expression
+func (v *Visitor) VisitFunctionCallExpr(ctx *parser.FunctionCallExprContext) any {
+	return v.Visit(ctx.FunctionCall())
+}
+
+// VisitFunctionCall translates a function call with the function mapping table
+func (v *Visitor) VisitFunctionCall(ctx *parser.FunctionCallContext) any {
+	name := ctx.FUNCTION_NAME().GetText()
+
+	var args []Argument
+	if argList := ctx.ArgumentList(); argList != nil {
+		for _, expr := range argList.AllExpression() {
+			args = append(args, v.generate(expr))
+		}
+	}
+
+	translate, ok := functions[name]
+	if !ok {
+		v.addError(ctx, fmt.Sprintf("Function %s cannot be translated to pandas", name))
+		return none()
+	}
+	result, err := translate(name, args)
+	if err != nil {
+		v.addError(ctx, err.Error())
+		return none()
+	}
+	return result
+}
+
+// VisitParenExpr generates a parenthesized expression; parentheses are re-inserted where precedence requires them
+func (v *Visitor) VisitParenExpr(ctx *parser.ParenExprContext) any {
+	return v.generate(ctx.Expression())
+}
+
+// VisitUnaryMinusExpr generates a negation
+func (v *Visitor) VisitUnaryMinusExpr(ctx *parser.UnaryMinusExprContext) any {
+	operand := v.generate(ctx.Expression())
+	code := operand.Code
+	if operand.precedence < precedenceUnary {
+		code = "(" + code + ")"
+	}
+	return Argument{Code: "-" + code, Series: operand.Series, precedence: precedenceUnary}
+}
+
+// VisitPowerExpr generates an exponentiation with the right-associative **.
+// ** binds more tightly than a unary minus on its left, so a negated base is parenthesized.
+func (v *Visitor) VisitPowerExpr(ctx *parser.PowerExprContext) any {
+	base, exponent := v.generate(ctx.Expression(0)), v.generate(ctx.Expression(1))
+	left, right := base.Code, exponent.Code
+	if base.precedence <= precedencePower {
+		left = "(" + left + ")"
+	}
+	if exponent.precedence < precedencePower {
+		right = "(" + right + ")"
+	}
+	return Argument{Code: left + " ** " + right, Series: base.Series || exponent.Series, precedence: precedencePower}
+}
+
+// VisitMulDivExpr generates a multiplication/division
+func (v *Visitor) VisitMulDivExpr(ctx *parser.MulDivExprContext) any {
+	return binary(v.generate(ctx.Expression(0)), infrastructure.OperatorText(ctx), v.generate(ctx.Expression(1)), precedenceMultiplicative)
+}
+
+// VisitAddSubExpr generates an addition/subtraction
+func (v *Visitor) VisitAddSubExpr(ctx *parser.AddSubExprContext) any {
+	return binary(v.generate(ctx.Expression(0)), infrastructure.OperatorText(ctx), v.generate(ctx.Expression(1)), precedenceAdditive)
+}
+
+// VisitComparisonExpr generates a comparison.
+// Python chains comparisons, so a comparison operand is always parenthesized.
+func (v *Visitor) VisitComparisonExpr(ctx *parser.ComparisonExprContext) any {
+	left := v.generate(ctx.Expression(0))
+	if left.precedence == precedenceComparison {
+		left = Argument{Code: "(" + left.Code + ")", Series: left.Series, precedence: precedencePrimary}
+	}
+	return binary(left, infrastructure.OperatorText(ctx), v.generate(ctx.Expression(1)), precedenceComparison)
+}
+
+// VisitAndExpr generates a logical AND as the element-wise &
+func (v *Visitor) VisitAndExpr(ctx *parser.AndExprContext) any {
+	return binary(v.generate(ctx.Expression(0)), "&", v.generate(ctx.Expression(1)), precedenceAnd)
+}
+
+// VisitOrExpr generates a logical OR as the element-wise |
+func (v *Visitor) VisitOrExpr(ctx *parser.OrExprContext) any {
+	return binary(v.generate(ctx.Expression(0)), "|", v.generate(ctx.Expression(1)), precedenceOr)
+}
+
+// binary generates a left-associative binary operation, parenthesizing operands that bind more loosely
+func binary(left Argument, operator string, right Argument, precedence int) Argument {
+	leftCode, rightCode := left.Code, right.Code
+	if left.precedence < precedence {
+		leftCode = "(" + leftCode + ")"
+	}
+	if right.precedence <= precedence {
+		rightCode = "(" + rightCode + ")"
+	}
+	return Argument{Code: leftCode + " " + operator + " " + rightCode, Series: left.Series || right.Series, precedence: precedence}
+}
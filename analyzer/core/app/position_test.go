@@ -0,0 +1,184 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+// multilingualExpression mixes one-unit ASCII, three-byte CJK and a four-byte, two UTF-16 unit emoji
+const multilingualExpression = "CONCAT('😀', [顧客名], [x])"
+
+func newMultilingualApp(t *testing.T, encoding models.PositionEncoding) *App {
+	app := NewApp()
+	app.SetSchema(models.NewSchema([]models.Column{{Name: "顧客名", Type: models.DataTypeString}}))
+	require.NoError(t, app.SetPositionEncoding(encoding))
+	return app
+}
+
+func findToken(tokens []models.TokenInfo, text string) models.TokenInfo {
+	for _, token := range tokens {
+		if token.Text == text {
+			return token
+		}
+	}
+	return models.TokenInfo{}
+}
+
+func TestApp_SetPositionEncoding(t *testing.T) {
+	testCases := []struct {
+		encoding models.PositionEncoding
+		customer models.Span // [顧客名]
+		unknown  models.Span // [x]
+	}{
+		{models.PositionEncodingRune, models.Span{Start: 12, End: 17}, models.Span{Start: 19, End: 22}},
+		{"", models.Span{Start: 12, End: 17}, models.Span{Start: 19, End: 22}},
+		{models.PositionEncodingUTF16, models.Span{Start: 13, End: 18}, models.Span{Start: 20, End: 23}},
+		{models.PositionEncodingByte, models.Span{Start: 15, End: 26}, models.Span{Start: 28, End: 31}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.encoding), func(t *testing.T) {
+			app := newMultilingualApp(t, tc.encoding)
+
+			t.Run("tokenize", func(t *testing.T) {
+				token := findToken(app.Tokenize(multilingualExpression).Tokens, "顧客名")
+				assert.Equal(t, models.Span{Start: tc.customer.Start + 1, End: tc.customer.End - 1}, models.Span{Start: token.Start, End: token.End})
+				assert.Equal(t, tc.customer.Start+1, token.Column)
+				bracket := findToken(app.Tokenize(multilingualExpression).Tokens, "]")
+				assert.Equal(t, tc.customer.End-1, bracket.Column)
+			})
+
+			t.Run("lint", func(t *testing.T) {
+				errors := app.Lint(multilingualExpression)
+				require.Len(t, errors, 1)
+				assert.Equal(t, tc.unknown, models.Span{Start: errors[0].Start, End: errors[0].End})
+				assert.Equal(t, tc.unknown.Start, errors[0].Column)
+			})
+
+			t.Run("parse tree", func(t *testing.T) {
+				tree := app.ParseTree(multilingualExpression).Tree
+				require.NotNil(t, tree)
+				arguments := tree.Children[0].Children[1]
+				require.Equal(t, models.NodeTypeArgumentList, arguments.Type)
+				column := arguments.Children[1]
+				assert.Equal(t, "[顧客名]", column.Text)
+				assert.Equal(t, tc.customer, models.Span{Start: column.Start, End: column.End})
+				assert.Equal(t, tc.unknown.End+1, tree.End)
+			})
+
+			t.Run("parse cst", func(t *testing.T) {
+				for _, token := range app.ParseCST(multilingualExpression).Tree.Tokens() {
+					if token.Text == "[x]" {
+						assert.Equal(t, tc.unknown, models.Span{Start: token.Start, End: token.End})
+					}
+				}
+			})
+
+			t.Run("references", func(t *testing.T) {
+				columns := app.References(multilingualExpression).Columns
+				require.Len(t, columns, 2)
+				assert.Equal(t, []models.Span{{Start: tc.unknown.Start + 1, End: tc.unknown.End - 1}}, columns[1].Occurrences)
+			})
+
+			t.Run("offsets are read in the encoding", func(t *testing.T) {
+				node := app.NodeAt(multilingualExpression, tc.unknown.Start).Node
+				require.NotNil(t, node)
+				assert.Equal(t, "[x]", node.Text)
+				assert.Equal(t, tc.unknown, models.Span{Start: node.Start, End: node.End})
+
+				help := app.SignatureHelp(multilingualExpression, tc.unknown.Start)
+				assert.Equal(t, 2, help.ActiveParameter)
+
+				items := app.Complete(multilingualExpression, tc.unknown.Start+1).Items
+				require.NotEmpty(t, items)
+				assert.Equal(t, tc.unknown, models.Span{Start: items[0].Edit.Start, End: items[0].Edit.End})
+			})
+
+			t.Run("rename", func(t *testing.T) {
+				edits := app.RenameColumn(multilingualExpression, "x", "数量").Edits
+				require.Len(t, edits, 1)
+				assert.Equal(t, tc.unknown.Start+1, edits[0].Start)
+				assert.Equal(t, tc.unknown.End-1, edits[0].End)
+			})
+		})
+	}
+}
+
+func TestApp_SetPositionEncoding_MultiLine(t *testing.T) {
+	expression := "[a]\n'😀' [顧客名]"
+	testCases := []struct {
+		encoding      models.PositionEncoding
+		start, column int
+	}{
+		{models.PositionEncodingRune, 9, 5},
+		{models.PositionEncodingUTF16, 10, 6},
+		{models.PositionEncodingByte, 12, 8},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.encoding), func(t *testing.T) {
+			app := newMultilingualApp(t, tc.encoding)
+			token := findToken(app.Tokenize(expression).Tokens, "顧客名")
+			assert.Equal(t, 2, token.Line)
+			assert.Equal(t, tc.start, token.Start)
+			assert.Equal(t, tc.column, token.Column, "columns count from the start of the line in the encoding")
+		})
+	}
+}
+
+func TestApp_SetPositionEncoding_Evaluate(t *testing.T) {
+	app := newMultilingualApp(t, models.PositionEncodingUTF16)
+	expression := "LEN('😀') / [n]"
+
+	result := app.Evaluate(expression, map[string]any{"n": 0})
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, 12, result.Errors[0].Start, "division by zero is reported at the divisor")
+	assert.Equal(t, 15, result.Errors[0].End)
+
+	compiled, errors := app.Compile(expression)
+	require.Empty(t, errors)
+	_, err := compiled.Eval([]models.Value{models.NumberValue(0)})
+	require.NotNil(t, err)
+	assert.Equal(t, 15, err.End)
+
+	batch, batchErr := compiled.EvaluateColumns(map[string]any{"n": []float64{1, 0}})
+	require.NoError(t, batchErr)
+	require.Len(t, batch.Errors, 1)
+	assert.Equal(t, 15, batch.Errors[0].End)
+
+	_, errors = app.Compile("'😀' +")
+	require.NotEmpty(t, errors)
+	assert.Equal(t, 6, errors[0].Start, "error at EOF")
+}
+
+func TestApp_SetPositionEncoding_Invalid(t *testing.T) {
+	app := NewApp()
+	assert.Error(t, app.SetPositionEncoding("utf-32"))
+	require.NoError(t, app.SetPositionEncoding(models.PositionEncodingUTF16))
+	assert.Error(t, app.SetPositionEncoding("UTF16"))
+
+	// The previous encoding is kept
+	token := findToken(app.Tokenize("'😀' + 1").Tokens, "1")
+	assert.Equal(t, 7, token.Start)
+}
+
+func TestPositionMap(t *testing.T) {
+	assert.Nil(t, models.NewPositionMap("[price] * 2", models.PositionEncodingByte), "ASCII needs no mapping")
+	assert.Nil(t, models.NewPositionMap("[顧客名]", models.PositionEncodingUTF16), "BMP characters are one UTF-16 unit")
+	assert.Nil(t, models.NewPositionMap("'😀'", models.PositionEncodingRune))
+
+	positions := models.NewPositionMap("a😀b", models.PositionEncodingUTF16)
+	require.NotNil(t, positions)
+	assert.Equal(t, []int{0, 1, 3, 4, 5}, []int{positions.Offset(0), positions.Offset(1), positions.Offset(2), positions.Offset(3), positions.Offset(4)})
+	assert.Equal(t, -1, positions.Offset(-1), "missing positions are kept")
+
+	assert.Equal(t, 1, positions.CodePoint(1))
+	assert.Equal(t, 1, positions.CodePoint(2), "inside a surrogate pair")
+	assert.Equal(t, 2, positions.CodePoint(3))
+	assert.Equal(t, 3, positions.CodePoint(4))
+	assert.Equal(t, 4, positions.CodePoint(5))
+}
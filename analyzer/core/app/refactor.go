@@ -0,0 +1,69 @@
+package app
+
+import (
+	"fmt"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+// RenameResult represents the result of renaming a column in an expression
+type RenameResult struct {
+	Expression string             `json:"expression"` // Expression with the edits applied
+	Edits      []models.TextEdit  `json:"edits"`      // One edit per renamed reference, in source order
+	Errors     []models.ErrorInfo `json:"errors"`     // Invalid column names
+}
+
+// AsMap converts RenameResult to a map for JSON serialization
+func (r *RenameResult) AsMap() map[string]any {
+	edits := make([]any, len(r.Edits))
+	for i, edit := range r.Edits {
+		edits[i] = edit.AsMap()
+	}
+
+	errors := make([]any, len(r.Errors))
+	for i, err := range r.Errors {
+		errors[i] = err.AsMap()
+	}
+
+	return map[string]any{
+		"expression": r.Expression,
+		"edits":      edits,
+		"errors":     errors,
+	}
+}
+
+// RenameColumn renames the references to column oldName in the expression to newName.
+// Only the names between the brackets of column reference tokens are replaced, so whitespace, layout and
+// string literals containing the name are left untouched. Since only tokens are needed, expressions with
+// syntax errors are renamed as well. The result holds both the edited expression and the edits, whose
+// positions refer to the original expression; a name that cannot be written as a column reference is an error.
+func (a *Analyzer) RenameColumn(expression, oldName, newName string) *RenameResult {
+	for _, name := range []string{oldName, newName} {
+		if !models.IsColumnName(name) {
+			return &RenameResult{
+				Expression: expression,
+				Edits:      []models.TextEdit{},
+				Errors: []models.ErrorInfo{{
+					Message: fmt.Sprintf("Invalid column name: %q must be non-empty without brackets or whitespace", name),
+					Line:    1,
+					Column:  0,
+					Start:   0,
+					End:     0,
+				}},
+			}
+		}
+	}
+
+	edits := make([]models.TextEdit, 0)
+	for _, token := range a.Tokenize(expression).Tokens {
+		if token.Type == models.TokenColumnReference && token.Text == oldName && oldName != newName {
+			edits = append(edits, models.TextEdit{Start: token.Start, End: token.End, NewText: newName})
+		}
+	}
+
+	return &RenameResult{
+		Expression: models.ApplyEdits(expression, edits),
+		Edits:      edits,
+		Errors:     []models.ErrorInfo{},
+	}
+}
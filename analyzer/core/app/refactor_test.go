@@ -0,0 +1,128 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+func TestAnalyzer_RenameColumn(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	testCases := []struct {
+		name       string
+		expression string
+		oldName    string
+		newName    string
+		expected   string
+		edits      []models.TextEdit
+	}{
+		{
+			"every reference", "[price] * [qty] + [price]", "price", "unit_price",
+			"[unit_price] * [qty] + [unit_price]",
+			[]models.TextEdit{{Start: 1, End: 6, NewText: "unit_price"}, {Start: 19, End: 24, NewText: "unit_price"}},
+		},
+		{
+			"layout kept", "IF(  [a]>1 ,\n\t[a] ,0 )", "a", "amount",
+			"IF(  [amount]>1 ,\n\t[amount] ,0 )",
+			[]models.TextEdit{{Start: 6, End: 7, NewText: "amount"}, {Start: 15, End: 16, NewText: "amount"}},
+		},
+		{
+			"string literals skipped", `CONCAT('[a]', "[a] and a", [a])`, "a", "b",
+			`CONCAT('[a]', "[a] and a", [b])`,
+			[]models.TextEdit{{Start: 28, End: 29, NewText: "b"}},
+		},
+		{
+			"other columns skipped", "[a] + [ab] + [A]", "a", "x",
+			"[x] + [ab] + [A]",
+			[]models.TextEdit{{Start: 1, End: 2, NewText: "x"}},
+		},
+		{
+			"code point positions", "'日本' + [列]", "列", "column",
+			"'日本' + [column]",
+			[]models.TextEdit{{Start: 8, End: 9, NewText: "column"}},
+		},
+		{
+			"quotes in names", `[unit"qty] * 2`, `unit"qty`, `unit'qty`,
+			`[unit'qty] * 2`,
+			[]models.TextEdit{{Start: 1, End: 9, NewText: `unit'qty`}},
+		},
+		{
+			"syntax errors", "[a] + + [a] #", "a", "b",
+			"[b] + + [b] #",
+			[]models.TextEdit{{Start: 1, End: 2, NewText: "b"}, {Start: 9, End: 10, NewText: "b"}},
+		},
+		{"no reference", "[b] + 1", "a", "c", "[b] + 1", []models.TextEdit{}},
+		{"same name", "[a] + 1", "a", "a", "[a] + 1", []models.TextEdit{}},
+		{"empty expression", "", "a", "b", "", []models.TextEdit{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.RenameColumn(tc.expression, tc.oldName, tc.newName)
+			require.Empty(t, result.Errors)
+			assert.Equal(t, tc.expected, result.Expression)
+			assert.Equal(t, tc.edits, result.Edits)
+			assert.Equal(t, tc.expected, models.ApplyEdits(tc.expression, result.Edits))
+		})
+	}
+}
+
+func TestAnalyzer_RenameColumn_Errors(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	testCases := []struct {
+		name     string
+		oldName  string
+		newName  string
+		expected string
+	}{
+		{"new name with space", "a", "unit price", `Invalid column name: "unit price" must be non-empty without brackets or whitespace`},
+		{"new name with bracket", "a", "a]", `Invalid column name: "a]" must be non-empty without brackets or whitespace`},
+		{"empty old name", "", "b", `Invalid column name: "" must be non-empty without brackets or whitespace`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.RenameColumn("[a] + 1", tc.oldName, tc.newName)
+			require.Len(t, result.Errors, 1)
+			assert.Equal(t, tc.expected, result.Errors[0].Message)
+			assert.Equal(t, "[a] + 1", result.Expression)
+			assert.Empty(t, result.Edits)
+		})
+	}
+}
+
+func TestRenameResult_AsMap(t *testing.T) {
+	result := NewApp().RenameColumn("[a]", "a", "b")
+	assert.Equal(t, map[string]any{
+		"expression": "[b]",
+		"edits":      []any{map[string]any{"start": 1, "end": 2, "newText": "b"}},
+		"errors":     []any{},
+	}, result.AsMap())
+}
+
+func TestApplyEdits(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		edits    []models.TextEdit
+		expected string
+	}{
+		{"no edits", "abc", nil, "abc"},
+		{"unordered", "one two three", []models.TextEdit{{Start: 8, End: 13, NewText: "3"}, {Start: 0, End: 3, NewText: "1"}}, "1 two 3"},
+		{"insertion", "ab", []models.TextEdit{{Start: 1, End: 1, NewText: "-"}}, "a-b"},
+		{"deletion", "a--b", []models.TextEdit{{Start: 1, End: 3}}, "ab"},
+		{"code points", "é[x]é", []models.TextEdit{{Start: 2, End: 3, NewText: "yz"}}, "é[yz]é"},
+		{"clamped", "abc", []models.TextEdit{{Start: 2, End: 10, NewText: "Z"}}, "abZ"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, models.ApplyEdits(tc.text, tc.edits))
+		})
+	}
+}
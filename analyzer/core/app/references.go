@@ -0,0 +1,84 @@
+package app
+
+import (
+	"antlr-editor/analyzer/core/models"
+)
+
+// ReferencesResult lists the columns and functions an expression uses
+type ReferencesResult struct {
+	Columns   []models.Reference `json:"columns"`   // Distinct columns, in order of first occurrence
+	Functions []models.Reference `json:"functions"` // Distinct functions, in order of first occurrence
+	Complete  bool               `json:"complete"`  // Whether the expression parsed without syntax errors
+	Errors    []models.ErrorInfo `json:"errors"`    // Syntax errors
+}
+
+// AsMap converts ReferencesResult to a map for JSON serialization
+func (r *ReferencesResult) AsMap() map[string]any {
+	columns := make([]any, len(r.Columns))
+	for i, column := range r.Columns {
+		columns[i] = column.AsMap()
+	}
+
+	functions := make([]any, len(r.Functions))
+	for i, function := range r.Functions {
+		functions[i] = function.AsMap()
+	}
+
+	errors := make([]any, len(r.Errors))
+	for i, err := range r.Errors {
+		errors[i] = err.AsMap()
+	}
+
+	return map[string]any{
+		"columns":   columns,
+		"functions": functions,
+		"complete":  r.Complete,
+		"errors":    errors,
+	}
+}
+
+// References returns each distinct column and function the expression uses, with the spans of all occurrences.
+// Occurrences are found in the tokens, so an expression with syntax errors still reports the references
+// it contains; Complete is false in that case, since the unparsed text may be incomplete or misread.
+// Spans cover the column name without brackets, or the function name without its arguments.
+func (a *Analyzer) References(expression string) *ReferencesResult {
+	result := &ReferencesResult{
+		Columns:   []models.Reference{},
+		Functions: []models.Reference{},
+		Complete:  true,
+		Errors:    []models.ErrorInfo{},
+	}
+	if expression == "" {
+		return result
+	}
+
+	parsed := lexAndParse(a.helper, expression)
+	if errors := a.syntaxErrors(parsed); len(errors) > 0 {
+		result.Complete, result.Errors = false, errors
+	}
+
+	// The tokens the parser read, so the expression is lexed only once
+	columns, functions := make(map[string]int), make(map[string]int)
+	for _, token := range a.collectTokens(parsed.tokens) {
+		span := models.Span{Start: token.Start, End: token.End}
+		switch token.Type {
+		case models.TokenColumnReference:
+			result.Columns = addOccurrence(result.Columns, columns, token.Text, span)
+		case models.TokenFunction:
+			result.Functions = addOccurrence(result.Functions, functions, token.Text, span)
+		}
+	}
+	return result
+}
+
+// addOccurrence records an occurrence of name, adding a reference the first time the name occurs
+func addOccurrence(references []models.Reference, indexes map[string]int, name string, span models.Span) []models.Reference {
+	index, ok := indexes[name]
+	if !ok {
+		index = len(references)
+		indexes[name] = index
+		references = append(references, models.Reference{Name: name})
+	}
+	references[index].Occurrences = append(references[index].Occurrences, span)
+	return references
+}
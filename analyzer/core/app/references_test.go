@@ -0,0 +1,79 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+func TestAnalyzer_References(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	result := analyzer.References(`IF([qty] > 0, ROUND([price] * [qty], 2), ROUND([price]))`)
+	require.Empty(t, result.Errors)
+	assert.True(t, result.Complete)
+	assert.Equal(t, []models.Reference{
+		{Name: "qty", Occurrences: []models.Span{{Start: 4, End: 7}, {Start: 31, End: 34}}},
+		{Name: "price", Occurrences: []models.Span{{Start: 21, End: 26}, {Start: 48, End: 53}}},
+	}, result.Columns)
+	assert.Equal(t, []models.Reference{
+		{Name: "IF", Occurrences: []models.Span{{Start: 0, End: 2}}},
+		{Name: "ROUND", Occurrences: []models.Span{{Start: 14, End: 19}, {Start: 41, End: 46}}},
+	}, result.Functions)
+}
+
+func TestAnalyzer_References_Cases(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	testCases := []struct {
+		name       string
+		expression string
+		columns    []string
+		functions  []string
+		complete   bool
+	}{
+		{"literals only", "1 + 'x'", []string{}, []string{}, true},
+		{"string literals skipped", `CONCAT('[a]', "UPPER([b])", [c])`, []string{"c"}, []string{"CONCAT"}, true},
+		{"case-sensitive columns", "[a] + [A] + [a]", []string{"a", "A"}, []string{}, true},
+		{"boolean literals are not functions", "TRUE && [flag]", []string{"flag"}, []string{}, true},
+		{"custom functions", "FX_RATE([currency]) * [amount]", []string{"currency", "amount"}, []string{"FX_RATE"}, true},
+		{"incomplete expression", "UPPER([name]) + ", []string{"name"}, []string{"UPPER"}, false},
+		{"invalid characters", "[a] # LOWER([b])", []string{"a", "b"}, []string{"LOWER"}, false},
+		{"empty expression", "", []string{}, []string{}, true},
+	}
+
+	names := func(references []models.Reference) []string {
+		result := make([]string, len(references))
+		for i, reference := range references {
+			result[i] = reference.Name
+		}
+		return result
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.References(tc.expression)
+			assert.Equal(t, tc.columns, names(result.Columns))
+			assert.Equal(t, tc.functions, names(result.Functions))
+			assert.Equal(t, tc.complete, result.Complete)
+			assert.Equal(t, tc.complete, len(result.Errors) == 0)
+		})
+	}
+}
+
+func TestReferencesResult_AsMap(t *testing.T) {
+	result := NewApp().References("ABS([a])")
+	assert.Equal(t, map[string]any{
+		"columns": []any{
+			map[string]any{"name": "a", "occurrences": []any{map[string]any{"start": 5, "end": 6}}},
+		},
+		"functions": []any{
+			map[string]any{"name": "ABS", "occurrences": []any{map[string]any{"start": 0, "end": 3}}},
+		},
+		"complete": true,
+		"errors":   []any{},
+	}, result.AsMap())
+}
@@ -0,0 +1,322 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/core/infrastructure"
+	"antlr-editor/analyzer/core/models"
+)
+
+// relexResult holds the tokens of an edited expression and which of them were lexed again.
+// The tokens before first are the old tokens; from newEnd on they are the old tokens from oldEnd on, moved in place,
+// so the old tokens from oldEnd on are at their positions in the edited expression.
+type relexResult struct {
+	expression string        // Edited expression
+	tokens     []antlr.Token // Tokens of the edited expression from all channels, ending with EOF
+	first      int           // Index of the first token lexed again, the same in the old and new tokens
+	oldEnd     int           // Index in the old tokens of the first token after the tokens lexed again
+	newEnd     int           // Index in the new tokens of the first token after the tokens lexed again
+	editEnd    int           // Code point offset of the end of the inserted text in the edited expression
+	delta      int           // Length difference of the edit, by which the old tokens from oldEnd on moved
+}
+
+// relex returns the tokens of the expression edited by replacing the code points [start, end) of source with text.
+// Only the tokens around the edit are lexed again: lexing restarts at the token holding the line break before the
+// line of the edit, and stops at the first token past the edit that starts where a token of source starts, from
+// which on the tokens of source are kept, moved by the length difference. The lexer only reads a window of whole
+// lines from the restart, widened while the tokens lexed again reach its last line break.
+//
+// No token spans a line break except whitespace and strings whose line break is escaped, and the lexer does not
+// look past a line break otherwise, so the tokens before the restart are not affected by the edit, and the tokens
+// of the window ending before its last line break are those of the whole expression; tokens depend only on the
+// input from their start, so lexing from a token boundary of source past the edit yields its tokens.
+func relex(helper *infrastructure.ParserHelper, source []rune, tokens []antlr.Token, start, end int, text []rune) *relexResult {
+	// The line of the edit, extended to the previous line while its line break is escaped
+	lineStart := start
+	for {
+		for lineStart > 0 && source[lineStart-1] != '\n' {
+			lineStart--
+		}
+		if lineStart < 2 || source[lineStart-2] != '\\' {
+			break
+		}
+		lineStart--
+	}
+
+	first := 0
+	if lineStart > 0 {
+		first = sort.Search(len(tokens), func(i int) bool { return tokens[i].GetStop() >= lineStart-1 })
+	}
+	restart := tokens[first]
+	offset, line, column := restart.GetStart(), restart.GetLine(), restart.GetColumn()
+
+	edited := make([]rune, 0, len(source)-(end-start)+len(text))
+	edited = append(append(append(edited, source[:start]...), text...), source[end:]...)
+	delta := len(text) - (end - start)
+
+	result := &relexResult{expression: string(edited), tokens: make([]antlr.Token, first, len(tokens)+max(delta, 0)), first: first, editEnd: start + len(text), delta: delta}
+	copy(result.tokens, tokens[:first])
+
+	windowEnd := lineEnd(edited, result.editEnd)
+	lexer := helper.CreateLexer(string(edited[offset:windowEnd]))
+	for {
+		token := lexer.NextToken()
+		tokenLine, tokenColumn := token.GetLine()+line-1, token.GetColumn()
+		if token.GetLine() == 1 {
+			tokenColumn += column
+		}
+		tokenStart := token.GetStart() + offset
+
+		// Past the edit, a token starting where an old token starts is followed by the old tokens
+		if tokenStart >= result.editEnd {
+			i := sort.Search(len(tokens)-first, func(i int) bool { return tokens[first+i].GetStart() >= tokenStart-delta })
+			if j := first + i; j < len(tokens) && tokens[j].GetStart() == tokenStart-delta {
+				result.oldEnd, result.newEnd = j, len(result.tokens)
+				result.tokens = append(result.tokens, moveTokens(tokens[j:], delta, tokenLine, tokenColumn)...)
+				return result
+			}
+		}
+
+		// A token reaching the last line break of the window may continue past it: lex again in a window twice as large
+		if windowEnd < len(edited) && tokenStart+token.GetStop()-token.GetStart() >= windowEnd-1 {
+			windowEnd = lineEnd(edited, windowEnd+(windowEnd-offset))
+			lexer = helper.CreateLexer(string(edited[offset:windowEnd]))
+			result.tokens = result.tokens[:first]
+			continue
+		}
+		result.tokens = append(result.tokens, moveToken(token, tokenStart, tokenLine, tokenColumn))
+	}
+}
+
+// lineEnd returns the offset past the first line break of text at or after offset, or the length of text
+func lineEnd(text []rune, offset int) int {
+	for i := offset; i < len(text); i++ {
+		if text[i] == '\n' {
+			return i + 1
+		}
+	}
+	return len(text)
+}
+
+// moveTokens moves tokens by delta code points so that the first one starts at line and column.
+// The tokens are moved in place, without copying them, so edits cost little however many tokens follow them.
+func moveTokens(tokens []antlr.Token, delta, line, column int) []antlr.Token {
+	firstLine, firstColumn := tokens[0].GetLine(), tokens[0].GetColumn()
+	if delta == 0 && line == firstLine && column == firstColumn {
+		return tokens
+	}
+
+	for _, token := range tokens {
+		t := token.(*editToken)
+		if t.line == firstLine {
+			t.column += column - firstColumn
+		}
+		t.line += line - firstLine
+		t.start += delta
+		t.stop += delta
+	}
+	return tokens
+}
+
+// moveToken copies the token to another position
+func moveToken(token antlr.Token, start, line, column int) antlr.Token {
+	stop := start + token.GetStop() - token.GetStart()
+	common := antlr.CommonTokenFactoryDEFAULT.Create(token.GetSource(), token.GetTokenType(), token.GetText(), token.GetChannel(), start, stop, line, column)
+	return &editToken{CommonToken: common.(*antlr.CommonToken), start: start, stop: stop, line: line, column: column}
+}
+
+// editToken is a token of a Session. The parse tree holds the tokens it was parsed from, so edits that keep
+// the tree move its tokens in place, updating their positions and text, instead of parsing the new ones.
+type editToken struct {
+	*antlr.CommonToken
+	start, stop, line, column int
+}
+
+// editTokens copies lexed tokens to tokens that edits can move
+func editTokens(tokens []antlr.Token) []antlr.Token {
+	copied := make([]antlr.Token, len(tokens))
+	for i, token := range tokens {
+		copied[i] = moveToken(token, token.GetStart(), token.GetLine(), token.GetColumn())
+	}
+	return copied
+}
+
+func (t *editToken) GetStart() int  { return t.start }
+func (t *editToken) GetStop() int   { return t.stop }
+func (t *editToken) GetLine() int   { return t.line }
+func (t *editToken) GetColumn() int { return t.column }
+
+// moveTo gives the token the position and text of another token of the same type
+func (t *editToken) moveTo(token antlr.Token) {
+	t.start, t.stop, t.line, t.column = token.GetStart(), token.GetStop(), token.GetLine(), token.GetColumn()
+	t.SetText(token.GetText())
+}
+
+// sameToken reports whether two tokens have the same type, channel and text
+func sameToken(a, b antlr.Token) bool {
+	return a.GetTokenType() == b.GetTokenType() && a.GetChannel() == b.GetChannel() && a.GetText() == b.GetText()
+}
+
+// significantTokens returns the tokens that are not whitespace
+func significantTokens(tokens []antlr.Token) []antlr.Token {
+	significant := make([]antlr.Token, 0, len(tokens))
+	for _, token := range tokens {
+		if token.GetChannel() != antlr.LexerHidden {
+			significant = append(significant, token)
+		}
+	}
+	return significant
+}
+
+// keepsStructure reports whether the edit changed only whitespace: the tokens lexed again are the same
+// as the ones they replace apart from whitespace, so the parser sees the same tokens at other positions
+func (r *relexResult) keepsStructure(old []antlr.Token) bool {
+	before := significantTokens(old[r.first:r.oldEnd])
+	after := significantTokens(r.tokens[r.first:r.newEnd])
+	if len(before) != len(after) {
+		return false
+	}
+	for i := range before {
+		if !sameToken(before[i], after[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// keepsShape reports whether the parser sees tokens of the same types as before, so a parse without errors
+// gives the same tree: only whitespace changed, or the text of tokens such as literals, columns or functions
+func (r *relexResult) keepsShape(old []antlr.Token) bool {
+	before := significantTokens(old[r.first:r.oldEnd])
+	after := significantTokens(r.tokens[r.first:r.newEnd])
+	if len(before) != len(after) {
+		return false
+	}
+	for i := range before {
+		if before[i].GetTokenType() != after[i].GetTokenType() || before[i].GetChannel() != after[i].GetChannel() {
+			return false
+		}
+	}
+	return true
+}
+
+// keepTree moves the old tokens other than whitespace that were lexed again to the positions and text of the
+// edited ones and puts them in place of those; the tokens before and after them already are the old ones. The parse
+// tree of the old tokens thus becomes the parse tree of the edited expression. Only valid for edits that keep the
+// shape; the old tokens no longer describe the old expression afterwards.
+func (r *relexResult) keepTree(old []antlr.Token) []antlr.Token {
+	before := significantTokens(old[r.first:r.oldEnd])
+	k := 0
+	for i := r.first; i < r.newEnd; i++ {
+		if r.tokens[i].GetChannel() != antlr.LexerHidden {
+			before[k].(*editToken).moveTo(r.tokens[i])
+			r.tokens[i] = before[k]
+			k++
+		}
+	}
+	return r.tokens
+}
+
+// oldStart returns the start of the old token at index i in the old expression
+func (r *relexResult) oldStart(old []antlr.Token, i int) int {
+	if i >= r.oldEnd {
+		return old[i].GetStart() - r.delta
+	}
+	return old[i].GetStart()
+}
+
+// oldTokenEnd returns the end, past its last code point, of the old token at index i in the old expression;
+// the start for EOF
+func (r *relexResult) oldTokenEnd(old []antlr.Token, i int) int {
+	end := max(old[i].GetStop()+1, old[i].GetStart())
+	if i >= r.oldEnd {
+		return end - r.delta
+	}
+	return end
+}
+
+// changedSpan returns the code point span of the edited expression whose tokens differ from the old ones,
+// not counting tokens that only moved; false if the tokens are the same. Only tokens past the edit can have moved.
+func (r *relexResult) changedSpan(old []antlr.Token) (models.Span, bool) {
+	before, after := old[r.first:r.oldEnd], r.tokens[r.first:r.newEnd]
+
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) &&
+		sameToken(before[prefix], after[prefix]) && before[prefix].GetStart() == after[prefix].GetStart() {
+		prefix++
+	}
+	delta := r.delta
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		sameToken(before[len(before)-1-suffix], after[len(after)-1-suffix]) &&
+		before[len(before)-1-suffix].GetStart()+delta == after[len(after)-1-suffix].GetStart() &&
+		after[len(after)-1-suffix].GetStart() >= r.editEnd {
+		suffix++
+	}
+
+	if prefix+suffix == len(before) && prefix+suffix == len(after) {
+		return models.Span{}, false
+	}
+	span := models.Span{Start: r.tokens[r.first+prefix].GetStart()}
+	span.End = span.Start
+	if last := len(after) - 1 - suffix; last >= prefix {
+		span.End = after[last].GetStop() + 1
+	}
+	return span, true
+}
+
+// moveErrors moves errors reported at the old tokens to the same tokens among the edited ones, taking lines and
+// columns from the tokens. Only valid for edits that keep the structure. Returns false if an error does not start
+// and end at the boundaries of tokens other than whitespace.
+func (r *relexResult) moveErrors(old []antlr.Token, errors []models.ErrorInfo) ([]models.ErrorInfo, bool) {
+	before := significantTokens(old[r.first:r.oldEnd])
+	after := significantTokens(r.tokens[r.first:r.newEnd])
+
+	// newToken returns the edited token corresponding to the old token at index i
+	newToken := func(i int) (antlr.Token, bool) {
+		switch {
+		case i < r.first:
+			return r.tokens[i], true
+		case i >= r.oldEnd:
+			return r.tokens[i-r.oldEnd+r.newEnd], true
+		case old[i].GetChannel() == antlr.LexerHidden:
+			return nil, false
+		}
+		for j, token := range before {
+			if token == old[i] {
+				return after[j], true
+			}
+		}
+		return nil, false
+	}
+
+	moved := make([]models.ErrorInfo, len(errors))
+	for k, err := range errors {
+		i := sort.Search(len(old), func(i int) bool { return r.oldStart(old, i) >= err.Start })
+		j := i
+		for j < len(old) && r.oldTokenEnd(old, j) < err.End {
+			j++
+		}
+		if i == len(old) || j == len(old) || r.oldStart(old, i) != err.Start || r.oldTokenEnd(old, j) != err.End {
+			return nil, false
+		}
+		startToken, ok := newToken(i)
+		if !ok {
+			return nil, false
+		}
+		endToken, ok := newToken(j)
+		if !ok {
+			return nil, false
+		}
+		moved[k] = models.ErrorInfo{
+			Message: err.Message,
+			Line:    startToken.GetLine(),
+			Column:  startToken.GetColumn(),
+			Start:   startToken.GetStart(),
+			End:     max(endToken.GetStop()+1, endToken.GetStart()),
+		}
+	}
+	return moved, true
+}
@@ -0,0 +1,348 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/core/app/formatter"
+	"antlr-editor/analyzer/core/models"
+)
+
+// AnalyzeResult combines the results an editor needs after each change of the expression
+type AnalyzeResult struct {
+	Tokens    []models.TokenInfo    `json:"tokens"`    // Tokens as returned by Tokenize
+	Errors    []models.ErrorInfo    `json:"errors"`    // Diagnostics as returned by Lint
+	Tree      *models.ParseTreeNode `json:"tree"`      // Parse tree as returned by ParseTree, nil for an empty expression
+	Formatted string                `json:"formatted"` // Expression as returned by Format, unchanged if it has syntax errors
+}
+
+// AsMap converts AnalyzeResult to a map for JSON serialization
+func (r *AnalyzeResult) AsMap() map[string]any {
+	tokens := make([]any, len(r.Tokens))
+	for i, token := range r.Tokens {
+		tokens[i] = token.AsMap()
+	}
+
+	errors := make([]any, len(r.Errors))
+	for i, err := range r.Errors {
+		errors[i] = err.AsMap()
+	}
+
+	var tree map[string]any
+	if r.Tree != nil {
+		tree = r.Tree.AsMap()
+	}
+
+	return map[string]any{
+		"tokens":    tokens,
+		"errors":    errors,
+		"tree":      tree,
+		"formatted": r.Formatted,
+	}
+}
+
+// EditResult holds the tokens and diagnostics of an expression after an edit, and where they changed
+type EditResult struct {
+	Tokens  []models.TokenInfo `json:"tokens"`  // Tokens as returned by Tokenize
+	Errors  []models.ErrorInfo `json:"errors"`  // Diagnostics as returned by Lint
+	Changed []models.Span      `json:"changed"` // Sorted, disjoint ranges whose tokens or diagnostics changed, not counting moves
+}
+
+// AsMap converts EditResult to a map for JSON serialization
+func (r *EditResult) AsMap() map[string]any {
+	tokens := make([]any, len(r.Tokens))
+	for i, token := range r.Tokens {
+		tokens[i] = token.AsMap()
+	}
+
+	errors := make([]any, len(r.Errors))
+	for i, err := range r.Errors {
+		errors[i] = err.AsMap()
+	}
+
+	changed := make([]any, len(r.Changed))
+	for i, span := range r.Changed {
+		changed[i] = map[string]any{"start": span.Start, "end": span.End}
+	}
+
+	return map[string]any{
+		"tokens":  tokens,
+		"errors":  errors,
+		"changed": changed,
+	}
+}
+
+// Session analyzes an expression for an editor, such as the content of an editor after each change.
+// The expression is lexed and parsed once when the session is created; tokens, diagnostics, the parse tree and
+// the formatted expression are derived from that result when first requested and cached, so asking for all of
+// them costs about as much as a single call to Lint instead of one lex and parse per call.
+// ApplyEdit and Update analyze an edited expression incrementally, lexing again only around the edit.
+// A session uses the functions, schema and position encoding of the App that created it as they are when a
+// result is first requested; create a new session after changing them. Returned results are shared between
+// calls and must not be modified.
+type Session struct {
+	analyzer   *Analyzer
+	formatter  *Formatter
+	expression string
+	tokens     []antlr.Token     // Tokens from all channels in input order, ending with EOF
+	parsed     *parsedExpression // Parse of tokens, nil until needed after an edit that changes the parse tree
+	clean      bool              // Whether the expression is not empty and the parser reported no errors for tokens
+	positions  *models.PositionMap
+
+	// Code point results; edits that change only whitespace keep them
+	lintErrors []models.ErrorInfo
+	formatted  *string // Only cached for expressions without parse errors
+
+	// Results in the position encoding
+	tokenized *TokenizeResult
+	errors    []models.ErrorInfo
+	tree      *ParseTreeResult
+}
+
+// NewSession lexes and parses the expression once for the results of a Session
+func (app *App) NewSession(expression string) *Session {
+	// The lexer reports no errors, so lexing before parsing gives the same result as lexAndParse,
+	// with tokens that edits can move
+	tokens := editTokens(lexTokens(app.analyzer.helper, expression))
+	parsed := parseTokens(app.analyzer.helper, expression, tokens)
+	return &Session{
+		analyzer:   app.analyzer,
+		formatter:  app.formatter,
+		expression: expression,
+		tokens:     tokens,
+		parsed:     parsed,
+		clean:      expression != "" && len(parsed.errors) == 0,
+		positions:  app.analyzer.positions(expression),
+	}
+}
+
+// Analyze returns the tokens, diagnostics, parse tree and formatted expression, lexing and parsing it once
+func (app *App) Analyze(expression string) *AnalyzeResult {
+	return app.NewSession(expression).Analyze()
+}
+
+// Expression returns the expression the session analyzes
+func (s *Session) Expression() string {
+	return s.expression
+}
+
+// parse returns the parse of the tokens, parsing them without lexing after an edit
+func (s *Session) parse() *parsedExpression {
+	if s.parsed == nil {
+		s.parsed = parseTokens(s.analyzer.helper, s.expression, s.tokens)
+		s.clean = s.expression != "" && len(s.parsed.errors) == 0
+	}
+	return s.parsed
+}
+
+// lint returns the Lint errors in code points
+func (s *Session) lint() []models.ErrorInfo {
+	if s.lintErrors == nil {
+		s.lintErrors = s.analyzer.lint(s.parse())
+		if s.lintErrors == nil {
+			s.lintErrors = []models.ErrorInfo{}
+		}
+	}
+	return s.lintErrors
+}
+
+// Tokenize returns the same tokens as App.Tokenize
+func (s *Session) Tokenize() *TokenizeResult {
+	if s.tokenized == nil {
+		tokens := []models.TokenInfo{}
+		if s.expression != "" {
+			tokens = s.positions.Tokens(s.analyzer.collectTokens(s.tokens))
+		}
+		s.tokenized = &TokenizeResult{Tokens: tokens, Errors: []models.ErrorInfo{}}
+	}
+	return s.tokenized
+}
+
+// Lint returns the same errors as App.Lint
+func (s *Session) Lint() []models.ErrorInfo {
+	if s.errors == nil {
+		s.errors = s.positions.Errors(slices.Clone(s.lint()))
+	}
+	return s.errors
+}
+
+// Validate reports whether the expression is not empty and has no errors, as App.Validate
+func (s *Session) Validate() bool {
+	return s.expression != "" && len(s.lint()) == 0
+}
+
+// ParseTree returns the same parse tree and errors as App.ParseTree
+func (s *Session) ParseTree() *ParseTreeResult {
+	if s.tree == nil {
+		s.tree = s.analyzer.parseTree(s.expression, s.parse())
+		s.positions.ParseTree(s.tree.Tree)
+		s.positions.Errors(s.tree.Errors)
+	}
+	return s.tree
+}
+
+// Format returns the same formatted expression as App.Format
+func (s *Session) Format() string {
+	if s.formatted != nil {
+		return *s.formatted
+	}
+	formatted := s.formatter.format(s.expression, s.parse())
+	if s.clean {
+		s.formatted = &formatted
+	}
+	return formatted
+}
+
+// FormatWithOptions returns the same formatted expression as App.FormatWithOptions; the result is not cached
+func (s *Session) FormatWithOptions(options *formatter.FormatOptions) string {
+	return NewFormatterWithOptions(options).format(s.expression, s.parse())
+}
+
+// Analyze returns the tokens, diagnostics, parse tree and formatted expression together
+func (s *Session) Analyze() *AnalyzeResult {
+	return &AnalyzeResult{
+		Tokens:    s.Tokenize().Tokens,
+		Errors:    s.Lint(),
+		Tree:      s.ParseTree().Tree,
+		Formatted: s.Format(),
+	}
+}
+
+// ApplyEdit replaces the text between the offsets start and end of the expression, in the position encoding,
+// with text and analyzes the edited expression incrementally. Only the tokens from the start of the line of the
+// edit up to the first token boundary past it that was a boundary before are lexed again; the other tokens are
+// reused, moved. If the expression had no syntax errors and the edit keeps the types of the tokens other than
+// whitespace, changing only whitespace or the text of tokens such as literals, column references or function names,
+// the parse tree is kept: its tokens are moved to the edited expression and only the type and lint rules are
+// checked again over the tree, since a changed operand can change the types of the expressions around it.
+// Edits that only change whitespace move the diagnostics instead and keep the formatting. The tokens of any
+// other edit are parsed again without lexing once the diagnostics or parse tree are requested.
+// The result holds the tokens and diagnostics of the edited expression, the same as from a new session,
+// and the ranges of the edited expression where they changed.
+func (s *Session) ApplyEdit(start, end int, text string) (*EditResult, error) {
+	source := []rune(s.expression)
+	codePointStart, codePointEnd := s.positions.CodePoint(start), s.positions.CodePoint(end)
+	if start < 0 || start > end || codePointEnd > len(source) {
+		return nil, fmt.Errorf("invalid edit range %d to %d of an expression of length %d", start, end, s.positions.Offset(len(source)))
+	}
+	return s.applyEdit(source, codePointStart, codePointEnd, []rune(text)), nil
+}
+
+// Update analyzes a new version of the expression incrementally, as ApplyEdit does for
+// the edit replacing the part between the common prefix and suffix of both versions
+func (s *Session) Update(expression string) *EditResult {
+	source, target := []rune(s.expression), []rune(expression)
+	prefix := 0
+	for prefix < len(source) && prefix < len(target) && source[prefix] == target[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(source)-prefix && suffix < len(target)-prefix && source[len(source)-1-suffix] == target[len(target)-1-suffix] {
+		suffix++
+	}
+	return s.applyEdit(source, prefix, len(source)-suffix, target[prefix:len(target)-suffix])
+}
+
+// applyEdit replaces the code points [start, end) of source, the expression, with text
+func (s *Session) applyEdit(source []rune, start, end int, text []rune) *EditResult {
+	oldTokens, oldErrors := s.tokens, s.lint()
+	relexed := relex(s.analyzer.helper, source, oldTokens, start, end, text)
+
+	// Compared with the old tokens before keepTree moves them
+	var changed []models.Span
+	if span, ok := relexed.changedSpan(oldTokens); ok {
+		changed = append(changed, span)
+	}
+
+	keepsStructure := relexed.keepsStructure(oldTokens)
+	var lintErrors []models.ErrorInfo
+	var parsed *parsedExpression
+	if s.clean && relexed.keepsShape(oldTokens) {
+		if keepsStructure {
+			lintErrors, _ = relexed.moveErrors(oldTokens, oldErrors)
+		}
+		parsed = &parsedExpression{tree: s.parsed.tree, errors: s.parsed.errors, tokens: relexed.keepTree(oldTokens)}
+	}
+	if !keepsStructure {
+		s.formatted = nil
+	}
+
+	s.expression = relexed.expression
+	s.tokens = relexed.tokens
+	s.parsed = parsed
+	s.positions = s.analyzer.positions(s.expression)
+	s.lintErrors = lintErrors
+	s.tokenized, s.errors, s.tree = nil, nil, nil
+
+	changed = append(changed, changedErrors(oldErrors, s.lint(), start, end, len(text))...)
+	changed = mergeSpans(changed)
+	for i, span := range changed {
+		changed[i] = s.positions.Span(span)
+	}
+
+	return &EditResult{Tokens: s.Tokenize().Tokens, Errors: s.Lint(), Changed: changed}
+}
+
+// changedErrors returns the spans of errors added by the edit replacing the code points [start, end) with
+// length code points, and the spans of removed errors moved to the edited expression
+func changedErrors(before, after []models.ErrorInfo, start, end, length int) []models.Span {
+	type key struct {
+		message    string
+		start, end int
+	}
+	// moveStart and moveEnd move the start and end of a span to the edited expression
+	moveStart := func(p int) int {
+		switch {
+		case p < start:
+			return p
+		case p >= end:
+			return p + length - (end - start)
+		}
+		return start
+	}
+	moveEnd := func(p int) int {
+		switch {
+		case p <= start:
+			return p
+		case p >= end:
+			return p + length - (end - start)
+		}
+		return start + length
+	}
+
+	remaining := make(map[key]int)
+	for _, err := range before {
+		remaining[key{err.Message, moveStart(err.Start), moveEnd(err.End)}]++
+	}
+	spans := []models.Span{}
+	for _, err := range after {
+		k := key{err.Message, err.Start, err.End}
+		if remaining[k] > 0 {
+			remaining[k]--
+			continue
+		}
+		spans = append(spans, models.Span{Start: err.Start, End: err.End})
+	}
+	for k, count := range remaining {
+		if count > 0 {
+			spans = append(spans, models.Span{Start: k.start, End: k.end})
+		}
+	}
+	return spans
+}
+
+// mergeSpans sorts spans and merges the overlapping and adjacent ones
+func mergeSpans(spans []models.Span) []models.Span {
+	slices.SortFunc(spans, func(a, b models.Span) int { return a.Start - b.Start })
+	merged := []models.Span{}
+	for _, span := range spans {
+		if last := len(merged) - 1; last >= 0 && span.Start <= merged[last].End {
+			merged[last].End = max(merged[last].End, span.End)
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
@@ -0,0 +1,505 @@
+package app
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/app/formatter"
+	"antlr-editor/analyzer/core/models"
+)
+
+var sessionExpressions = []string{
+	"",
+	"   ",
+	"[price] * 2",
+	"ROUND([price] * (1 - [discount]), 2) > 100 && [status] == 'active'",
+	"IF([qty] > 0,\n  [price] / [qty],\n  0)",
+	"[price] + ",
+	"1 2 3",
+	"[price] @ 2 # [qty]",
+	"UPPER([name]) == 'A' && [unknown] > 1",
+	"ROUND('a')",
+	"CONCAT('😀', [顧客名], [x])",
+	"'a\\\nb' +\n  [price] *\n\n  2 +\n  'c\\\n\\\nd'\n",
+	"1 +\n2 +\n3 +\n4 +\n5 +\n6 +\n7 +\n8",
+}
+
+func newSessionApp(t *testing.T, encoding models.PositionEncoding) *App {
+	app := NewApp()
+	app.SetSchema(models.NewSchema([]models.Column{
+		{Name: "price", Type: models.DataTypeNumber},
+		{Name: "discount", Type: models.DataTypeNumber},
+		{Name: "qty", Type: models.DataTypeNumber},
+		{Name: "status", Type: models.DataTypeString},
+		{Name: "name", Type: models.DataTypeString},
+		{Name: "顧客名", Type: models.DataTypeString},
+	}))
+	require.NoError(t, app.SetPositionEncoding(encoding))
+	return app
+}
+
+func TestSession_MatchesApp(t *testing.T) {
+	for _, encoding := range []models.PositionEncoding{models.PositionEncodingRune, models.PositionEncodingUTF16, models.PositionEncodingByte} {
+		app := newSessionApp(t, encoding)
+		for _, expression := range sessionExpressions {
+			t.Run(string(encoding)+"/"+expression, func(t *testing.T) {
+				session := app.NewSession(expression)
+				assert.Equal(t, expression, session.Expression())
+				assert.Equal(t, app.Tokenize(expression), session.Tokenize())
+				assert.Equal(t, app.ParseTree(expression), session.ParseTree())
+				assert.Equal(t, app.Format(expression), session.Format())
+				assert.Equal(t, app.Validate(expression), session.Validate())
+
+				options := &formatter.FormatOptions{IndentSize: 4, MaxLineLength: 20, SpaceAroundOps: false, BreakLongExpressions: true}
+				assert.Equal(t, app.FormatWithOptions(expression, options), session.FormatWithOptions(options))
+
+				errors := app.Lint(expression)
+				if errors == nil {
+					errors = []models.ErrorInfo{}
+				}
+				assert.Equal(t, errors, session.Lint())
+			})
+		}
+	}
+}
+
+func TestSession_Analyze(t *testing.T) {
+	app := newSessionApp(t, models.PositionEncodingRune)
+	expression := "[price]*2+[unknown]"
+
+	result := app.Analyze(expression)
+	assert.Equal(t, app.Tokenize(expression).Tokens, result.Tokens)
+	assert.Equal(t, app.Lint(expression), result.Errors)
+	assert.Equal(t, app.ParseTree(expression).Tree, result.Tree)
+	assert.Equal(t, "[price] * 2 + [unknown]", result.Formatted)
+
+	empty := app.Analyze("")
+	assert.Empty(t, empty.Tokens)
+	assert.Empty(t, empty.Errors)
+	assert.Nil(t, empty.Tree)
+	assert.Equal(t, "", empty.Formatted)
+}
+
+func TestSession_CachesResults(t *testing.T) {
+	session := NewApp().NewSession("[a] + 1")
+	assert.Same(t, session.Tokenize(), session.Tokenize())
+	assert.Same(t, session.ParseTree(), session.ParseTree())
+
+	errors := session.Lint()
+	assert.NotNil(t, errors)
+	assert.Empty(t, session.Lint())
+
+	result := session.Analyze()
+	assert.Same(t, session.ParseTree().Tree, result.Tree)
+}
+
+func TestAnalyzeResult_AsMap(t *testing.T) {
+	result := NewApp().Analyze("[a]")
+	m := result.AsMap()
+	assert.Len(t, m["tokens"], 4)
+	assert.Equal(t, []any{}, m["errors"])
+	assert.Equal(t, result.Tree.AsMap(), m["tree"])
+	assert.Equal(t, "[a]", m["formatted"])
+
+	assert.Nil(t, NewApp().Analyze("").AsMap()["tree"])
+}
+
+// checkEdit verifies that editing the session gives the same results as analyzing the edited expression anew,
+// and that the tokens and errors outside the changed ranges are the old ones moved by the edit
+func checkEdit(t *testing.T, app *App, session *Session, start, end int, text string) *EditResult {
+	t.Helper()
+	before := app.NewSession(session.Expression())
+	runes := []rune(session.Expression())
+	expression := string(runes[:start]) + text + string(runes[end:])
+
+	result, err := session.ApplyEdit(start, end, text)
+	require.NoError(t, err)
+	require.Equal(t, expression, session.Expression())
+
+	fresh := app.NewSession(expression)
+	require.Equal(t, fresh.Tokenize().Tokens, result.Tokens, "tokens of %q", expression)
+	require.Equal(t, fresh.Lint(), result.Errors, "errors of %q", expression)
+	require.Equal(t, fresh.ParseTree(), session.ParseTree(), "parse tree of %q", expression)
+	require.Equal(t, fresh.Format(), session.Format(), "formatting of %q", expression)
+
+	// Old positions moved to the edited expression; positions inside the replaced text move to its bounds
+	delta := utf8.RuneCountInString(text) - (end - start)
+	moveStart := func(p int) int {
+		switch {
+		case p < start:
+			return p
+		case p >= end:
+			return p + delta
+		}
+		return start
+	}
+	moveEnd := func(p int) int {
+		switch {
+		case p <= start:
+			return p
+		case p >= end:
+			return p + delta
+		}
+		return end + delta
+	}
+	covered := func(startPosition, endPosition int) bool {
+		for _, span := range result.Changed {
+			if span.Start <= startPosition && endPosition <= span.End {
+				return true
+			}
+		}
+		return false
+	}
+	for i, span := range result.Changed {
+		require.LessOrEqual(t, span.Start, span.End)
+		if i > 0 {
+			require.Less(t, result.Changed[i-1].End, span.Start, "changed ranges are sorted and disjoint")
+		}
+	}
+
+	for _, token := range result.Tokens {
+		if covered(token.Start, token.End) {
+			continue
+		}
+		// Tokens lexed again are unchanged if they equal an old token at the same position or moved by the edit,
+		// as when replacing text by itself
+		found := false
+		for _, old := range before.Tokenize().Tokens {
+			if old.Type == token.Type && old.Text == token.Text {
+				found = found || old.Start == token.Start || (token.Start >= start && old.Start == token.Start-delta)
+			}
+		}
+		require.True(t, found, "token %q at %d of %q outside the changed ranges is unchanged", token.Text, token.Start, expression)
+	}
+	for _, e := range result.Errors {
+		if covered(e.Start, e.End) {
+			continue
+		}
+		found := false
+		for _, old := range before.Lint() {
+			found = found || (old.Message == e.Message && moveStart(old.Start) == e.Start && moveEnd(old.End) == e.End)
+		}
+		require.True(t, found, "error %q of %q outside the changed ranges is unchanged", e.Message, expression)
+	}
+	return result
+}
+
+func TestSession_ApplyEdit(t *testing.T) {
+	testCases := []struct {
+		name       string
+		expression string
+		start, end int
+		text       string
+		changed    []models.Span
+	}{
+		{"insert into a column name", "[price] * 2", 6, 6, "s", []models.Span{{Start: 0, End: 8}}},
+		{"replace an operator", "[price] * 2", 8, 9, "+", []models.Span{{Start: 8, End: 9}}},
+		{"delete a token", "[price] * 2 + 1", 11, 15, "", []models.Span{{Start: 11, End: 11}}},
+		{"merge tokens", "1 2", 1, 2, "", []models.Span{{Start: 0, End: 2}}},
+		{"operator becomes longer", "[a] < 1", 5, 5, "=", []models.Span{{Start: 4, End: 6}}},
+		{"whitespace only", "[a] + 1", 3, 4, "   ", []models.Span{{Start: 3, End: 6}}},
+		{"unchanged text", "[a] + 1", 4, 5, "+", nil},
+		{"break a line", "ROUND([a], 2)", 10, 11, "\n  ", []models.Span{{Start: 10, End: 13}}},
+		{"open a string", "'a' + [b]", 6, 6, "'", nil},
+		{"close an unknown column", "[price] + [qty", 14, 14, "]", nil},
+		{"append to empty", "", 0, 0, "[a] +", nil},
+		{"clear", "[a] + 1", 0, 7, "", nil},
+		{"second line", "IF([a] > 0,\n  [a],\n  0)", 17, 17, "+ 1", nil},
+		{"escaped line break in a string", "'a\\\n' + 1", 7, 7, "b", nil},
+		{"close a string across an escaped line break", "'a\\\nb + 1", 5, 5, "'", nil},
+		{"number becomes a float", "12 .5", 2, 3, "", []models.Span{{Start: 0, End: 4}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newSessionApp(t, models.PositionEncodingRune)
+			result := checkEdit(t, app, app.NewSession(tc.expression), tc.start, tc.end, tc.text)
+			if tc.changed != nil {
+				assert.Equal(t, tc.changed, result.Changed)
+			}
+			if tc.name == "unchanged text" {
+				assert.Empty(t, result.Changed)
+			}
+		})
+	}
+}
+
+func TestSession_ApplyEdit_Diagnostics(t *testing.T) {
+	app := newSessionApp(t, models.PositionEncodingRune)
+	session := app.NewSession("[price] + [qty] + [unknown]")
+	require.Len(t, session.Lint(), 1)
+
+	// Fixing a typo far from the remaining error reports the range of the removed error
+	result := checkEdit(t, app, session, 19, 26, "price")
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, []models.Span{{Start: 18, End: 25}}, result.Changed)
+
+	// Adding a syntax error elsewhere
+	result = checkEdit(t, app, session, 0, 0, "(")
+	require.NotEmpty(t, result.Errors)
+	assert.Equal(t, models.Span{Start: 0, End: 1}, result.Changed[0])
+}
+
+func TestSession_ApplyEdit_Whitespace(t *testing.T) {
+	app := newSessionApp(t, models.PositionEncodingRune)
+	session := app.NewSession("ROUND([price], 2) + [unknown]")
+	assert.Equal(t, "ROUND([price], 2) + [unknown]", session.Format())
+	require.Len(t, session.Lint(), 1)
+
+	tree := session.parsed.tree
+	result, err := session.ApplyEdit(17, 18, "\n    ")
+	require.NoError(t, err)
+	assert.Same(t, tree, session.parsed.tree, "an edit of whitespace keeps the parse tree")
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, models.ErrorInfo{Message: result.Errors[0].Message, Line: 2, Column: 6, Start: 24, End: 33}, result.Errors[0])
+	assert.Equal(t, app.Lint(session.Expression()), result.Errors)
+	assert.NotNil(t, session.formatted, "the formatting is kept")
+	assert.Equal(t, app.Format(session.Expression()), session.Format())
+
+	// Whitespace edits of expressions with syntax errors parse again, as the messages may quote whitespace
+	session = app.NewSession("ROUND([price],, 2)")
+	checkEdit(t, app, session, 13, 13, " ")
+}
+
+func TestSession_ApplyEdit_KeepsTree(t *testing.T) {
+	testCases := []struct {
+		name       string
+		expression string
+		start, end int
+		text       string
+		keepsTree  bool
+	}{
+		{"change a number", "ROUND([price] * 2, 1) + [qty]", 16, 17, "25", true},
+		{"rename a column", "ROUND([price] * 2, 1) + [qty]", 7, 12, "status", true},
+		{"rename a function", "ROUND([price] * 2, 1) + [qty]", 0, 5, "UPPER", true},
+		{"change a string on another line", "CONCAT([name],\n  'a')", 18, 19, "bc", true},
+		{"change an operator", "[price] * 2", 8, 9, "/", false},
+		{"add an operand", "[price] * 2", 11, 11, " + 1", false},
+		{"number becomes a float", "[price] * 2", 11, 11, ".5", false},
+		{"syntax error", "[price] * * 2", 8, 9, "-", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := newSessionApp(t, models.PositionEncodingRune)
+			session := app.NewSession(tc.expression)
+			session.Lint()
+			tree := session.parsed.tree
+			checkEdit(t, app, session, tc.start, tc.end, tc.text)
+			if tc.keepsTree {
+				assert.Same(t, tree, session.parsed.tree)
+			} else {
+				assert.NotSame(t, tree, session.parsed.tree)
+			}
+		})
+	}
+}
+
+func TestSession_ApplyEdit_PositionEncoding(t *testing.T) {
+	app := newSessionApp(t, models.PositionEncodingUTF16)
+	session := app.NewSession("CONCAT('😀', [顧客名], [x])")
+
+	// [x] starts at code point 19, UTF-16 offset 20
+	result, err := session.ApplyEdit(22, 22, "顧客名")
+	require.NoError(t, err)
+	assert.Equal(t, "CONCAT('😀', [顧客名], [x顧客名])", session.Expression())
+	assert.Equal(t, app.Tokenize(session.Expression()).Tokens, result.Tokens)
+	assert.Equal(t, app.Lint(session.Expression()), result.Errors)
+	assert.Equal(t, []models.Span{{Start: 20, End: 26}}, result.Changed)
+
+	_, err = session.ApplyEdit(5, 4, "")
+	assert.Error(t, err)
+	_, err = session.ApplyEdit(0, 31, "")
+	assert.Error(t, err, "past the end")
+	_, err = session.ApplyEdit(-1, 0, "")
+	assert.Error(t, err)
+	assert.Equal(t, "CONCAT('😀', [顧客名], [x顧客名])", session.Expression(), "invalid edits keep the expression")
+}
+
+func TestSession_Update(t *testing.T) {
+	app := newSessionApp(t, models.PositionEncodingRune)
+	session := app.NewSession("[price] * 2")
+
+	result := session.Update("[price] * 20 + [qty]")
+	assert.Equal(t, "[price] * 20 + [qty]", session.Expression())
+	assert.Equal(t, app.Tokenize("[price] * 20 + [qty]").Tokens, result.Tokens)
+	assert.Equal(t, []models.Span{{Start: 10, End: 20}}, result.Changed)
+
+	result = session.Update("[price] * 20 + [qty]")
+	assert.Empty(t, result.Changed)
+
+	result = session.Update("")
+	assert.Empty(t, result.Tokens)
+	assert.Empty(t, result.Errors)
+}
+
+func TestEditResult_AsMap(t *testing.T) {
+	result := NewApp().NewSession("1").Update("1 + 2")
+	m := result.AsMap()
+	assert.Len(t, m["tokens"], 6)
+	assert.Equal(t, []any{}, m["errors"])
+	assert.Equal(t, []any{map[string]any{"start": 1, "end": 5}}, m["changed"])
+}
+
+// editAlphabet holds fragments that change tokens, whitespace, strings and lines when inserted
+var editAlphabet = []string{"[", "]", "'", "\"", "\\", " ", "\n", "(", ")", ",", "+", "*", "=", "<", ".", "e", "1", "9", "a", "ROUND", "IF", "[price]", "[x", "TRUE", "😀", "顧"}
+
+func TestSession_ApplyEdit_Random(t *testing.T) {
+	app := newSessionApp(t, models.PositionEncodingRune)
+	random := rand.New(rand.NewSource(1))
+
+	for round := 0; round < 20; round++ {
+		session := app.NewSession(sessionExpressions[round%len(sessionExpressions)])
+		for i := 0; i < 50; i++ {
+			length := utf8.RuneCountInString(session.Expression())
+			start := random.Intn(length + 1)
+			end := start + random.Intn(min(length-start, 3)+1)
+			var text strings.Builder
+			for n := random.Intn(3); n > 0; n-- {
+				text.WriteString(editAlphabet[random.Intn(len(editAlphabet))])
+			}
+			checkEdit(t, app, session, start, end, text.String())
+		}
+	}
+}
+
+func FuzzSession_ApplyEdit(f *testing.F) {
+	for _, expression := range sessionExpressions {
+		f.Add(expression, 0, 0, "(")
+		f.Add(expression, 3, 5, " ")
+		f.Add(expression, 7, 7, "\n'")
+	}
+	f.Add("'a\\\nb' + 1", 2, 3, "")
+	f.Add("1.5e+3 * [a]", 3, 4, "")
+	f.Add("a0a00", 2, 4, "")
+
+	app := NewApp()
+	app.SetSchema(models.NewSchema([]models.Column{{Name: "price", Type: models.DataTypeNumber}}))
+	f.Fuzz(func(t *testing.T, expression string, start, end int, text string) {
+		if !utf8.ValidString(expression) || !utf8.ValidString(text) {
+			return
+		}
+		length := utf8.RuneCountInString(expression)
+		start, end = abs(start)%(length+1), abs(end)%(length+1)
+		if start > end {
+			start, end = end, start
+		}
+		checkEdit(t, app, app.NewSession(expression), start, end, text)
+	})
+}
+
+// Baseline for an edit: analyze the whole edited expression anew
+func BenchmarkSession_EditNewSession(b *testing.B) {
+	app := NewApp()
+	expressions := [2]string{benchmarkSessionExpression, strings.Replace(benchmarkSessionExpression, "2), 0)", "3), 0)", 1)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		session := app.NewSession(expressions[i%2])
+		session.Tokenize()
+		session.Lint()
+	}
+}
+
+func BenchmarkSession_ApplyEdit(b *testing.B) {
+	app := NewApp()
+	session := app.NewSession(benchmarkSessionExpression)
+	offset := strings.Index(benchmarkSessionExpression, "2), 0)")
+	digits := [2]string{"3", "2"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = session.ApplyEdit(offset, offset+1, digits[i%2])
+	}
+}
+
+func BenchmarkSession_ApplyEditWhitespace(b *testing.B) {
+	app := NewApp()
+	session := app.NewSession(benchmarkSessionExpression)
+	offset := strings.Index(benchmarkSessionExpression, " 0)")
+	spaces := [2]string{"  ", " "}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = session.ApplyEdit(offset, offset+len(spaces[(i+1)%2]), spaces[i%2])
+	}
+}
+
+// editFirstLine returns a session of a formula of the given number of lines and an edit typing on its first line,
+// alternately inserting and deleting a space
+func editFirstLine(lines int) (*Session, func(i int) (*EditResult, error)) {
+	expression := strings.Repeat("IF([status] == 'active', ROUND([price] * (1 - [discount]), 2), 0) +\n", lines) + "0"
+	session := NewApp().NewSession(expression)
+	offset := strings.Index(expression, " ==")
+	return session, func(i int) (*EditResult, error) {
+		if i%2 == 0 {
+			return session.ApplyEdit(offset, offset, " ")
+		}
+		return session.ApplyEdit(offset, offset+1, "")
+	}
+}
+
+// Typing on the first line moves all the tokens that follow; the cost per token should stay small
+func BenchmarkSession_ApplyEditFirstLine(b *testing.B) {
+	for _, lines := range []int{20, 200, 2000} {
+		b.Run(fmt.Sprintf("lines=%d", lines), func(b *testing.B) {
+			_, edit := editFirstLine(lines)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = edit(i)
+			}
+		})
+	}
+}
+
+func TestSession_ApplyEdit_FirstLineAllocations(t *testing.T) {
+	allocations := func(lines int) float64 {
+		session, edit := editFirstLine(lines)
+		i := 0
+		allocs := testing.AllocsPerRun(20, func() {
+			_, _ = edit(i)
+			i++
+		})
+		require.Equal(t, 0, len(session.Lint()))
+		return allocs
+	}
+	assert.Equal(t, allocations(20), allocations(2000), "allocations of an edit do not grow with the tokens after it")
+}
+
+// benchmarkSessionExpression is a multi-line formula of the size edited in the editor
+var benchmarkSessionExpression = strings.Repeat("IF([status] == 'active', ROUND([price] * (1 - [discount]), 2), 0) +\n", 20) + "0"
+
+// Baseline: the editor calls tokenize, lint, parseTree and format separately after each change
+func BenchmarkSession_SeparateCalls(b *testing.B) {
+	app := NewApp()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		app.Tokenize(benchmarkSessionExpression)
+		app.Lint(benchmarkSessionExpression)
+		app.ParseTree(benchmarkSessionExpression)
+		app.Format(benchmarkSessionExpression)
+	}
+}
+
+func BenchmarkSession_Analyze(b *testing.B) {
+	app := NewApp()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		app.Analyze(benchmarkSessionExpression)
+	}
+}
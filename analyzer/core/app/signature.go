@@ -0,0 +1,146 @@
+package app
+
+import (
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/core/models"
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// SignatureHelpResult describes the function call enclosing a cursor position
+type SignatureHelpResult struct {
+	Name            string                    `json:"name"`            // Function name as written, empty outside calls
+	Signature       *models.FunctionSignature `json:"signature"`       // Signature and parameter docs, nil outside calls or for unknown functions
+	ActiveParameter int                       `json:"activeParameter"` // Index of the argument at the cursor, -1 outside calls
+	Parameter       *models.Parameter         `json:"parameter"`       // Parameter receiving that argument, nil if the function takes no more arguments
+}
+
+// AsMap converts SignatureHelpResult to a map for JSON serialization
+func (r *SignatureHelpResult) AsMap() map[string]any {
+	var signature, parameter any
+	if r.Signature != nil {
+		signature = r.Signature.AsMap()
+	}
+	if r.Parameter != nil {
+		parameter = r.Parameter.AsMap()
+	}
+	return map[string]any{
+		"name":            r.Name,
+		"signature":       signature,
+		"activeParameter": r.ActiveParameter,
+		"parameter":       parameter,
+	}
+}
+
+// SignatureHelp finds the innermost function call whose parentheses enclose the cursor offset, a code point
+// position in the expression, and the argument the cursor is in: the number of commas between the opening
+// parenthesis and the cursor at the nesting level of the call. The call is looked up in the parse tree; when
+// the parser could not build it, as in "1 2 ROUND(", the open parentheses before the cursor are matched instead.
+func (a *Analyzer) SignatureHelp(expression string, offset int) *SignatureHelpResult {
+	return a.signatureHelp(lexAndParse(a.helper, expression), offset)
+}
+
+// signatureHelp finds the function call enclosing offset in an expression parsed earlier
+func (a *Analyzer) signatureHelp(parsed *parsedExpression, offset int) *SignatureHelpResult {
+	result := &SignatureHelpResult{ActiveParameter: -1}
+
+	var tokens []antlr.Token
+	for _, token := range parsed.tokens {
+		if token.GetChannel() == antlr.TokenDefaultChannel && token.GetTokenType() != antlr.TokenEOF {
+			tokens = append(tokens, token)
+		}
+	}
+
+	name, lparen := enclosingCallInTree(parsed.tree, offset)
+	if lparen == nil {
+		name, lparen = enclosingCallInTokens(tokens, offset)
+	}
+	if lparen == nil {
+		return result
+	}
+
+	// Count the commas of the call before the cursor, skipping those of nested calls
+	result.Name, result.ActiveParameter = name, 0
+	depth := 0
+	for _, token := range tokens {
+		if token.GetStart() <= lparen.GetStart() || token.GetStart() >= offset {
+			continue
+		}
+		switch token.GetTokenType() {
+		case parser.ExpressionLexerLPAREN:
+			depth++
+		case parser.ExpressionLexerRPAREN:
+			depth--
+		case parser.ExpressionLexerCOMMA:
+			if depth == 0 {
+				result.ActiveParameter++
+			}
+		}
+	}
+
+	if fn, ok := a.registry.Lookup(name); ok {
+		signature := fn.FunctionSignature
+		result.Signature = &signature
+		result.Parameter = signature.ParameterAt(result.ActiveParameter)
+	}
+	return result
+}
+
+// enclosingCallInTree returns the name and opening parenthesis of the innermost function call in the parse tree
+// whose parentheses enclose offset. A call without closing parenthesis extends to the end of the expression.
+func enclosingCallInTree(tree antlr.Tree, offset int) (string, antlr.Token) {
+	if tree == nil {
+		return "", nil
+	}
+
+	var name string
+	var lparen antlr.Token
+	if call, ok := tree.(*parser.FunctionCallContext); ok && call.LPAREN() != nil {
+		open := call.LPAREN().GetSymbol()
+		closed := call.RPAREN() != nil && call.RPAREN().GetSymbol().GetStart() >= 0
+		if open.GetStart() < offset && (!closed || offset <= call.RPAREN().GetSymbol().GetStart()) {
+			name, lparen = call.FUNCTION_NAME().GetText(), open
+		}
+	}
+	for _, child := range tree.GetChildren() {
+		if childName, childParen := enclosingCallInTree(child, offset); childParen != nil {
+			name, lparen = childName, childParen
+		}
+	}
+	return name, lparen
+}
+
+// enclosingCallInTokens returns the name and opening parenthesis of the innermost function call
+// left open before offset, matching the parentheses of the tokens
+func enclosingCallInTokens(tokens []antlr.Token, offset int) (string, antlr.Token) {
+	type frame struct {
+		name   string // Function name, empty for grouping parentheses
+		lparen antlr.Token
+	}
+	var stack []frame
+
+	for i, token := range tokens {
+		if token.GetStart() >= offset {
+			break
+		}
+		switch token.GetTokenType() {
+		case parser.ExpressionLexerLPAREN:
+			f := frame{lparen: token}
+			if i > 0 && tokens[i-1].GetTokenType() == parser.ExpressionLexerFUNCTION_NAME {
+				f.name = tokens[i-1].GetText()
+			}
+			stack = append(stack, f)
+		case parser.ExpressionLexerRPAREN:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].name != "" {
+			return stack[i].name, stack[i].lparen
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzer_SignatureHelp(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	testCases := []struct {
+		name       string
+		expression string
+		offset     int
+		function   string
+		active     int
+	}{
+		{"first argument", "SUBSTRING([name], 1, 3)", 10, "SUBSTRING", 0},
+		{"after first comma", "SUBSTRING([name], 1, 3)", 17, "SUBSTRING", 1},
+		{"last argument", "SUBSTRING([name], 1, 3)", 22, "SUBSTRING", 2},
+		{"right after opening parenthesis", "ROUND([a])", 6, "ROUND", 0},
+		{"before closing parenthesis", "ROUND([a], 2)", 12, "ROUND", 1},
+		{"incomplete after comma", "SUBSTRING([name], ", 18, "SUBSTRING", 1},
+		{"incomplete without arguments", "UPPER(", 6, "UPPER", 0},
+		{"nested call is innermost", "ROUND(ABS([a], ", 15, "ABS", 1},
+		{"commas of nested calls are skipped", "CONCAT(ROUND([a], 2), ", 22, "CONCAT", 1},
+		{"grouping parentheses inside a call", "ROUND(([a] + 1) * 2, ", 21, "ROUND", 1},
+		{"unparsable prefix", "1 2 ROUND([a], ", 15, "ROUND", 1},
+		{"invalid characters", "ROUND([a] # , ", 14, "ROUND", 1},
+		{"unknown function", "FOO(1, 2", 8, "FOO", 1},
+		{"multi-line", "IF(\n  [a] > 0,\n  'yes',\n  ", 26, "IF", 2},
+		{"outside any call", "[a] + 1", 3, "", -1},
+		{"before opening parenthesis", "ROUND([a])", 5, "", -1},
+		{"after closing parenthesis", "ROUND([a])", 10, "", -1},
+		{"after closed nested call", "ABS(1) + ROUND(2) ", 18, "", -1},
+		{"empty expression", "", 0, "", -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.SignatureHelp(tc.expression, tc.offset)
+			assert.Equal(t, tc.function, result.Name)
+			assert.Equal(t, tc.active, result.ActiveParameter)
+		})
+	}
+}
+
+func TestAnalyzer_SignatureHelp_Parameters(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	result := analyzer.SignatureHelp("SUBSTRING([name], ", 18)
+	require.NotNil(t, result.Signature)
+	assert.Equal(t, "SUBSTRING", result.Signature.Name)
+	require.NotNil(t, result.Parameter)
+	assert.Equal(t, result.Signature.Parameters[1], *result.Parameter)
+	assert.NotEmpty(t, result.Parameter.Description)
+
+	result = analyzer.SignatureHelp("CONCAT('a', 'b', 'c', ", 22)
+	require.NotNil(t, result.Signature)
+	assert.Equal(t, 3, result.ActiveParameter)
+	assert.Equal(t, result.Signature.Variadic, result.Parameter, "extra arguments go to the variadic parameter")
+
+	result = analyzer.SignatureHelp("UPPER('a', ", 11)
+	require.NotNil(t, result.Signature)
+	assert.Equal(t, 1, result.ActiveParameter)
+	assert.Nil(t, result.Parameter, "the function takes no more arguments")
+
+	result = analyzer.SignatureHelp("FOO(", 4)
+	assert.Nil(t, result.Signature)
+	assert.Nil(t, result.Parameter)
+}
+
+func TestSignatureHelpResult_AsMap(t *testing.T) {
+	result := NewApp().SignatureHelp("[a] + 1", 2).AsMap()
+	assert.Equal(t, map[string]any{"name": "", "signature": nil, "activeParameter": -1, "parameter": nil}, result)
+
+	result = NewApp().SignatureHelp("ROUND([a], ", 11).AsMap()
+	assert.Equal(t, "ROUND", result["name"])
+	assert.Equal(t, 1, result["activeParameter"])
+	assert.Equal(t, "ROUND", result["signature"].(map[string]any)["name"])
+	assert.Equal(t, "decimals", result["parameter"].(map[string]any)["name"])
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"antlr-editor/analyzer/core/app/ast"
+	"antlr-editor/analyzer/core/app/tree"
+	"antlr-editor/analyzer/core/models"
+)
+
+// decodeTreeDocument decodes a JSON document holding a parse tree or an abstract syntax tree.
+// The document is a node serialized by ParseTreeNode.AsMap, recognized by its "type" field, or by Expr.AsMap,
+// recognized by its "node" field; a whole ParseTreeResult or ASTResult is accepted as well.
+// The positions of parse tree nodes count units of the encoding.
+func decodeTreeDocument(data []byte, encoding models.PositionEncoding) (models.Expr, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after the document")
+	}
+	node, ok := document.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("document must be a JSON object")
+	}
+
+	// Unwrap results of ParseTree and ParseAST
+	for _, key := range []string{"tree", "ast"} {
+		if wrapped, found := node[key]; found {
+			if node, ok = wrapped.(map[string]any); !ok {
+				return nil, fmt.Errorf("document has no %s", key)
+			}
+			break
+		}
+	}
+
+	switch {
+	case node["node"] != nil:
+		return ast.Decode(node)
+	case node["type"] != nil:
+		decoder := tree.NewTreeDecoder()
+		decoder.SetPositionEncoding(encoding)
+		return decoder.Decode(node)
+	}
+	return nil, fmt.Errorf("document must be a parse tree node with a \"type\" field or an AST node with a \"node\" field")
+}
+
+// sourceFailure creates a TranspileResult for an invalid tree document
+func sourceFailure(message string) *TranspileResult {
+	return &TranspileResult{Errors: []models.ErrorInfo{{Message: message, Line: 1, Column: 0, Start: 0, End: 0}}}
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/models"
+)
+
+func TestApp_SourceFromJSON_RoundTrip(t *testing.T) {
+	app := NewApp()
+
+	expressions := []string{
+		"[price] * [qty]",
+		"([a] + [b]) * 2",
+		"-[a] ^ 2 ^ 3",
+		`IF([status] == "open" && [total] >= 100, UPPER("yes"), "no")`,
+		"NOW()",
+		"[a] != [b] || [c] < 1.5 || true",
+		"2 - (3 - 4) / [x]",
+	}
+
+	// Literals are written in canonical form, so the expressions use double quotes and lowercase booleans
+	for _, expression := range expressions {
+		t.Run(expression, func(t *testing.T) {
+			expected := app.Format(expression)
+
+			data, err := json.Marshal(app.ParseTree(expression).Tree.AsMap())
+			require.NoError(t, err)
+			result := app.SourceFromJSON(data)
+			require.Empty(t, result.Errors)
+			assert.Equal(t, expected, result.Code)
+
+			// Whole results are accepted, and the AST format gives the same source
+			data, err = json.Marshal(app.ParseAST(expression).AsMap())
+			require.NoError(t, err)
+			result = app.SourceFromJSON(data)
+			require.Empty(t, result.Errors)
+			assert.Equal(t, expected, result.Code)
+		})
+	}
+}
+
+func TestApp_SourceFromJSON_PositionEncoding(t *testing.T) {
+	// The operators of comparisons are read from the text between the operands, located by their positions.
+	// Literals are written in canonical form, so the expressions use double quotes.
+	expressions := []string{
+		`"é" == "é"`,
+		`"😀" + "x" < "y"`,
+		`[顧客名] != "😀😀" && [a] >= 1`,
+	}
+
+	for _, encoding := range []models.PositionEncoding{models.PositionEncodingUTF16, models.PositionEncodingByte, models.PositionEncodingRune} {
+		app := NewApp()
+		require.NoError(t, app.SetPositionEncoding(encoding))
+		for _, expression := range expressions {
+			t.Run(string(encoding)+" "+expression, func(t *testing.T) {
+				data, err := json.Marshal(app.ParseTree(expression).Tree.AsMap())
+				require.NoError(t, err)
+				result := app.SourceFromJSON(data)
+				require.Empty(t, result.Errors)
+				assert.Equal(t, app.Format(expression), result.Code)
+			})
+		}
+	}
+}
+
+func TestApp_SourceFromJSON_EditedTree(t *testing.T) {
+	app := NewApp()
+
+	// A visual tool replaced the right operand and inserted an operator node, leaving stale text and positions
+	document := `{"type": 0, "text": "[a] + 1", "start": 0, "end": 7, "children": [
+		{"type": 8, "text": "[a] + 1", "start": 0, "end": 7, "children": [
+			{"type": 2, "text": "[a]", "start": 0, "end": 3},
+			{"type": 17},
+			{"type": 14, "children": [
+				{"type": 38, "text": "ROUND"},
+				{"type": 15, "children": [
+					{"type": 2, "text": "[b]"},
+					{"type": 1, "children": [{"type": 36, "text": "2"}]}
+				]}
+			]}
+		]}
+	]}`
+	result := app.SourceFromJSON([]byte(document))
+	require.Empty(t, result.Errors)
+	assert.Equal(t, "[a] - ROUND([b], 2)", result.Code)
+
+	// Parentheses are added where the edited tree needs them
+	document = `{"node": "binary", "op": "*", "left": {"node": "binary", "op": "+", "left": {"node": "column", "name": "a"},
+		"right": {"node": "literal", "kind": "number", "value": 1}}, "right": {"node": "unary", "op": "-",
+		"operand": {"node": "literal", "kind": "string", "value": "it's"}}}`
+	result = app.SourceFromJSON([]byte(document))
+	require.Empty(t, result.Errors)
+	assert.Equal(t, `([a] + 1) * -"it's"`, result.Code)
+
+	// Literal text is canonicalized
+	document = `{"type": 1, "children": [{"type": 37, "text": "'say \\'hi\\''"}]}`
+	result = app.SourceFromJSON([]byte(document))
+	require.Empty(t, result.Errors)
+	assert.Equal(t, `"say 'hi'"`, result.Code)
+}
+
+func TestApp_SourceFromJSON_Errors(t *testing.T) {
+	app := NewApp()
+
+	testCases := []struct {
+		name     string
+		document string
+		expected string
+	}{
+		{"malformed JSON", `{"type": 0`, "Invalid tree: invalid JSON: unexpected EOF"},
+		{"not an object", `[1, 2]`, "Invalid tree: document must be a JSON object"},
+		{"unknown format", `{"kind": "tree"}`, `Invalid tree: document must be a parse tree node with a "type" field or an AST node with a "node" field`},
+		{"empty result", `{"tree": null, "errors": []}`, "Invalid tree: document has no tree"},
+		{"unknown node type", `{"type": 99}`, "Invalid tree: tree: unknown node type 99"},
+		{"non-integer type", `{"type": "AddSubExpr"}`, "Invalid tree: tree: type must be an integer node type"},
+		{"token as expression", `{"type": 0, "children": [{"type": 16, "text": "+"}]}`, "Invalid tree: tree.children[0]: Add node cannot be used as an expression"},
+		{"root arity", `{"type": 0, "children": []}`, "Invalid tree: tree: Expression must have 1 child, got 0"},
+		{"binary arity", `{"type": 7, "children": [{"type": 2, "text": "[a]"}]}`, "Invalid tree: tree: MulDivExpr must have 2 operand children, or 3 with an operator node in the middle, got 1 children"},
+		{
+			"operator not allowed",
+			`{"type": 7, "children": [{"type": 2, "text": "[a]"}, {"type": 16}, {"type": 2, "text": "[b]"}]}`,
+			`Invalid tree: tree: MulDivExpr cannot have operator "+", expected one of * /`,
+		},
+		{
+			"operator from text",
+			`{"type": 9, "text": "[a] = [b]", "start": 0, "children": [{"type": 2, "text": "[a]", "start": 0, "end": 3}, {"type": 2, "text": "[b]", "start": 6, "end": 9}]}`,
+			`Invalid tree: tree: ComparisonExpr cannot have operator "=", expected one of < <= > >= == !=`,
+		},
+		{
+			"operator without positions",
+			`{"type": 8, "text": "[a] + [b]", "children": [{"type": 2, "text": "[a]"}, {"type": 2, "text": "[b]"}]}`,
+			"Invalid tree: tree: cannot determine the operator of AddSubExpr from its text; add an operator node between the operands",
+		},
+		{"invalid column text", `{"type": 2, "text": "[unit price]"}`, `Invalid tree: tree: invalid ColumnRefExpr text "[unit price]"`},
+		{"invalid literal text", `{"type": 1, "children": [{"type": 36, "text": "1.5"}]}`, `Invalid tree: tree.children[0]: invalid IntegerLiteral text "1.5"`},
+		{"literal node type", `{"type": 1, "children": [{"type": 2, "text": "[a]"}]}`, "Invalid tree: tree.children[0]: LiteralExpr must contain a StringLiteral, IntegerLiteral, FloatLiteral or BooleanLiteral node, got ColumnRefExpr"},
+		{"missing text", `{"type": 14, "children": [{"type": 38}]}`, "Invalid tree: tree.children[0]: FunctionName node must have a text"},
+		{"empty argument list", `{"type": 14, "children": [{"type": 38, "text": "NOW"}, {"type": 15, "children": []}]}`, "Invalid tree: tree.children[1]: ArgumentList must have at least 1 child; omit it for a call without arguments"},
+		{"child not an object", `{"type": 4, "children": [1]}`, "Invalid tree: tree.children[0]: node must be an object"},
+		{"AST unknown kind", `{"node": "lambda"}`, `Invalid tree: ast: unknown node kind "lambda"`},
+		{"AST missing operand", `{"node": "binary", "op": "+", "left": {"node": "column", "name": "a"}}`, "Invalid tree: ast.right: missing node"},
+		{"AST invalid operator", `{"node": "binary", "op": "%", "left": {"node": "column", "name": "a"}, "right": {"node": "column", "name": "b"}}`, `Invalid tree: ast: invalid binary operator "%"`},
+		{"AST literal value", `{"node": "call", "name": "ABS", "args": [{"node": "literal", "kind": "number", "value": "1"}]}`, "Invalid tree: ast.args[0]: invalid number literal value 1"},
+		{"AST function name", `{"node": "call", "name": "abs", "args": []}`, "Invalid tree: ast: function name must start with an uppercase letter followed by uppercase letters, digits or underscores"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := app.SourceFromJSON([]byte(tc.document))
+			assert.Empty(t, result.Code)
+			require.Len(t, result.Errors, 1)
+			assert.Equal(t, tc.expected, result.Errors[0].Message)
+			assert.Equal(t, 1, result.Errors[0].Line)
+			assert.Equal(t, 0, result.Errors[0].Start)
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package sqlgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Argument is a translated function argument
+type Argument struct {
+	SQL    string // Translated argument
+	atomic bool   // Whether SQL can be used as an operand without parentheses
+}
+
+// Operand returns the argument in a form that can be used as an operand of any operator
+func (a Argument) Operand() string {
+	if a.atomic {
+		return a.SQL
+	}
+	return "(" + a.SQL + ")"
+}
+
+// FunctionTranslator renders a function call from its translated arguments.
+// The result must be usable as an operand, e.g. a function call or a parenthesized expression.
+// It returns an error if the call cannot be expressed in the dialect.
+type FunctionTranslator func(d *Dialect, args []Argument) (string, error)
+
+// Dialect describes how expressions are written in one SQL dialect
+type Dialect struct {
+	// Name identifies the dialect, e.g. "sqlite"
+	Name string
+
+	// FloatType is the type numbers are cast to so that division is never integer division
+	FloatType string
+
+	functions map[string]FunctionTranslator
+}
+
+var (
+	// SQLite targets SQLite 3.35 or later built with the math functions (the default for the CLI; go-sqlite3
+	// needs the sqlite_math_functions build tag). Division by zero yields NULL where Evaluate reports an error.
+	SQLite = &Dialect{Name: "sqlite", FloatType: "REAL", functions: sqliteFunctions()}
+
+	// PostgreSQL targets PostgreSQL 12 or later
+	PostgreSQL = &Dialect{Name: "postgresql", FloatType: "DOUBLE PRECISION", functions: postgresqlFunctions()}
+
+	// ANSI targets standard SQL and rejects functions without a standard equivalent
+	ANSI = &Dialect{Name: "ansi", FloatType: "DOUBLE PRECISION", functions: ansiFunctions()}
+)
+
+// Dialects returns the built-in dialects
+func Dialects() []*Dialect {
+	return []*Dialect{SQLite, PostgreSQL, ANSI}
+}
+
+// LookupDialect returns the built-in dialect with the given name, case-insensitively
+func LookupDialect(name string) (*Dialect, bool) {
+	for _, dialect := range Dialects() {
+		if strings.EqualFold(dialect.Name, name) {
+			return dialect, true
+		}
+	}
+	return nil, false
+}
+
+// WithFunction returns a copy of the dialect that translates calls to the named function,
+// typically a custom registered function, with translate
+func (d *Dialect) WithFunction(name string, translate FunctionTranslator) *Dialect {
+	copy := *d
+	copy.functions = make(map[string]FunctionTranslator, len(d.functions)+1)
+	for n, t := range d.functions {
+		copy.functions[n] = t
+	}
+	copy.functions[name] = translate
+	return &copy
+}
+
+// QuoteIdentifier quotes a column name as a delimited identifier
+func (d *Dialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// QuoteString quotes a string literal
+func (d *Dialect) QuoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// unsupported returns a translator for a function the dialect cannot express
+func unsupported(name string) FunctionTranslator {
+	return func(d *Dialect, _ []Argument) (string, error) {
+		return "", fmt.Errorf("Function %s cannot be expressed in the %s dialect", name, d.Name)
+	}
+}
+
+// rename returns a translator that calls a function of the given name with the same arguments
+func rename(name string) FunctionTranslator {
+	return func(_ *Dialect, args []Argument) (string, error) {
+		return call(name, sqlOf(args)...), nil
+	}
+}
+
+// call renders a function call
+func call(name string, args ...string) string {
+	return name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// sqlOf returns the SQL of the arguments
+func sqlOf(args []Argument) []string {
+	sql := make([]string, len(args))
+	for i, arg := range args {
+		sql[i] = arg.SQL
+	}
+	return sql
+}
+
+// operands returns the arguments in a form that can be used as operands
+func operands(args []Argument) []string {
+	sql := make([]string, len(args))
+	for i, arg := range args {
+		sql[i] = arg.Operand()
+	}
+	return sql
+}
+
+// commonFunctions returns the translations shared by every dialect
+func commonFunctions() map[string]FunctionTranslator {
+	return map[string]FunctionTranslator{
+		"UPPER":    rename("UPPER"),
+		"LOWER":    rename("LOWER"),
+		"TRIM":     rename("TRIM"),
+		"ABS":      rename("ABS"),
+		"FLOOR":    rename("FLOOR"),
+		"CONCAT":   concat,
+		"SUM":      sum,
+		"AVG":      avg,
+		"COUNT":    count,
+		"IF":       ifThenElse,
+		"COALESCE": coalesce,
+		"NOW":      func(_ *Dialect, _ []Argument) (string, error) { return "CURRENT_TIMESTAMP", nil },
+	}
+}
+
+func sqliteFunctions() map[string]FunctionTranslator {
+	functions := commonFunctions()
+	functions["LENGTH"] = rename("LENGTH")
+	functions["LEN"] = rename("LENGTH")
+	functions["SUBSTRING"] = func(_ *Dialect, args []Argument) (string, error) {
+		return call("SUBSTR", args[0].SQL, oneBased(args[1]), args[2].SQL), nil
+	}
+	functions["REPLACE"] = rename("REPLACE")
+	functions["ROUND"] = rename("ROUND")
+	functions["CEIL"] = rename("CEIL")
+	// The scalar MIN and MAX of SQLite return NULL if any argument is NULL. Replacing each argument
+	// by the first non-null argument from it onwards or before it ignores NULLs without changing the result.
+	functions["MIN"] = ignoringNulls("MIN")
+	functions["MAX"] = ignoringNulls("MAX")
+	functions["DATE"] = func(_ *Dialect, args []Argument) (string, error) {
+		return call("DATETIME", call("DATE", args[0].SQL)), nil
+	}
+	functions["YEAR"] = strftime("%Y")
+	functions["MONTH"] = strftime("%m")
+	functions["DAY"] = strftime("%d")
+	return functions
+}
+
+func postgresqlFunctions() map[string]FunctionTranslator {
+	functions := commonFunctions()
+	functions["LENGTH"] = rename("LENGTH")
+	functions["LEN"] = rename("LENGTH")
+	functions["SUBSTRING"] = substringFromFor
+	functions["REPLACE"] = rename("REPLACE")
+	// ROUND on DOUBLE PRECISION rounds half to even; NUMERIC rounds half away from zero like Evaluate
+	functions["ROUND"] = func(d *Dialect, args []Argument) (string, error) {
+		sql := sqlOf(args)
+		sql[0] = "CAST(" + sql[0] + " AS NUMERIC)"
+		return "CAST(" + call("ROUND", sql...) + " AS " + d.FloatType + ")", nil
+	}
+	functions["CEIL"] = rename("CEIL")
+	// LEAST and GREATEST ignore NULLs
+	functions["MIN"] = rename("LEAST")
+	functions["MAX"] = rename("GREATEST")
+	functions["DATE"] = func(_ *Dialect, args []Argument) (string, error) {
+		return call("DATE_TRUNC", "'day'", args[0].SQL), nil
+	}
+	functions["YEAR"] = extract("YEAR")
+	functions["MONTH"] = extract("MONTH")
+	functions["DAY"] = extract("DAY")
+	return functions
+}
+
+func ansiFunctions() map[string]FunctionTranslator {
+	functions := commonFunctions()
+	functions["LENGTH"] = rename("CHAR_LENGTH")
+	functions["LEN"] = rename("CHAR_LENGTH")
+	functions["SUBSTRING"] = substringFromFor
+	functions["REPLACE"] = unsupported("REPLACE")
+	functions["ROUND"] = unsupported("ROUND")
+	functions["CEIL"] = rename("CEILING")
+	functions["MIN"] = unsupported("MIN")
+	functions["MAX"] = unsupported("MAX")
+	functions["DATE"] = func(_ *Dialect, args []Argument) (string, error) {
+		return "CAST(CAST(" + args[0].SQL + " AS DATE) AS TIMESTAMP)", nil
+	}
+	functions["YEAR"] = extract("YEAR")
+	functions["MONTH"] = extract("MONTH")
+	functions["DAY"] = extract("DAY")
+	return functions
+}
+
+// oneBased converts a zero-based position to a one-based one, folding integer literals
+func oneBased(position Argument) string {
+	if n, err := strconv.Atoi(position.SQL); err == nil {
+		return strconv.Itoa(n + 1)
+	}
+	return position.Operand() + " + 1"
+}
+
+func substringFromFor(_ *Dialect, args []Argument) (string, error) {
+	return "SUBSTRING(" + args[0].SQL + " FROM " + oneBased(args[1]) + " FOR " + args[2].SQL + ")", nil
+}
+
+func strftime(format string) FunctionTranslator {
+	return func(_ *Dialect, args []Argument) (string, error) {
+		return "CAST(" + call("STRFTIME", "'"+format+"'", args[0].SQL) + " AS INTEGER)", nil
+	}
+}
+
+func extract(field string) FunctionTranslator {
+	return func(_ *Dialect, args []Argument) (string, error) {
+		return "EXTRACT(" + field + " FROM " + args[0].SQL + ")", nil
+	}
+}
+
+func ignoringNulls(name string) FunctionTranslator {
+	return func(_ *Dialect, args []Argument) (string, error) {
+		if len(args) == 1 {
+			return args[0].Operand(), nil
+		}
+		sql := sqlOf(args)
+		rotated := make([]string, len(sql))
+		for i := range sql {
+			rotated[i] = call("COALESCE", append(append([]string{}, sql[i:]...), sql[:i]...)...)
+		}
+		return call(name, rotated...), nil
+	}
+}
+
+// concat uses || rather than CONCAT, which ignores NULLs in PostgreSQL
+func concat(_ *Dialect, args []Argument) (string, error) {
+	if len(args) == 1 {
+		return args[0].Operand(), nil
+	}
+	return "(" + strings.Join(operands(args), " || ") + ")", nil
+}
+
+// allNull renders a condition that holds if every argument is NULL
+func allNull(args []Argument) string {
+	if len(args) == 1 {
+		return args[0].Operand() + " IS NULL"
+	}
+	return call("COALESCE", sqlOf(args)...) + " IS NULL"
+}
+
+// sumOfNonNull adds the arguments, treating NULL as 0
+func sumOfNonNull(args []Argument) string {
+	terms := make([]string, len(args))
+	for i, arg := range args {
+		terms[i] = call("COALESCE", arg.SQL, "0")
+	}
+	return strings.Join(terms, " + ")
+}
+
+// countOfNonNull counts the arguments that are not NULL
+func countOfNonNull(args []Argument) string {
+	terms := make([]string, len(args))
+	for i, arg := range args {
+		terms[i] = "CASE WHEN " + arg.Operand() + " IS NULL THEN 0 ELSE 1 END"
+	}
+	return strings.Join(terms, " + ")
+}
+
+func sum(_ *Dialect, args []Argument) (string, error) {
+	if len(args) == 1 {
+		return args[0].Operand(), nil
+	}
+	return "CASE WHEN " + allNull(args) + " THEN NULL ELSE " + sumOfNonNull(args) + " END", nil
+}
+
+func avg(d *Dialect, args []Argument) (string, error) {
+	if len(args) == 1 {
+		return args[0].Operand(), nil
+	}
+	return "(CAST(" + sumOfNonNull(args) + " AS " + d.FloatType + ") / NULLIF(" + countOfNonNull(args) + ", 0))", nil
+}
+
+func count(_ *Dialect, args []Argument) (string, error) {
+	if len(args) == 0 {
+		return "0", nil
+	}
+	return "(" + countOfNonNull(args) + ")", nil
+}
+
+func ifThenElse(_ *Dialect, args []Argument) (string, error) {
+	return "CASE WHEN " + args[0].SQL + " THEN " + args[1].SQL + " ELSE " + args[2].SQL + " END", nil
+}
+
+func coalesce(_ *Dialect, args []Argument) (string, error) {
+	if len(args) == 1 {
+		return args[0].Operand(), nil
+	}
+	return call("COALESCE", sqlOf(args)...), nil
+}
@@ -0,0 +1,213 @@
+package sqlgen
+
+import (
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/core/app/eval"
+	"antlr-editor/analyzer/core/infrastructure"
+	"antlr-editor/analyzer/core/models"
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// Operator precedence of generated SQL, from loosest to tightest binding
+const (
+	precedenceOr = iota + 1
+	precedenceAnd
+	precedenceComparison
+	precedenceAdditive
+	precedenceMultiplicative
+	precedenceUnary
+	precedencePrimary
+)
+
+// fragment is generated SQL together with the precedence of its outermost operator
+type fragment struct {
+	sql        string
+	precedence int
+}
+
+// Visitor generates SQL from a parse tree
+type Visitor struct {
+	*parser.BaseExpressionVisitor
+	dialect *Dialect
+	errors  []models.ErrorInfo
+}
+
+// NewSQLVisitor creates a visitor generating SQL in the given dialect
+func NewSQLVisitor(dialect *Dialect) *Visitor {
+	return &Visitor{
+		BaseExpressionVisitor: &parser.BaseExpressionVisitor{},
+		dialect:               dialect,
+	}
+}
+
+// Generate returns the SQL for a syntactically valid parse tree,
+// or the constructs that cannot be expressed in the dialect
+func (v *Visitor) Generate(tree parser.IExpressionContext) (string, []models.ErrorInfo) {
+	sql := v.generate(tree).sql
+	if len(v.errors) > 0 {
+		return "", v.errors
+	}
+	return sql, nil
+}
+
+// Visit visits a parse tree node and returns its SQL fragment
+func (v *Visitor) Visit(tree antlr.ParseTree) any {
+	if tree == nil {
+		return fragment{sql: "NULL", precedence: precedencePrimary}
+	}
+	if result, ok := tree.Accept(v).(fragment); ok {
+		return result
+	}
+	return fragment{sql: "NULL", precedence: precedencePrimary}
+}
+
+// generate generates the SQL of the given expression
+func (v *Visitor) generate(expr parser.IExpressionContext) fragment {
+	if expr == nil {
+		return fragment{sql: "NULL", precedence: precedencePrimary}
+	}
+	return v.Visit(expr).(fragment)
+}
+
+// addError records an error located on the span of the given context
+func (v *Visitor) addError(ctx antlr.ParserRuleContext, message string) {
+	if err, ok := infrastructure.ErrorAt(ctx, message); ok {
+		v.errors = append(v.errors, err)
+	}
+}
+
+// VisitLiteralExpr generates a literal expression
+func (v *Visitor) VisitLiteralExpr(ctx *parser.LiteralExprContext) any {
+	return v.Visit(ctx.Literal())
+}
+
+// VisitLiteral generates a literal value
+func (v *Visitor) VisitLiteral(ctx *parser.LiteralContext) any {
+	switch {
+	case ctx.STRING_LITERAL() != nil:
+		return fragment{sql: v.dialect.QuoteString(eval.UnquoteString(ctx.STRING_LITERAL().GetText())), precedence: precedencePrimary}
+	case ctx.BOOLEAN_LITERAL() != nil:
+		return fragment{sql: strings.ToUpper(ctx.GetText()), precedence: precedencePrimary}
+	default:
+		return fragment{sql: ctx.GetText(), precedence: precedencePrimary}
+	}
+}
+
+// VisitColumnRefExpr generates a column reference expression
+func (v *Visitor) VisitColumnRefExpr(ctx *parser.ColumnRefExprContext) any {
+	return v.Visit(ctx.ColumnReference())
+}
+
+// VisitColumnReference generates a quoted identifier
+func (v *Visitor) VisitColumnReference(ctx *parser.ColumnReferenceContext) any {
+	text := ctx.COLUMN_REF().GetText()
+	return fragment{sql: v.dialect.QuoteIdentifier(text[1 : len(text)-1]), precedence: precedencePrimary}
+}
+
+// VisitFunctionCallExpr generates a function call expression
+func (v *Visitor) VisitFunctionCallExpr(ctx *parser.FunctionCallExprContext) any {
+	return v.Visit(ctx.FunctionCall())
+}
+
+// VisitFunctionCall translates a function call with the dialect
+func (v *Visitor) VisitFunctionCall(ctx *parser.FunctionCallContext) any {
+	name := ctx.FUNCTION_NAME().GetText()
+
+	var args []Argument
+	if argList := ctx.ArgumentList(); argList != nil {
+		for _, expr := range argList.AllExpression() {
+			arg := v.generate(expr)
+			args = append(args, Argument{SQL: arg.sql, atomic: arg.precedence == precedencePrimary})
+		}
+	}
+
+	translate, ok := v.dialect.functions[name]
+	if !ok {
+		translate = unsupported(name)
+	}
+	sql, err := translate(v.dialect, args)
+	if err != nil {
+		v.addError(ctx, err.Error())
+		return fragment{sql: "NULL", precedence: precedencePrimary}
+	}
+	return fragment{sql: sql, precedence: precedencePrimary}
+}
+
+// VisitParenExpr generates a parenthesized expression; parentheses are re-inserted where precedence requires them
+func (v *Visitor) VisitParenExpr(ctx *parser.ParenExprContext) any {
+	return v.generate(ctx.Expression())
+}
+
+// VisitUnaryMinusExpr generates a negation
+func (v *Visitor) VisitUnaryMinusExpr(ctx *parser.UnaryMinusExprContext) any {
+	operand := v.generate(ctx.Expression())
+	sql := operand.sql
+	// "--" starts a comment in SQL
+	if operand.precedence < precedencePrimary || strings.HasPrefix(sql, "-") {
+		sql = "(" + sql + ")"
+	}
+	return fragment{sql: "-" + sql, precedence: precedenceUnary}
+}
+
+// VisitPowerExpr generates an exponentiation as POWER()
+func (v *Visitor) VisitPowerExpr(ctx *parser.PowerExprContext) any {
+	base, exponent := v.generate(ctx.Expression(0)), v.generate(ctx.Expression(1))
+	return fragment{sql: call("POWER", base.sql, exponent.sql), precedence: precedencePrimary}
+}
+
+// VisitMulDivExpr generates a multiplication/division.
+// The dividend is cast to a floating point type so that integer operands do not truncate.
+func (v *Visitor) VisitMulDivExpr(ctx *parser.MulDivExprContext) any {
+	left, right := v.generate(ctx.Expression(0)), v.generate(ctx.Expression(1))
+	if ctx.DIV() != nil {
+		left = fragment{sql: "CAST(" + left.sql + " AS " + v.dialect.FloatType + ")", precedence: precedencePrimary}
+	}
+	return binary(left, infrastructure.OperatorText(ctx), right, precedenceMultiplicative)
+}
+
+// VisitAddSubExpr generates an addition/subtraction
+func (v *Visitor) VisitAddSubExpr(ctx *parser.AddSubExprContext) any {
+	return binary(v.generate(ctx.Expression(0)), infrastructure.OperatorText(ctx), v.generate(ctx.Expression(1)), precedenceAdditive)
+}
+
+// VisitComparisonExpr generates a comparison
+func (v *Visitor) VisitComparisonExpr(ctx *parser.ComparisonExprContext) any {
+	operator := infrastructure.OperatorText(ctx)
+	switch operator {
+	case "==":
+		operator = "="
+	case "!=":
+		operator = "<>"
+	}
+	left := v.generate(ctx.Expression(0))
+	// Comparisons do not associate in every dialect, so a nested comparison is always parenthesized
+	if left.precedence == precedenceComparison {
+		left = fragment{sql: "(" + left.sql + ")", precedence: precedencePrimary}
+	}
+	return binary(left, operator, v.generate(ctx.Expression(1)), precedenceComparison)
+}
+
+// VisitAndExpr generates a logical AND
+func (v *Visitor) VisitAndExpr(ctx *parser.AndExprContext) any {
+	return binary(v.generate(ctx.Expression(0)), "AND", v.generate(ctx.Expression(1)), precedenceAnd)
+}
+
+// VisitOrExpr generates a logical OR
+func (v *Visitor) VisitOrExpr(ctx *parser.OrExprContext) any {
+	return binary(v.generate(ctx.Expression(0)), "OR", v.generate(ctx.Expression(1)), precedenceOr)
+}
+
+// binary generates a left-associative binary operation, parenthesizing operands that bind more loosely
+func binary(left fragment, operator string, right fragment, precedence int) fragment {
+	leftSQL, rightSQL := left.sql, right.sql
+	if left.precedence < precedence {
+		leftSQL = "(" + leftSQL + ")"
+	}
+	if right.precedence <= precedence {
+		rightSQL = "(" + rightSQL + ")"
+	}
+	return fragment{sql: leftSQL + " " + operator + " " + rightSQL, precedence: precedence}
+}
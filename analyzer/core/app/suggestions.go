@@ -0,0 +1,77 @@
+package app
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the maximum number of names offered in a "did you mean" hint
+const maxSuggestions = 3
+
+// suggestNames returns the candidates closest to name by edit distance, best match first.
+// Candidates further away than a third of the name's length (at least 1, at most 3 edits) are ignored.
+func suggestNames(name string, candidates []string) []string {
+	threshold := min(max(len([]rune(name))/3, 1), 3)
+
+	type suggestion struct {
+		name     string
+		distance int
+	}
+	suggestions := make([]suggestion, 0)
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if distance <= threshold {
+			suggestions = append(suggestions, suggestion{candidate, distance})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+
+	result := make([]string, 0, maxSuggestions)
+	for i := 0; i < len(suggestions) && i < maxSuggestions; i++ {
+		result = append(result, suggestions[i].name)
+	}
+	return result
+}
+
+// formatSuggestions joins bracketed column names as "[a]", "[a] or [b]" or "[a], [b] or [c]"
+func formatSuggestions(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "[" + name + "]"
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
+
+// editDistance returns the number of single rune insertions, deletions, substitutions and
+// adjacent transpositions needed to turn a into b (optimal string alignment distance)
+func editDistance(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	rows := make([][]int, len(source)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(target)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && source[i-1] == target[j-2] && source[i-2] == target[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(source)][len(target)]
+}
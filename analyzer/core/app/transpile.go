@@ -0,0 +1,85 @@
+package app
+
+import (
+	"antlr-editor/analyzer/core/app/jsgen"
+	"antlr-editor/analyzer/core/app/pandasgen"
+	"antlr-editor/analyzer/core/app/sqlgen"
+	"antlr-editor/analyzer/core/models"
+)
+
+// TranspileResult represents the result of translating an expression to another language
+type TranspileResult struct {
+	Code   string             `json:"code"`   // Generated code, empty if there are errors
+	Errors []models.ErrorInfo `json:"errors"` // Lint errors, or constructs the target cannot express
+}
+
+// AsMap converts TranspileResult to a map for JSON serialization
+func (r *TranspileResult) AsMap() map[string]any {
+	errors := make([]any, len(r.Errors))
+	for i, err := range r.Errors {
+		errors[i] = err.AsMap()
+	}
+	return map[string]any{
+		"code":   r.Code,
+		"errors": errors,
+	}
+}
+
+// transpileErrors returns the errors that prevent generating code: code is only generated for expressions that pass Lint
+func (a *Analyzer) transpileErrors(expression string) []models.ErrorInfo {
+	if expression == "" {
+		return []models.ErrorInfo{{Message: "Empty expression", Line: 1, Column: 0, Start: 0, End: 0}}
+	}
+	return a.Lint(expression)
+}
+
+// ToSQL translates the expression to a SQL expression in the given dialect.
+// Column references become quoted identifiers and functions are mapped to their dialect equivalents;
+// an expression that fails Lint, or uses a function the dialect cannot express, yields errors instead.
+func (a *Analyzer) ToSQL(expression string, dialect *sqlgen.Dialect) *TranspileResult {
+	if errors := a.transpileErrors(expression); len(errors) > 0 {
+		return &TranspileResult{Errors: errors}
+	}
+
+	tree, _ := a.parseWithSyntaxErrors(expression)
+	code, errors := sqlgen.NewSQLVisitor(dialect).Generate(tree)
+	if len(errors) > 0 {
+		return &TranspileResult{Errors: errors}
+	}
+	return &TranspileResult{Code: code, Errors: []models.ErrorInfo{}}
+}
+
+// ToJavaScript translates the expression to the source of a JavaScript function `(row) => value`
+// that evaluates it in the browser without calling into the analyzer for each row.
+// The function receives a row object keyed by column name and behaves like Evaluate,
+// throwing an Error with the same message where Evaluate reports an error.
+// Calls to custom functions cannot be translated and yield errors.
+func (a *Analyzer) ToJavaScript(expression string) *TranspileResult {
+	if errors := a.transpileErrors(expression); len(errors) > 0 {
+		return &TranspileResult{Errors: errors}
+	}
+
+	tree, _ := a.parseWithSyntaxErrors(expression)
+	code, errors := jsgen.NewJSVisitor(a.registry).Generate(tree)
+	if len(errors) > 0 {
+		return &TranspileResult{Errors: errors}
+	}
+	return &TranspileResult{Code: code, Errors: []models.ErrorInfo{}}
+}
+
+// ToPandas translates the expression to a vectorized pandas expression computing it for every row of a DataFrame.
+// The code reads columns from a DataFrame named df and uses pandas as pd and numpy as np:
+// [price] becomes df["price"], && and || become & and |, and ^ becomes **.
+// Missing values follow pandas semantics, e.g. comparisons with NaN are False rather than null.
+func (a *Analyzer) ToPandas(expression string) *TranspileResult {
+	if errors := a.transpileErrors(expression); len(errors) > 0 {
+		return &TranspileResult{Errors: errors}
+	}
+
+	tree, _ := a.parseWithSyntaxErrors(expression)
+	code, errors := pandasgen.NewPandasVisitor().Generate(tree)
+	if len(errors) > 0 {
+		return &TranspileResult{Errors: errors}
+	}
+	return &TranspileResult{Code: code, Errors: []models.ErrorInfo{}}
+}
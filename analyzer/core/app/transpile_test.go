@@ -0,0 +1,878 @@
+package app
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/app/functions"
+	"antlr-editor/analyzer/core/app/sqlgen"
+	"antlr-editor/analyzer/core/models"
+)
+
+func TestAnalyzer_ToSQL(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	testCases := []struct {
+		name       string
+		expression string
+		sqlite     string
+		postgresql string
+		ansi       string
+	}{
+		{
+			"column references", `[price] * [unit"qty]`,
+			`"price" * "unit""qty"`,
+			`"price" * "unit""qty"`,
+			`"price" * "unit""qty"`,
+		},
+		{
+			"string literals", `CONCAT('it\'s', "a \"b\"", [name])`,
+			`('it''s' || 'a "b"' || "name")`,
+			`('it''s' || 'a "b"' || "name")`,
+			`('it''s' || 'a "b"' || "name")`,
+		},
+		{
+			"power", "[x] ^ 2 + 1",
+			`POWER("x", 2) + 1`,
+			`POWER("x", 2) + 1`,
+			`POWER("x", 2) + 1`,
+		},
+		{
+			"logical operators", "[a] == 1 && [b] != 'x' || [c] >= 2",
+			`"a" = 1 AND "b" <> 'x' OR "c" >= 2`,
+			`"a" = 1 AND "b" <> 'x' OR "c" >= 2`,
+			`"a" = 1 AND "b" <> 'x' OR "c" >= 2`,
+		},
+		{
+			"precedence kept", "([a] || [b]) && [c]",
+			`("a" OR "b") AND "c"`,
+			`("a" OR "b") AND "c"`,
+			`("a" OR "b") AND "c"`,
+		},
+		{
+			"redundant parentheses dropped", "(([a] * 2)) + (3)",
+			`"a" * 2 + 3`,
+			`"a" * 2 + 3`,
+			`"a" * 2 + 3`,
+		},
+		{
+			"right operand of subtraction", "10 - ([a] - [b])",
+			`10 - ("a" - "b")`,
+			`10 - ("a" - "b")`,
+			`10 - ("a" - "b")`,
+		},
+		{
+			"floating point division", "[a] / ([b] + 1)",
+			`CAST("a" AS REAL) / ("b" + 1)`,
+			`CAST("a" AS DOUBLE PRECISION) / ("b" + 1)`,
+			`CAST("a" AS DOUBLE PRECISION) / ("b" + 1)`,
+		},
+		{
+			"double negation", "--[a]",
+			`-(-"a")`,
+			`-(-"a")`,
+			`-(-"a")`,
+		},
+		{
+			"boolean literals", "[flag] == TRUE",
+			`"flag" = TRUE`,
+			`"flag" = TRUE`,
+			`"flag" = TRUE`,
+		},
+		{
+			"conditional", "IF([a] > 0 && [b], 'yes', 'no')",
+			`CASE WHEN "a" > 0 AND "b" THEN 'yes' ELSE 'no' END`,
+			`CASE WHEN "a" > 0 AND "b" THEN 'yes' ELSE 'no' END`,
+			`CASE WHEN "a" > 0 AND "b" THEN 'yes' ELSE 'no' END`,
+		},
+		{
+			"length", "LEN([name]) > 3",
+			`LENGTH("name") > 3`,
+			`LENGTH("name") > 3`,
+			`CHAR_LENGTH("name") > 3`,
+		},
+		{
+			"zero-based substring", "SUBSTRING([name], 2, [n] + 1)",
+			`SUBSTR("name", 3, "n" + 1)`,
+			`SUBSTRING("name" FROM 3 FOR "n" + 1)`,
+			`SUBSTRING("name" FROM 3 FOR "n" + 1)`,
+		},
+		{
+			"computed substring start", "SUBSTRING([name], [n] - 1, 2)",
+			`SUBSTR("name", ("n" - 1) + 1, 2)`,
+			`SUBSTRING("name" FROM ("n" - 1) + 1 FOR 2)`,
+			`SUBSTRING("name" FROM ("n" - 1) + 1 FOR 2)`,
+		},
+		{
+			"rounding", "ROUND([a] / 3, 2)",
+			`ROUND(CAST("a" AS REAL) / 3, 2)`,
+			`CAST(ROUND(CAST(CAST("a" AS DOUBLE PRECISION) / 3 AS NUMERIC), 2) AS DOUBLE PRECISION)`,
+			"Function ROUND cannot be expressed in the ansi dialect",
+		},
+		{
+			"ceiling", "CEIL([a])",
+			`CEIL("a")`,
+			`CEIL("a")`,
+			`CEILING("a")`,
+		},
+		{
+			"minimum ignoring nulls", "MIN([a], [b], 3)",
+			`MIN(COALESCE("a", "b", 3), COALESCE("b", 3, "a"), COALESCE(3, "a", "b"))`,
+			`LEAST("a", "b", 3)`,
+			"Function MIN cannot be expressed in the ansi dialect",
+		},
+		{
+			"sum ignoring nulls", "SUM([a], [b]) * 2",
+			`CASE WHEN COALESCE("a", "b") IS NULL THEN NULL ELSE COALESCE("a", 0) + COALESCE("b", 0) END * 2`,
+			`CASE WHEN COALESCE("a", "b") IS NULL THEN NULL ELSE COALESCE("a", 0) + COALESCE("b", 0) END * 2`,
+			`CASE WHEN COALESCE("a", "b") IS NULL THEN NULL ELSE COALESCE("a", 0) + COALESCE("b", 0) END * 2`,
+		},
+		{
+			"single argument keeps precedence", "SUM([a] + 1) * 2",
+			`("a" + 1) * 2`,
+			`("a" + 1) * 2`,
+			`("a" + 1) * 2`,
+		},
+		{
+			"count", "COUNT([a], [b] > 1)",
+			`(CASE WHEN "a" IS NULL THEN 0 ELSE 1 END + CASE WHEN ("b" > 1) IS NULL THEN 0 ELSE 1 END)`,
+			`(CASE WHEN "a" IS NULL THEN 0 ELSE 1 END + CASE WHEN ("b" > 1) IS NULL THEN 0 ELSE 1 END)`,
+			`(CASE WHEN "a" IS NULL THEN 0 ELSE 1 END + CASE WHEN ("b" > 1) IS NULL THEN 0 ELSE 1 END)`,
+		},
+		{
+			"replace", "REPLACE([name], 'a', 'b')",
+			`REPLACE("name", 'a', 'b')`,
+			`REPLACE("name", 'a', 'b')`,
+			"Function REPLACE cannot be expressed in the ansi dialect",
+		},
+		{
+			"datetime parts", "YEAR([created]) * 100 + MONTH(DATE(NOW()))",
+			`CAST(STRFTIME('%Y', "created") AS INTEGER) * 100 + CAST(STRFTIME('%m', DATETIME(DATE(CURRENT_TIMESTAMP))) AS INTEGER)`,
+			`EXTRACT(YEAR FROM "created") * 100 + EXTRACT(MONTH FROM DATE_TRUNC('day', CURRENT_TIMESTAMP))`,
+			`EXTRACT(YEAR FROM "created") * 100 + EXTRACT(MONTH FROM CAST(CAST(CURRENT_TIMESTAMP AS DATE) AS TIMESTAMP))`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for dialect, expected := range map[*sqlgen.Dialect]string{
+				sqlgen.SQLite:     tc.sqlite,
+				sqlgen.PostgreSQL: tc.postgresql,
+				sqlgen.ANSI:       tc.ansi,
+			} {
+				result := analyzer.ToSQL(tc.expression, dialect)
+				if strings.HasPrefix(expected, "Function ") {
+					require.Len(t, result.Errors, 1, dialect.Name)
+					assert.Equal(t, expected, result.Errors[0].Message, dialect.Name)
+					assert.Empty(t, result.Code, dialect.Name)
+					continue
+				}
+				assert.Empty(t, result.Errors, dialect.Name)
+				assert.Equal(t, expected, result.Code, dialect.Name)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_ToSQL_Errors(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	testCases := []struct {
+		name       string
+		expression string
+		dialect    *sqlgen.Dialect
+		expected   []models.ErrorInfo
+	}{
+		{
+			"empty expression", "", sqlgen.SQLite,
+			[]models.ErrorInfo{{Message: "Empty expression", Line: 1, Column: 0, Start: 0, End: 0}},
+		},
+		{
+			"type error", "[a] + 'x'", sqlgen.SQLite,
+			[]models.ErrorInfo{{Message: "Operator '+' expects number operands, got string", Line: 1, Column: 6, Start: 6, End: 9}},
+		},
+		{
+			"unsupported functions", "ROUND(MAX([a], 1))", sqlgen.ANSI,
+			[]models.ErrorInfo{
+				{Message: "Function MAX cannot be expressed in the ansi dialect", Line: 1, Column: 6, Start: 6, End: 17},
+				{Message: "Function ROUND cannot be expressed in the ansi dialect", Line: 1, Column: 0, Start: 0, End: 18},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.ToSQL(tc.expression, tc.dialect)
+			assert.Empty(t, result.Code)
+			assert.Equal(t, tc.expected, result.Errors)
+		})
+	}
+}
+
+func TestAnalyzer_ToSQL_CustomFunction(t *testing.T) {
+	app := NewApp()
+	require.NoError(t, app.RegisterFunction(&functions.Function{
+		FunctionSignature: models.FunctionSignature{
+			Name:       "FX_RATE",
+			Parameters: []models.Parameter{{Name: "currency", Type: models.DataTypeString}},
+			ReturnType: models.DataTypeNumber,
+		},
+	}))
+
+	result := app.ToSQL("[amount] * FX_RATE('EUR')", sqlgen.PostgreSQL)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "Function FX_RATE cannot be expressed in the postgresql dialect", result.Errors[0].Message)
+
+	dialect := sqlgen.PostgreSQL.WithFunction("FX_RATE", func(_ *sqlgen.Dialect, args []sqlgen.Argument) (string, error) {
+		return "(SELECT rate FROM fx WHERE currency = " + args[0].SQL + ")", nil
+	})
+	result = app.ToSQL("[amount] * FX_RATE('EUR')", dialect)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, `"amount" * (SELECT rate FROM fx WHERE currency = 'EUR')`, result.Code)
+
+	_, ok := sqlgen.LookupDialect("PostgreSQL")
+	assert.True(t, ok)
+	_, ok = sqlgen.LookupDialect("oracle")
+	assert.False(t, ok)
+}
+
+// Golden tests: the generated SQLite must compute what Evaluate computes
+func TestAnalyzer_ToSQL_SQLite(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Skipf("SQLite is not available: %v", err)
+	}
+	// The dialect relies on the math functions, which go-sqlite3 only compiles in with the sqlite_math_functions tag
+	_, err = db.Exec("SELECT POWER(2, 3), FLOOR(1.5), CEIL(1.5)")
+	mathFunctions := err == nil
+
+	_, err = db.Exec(`CREATE TABLE t (id INTEGER, "price" REAL, "quantity" INTEGER, "name" TEXT, "active" BOOLEAN, "discount" REAL, "created" TEXT)`)
+	require.NoError(t, err)
+	created := time.Date(2024, 3, 15, 18, 30, 0, 0, time.UTC)
+	rows := []map[string]any{
+		{"price": 19.5, "quantity": 4, "name": "  Widget  ", "active": true, "discount": 0.25, "created": created},
+		{"price": 3.0, "quantity": 7, "name": "it's", "active": false, "discount": nil, "created": created.AddDate(1, -2, 20)},
+		{"price": -2.5, "quantity": 0, "name": "", "active": nil, "discount": 1.5, "created": nil},
+	}
+	for i, row := range rows {
+		var createdText any
+		if c, ok := row["created"].(time.Time); ok {
+			createdText = c.Format(time.DateTime)
+		}
+		_, err := db.Exec(`INSERT INTO t VALUES (?, ?, ?, ?, ?, ?, ?)`, i, row["price"], row["quantity"], row["name"], row["active"], row["discount"], createdText)
+		require.NoError(t, err)
+	}
+
+	analyzer := newAnalyzer()
+	expressions := []string{
+		"[price] * [quantity] - 1",
+		"[quantity] / 8",
+		"2 ^ [quantity] + -[price]",
+		"--[price]",
+		"[price] > 5 && [active] || [quantity] == 0",
+		"[active] && [discount] > 0",
+		"[name] == 'it\\'s'",
+		"UPPER(TRIM([name]))",
+		"CONCAT([name], '!', LOWER('AB'))",
+		"LENGTH([name]) + LEN('abc')",
+		"SUBSTRING('abcdef', FLOOR([quantity] / 2), 2)",
+		"REPLACE([name], 'i', 'I')",
+		"ROUND([price] / 3, 2)",
+		"FLOOR([price]) + CEIL([price]) + ABS([price])",
+		"MIN([discount], [price], 2)",
+		"MAX([discount], [price])",
+		"SUM([price], [discount], 1)",
+		"AVG([price], [discount])",
+		"COUNT([price], [discount], [active])",
+		"IF([active], 'on', 'off')",
+		"IF([quantity] > 3, [price], [discount])",
+		"COALESCE([discount], [price] * 2)",
+		"YEAR([created]) * 10000 + MONTH([created]) * 100 + DAY([created])",
+		"YEAR(DATE([created]))",
+		"[created] < [created]",
+	}
+
+	for _, expression := range expressions {
+		t.Run(expression, func(t *testing.T) {
+			result := analyzer.ToSQL(expression, sqlgen.SQLite)
+			require.Empty(t, result.Errors)
+
+			queryRows, err := db.Query("SELECT " + result.Code + " FROM t ORDER BY id")
+			if err != nil && !mathFunctions && strings.HasPrefix(err.Error(), "no such function") {
+				t.Skipf("SQLite is built without math functions, run with -tags sqlite_math_functions: %v", err)
+			}
+			require.NoError(t, err, result.Code)
+			defer queryRows.Close()
+
+			for i := 0; queryRows.Next(); i++ {
+				var actual any
+				require.NoError(t, queryRows.Scan(&actual))
+				expected := analyzer.Evaluate(expression, rows[i])
+				require.Empty(t, expected.Errors)
+				assertSQLiteValue(t, expected.Value, actual, "row %d of %s", i, result.Code)
+			}
+			require.NoError(t, queryRows.Err())
+		})
+	}
+
+	t.Run("division by zero", func(t *testing.T) {
+		// SQLite yields NULL where Evaluate reports an error
+		result := analyzer.ToSQL("[price] / [quantity]", sqlgen.SQLite)
+		require.Empty(t, result.Errors)
+		var actual any
+		require.NoError(t, db.QueryRow("SELECT "+result.Code+" FROM t WHERE id = 2").Scan(&actual))
+		assert.Nil(t, actual)
+		assert.NotEmpty(t, analyzer.Evaluate("[price] / [quantity]", rows[2]).Errors)
+	})
+}
+
+// assertSQLiteValue compares a value computed by SQLite with the one computed by Evaluate
+func assertSQLiteValue(t *testing.T, expected models.Value, actual any, msgAndArgs ...any) {
+	t.Helper()
+	if expected.IsNull() {
+		assert.Nil(t, actual, msgAndArgs...)
+		return
+	}
+	switch expected.Type() {
+	case models.DataTypeNumber:
+		var number float64
+		switch n := actual.(type) {
+		case int64:
+			number = float64(n)
+		case float64:
+			number = n
+		default:
+			assert.Failf(t, "expected a number", "got %T %v", actual, actual)
+			return
+		}
+		assert.InDelta(t, expected.Number(), number, 1e-9, msgAndArgs...)
+	case models.DataTypeBoolean:
+		assert.Equal(t, expected.Boolean(), actual == int64(1), msgAndArgs...)
+	case models.DataTypeString:
+		assert.Equal(t, expected.Str(), actual, msgAndArgs...)
+	default:
+		assert.Equal(t, expected.Interface(), actual, msgAndArgs...)
+	}
+}
+
+func TestAnalyzer_ToJavaScript(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   string // The generated function, after the runtime helpers
+	}{
+		{"string literal", `"a\"b"`, `(row) => "a\"b"`},
+		{"boolean literal", "TRUE", "(row) => true"},
+		{"negative number", "-1.50", "(row) => -1.5"},
+		{"exponent literal", "1e3", "(row) => 1000"},
+		{"quoted column", `[unit"qty]`, `return (row) => $col(row, "unit\"qty");`},
+		{"right associative power", "2 ^ 3 ^ 2", `return (row) => $arith("^", 2, $arith("^", 3, 2));`},
+		{"left associative subtraction", "10 - 4 - 3", `return (row) => $arith("-", $arith("-", 10, 4), 3);`},
+		{"precedence", "1 + 2 * 3", `return (row) => $arith("+", 1, $arith("*", 2, 3));`},
+		{"parentheses", "(1 + 2) * 3", `return (row) => $arith("*", $arith("+", 1, 2), 3);`},
+		{"negated column", "-[x]", `return (row) => $neg($col(row, "x"));`},
+		{"comparison", "[x] != 'a'", `return (row) => $compare("!=", $col(row, "x"), "a");`},
+		{"short circuit", "[a] && [b] || [c]", `return (row) => $logical("||", $logical("&&", $col(row, "a"), () => $col(row, "b")), () => $col(row, "c"));`},
+		{"lazy IF", "IF([a], 1, 2)", `return (row) => ($if($col(row, "a")) ? 1 : 2);`},
+		{"lazy COALESCE", "COALESCE([a], 0)", `return (row) => $coalesce(() => $col(row, "a"), () => 0);`},
+		{"function", "UPPER([a])", `const $UPPER = $strict("UPPER", [["text", "string"]], (text) => text.toUpperCase());`},
+		{"variadic function", "SUM(1, [a])", `const $SUM = $nullAware("SUM", [["number1", "number"], ["number", "number"]], `},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.ToJavaScript(tc.expression)
+			require.Empty(t, result.Errors)
+			if strings.HasPrefix(tc.expected, "(row) =>") {
+				assert.Equal(t, tc.expected, result.Code)
+				return
+			}
+			assert.True(t, strings.HasPrefix(result.Code, "(() => {\n  \"use strict\";\n"), result.Code)
+			assert.True(t, strings.HasSuffix(result.Code, ";\n})()"), result.Code)
+			assert.Contains(t, result.Code, tc.expected)
+		})
+	}
+
+	// Helpers are defined once, before the helpers that call them while being defined
+	result := analyzer.ToJavaScript("MIN([a], [b]) > MAX(1, 2) && MIN(3) < 4")
+	require.Empty(t, result.Errors)
+	assert.Equal(t, 1, strings.Count(result.Code, "const $extremum = "))
+	assert.Equal(t, 1, strings.Count(result.Code, "const $MIN = "))
+	assert.Less(t, strings.Index(result.Code, "const $nullAware = "), strings.Index(result.Code, "const $MIN = "))
+	assert.Less(t, strings.Index(result.Code, "const $extremum = "), strings.Index(result.Code, "const $MAX = "))
+}
+
+func TestAnalyzer_ToJavaScript_Errors(t *testing.T) {
+	analyzer := newAnalyzer()
+	require.NoError(t, analyzer.RegisterFunction(&functions.Function{
+		FunctionSignature: models.FunctionSignature{Name: "FX_RATE", ReturnType: models.DataTypeNumber},
+		Impl:              func(functions.Arguments) (models.Value, error) { return models.NumberValue(1), nil },
+	}))
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   []models.ErrorInfo
+	}{
+		{"empty", "", []models.ErrorInfo{{Message: "Empty expression", Line: 1, Column: 0, Start: 0, End: 0}}},
+		{"type error", "'a' * 2", []models.ErrorInfo{{Message: "Operator '*' expects number operands, got string", Line: 1, Column: 0, Start: 0, End: 3}}},
+		{
+			"custom function", "[price] * FX_RATE()",
+			[]models.ErrorInfo{{Message: "Function FX_RATE cannot be translated to JavaScript", Line: 1, Column: 10, Start: 10, End: 19}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.ToJavaScript(tc.expression)
+			assert.Empty(t, result.Code)
+			assert.Equal(t, tc.expected, result.Errors)
+		})
+	}
+}
+
+// nodeScript evaluates generated functions over rows read as JSON from stdin.
+// Datetimes are exchanged as {"$date": "<RFC 3339>"}; each result is {type, value} or {error}.
+const nodeScript = `
+const input = JSON.parse(require("fs").readFileSync(0, "utf8"), (key, value) =>
+  value !== null && typeof value === "object" && "$date" in value ? new Date(value.$date) : value);
+const typeOf = (v) => v === null ? "null" : v instanceof Date ? "datetime" : typeof v;
+const results = input.codes.map((code) => {
+  const fn = eval(code);
+  return input.rows.map((row) => {
+    try {
+      const value = fn(row);
+      return { type: typeOf(value), value: value instanceof Date ? value.toISOString() : value };
+    } catch (e) {
+      return { error: e.message };
+    }
+  });
+});
+process.stdout.write(JSON.stringify(results));
+`
+
+func TestAnalyzer_ToJavaScript_Node(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("Node.js is not available")
+	}
+
+	created := time.Date(2024, 3, 15, 18, 30, 0, 0, time.UTC)
+	rows := []map[string]any{
+		{"price": 19.5, "quantity": 4, "name": "  Widget  ", "active": true, "discount": 0.25, "created": created},
+		{"price": 3.0, "quantity": 7, "name": "it's ünïcode", "active": false, "discount": nil, "created": created.AddDate(1, -2, 20)},
+		{"price": -2.5, "quantity": 0, "name": "", "active": nil, "discount": 1.5, "created": nil},
+	}
+	expressions := []string{
+		"[price] * [quantity] - 1",
+		"2 ^ 3 ^ 2 + 10 - 4 - 3",
+		"-2 ^ 2 + 2 * -[price]",
+		"--[price]",
+		"[price] > 5 && [active] || [quantity] == 0",
+		"[active] && [discount] > 0",
+		"[active] || [price] / 0 > 1",
+		"[name] == 'it\\'s ünïcode'",
+		"UPPER(TRIM([name]))",
+		"CONCAT([name], '!', LOWER('AB'))",
+		"LENGTH([name]) + LEN('abc')",
+		"SUBSTRING([name], 3, 4)",
+		"REPLACE([name], 'i', 'I')",
+		"ROUND([price] / 3, 2) + ROUND(-2.5) + ROUND(1234, -2)",
+		"FLOOR([price]) + CEIL([price]) + ABS([price])",
+		"MIN([discount], [price], 2)",
+		"MAX([name], 'it')",
+		"MAX([created], DATE([created]))",
+		"SUM([price], [discount], 1)",
+		"AVG([price], [discount])",
+		"COUNT([price], [discount], [active])",
+		"IF([active], 'on', 'off')",
+		"IF([quantity] > 3, [price], [price] / 0)",
+		"COALESCE([discount], [price] * 2, [price] / 0)",
+		"YEAR([created]) * 10000 + MONTH([created]) * 100 + DAY([created])",
+		"DATE([created])",
+		"[created] < DATE([created])",
+		"[price] / [quantity]",
+		"[price] == [name]",
+		"[name] + 1",
+		"[missing]",
+		"2 ^ 2000",
+	}
+
+	analyzer := newAnalyzer()
+	codes := make([]string, len(expressions))
+	for i, expression := range expressions {
+		result := analyzer.ToJavaScript(expression)
+		require.Empty(t, result.Errors, expression)
+		codes[i] = result.Code
+	}
+
+	jsonRows := make([]map[string]any, len(rows))
+	for i, row := range rows {
+		jsonRows[i] = make(map[string]any, len(row))
+		for name, value := range row {
+			if datetime, ok := value.(time.Time); ok {
+				value = map[string]any{"$date": datetime.Format(time.RFC3339)}
+			}
+			jsonRows[i][name] = value
+		}
+	}
+	input, err := json.Marshal(map[string]any{"codes": codes, "rows": jsonRows})
+	require.NoError(t, err)
+
+	cmd := exec.Command(node, "-e", nodeScript)
+	cmd.Stdin = bytes.NewReader(input)
+	output, err := cmd.Output()
+	require.NoError(t, err)
+
+	var results [][]struct {
+		Type  string `json:"type"`
+		Value any    `json:"value"`
+		Error string `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(output, &results))
+
+	for i, expression := range expressions {
+		t.Run(expression, func(t *testing.T) {
+			for row, actual := range results[i] {
+				expected := analyzer.Evaluate(expression, rows[row])
+				if len(expected.Errors) > 0 {
+					assert.Equal(t, expected.Errors[0].Message, actual.Error, "row %d", row)
+					continue
+				}
+				require.Empty(t, actual.Error, "row %d", row)
+				if expected.Value.IsNull() {
+					assert.Equal(t, "null", actual.Type, "row %d", row)
+					continue
+				}
+				assert.Equal(t, string(expected.Value.Type()), actual.Type, "row %d", row)
+				switch expected.Value.Type() {
+				case models.DataTypeNumber:
+					assert.InDelta(t, expected.Value.Number(), actual.Value, 1e-9, "row %d", row)
+				case models.DataTypeDateTime:
+					datetime, err := time.Parse(time.RFC3339, actual.Value.(string))
+					require.NoError(t, err)
+					assert.True(t, expected.Value.DateTime().Equal(datetime), "row %d = %v, want %v", row, datetime, expected.Value.DateTime())
+				default:
+					assert.Equal(t, expected.Value.Interface(), actual.Value, "row %d", row)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzer_ToPandas(t *testing.T) {
+	analyzer := newAnalyzer()
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   string
+	}{
+		// Operators
+		{"column", `[unit"price]`, `df["unit\"price"]`},
+		{"string literal", `'it\'s'`, `"it's"`},
+		{"boolean literal", "[a] == true", `df["a"] == True`},
+		{"number literal", "007 + 1.50", "7 + 1.5"},
+		{"power", "[a] ^ 2", `df["a"] ** 2`},
+		{"right associative power", "2 ^ 3 ^ 2", "2 ** 3 ** 2"},
+		{"left grouped power", "(2 ^ 3) ^ 2", "(2 ** 3) ** 2"},
+		{"negated base", "-[a] ^ 2", `(-df["a"]) ** 2`},
+		{"negated power", "-([a] ^ 2)", `-df["a"] ** 2`},
+		{"negated exponent", "2 ^ -[a]", `2 ** (-df["a"])`},
+		{"precedence", "[a] + [b] * [c]", `df["a"] + df["b"] * df["c"]`},
+		{"parentheses", "([a] + [b]) * [c]", `(df["a"] + df["b"]) * df["c"]`},
+		{"left associative", "[a] - ([b] - [c])", `df["a"] - (df["b"] - df["c"])`},
+		{"negated sum", "-([a] + 1)", `-(df["a"] + 1)`},
+		{"and", "[a] > 1 && [b] < 2", `(df["a"] > 1) & (df["b"] < 2)`},
+		{"or of ands", "[a] && [b] || [c] && [d]", `df["a"] & df["b"] | df["c"] & df["d"]`},
+		{"and of ors", "([a] || [b]) && [c]", `(df["a"] | df["b"]) & df["c"]`},
+		{"comparison of comparison", "[a] < [b] == [c]", `(df["a"] < df["b"]) == df["c"]`},
+		{"comparison of and", "([a] && [b]) == [c]", `df["a"] & df["b"] == df["c"]`},
+		{"comparison operators", "[a] != 1 || [b] >= 2", `(df["a"] != 1) | (df["b"] >= 2)`},
+
+		// Functions
+		{"string method", "UPPER(TRIM([name]))", `df["name"].str.strip().str.upper()`},
+		{"string method of expression", "LOWER(CONCAT([a], 'x'))", `(df["a"] + "x").str.lower()`},
+		{"string method of scalar", "LEN('abc')", `pd.Series("abc", index=df.index).str.len()`},
+		{"substring", "SUBSTRING([name], 1, 3)", `df["name"].str.slice(1, 4)`},
+		{"replace", "REPLACE([name], 'a', 'b')", `df["name"].str.replace("a", "b", regex=False)`},
+		{"round", "ROUND([a])", `(np.sign(df["a"]) * np.floor(np.abs(df["a"]) + 0.5))`},
+		{"round decimals", "ROUND([a] / 3, 2)", `(np.sign(df["a"] / 3) * np.floor(np.abs(df["a"] / 3) * 100 + 0.5) / 100)`},
+		{"round tens", "ROUND([a], -1)", `(np.sign(df["a"]) * np.floor(np.abs(df["a"]) * 0.1 + 0.5) / 0.1)`},
+		{"numpy function", "FLOOR([a]) + CEIL(1.5) + ABS(-[a])", `np.floor(df["a"]) + np.ceil(1.5) + np.abs(-df["a"])`},
+		{"min", "MIN([a], 1, [b])", `np.fmin(np.fmin(df["a"], 1), df["b"])`},
+		{"max of one", "MAX([a]) * 2", `df["a"] * 2`},
+		{"sum", "SUM([a], 1)", `pd.concat([df["a"], pd.Series(1, index=df.index)], axis=1).sum(axis=1, min_count=1)`},
+		{"avg", "AVG([a], [b])", `pd.concat([df["a"], df["b"]], axis=1).mean(axis=1)`},
+		{"count", "COUNT([a], [b])", `pd.concat([df["a"], df["b"]], axis=1).count(axis=1)`},
+		{"count nothing", "COUNT()", `pd.Series(0, index=df.index)`},
+		{"if", "IF([a] > 1, [b], 0)", `df["b"].where((df["a"] > 1) == True, 0)`},
+		{"coalesce", "COALESCE([a], [b], 0)", `df["a"].fillna(df["b"]).fillna(0)`},
+		{"datetime", "YEAR([d]) > YEAR(NOW())", `df["d"].dt.year > pd.Series(pd.Timestamp.now(), index=df.index).dt.year`},
+		{"date", "DATE([d])", `df["d"].dt.normalize()`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.ToPandas(tc.expression)
+			require.Empty(t, result.Errors)
+			assert.Equal(t, tc.expected, result.Code)
+		})
+	}
+}
+
+func TestAnalyzer_ToPandas_Errors(t *testing.T) {
+	analyzer := newAnalyzer()
+	require.NoError(t, analyzer.RegisterFunction(&functions.Function{
+		FunctionSignature: models.FunctionSignature{Name: "FX_RATE", ReturnType: models.DataTypeNumber},
+	}))
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   []models.ErrorInfo
+	}{
+		{"empty", "", []models.ErrorInfo{{Message: "Empty expression", Line: 1, Column: 0, Start: 0, End: 0}}},
+		{"syntax error", "1 +", []models.ErrorInfo{{Message: "mismatched input '<EOF>' expecting {'-', '(', BOOLEAN_LITERAL, FLOAT_LITERAL, INTEGER_LITERAL, STRING_LITERAL, FUNCTION_NAME, COLUMN_REF}", Line: 1, Column: 3, Start: 3, End: 3}}},
+		{
+			"custom function", "[price] * FX_RATE()",
+			[]models.ErrorInfo{{Message: "Function FX_RATE cannot be translated to pandas", Line: 1, Column: 10, Start: 10, End: 19}},
+		},
+		{
+			"column substring bounds", "SUBSTRING([name], [start], 2)",
+			[]models.ErrorInfo{{Message: "Argument 'start' of SUBSTRING must be a constant to be translated to pandas", Line: 1, Column: 0, Start: 0, End: 29}},
+		},
+		{
+			"column replacement", "1 + LEN(REPLACE([name], 'a', [b]))",
+			[]models.ErrorInfo{{Message: "Argument 'replace' of REPLACE must be a constant to be translated to pandas", Line: 1, Column: 8, Start: 8, End: 33}},
+		},
+		{
+			"column decimals", "ROUND([price], [digits])",
+			[]models.ErrorInfo{{Message: "Argument 'decimals' of ROUND must be a constant to be translated to pandas", Line: 1, Column: 0, Start: 0, End: 24}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.ToPandas(tc.expression)
+			assert.Empty(t, result.Code)
+			assert.Equal(t, tc.expected, result.Errors)
+		})
+	}
+}
+
+// pythonScript evaluates generated expressions with df bound to rows of scalars read as JSON from stdin.
+// Without pandas this checks that operators group as in Evaluate; each result is {value} or {error}.
+const pythonScript = `
+import json, sys
+data = json.load(sys.stdin)
+results = []
+for code in data["codes"]:
+    outcomes = []
+    for row in data["rows"]:
+        try:
+            outcomes.append({"value": eval(code, {"df": row})})
+        except Exception as e:
+            outcomes.append({"error": type(e).__name__ + ": " + str(e)})
+    results.append(outcomes)
+json.dump(results, sys.stdout)
+`
+
+func TestAnalyzer_ToPandas_Python(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("Python is not available")
+	}
+
+	rows := []map[string]any{
+		{"a": 2.0, "b": 3.0, "c": -1.5, "p": true, "q": false},
+		{"a": -4.0, "b": 0.5, "c": 2.0, "p": false, "q": true},
+		{"a": 0.0, "b": -2.0, "c": 3.0, "p": true, "q": true},
+	}
+	expressions := []string{
+		"[a] + [b] * [c] - [a] / [b]",
+		"([a] + [b]) * -([c] - [a])",
+		"[a] - [b] - [c]",
+		"2 ^ 3 ^ 2 + [b] ^ 2 ^ [c]",
+		"-[a] ^ 2 + -2 ^ 2 - -([a] ^ 2)",
+		"(2 ^ [c]) ^ 2",
+		"2 ^ -[a]",
+		"[a] < [b] && [b] < [c] || [p]",
+		"[p] || [q] && [a] > 0",
+		"([p] || [q]) && [a] >= 0",
+		"[a] < [b] == [p]",
+		"[a] + 1 > [b] * 2 != ([c] <= 2)",
+		"[p] == [q] || [a] != [b] && [q]",
+		"([p] && [q]) == [p] || [q]",
+	}
+
+	analyzer := newAnalyzer()
+	codes := make([]string, len(expressions))
+	for i, expression := range expressions {
+		result := analyzer.ToPandas(expression)
+		require.Empty(t, result.Errors, expression)
+		codes[i] = result.Code
+	}
+	input, err := json.Marshal(map[string]any{"codes": codes, "rows": rows})
+	require.NoError(t, err)
+
+	cmd := exec.Command(python, "-c", pythonScript)
+	cmd.Stdin = bytes.NewReader(input)
+	output, err := cmd.Output()
+	require.NoError(t, err)
+
+	var results [][]struct {
+		Value any    `json:"value"`
+		Error string `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(output, &results))
+
+	for i, expression := range expressions {
+		t.Run(expression, func(t *testing.T) {
+			for row, actual := range results[i] {
+				expected := analyzer.Evaluate(expression, rows[row])
+				require.Empty(t, expected.Errors)
+				require.Empty(t, actual.Error, "row %d of %s", row, codes[i])
+				if expected.Value.Type() == models.DataTypeNumber {
+					assert.InDelta(t, expected.Value.Number(), actual.Value, 1e-9, "row %d of %s", row, codes[i])
+				} else {
+					assert.Equal(t, expected.Value.Interface(), actual.Value, "row %d of %s", row, codes[i])
+				}
+			}
+		})
+	}
+}
+
+// pandasScript evaluates generated expressions on a DataFrame built from columns read as JSON from stdin.
+// Each result holds one value per row, with missing values as null and timestamps formatted like time.DateTime.
+const pandasScript = `
+import json, sys
+import numpy as np
+import pandas as pd
+
+def plain(value):
+    if isinstance(value, pd.Timestamp):
+        return value.strftime("%Y-%m-%d %H:%M:%S")
+    if not isinstance(value, str) and pd.isna(value):
+        return None
+    return value.item() if hasattr(value, "item") else value
+
+data = json.load(sys.stdin)
+df = pd.DataFrame(data["columns"])
+for column in data["dates"]:
+    df[column] = pd.to_datetime(df[column])
+results = []
+for code in data["codes"]:
+    result = eval(code, {"df": df, "pd": pd, "np": np})
+    values = list(result) if isinstance(result, pd.Series) else [result] * len(df)
+    results.append([plain(value) for value in values])
+json.dump(results, sys.stdout)
+`
+
+// Golden tests: the generated pandas code evaluated on a DataFrame must compute what Evaluate computes
+func TestAnalyzer_ToPandas_DataFrame(t *testing.T) {
+	// CI installs pandas, so the golden tests must not be skipped there
+	skip := t.Skip
+	if os.Getenv("CI") != "" {
+		skip = t.Fatal
+	}
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		skip("Python is not available")
+	}
+	if err := exec.Command(python, "-c", "import pandas").Run(); err != nil {
+		skip("pandas is not available")
+	}
+
+	created := time.Date(2024, 3, 15, 18, 30, 0, 0, time.UTC)
+	rows := []map[string]any{
+		{"price": 19.5, "quantity": 4.0, "name": "  Widget  ", "active": true, "discount": 0.25, "created": created},
+		{"price": 3.0, "quantity": 7.0, "name": nil, "active": false, "discount": nil, "created": created.AddDate(1, -2, 20)},
+		{"price": nil, "quantity": 0.0, "name": "Gizmo", "active": true, "discount": nil, "created": nil},
+	}
+	columns := map[string][]any{}
+	for _, row := range rows {
+		for name, value := range row {
+			if c, ok := value.(time.Time); ok {
+				value = c.Format(time.DateTime)
+			}
+			columns[name] = append(columns[name], value)
+		}
+	}
+
+	expressions := []string{
+		"UPPER(TRIM([name]))",
+		"LEN([name]) + LENGTH('abc')",
+		"SUBSTRING([name], 1, 3)",
+		"REPLACE([name], 'i', 'I')",
+		"CONCAT([name], '!', LOWER('AB'))",
+		"SUM([price], [discount], 1)",
+		"SUM([price], [discount])",
+		"AVG([price], [discount])",
+		"COUNT([price], [discount], [quantity])",
+		"IF([quantity] > 3, [price], [discount])",
+		"IF([active], 'on', 'off')",
+		"COALESCE([discount], [price] * 2)",
+		"COALESCE([discount], [price], 0)",
+		"MIN([discount], [price])",
+		"MAX([discount], [quantity])",
+		"ROUND([quantity] / 3, 2) + ROUND(-2.5)",
+		"FLOOR([quantity] / 2) + CEIL([quantity] / 4) + ABS(-[quantity])",
+		"YEAR([created]) * 10000 + MONTH([created]) * 100 + DAY([created])",
+		"DATE([created])",
+	}
+
+	analyzer := newAnalyzer()
+	codes := make([]string, len(expressions))
+	for i, expression := range expressions {
+		result := analyzer.ToPandas(expression)
+		require.Empty(t, result.Errors, expression)
+		codes[i] = result.Code
+	}
+	input, err := json.Marshal(map[string]any{"codes": codes, "columns": columns, "dates": []string{"created"}})
+	require.NoError(t, err)
+
+	cmd := exec.Command(python, "-c", pandasScript)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	require.NoError(t, err, stderr.String())
+
+	var results [][]any
+	require.NoError(t, json.Unmarshal(output, &results))
+
+	for i, expression := range expressions {
+		t.Run(expression, func(t *testing.T) {
+			require.Len(t, results[i], len(rows))
+			for row, actual := range results[i] {
+				expected := analyzer.Evaluate(expression, rows[row])
+				require.Empty(t, expected.Errors)
+				switch {
+				case expected.Value.IsNull():
+					assert.Nil(t, actual, "row %d of %s", row, codes[i])
+				case expected.Value.Type() == models.DataTypeNumber:
+					assert.InDelta(t, expected.Value.Number(), actual, 1e-9, "row %d of %s", row, codes[i])
+				case expected.Value.Type() == models.DataTypeDateTime:
+					assert.Equal(t, expected.Value.DateTime().Format(time.DateTime), actual, "row %d of %s", row, codes[i])
+				default:
+					assert.Equal(t, expected.Value.Interface(), actual, "row %d of %s", row, codes[i])
+				}
+			}
+		})
+	}
+}
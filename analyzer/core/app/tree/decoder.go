@@ -0,0 +1,391 @@
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/core/app/eval"
+	"antlr-editor/analyzer/core/infrastructure"
+	"antlr-editor/analyzer/core/models"
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// binaryOperators lists the operators each binary expression node may have
+var binaryOperators = map[models.NodeType][]models.Operator{
+	models.NodeTypePowerExpr:      {models.OpPow},
+	models.NodeTypeMulDivExpr:     {models.OpMul, models.OpDiv},
+	models.NodeTypeAddSubExpr:     {models.OpAdd, models.OpSub},
+	models.NodeTypeComparisonExpr: {models.OpLt, models.OpLe, models.OpGt, models.OpGe, models.OpEq, models.OpNeq},
+	models.NodeTypeAndExpr:        {models.OpAnd},
+	models.NodeTypeOrExpr:         {models.OpOr},
+}
+
+// operatorTokens maps operator token node types to operators
+var operatorTokens = map[models.NodeType]models.Operator{
+	models.NodeTypeAdd: models.OpAdd,
+	models.NodeTypeSub: models.OpSub,
+	models.NodeTypeMul: models.OpMul,
+	models.NodeTypeDiv: models.OpDiv,
+	models.NodeTypePow: models.OpPow,
+	models.NodeTypeLt:  models.OpLt,
+	models.NodeTypeLe:  models.OpLe,
+	models.NodeTypeGt:  models.OpGt,
+	models.NodeTypeGe:  models.OpGe,
+	models.NodeTypeEq:  models.OpEq,
+	models.NodeTypeNeq: models.OpNeq,
+	models.NodeTypeOr:  models.OpOr,
+	models.NodeTypeAnd: models.OpAnd,
+}
+
+// literalTokens maps literal node types to the lexer token types their text must match
+var literalTokens = map[models.NodeType]int{
+	models.NodeTypeStringLiteral:  parser.ExpressionLexerSTRING_LITERAL,
+	models.NodeTypeIntegerLiteral: parser.ExpressionLexerINTEGER_LITERAL,
+	models.NodeTypeFloatLiteral:   parser.ExpressionLexerFLOAT_LITERAL,
+	models.NodeTypeBooleanLiteral: parser.ExpressionLexerBOOLEAN_LITERAL,
+}
+
+// Decoder converts parse trees serialized by ParseTreeNode.AsMap back into abstract syntax trees
+type Decoder struct {
+	helper   *infrastructure.ParserHelper
+	encoding models.PositionEncoding // Unit of the positions of nodes
+}
+
+// NewTreeDecoder creates a new parse tree decoder
+func NewTreeDecoder() *Decoder {
+	return &Decoder{helper: infrastructure.NewParserHelper()}
+}
+
+// SetPositionEncoding sets the unit in which the positions of nodes are counted, as set on the App whose
+// ParseTree serialized them; positions count code points by default
+func (d *Decoder) SetPositionEncoding(encoding models.PositionEncoding) {
+	d.encoding = encoding
+}
+
+// Decode validates a parse tree decoded from JSON and converts it into an abstract syntax tree.
+// Nodes must have the shape ParseTree produces: the node types, the number of children and the text of tokens
+// are checked against the grammar. The operator of a binary expression is taken from an operator node between
+// its operands if there is one, or else from the text of the node between the positions of its operands;
+// PowerExpr, AndExpr and OrExpr only have one operator each. Errors name the path of the offending node.
+func (d *Decoder) Decode(node map[string]any) (models.Expr, error) {
+	return d.decodeExpr(node, "tree")
+}
+
+// decodeExpr converts a node in expression position
+func (d *Decoder) decodeExpr(node map[string]any, path string) (models.Expr, error) {
+	nodeType, err := typeOf(node, path)
+	if err != nil {
+		return nil, err
+	}
+	children, err := childrenOf(node, path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch nodeType {
+	case models.NodeTypeExpression:
+		if err := checkArity(nodeType, children, path, 1); err != nil {
+			return nil, err
+		}
+		return d.decodeExpr(children[0], childPath(path, 0))
+
+	case models.NodeTypeLiteralExpr:
+		if err := checkArity(nodeType, children, path, 1); err != nil {
+			return nil, err
+		}
+		return d.decodeLiteral(children[0], childPath(path, 0))
+
+	case models.NodeTypeColumnRefExpr:
+		if err := checkArity(nodeType, children, path, 0); err != nil {
+			return nil, err
+		}
+		text, err := d.tokenText(node, path, parser.ExpressionLexerCOLUMN_REF, nodeType)
+		if err != nil {
+			return nil, err
+		}
+		return models.ColumnReference(text[1 : len(text)-1]), nil
+
+	case models.NodeTypeFunctionCallExpr:
+		// ParseTree unwraps FunctionCallExpr, but the wrapper of the grammar is accepted too
+		if err := checkArity(nodeType, children, path, 1); err != nil {
+			return nil, err
+		}
+		return d.decodeExpr(children[0], childPath(path, 0))
+
+	case models.NodeTypeFunctionCall:
+		return d.decodeCall(children, path)
+
+	case models.NodeTypeParenExpr:
+		if err := checkArity(nodeType, children, path, 1); err != nil {
+			return nil, err
+		}
+		inner, err := d.decodeExpr(children[0], childPath(path, 0))
+		if err != nil {
+			return nil, err
+		}
+		return &models.ParenExpr{Inner: inner}, nil
+
+	case models.NodeTypeUnaryMinusExpr:
+		if err := checkArity(nodeType, children, path, 1); err != nil {
+			return nil, err
+		}
+		operand, err := d.decodeExpr(children[0], childPath(path, 0))
+		if err != nil {
+			return nil, err
+		}
+		return models.Negate(operand), nil
+
+	case models.NodeTypePowerExpr, models.NodeTypeMulDivExpr, models.NodeTypeAddSubExpr,
+		models.NodeTypeComparisonExpr, models.NodeTypeAndExpr, models.NodeTypeOrExpr:
+		return d.decodeBinary(nodeType, node, children, path)
+	}
+
+	if nodeType < models.NodeTypeExpression || nodeType > models.NodeTypeEOF {
+		return nil, fmt.Errorf("%s: unknown node type %d", path, int(nodeType))
+	}
+	return nil, fmt.Errorf("%s: %s node cannot be used as an expression", path, nodeType)
+}
+
+// decodeLiteral converts the literal token of a LiteralExpr
+func (d *Decoder) decodeLiteral(node map[string]any, path string) (models.Expr, error) {
+	nodeType, err := typeOf(node, path)
+	if err != nil {
+		return nil, err
+	}
+	tokenType, ok := literalTokens[nodeType]
+	if !ok {
+		return nil, fmt.Errorf("%s: LiteralExpr must contain a StringLiteral, IntegerLiteral, FloatLiteral or BooleanLiteral node, got %s", path, nodeType)
+	}
+	text, err := d.tokenText(node, path, tokenType, nodeType)
+	if err != nil {
+		return nil, err
+	}
+
+	switch nodeType {
+	case models.NodeTypeStringLiteral:
+		return models.StringLiteral(eval.UnquoteString(text)), nil
+	case models.NodeTypeBooleanLiteral:
+		return models.BooleanLiteral(strings.EqualFold(text, "true")), nil
+	default:
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid number %q: %v", path, text, err)
+		}
+		return models.NumberLiteral(number), nil
+	}
+}
+
+// decodeCall converts a FunctionCall node: a FunctionName node, followed by an ArgumentList node unless there are no arguments
+func (d *Decoder) decodeCall(children []map[string]any, path string) (models.Expr, error) {
+	if len(children) != 1 && len(children) != 2 {
+		return nil, fmt.Errorf("%s: FunctionCall must have a FunctionName child and an optional ArgumentList child, got %d children", path, len(children))
+	}
+
+	namePath := childPath(path, 0)
+	if nodeType, err := typeOf(children[0], namePath); err != nil {
+		return nil, err
+	} else if nodeType != models.NodeTypeFunctionName {
+		return nil, fmt.Errorf("%s: FunctionCall must start with a FunctionName node, got %s", namePath, nodeType)
+	}
+	name, err := d.tokenText(children[0], namePath, parser.ExpressionLexerFUNCTION_NAME, models.NodeTypeFunctionName)
+	if err != nil {
+		return nil, err
+	}
+	if len(children) == 1 {
+		return models.FunctionCall(name), nil
+	}
+
+	listPath := childPath(path, 1)
+	if nodeType, err := typeOf(children[1], listPath); err != nil {
+		return nil, err
+	} else if nodeType != models.NodeTypeArgumentList {
+		return nil, fmt.Errorf("%s: FunctionCall arguments must be an ArgumentList node, got %s", listPath, nodeType)
+	}
+	arguments, err := childrenOf(children[1], listPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(arguments) == 0 {
+		return nil, fmt.Errorf("%s: ArgumentList must have at least 1 child; omit it for a call without arguments", listPath)
+	}
+
+	args := make([]models.Expr, len(arguments))
+	for i, argument := range arguments {
+		if args[i], err = d.decodeExpr(argument, childPath(listPath, i)); err != nil {
+			return nil, err
+		}
+	}
+	return models.FunctionCall(name, args...), nil
+}
+
+// decodeBinary converts a binary expression node with two operand children, or three with an operator node in the middle
+func (d *Decoder) decodeBinary(nodeType models.NodeType, node map[string]any, children []map[string]any, path string) (models.Expr, error) {
+	if len(children) != 2 && len(children) != 3 {
+		return nil, fmt.Errorf("%s: %s must have 2 operand children, or 3 with an operator node in the middle, got %d children", path, nodeType, len(children))
+	}
+
+	operands := []map[string]any{children[0], children[len(children)-1]}
+	op, err := d.binaryOperator(nodeType, node, children, path)
+	if err != nil {
+		return nil, err
+	}
+
+	left, err := d.decodeExpr(operands[0], childPath(path, 0))
+	if err != nil {
+		return nil, err
+	}
+	right, err := d.decodeExpr(operands[1], childPath(path, len(children)-1))
+	if err != nil {
+		return nil, err
+	}
+	return models.Binary(op, left, right), nil
+}
+
+// binaryOperator determines the operator of a binary expression node and checks that the node type allows it
+func (d *Decoder) binaryOperator(nodeType models.NodeType, node map[string]any, children []map[string]any, path string) (models.Operator, error) {
+	allowed := binaryOperators[nodeType]
+
+	var op models.Operator
+	switch {
+	case len(children) == 3:
+		opPath := childPath(path, 1)
+		opType, err := typeOf(children[1], opPath)
+		if err != nil {
+			return "", err
+		}
+		var ok bool
+		if op, ok = operatorTokens[opType]; !ok {
+			return "", fmt.Errorf("%s: the middle child of %s must be an operator node, got %s", opPath, nodeType, opType)
+		}
+	case len(allowed) == 1:
+		return allowed[0], nil
+	default:
+		text, ok := d.operatorText(node, children)
+		if !ok {
+			return "", fmt.Errorf("%s: cannot determine the operator of %s from its text; add an operator node between the operands", path, nodeType)
+		}
+		op = models.Operator(text)
+	}
+
+	for _, candidate := range allowed {
+		if op == candidate {
+			return op, nil
+		}
+	}
+	names := make([]string, len(allowed))
+	for i, candidate := range allowed {
+		names[i] = string(candidate)
+	}
+	return "", fmt.Errorf("%s: %s cannot have operator %q, expected one of %s", path, nodeType, op, strings.Join(names, " "))
+}
+
+// operatorText returns the text of a binary expression node between its operands, using the positions of the nodes
+// converted from the position encoding to code points of the node text
+func (d *Decoder) operatorText(node map[string]any, children []map[string]any) (string, bool) {
+	text, _ := node["text"].(string)
+	start, ok1 := intField(node, "start")
+	leftEnd, ok2 := intField(children[0], "end")
+	rightStart, ok3 := intField(children[1], "start")
+	if !ok1 || !ok2 || !ok3 {
+		return "", false
+	}
+
+	runes := []rune(text)
+	from, to := leftEnd-start, rightStart-start
+	if from < 0 || from > to {
+		return "", false
+	}
+	positions := models.NewPositionMap(text, d.encoding)
+	from, to = positions.CodePoint(from), positions.CodePoint(to)
+	if to > len(runes) {
+		return "", false
+	}
+	op := strings.TrimSpace(string(runes[from:to]))
+	return op, op != ""
+}
+
+// tokenText returns the text of a token node after checking that the lexer reads it as exactly one token of the given type
+func (d *Decoder) tokenText(node map[string]any, path string, tokenType int, nodeType models.NodeType) (string, error) {
+	value, ok := node["text"]
+	if !ok {
+		return "", fmt.Errorf("%s: %s node must have a text", path, nodeType)
+	}
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s: text must be a string", path)
+	}
+
+	lexer := d.helper.CreateLexer(text)
+	token := lexer.NextToken()
+	if token.GetTokenType() != tokenType || token.GetChannel() != antlr.TokenDefaultChannel || lexer.NextToken().GetTokenType() != antlr.TokenEOF {
+		return "", fmt.Errorf("%s: invalid %s text %q", path, nodeType, text)
+	}
+	return text, nil
+}
+
+// typeOf returns the node type of a node
+func typeOf(node map[string]any, path string) (models.NodeType, error) {
+	if _, ok := node["type"]; !ok {
+		return 0, fmt.Errorf("%s: node must have a type", path)
+	}
+	value, ok := intField(node, "type")
+	if !ok {
+		return 0, fmt.Errorf("%s: type must be an integer node type", path)
+	}
+	return models.NodeType(value), nil
+}
+
+// childrenOf returns the children of a node; a missing or null children field means no children
+func childrenOf(node map[string]any, path string) ([]map[string]any, error) {
+	value, ok := node["children"]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: children must be an array", path)
+	}
+
+	children := make([]map[string]any, len(list))
+	for i, item := range list {
+		if children[i], ok = item.(map[string]any); !ok {
+			return nil, fmt.Errorf("%s: node must be an object", childPath(path, i))
+		}
+	}
+	return children, nil
+}
+
+// checkArity checks the number of children of a node
+func checkArity(nodeType models.NodeType, children []map[string]any, path string, want int) error {
+	if len(children) == want {
+		return nil
+	}
+	plural := "children"
+	if want == 1 {
+		plural = "child"
+	}
+	return fmt.Errorf("%s: %s must have %d %s, got %d", path, nodeType, want, plural, len(children))
+}
+
+// intField returns an integer field of a node decoded from JSON, as json.Number or float64
+func intField(node map[string]any, key string) (int, bool) {
+	switch value := node[key].(type) {
+	case json.Number:
+		n, err := strconv.Atoi(value.String())
+		return n, err == nil
+	case float64:
+		return int(value), value == float64(int(value))
+	case int:
+		return value, true
+	}
+	return 0, false
+}
+
+// childPath returns the path of the i-th child of the node at path
+func childPath(path string, i int) string {
+	return fmt.Sprintf("%s.children[%d]", path, i)
+}
@@ -11,13 +11,13 @@ import (
 // Visitor implements the ANTLR visitor pattern for building parse trees
 type Visitor struct {
 	parser.BaseExpressionVisitor
-	input string
+	input []rune // Token positions are code point offsets
 }
 
 // NewParseTreeVisitor creates a new parse tree visitor
 func NewParseTreeVisitor(input string) *Visitor {
 	return &Visitor{
-		input: input,
+		input: []rune(input),
 	}
 }
 
@@ -67,7 +67,7 @@ func (v *Visitor) VisitErrorNode(node antlr.ErrorNode) interface{} {
 func (v *Visitor) VisitLiteralExpr(ctx *parser.LiteralExprContext) interface{} {
 	start := ctx.GetStart().GetStart()
 	end := ctx.GetStop().GetStop() + 1
-	text := v.input[start:end]
+	text := string(v.input[start:end])
 
 	children := []models.ParseTreeNode{}
 	if literalNode := v.Visit(ctx.Literal()); literalNode != nil {
@@ -89,7 +89,7 @@ func (v *Visitor) VisitLiteralExpr(ctx *parser.LiteralExprContext) interface{} {
 func (v *Visitor) VisitColumnRefExpr(ctx *parser.ColumnRefExprContext) interface{} {
 	start := ctx.GetStart().GetStart()
 	end := ctx.GetStop().GetStop() + 1
-	text := v.input[start:end]
+	text := string(v.input[start:end])
 
 	return &models.ParseTreeNode{
 		Type:     models.NodeTypeColumnRefExpr,
@@ -112,7 +112,7 @@ func (v *Visitor) VisitFunctionCallExpr(ctx *parser.FunctionCallExprContext) int
 func (v *Visitor) VisitParenExpr(ctx *parser.ParenExprContext) interface{} {
 	start := ctx.GetStart().GetStart()
 	end := ctx.GetStop().GetStop() + 1
-	text := v.input[start:end]
+	text := string(v.input[start:end])
 
 	children := []models.ParseTreeNode{}
 	if expr := ctx.Expression(); expr != nil {
@@ -136,7 +136,7 @@ func (v *Visitor) VisitParenExpr(ctx *parser.ParenExprContext) interface{} {
 func (v *Visitor) VisitUnaryMinusExpr(ctx *parser.UnaryMinusExprContext) interface{} {
 	start := ctx.GetStart().GetStart()
 	end := ctx.GetStop().GetStop() + 1
-	text := v.input[start:end]
+	text := string(v.input[start:end])
 
 	children := []models.ParseTreeNode{}
 	if expr := ctx.Expression(); expr != nil {
@@ -160,7 +160,7 @@ func (v *Visitor) VisitUnaryMinusExpr(ctx *parser.UnaryMinusExprContext) interfa
 func (v *Visitor) VisitPowerExpr(ctx *parser.PowerExprContext) interface{} {
 	start := ctx.GetStart().GetStart()
 	end := ctx.GetStop().GetStop() + 1
-	text := v.input[start:end]
+	text := string(v.input[start:end])
 
 	children := []models.ParseTreeNode{}
 	for _, expr := range ctx.AllExpression() {
@@ -184,7 +184,7 @@ func (v *Visitor) VisitPowerExpr(ctx *parser.PowerExprContext) interface{} {
 func (v *Visitor) VisitMulDivExpr(ctx *parser.MulDivExprContext) interface{} {
 	start := ctx.GetStart().GetStart()
 	end := ctx.GetStop().GetStop() + 1
-	text := v.input[start:end]
+	text := string(v.input[start:end])
 
 	children := []models.ParseTreeNode{}
 	for _, expr := range ctx.AllExpression() {
@@ -208,7 +208,7 @@ func (v *Visitor) VisitMulDivExpr(ctx *parser.MulDivExprContext) interface{} {
 func (v *Visitor) VisitAddSubExpr(ctx *parser.AddSubExprContext) interface{} {
 	start := ctx.GetStart().GetStart()
 	end := ctx.GetStop().GetStop() + 1
-	text := v.input[start:end]
+	text := string(v.input[start:end])
 
 	children := []models.ParseTreeNode{}
 	for _, expr := range ctx.AllExpression() {
@@ -232,7 +232,7 @@ func (v *Visitor) VisitAddSubExpr(ctx *parser.AddSubExprContext) interface{} {
 func (v *Visitor) VisitComparisonExpr(ctx *parser.ComparisonExprContext) interface{} {
 	start := ctx.GetStart().GetStart()
 	end := ctx.GetStop().GetStop() + 1
-	text := v.input[start:end]
+	text := string(v.input[start:end])
 
 	children := []models.ParseTreeNode{}
 	for _, expr := range ctx.AllExpression() {
@@ -256,7 +256,7 @@ func (v *Visitor) VisitComparisonExpr(ctx *parser.ComparisonExprContext) interfa
 func (v *Visitor) VisitAndExpr(ctx *parser.AndExprContext) interface{} {
 	start := ctx.GetStart().GetStart()
 	end := ctx.GetStop().GetStop() + 1
-	text := v.input[start:end]
+	text := string(v.input[start:end])
 
 	children := []models.ParseTreeNode{}
 	for _, expr := range ctx.AllExpression() {
@@ -280,7 +280,7 @@ func (v *Visitor) VisitAndExpr(ctx *parser.AndExprContext) interface{} {
 func (v *Visitor) VisitOrExpr(ctx *parser.OrExprContext) interface{} {
 	start := ctx.GetStart().GetStart()
 	end := ctx.GetStop().GetStop() + 1
-	text := v.input[start:end]
+	text := string(v.input[start:end])
 
 	children := []models.ParseTreeNode{}
 	for _, expr := range ctx.AllExpression() {
@@ -304,7 +304,7 @@ func (v *Visitor) VisitOrExpr(ctx *parser.OrExprContext) interface{} {
 func (v *Visitor) VisitLiteral(ctx *parser.LiteralContext) interface{} {
 	start := ctx.GetStart().GetStart()
 	end := ctx.GetStop().GetStop() + 1
-	text := v.input[start:end]
+	text := string(v.input[start:end])
 
 	var nodeType models.NodeType
 	if ctx.STRING_LITERAL() != nil {
@@ -332,7 +332,7 @@ func (v *Visitor) VisitLiteral(ctx *parser.LiteralContext) interface{} {
 func (v *Visitor) VisitColumnReference(ctx *parser.ColumnReferenceContext) interface{} {
 	start := ctx.GetStart().GetStart()
 	end := ctx.GetStop().GetStop() + 1
-	text := v.input[start:end]
+	text := string(v.input[start:end])
 
 	return &models.ParseTreeNode{
 		Type:     models.NodeTypeColumnReference,
@@ -347,7 +347,7 @@ func (v *Visitor) VisitColumnReference(ctx *parser.ColumnReferenceContext) inter
 func (v *Visitor) VisitFunctionCall(ctx *parser.FunctionCallContext) interface{} {
 	start := ctx.GetStart().GetStart()
 	end := ctx.GetStop().GetStop() + 1
-	text := v.input[start:end]
+	text := string(v.input[start:end])
 
 	children := []models.ParseTreeNode{}
 
@@ -385,7 +385,7 @@ func (v *Visitor) VisitFunctionCall(ctx *parser.FunctionCallContext) interface{}
 func (v *Visitor) VisitArgumentList(ctx *parser.ArgumentListContext) interface{} {
 	start := ctx.GetStart().GetStart()
 	end := ctx.GetStop().GetStop() + 1
-	text := v.input[start:end]
+	text := string(v.input[start:end])
 
 	children := []models.ParseTreeNode{}
 	for _, expr := range ctx.AllExpression() {
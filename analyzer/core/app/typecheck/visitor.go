@@ -0,0 +1,233 @@
+package typecheck
+
+import (
+	"fmt"
+
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/core/app/functions"
+	"antlr-editor/analyzer/core/infrastructure"
+	"antlr-editor/analyzer/core/models"
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// Visitor infers the static type of every expression node and collects type mismatches
+type Visitor struct {
+	*parser.BaseExpressionVisitor
+	registry *functions.Registry
+	schema   *models.Schema
+	errors   []models.ErrorInfo
+}
+
+// NewTypeCheckVisitor creates a new type check visitor resolving function calls against the given registry.
+// Column types are taken from schema; without a schema every column is treated as any.
+func NewTypeCheckVisitor(registry *functions.Registry, schema *models.Schema) *Visitor {
+	return &Visitor{
+		BaseExpressionVisitor: &parser.BaseExpressionVisitor{},
+		registry:              registry,
+		schema:                schema,
+		errors:                make([]models.ErrorInfo, 0),
+	}
+}
+
+// Errors returns the type errors collected while visiting
+func (v *Visitor) Errors() []models.ErrorInfo {
+	return v.errors
+}
+
+// Visit visits a parse tree node and returns its inferred models.DataType
+func (v *Visitor) Visit(tree antlr.ParseTree) any {
+	if tree == nil {
+		return models.DataTypeAny
+	}
+	if result, ok := tree.Accept(v).(models.DataType); ok {
+		return result
+	}
+	return models.DataTypeAny
+}
+
+// typeOf visits the given expression and returns its inferred type.
+// Missing operands of partially parsed trees are treated as any.
+func (v *Visitor) typeOf(expr parser.IExpressionContext) models.DataType {
+	if expr == nil {
+		return models.DataTypeAny
+	}
+	return v.Visit(expr).(models.DataType)
+}
+
+// addError records a type error located on the span of the given context
+func (v *Visitor) addError(ctx antlr.ParserRuleContext, message string) {
+	if err, ok := infrastructure.ErrorAt(ctx, message); ok {
+		v.errors = append(v.errors, err)
+	}
+}
+
+// expectOperand checks that the operand has the expected type and reports an error on the operand otherwise
+func (v *Visitor) expectOperand(operand parser.IExpressionContext, actual, expected models.DataType, operator string) {
+	if operand == nil || actual.IsAssignableTo(expected) {
+		return
+	}
+	v.addError(operand, fmt.Sprintf("Operator '%s' expects %s operands, got %s", operator, expected, actual))
+}
+
+// VisitLiteralExpr infers the type of a literal expression
+func (v *Visitor) VisitLiteralExpr(ctx *parser.LiteralExprContext) any {
+	return v.Visit(ctx.Literal())
+}
+
+// VisitLiteral infers the type of a literal value
+func (v *Visitor) VisitLiteral(ctx *parser.LiteralContext) any {
+	switch {
+	case ctx.STRING_LITERAL() != nil:
+		return models.DataTypeString
+	case ctx.INTEGER_LITERAL() != nil, ctx.FLOAT_LITERAL() != nil:
+		return models.DataTypeNumber
+	case ctx.BOOLEAN_LITERAL() != nil:
+		return models.DataTypeBoolean
+	default:
+		return models.DataTypeAny
+	}
+}
+
+// VisitColumnRefExpr infers the type of a column reference expression
+func (v *Visitor) VisitColumnRefExpr(ctx *parser.ColumnRefExprContext) any {
+	return v.Visit(ctx.ColumnReference())
+}
+
+// VisitColumnReference infers the type of a column reference from the schema.
+// Unknown columns are reported separately by the analyzer and treated as any here.
+func (v *Visitor) VisitColumnReference(ctx *parser.ColumnReferenceContext) any {
+	if v.schema == nil {
+		return models.DataTypeAny
+	}
+	text := ctx.COLUMN_REF().GetText()
+	if column, ok := v.schema.Lookup(text[1 : len(text)-1]); ok {
+		return column.Type
+	}
+	return models.DataTypeAny
+}
+
+// VisitFunctionCallExpr infers the type of a function call expression
+func (v *Visitor) VisitFunctionCallExpr(ctx *parser.FunctionCallExprContext) any {
+	return v.Visit(ctx.FunctionCall())
+}
+
+// VisitFunctionCall checks a function call against its registered signature and returns its return type.
+// Arguments are always visited so that errors nested inside them are reported.
+func (v *Visitor) VisitFunctionCall(ctx *parser.FunctionCallContext) any {
+	var args []parser.IExpressionContext
+	if argList := ctx.ArgumentList(); argList != nil {
+		args = argList.AllExpression()
+	}
+	argTypes := make([]models.DataType, len(args))
+	for i, arg := range args {
+		argTypes[i] = v.typeOf(arg)
+	}
+
+	name := ctx.FUNCTION_NAME().GetText()
+	fn, ok := v.registry.Lookup(name)
+	if !ok {
+		v.addError(ctx, fmt.Sprintf("Unknown function: %s", name))
+		return models.DataTypeAny
+	}
+
+	if message := functions.ArityMessage(&fn.FunctionSignature, len(args)); message != "" {
+		if argList := ctx.ArgumentList(); argList != nil {
+			v.addError(argList, message)
+		} else {
+			v.addError(ctx, message)
+		}
+		return fn.ReturnType
+	}
+
+	for i, arg := range args {
+		param := fn.ParameterAt(i)
+		if param == nil || argTypes[i].IsAssignableTo(param.Type) {
+			continue
+		}
+		v.addError(arg, functions.ArgumentTypeMessage(&fn.FunctionSignature, param, argTypes[i]))
+	}
+	return fn.ReturnType
+}
+
+// VisitArgumentList visits the arguments of a function call
+func (v *Visitor) VisitArgumentList(ctx *parser.ArgumentListContext) any {
+	for _, arg := range ctx.AllExpression() {
+		v.typeOf(arg)
+	}
+	return models.DataTypeAny
+}
+
+// VisitParenExpr infers the type of a parenthesized expression
+func (v *Visitor) VisitParenExpr(ctx *parser.ParenExprContext) any {
+	return v.typeOf(ctx.Expression())
+}
+
+// VisitUnaryMinusExpr checks that the negated operand is a number
+func (v *Visitor) VisitUnaryMinusExpr(ctx *parser.UnaryMinusExprContext) any {
+	operand := ctx.Expression()
+	v.expectOperand(operand, v.typeOf(operand), models.DataTypeNumber, "-")
+	return models.DataTypeNumber
+}
+
+// VisitPowerExpr checks an exponentiation
+func (v *Visitor) VisitPowerExpr(ctx *parser.PowerExprContext) any {
+	return v.visitBinaryExpression(ctx, models.DataTypeNumber, models.DataTypeNumber)
+}
+
+// VisitMulDivExpr checks a multiplication/division
+func (v *Visitor) VisitMulDivExpr(ctx *parser.MulDivExprContext) any {
+	return v.visitBinaryExpression(ctx, models.DataTypeNumber, models.DataTypeNumber)
+}
+
+// VisitAddSubExpr checks an addition/subtraction
+func (v *Visitor) VisitAddSubExpr(ctx *parser.AddSubExprContext) any {
+	return v.visitBinaryExpression(ctx, models.DataTypeNumber, models.DataTypeNumber)
+}
+
+// VisitAndExpr checks a logical AND
+func (v *Visitor) VisitAndExpr(ctx *parser.AndExprContext) any {
+	return v.visitBinaryExpression(ctx, models.DataTypeBoolean, models.DataTypeBoolean)
+}
+
+// VisitOrExpr checks a logical OR
+func (v *Visitor) VisitOrExpr(ctx *parser.OrExprContext) any {
+	return v.visitBinaryExpression(ctx, models.DataTypeBoolean, models.DataTypeBoolean)
+}
+
+// VisitComparisonExpr checks a comparison.
+// Both operands must share a type; ordering operators additionally reject booleans.
+func (v *Visitor) VisitComparisonExpr(ctx *parser.ComparisonExprContext) any {
+	left, right := ctx.Expression(0), ctx.Expression(1)
+	leftType, rightType := v.typeOf(left), v.typeOf(right)
+	operator := infrastructure.OperatorText(ctx)
+
+	if ctx.EQ() == nil && ctx.NEQ() == nil {
+		if leftType == models.DataTypeBoolean {
+			v.addError(left, fmt.Sprintf("Operator '%s' cannot be applied to boolean operands", operator))
+			return models.DataTypeBoolean
+		}
+		if rightType == models.DataTypeBoolean {
+			if right != nil {
+				v.addError(right, fmt.Sprintf("Operator '%s' cannot be applied to boolean operands", operator))
+			}
+			return models.DataTypeBoolean
+		}
+	}
+
+	if right != nil && !rightType.IsAssignableTo(leftType) {
+		v.addError(right, fmt.Sprintf("Cannot compare %s with %s using '%s'", leftType, rightType, operator))
+	}
+	return models.DataTypeBoolean
+}
+
+// visitBinaryExpression checks that both operands have the operand type and returns the result type
+func (v *Visitor) visitBinaryExpression(ctx infrastructure.BinaryExpressionContext, operandType, resultType models.DataType) models.DataType {
+	left, right := ctx.Expression(0), ctx.Expression(1)
+	operator := infrastructure.OperatorText(ctx)
+
+	v.expectOperand(left, v.typeOf(left), operandType, operator)
+	v.expectOperand(right, v.typeOf(right), operandType, operator)
+
+	return resultType
+}
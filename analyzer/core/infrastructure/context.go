@@ -0,0 +1,53 @@
+package infrastructure
+
+import (
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/core/models"
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// BinaryExpressionContext is implemented by the contexts of binary expressions,
+// whose children are the left operand, the operator token and the right operand
+type BinaryExpressionContext interface {
+	antlr.ParserRuleContext
+	Expression(i int) parser.IExpressionContext
+}
+
+// OperatorText returns the text of the operator token of a binary expression
+func OperatorText(ctx antlr.ParserRuleContext) string {
+	for _, child := range ctx.GetChildren() {
+		if terminal, ok := child.(antlr.TerminalNode); ok {
+			return terminal.GetText()
+		}
+	}
+	return ""
+}
+
+// ContextTokens returns the first and last tokens of the context; the last token is the first one for an empty
+// context, and both are nil for a context without tokens
+func ContextTokens(ctx antlr.ParserRuleContext) (antlr.Token, antlr.Token) {
+	start, stop := ctx.GetStart(), ctx.GetStop()
+	if start == nil {
+		return nil, nil
+	}
+	if stop == nil || stop.GetStop() < start.GetStart() {
+		stop = start
+	}
+	return start, stop
+}
+
+// ErrorAt creates an error located on the span of the given context, or returns false if the context has no tokens
+func ErrorAt(ctx antlr.ParserRuleContext, message string) (models.ErrorInfo, bool) {
+	start, stop := ContextTokens(ctx)
+	if start == nil {
+		return models.ErrorInfo{}, false
+	}
+	return models.ErrorInfo{
+		Message: message,
+		Line:    start.GetLine(),
+		Column:  start.GetColumn(),
+		Start:   start.GetStart(),
+		End:     stop.GetStop() + 1,
+	}, true
+}
@@ -1,6 +1,8 @@
 package infrastructure
 
 import (
+	"slices"
+
 	"github.com/antlr4-go/antlr/v4"
 
 	"antlr-editor/analyzer/core/models"
@@ -68,3 +70,68 @@ func (c *CollectingErrorListener) SyntaxError(recognizer antlr.Recognizer, offen
 	errorInfo := c.ExtractErrorInfo(recognizer, offendingSymbol, line, column, msg, e)
 	*c.Errors = append(*c.Errors, errorInfo)
 }
+
+// ExpectedTokensListener records the token types the parser accepts at the end of the input, in ascending order.
+// At the first error at the end it records the tokens the parser expected; later ones are reported while
+// recovering and expect less. ExpectedTokensStrategy adds the tokens accepted where an expression may also end.
+type ExpectedTokensListener struct {
+	BaseErrorListener
+	Expected []int // Token types the parser accepted at the end of the input, ascending
+	atEnd    bool
+}
+
+// NewExpectedTokensListener creates a new expected tokens listener
+func NewExpectedTokensListener() *ExpectedTokensListener {
+	return &ExpectedTokensListener{
+		BaseErrorListener: BaseErrorListener{DefaultErrorListener: &antlr.DefaultErrorListener{}},
+	}
+}
+
+// SyntaxError is called when a syntax error is encountered
+func (l *ExpectedTokensListener) SyntaxError(recognizer antlr.Recognizer, offendingSymbol any, line, column int, msg string, e antlr.RecognitionException) {
+	token, ok := offendingSymbol.(antlr.Token)
+	if !ok || token.GetTokenType() != antlr.TokenEOF || l.atEnd {
+		return
+	}
+	if p, ok := recognizer.(antlr.Parser); ok {
+		l.atEnd = true
+		l.record(p.GetExpectedTokens())
+	}
+}
+
+// record adds the token types of set to Expected, keeping it sorted and free of duplicates
+func (l *ExpectedTokensListener) record(set *antlr.IntervalSet) {
+	for _, interval := range set.GetIntervals() {
+		for tokenType := interval.Start; tokenType < interval.Stop; tokenType++ {
+			if tokenType == antlr.TokenEOF || tokenType == antlr.TokenEpsilon {
+				continue
+			}
+			if i, found := slices.BinarySearch(l.Expected, tokenType); !found {
+				l.Expected = slices.Insert(l.Expected, i, tokenType)
+			}
+		}
+	}
+}
+
+// ExpectedTokensStrategy wraps the error strategy of a parser to record the tokens its ATN accepts at each
+// decision reached at the end of the input. Generated code syncs before every loop and optional block, so
+// where an expression may end, as after an operand, the tokens that would continue it are recorded without
+// any error being reported.
+type ExpectedTokensStrategy struct {
+	antlr.ErrorStrategy
+	listener *ExpectedTokensListener
+}
+
+// NewExpectedTokensStrategy creates a strategy wrapping strategy that records expected tokens in listener
+func NewExpectedTokensStrategy(strategy antlr.ErrorStrategy, listener *ExpectedTokensListener) *ExpectedTokensStrategy {
+	return &ExpectedTokensStrategy{ErrorStrategy: strategy, listener: listener}
+}
+
+// Sync records the tokens expected at the current ATN state when the input is at its end, then syncs.
+// Nothing is recorded while recovering from an error: the enclosing rules then resume after an incomplete one.
+func (s *ExpectedTokensStrategy) Sync(recognizer antlr.Parser) {
+	if recognizer.GetTokenStream().LA(1) == antlr.TokenEOF && !s.InErrorRecoveryMode(recognizer) {
+		s.listener.record(recognizer.GetExpectedTokens())
+	}
+	s.ErrorStrategy.Sync(recognizer)
+}
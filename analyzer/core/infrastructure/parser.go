@@ -9,7 +9,7 @@ import (
 // ParserContext holds the components needed for parsing
 type ParserContext struct {
 	Input  *antlr.InputStream
-	Lexer  *parser.ExpressionLexer
+	Lexer  *parser.ExpressionLexer // nil when parsing tokens lexed earlier
 	Stream *antlr.CommonTokenStream
 	Parser *parser.ExpressionParser
 }
@@ -57,12 +57,16 @@ func (h *ParserHelper) CreateParser(expression string) *ParserContext {
 func (h *ParserHelper) SetupErrorListeners(ctx *ParserContext, errorListener antlr.ErrorListener) {
 	// Remove default error listeners to prevent console output
 	ctx.Parser.RemoveErrorListeners()
-	ctx.Lexer.RemoveErrorListeners()
+	if ctx.Lexer != nil {
+		ctx.Lexer.RemoveErrorListeners()
+	}
 
 	// Add custom error listener
 	if errorListener != nil {
 		ctx.Parser.AddErrorListener(errorListener)
-		ctx.Lexer.AddErrorListener(errorListener)
+		if ctx.Lexer != nil {
+			ctx.Lexer.AddErrorListener(errorListener)
+		}
 	}
 }
 
@@ -76,3 +80,10 @@ func (h *ParserHelper) IsAllTokensConsumed(ctx *ParserContext) bool {
 	token := ctx.Parser.GetCurrentToken()
 	return token.GetTokenType() == antlr.TokenEOF
 }
+
+// SetupExpectedTokens makes the parser record in listener the token types that may legally follow its input:
+// those expected at the first error at the end and those its ATN accepts at the decisions reached there.
+// The listener must have been set up with SetupErrorListeners; one parse then gives the whole set.
+func (h *ParserHelper) SetupExpectedTokens(ctx *ParserContext, listener *ExpectedTokensListener) {
+	ctx.Parser.SetErrorHandler(NewExpectedTokensStrategy(ctx.Parser.GetErrorHandler(), listener))
+}
@@ -0,0 +1,53 @@
+package infrastructure
+
+import (
+	"github.com/antlr4-go/antlr/v4"
+
+	"antlr-editor/analyzer/gen/parser"
+)
+
+// TokenListSource feeds a parser tokens lexed earlier instead of lexing the input again.
+// The tokens keep the source they were lexed from, so tokens the parser conjures while recovering from errors
+// are created as they would be by the lexer.
+type TokenListSource struct {
+	*antlr.BaseLexer
+	tokens []antlr.Token
+	index  int
+}
+
+// NewTokenListSource creates a source of the tokens of input, which must end with EOF
+func NewTokenListSource(input antlr.CharStream, tokens []antlr.Token) *TokenListSource {
+	return &TokenListSource{BaseLexer: antlr.NewBaseLexer(input), tokens: tokens}
+}
+
+// NextToken returns the next token of the list, and EOF again once the list is exhausted
+func (s *TokenListSource) NextToken() antlr.Token {
+	token := s.tokens[s.index]
+	if s.index < len(s.tokens)-1 {
+		s.index++
+	}
+	return token
+}
+
+// GetLine returns the line of the next token
+func (s *TokenListSource) GetLine() int {
+	return s.tokens[s.index].GetLine()
+}
+
+// GetCharPositionInLine returns the column of the next token
+func (s *TokenListSource) GetCharPositionInLine() int {
+	return s.tokens[s.index].GetColumn()
+}
+
+// CreateParserFromTokens creates a parser context that parses the tokens of the expression without lexing it.
+// The tokens are from all channels in input order and end with EOF; the context has no lexer.
+func (h *ParserHelper) CreateParserFromTokens(expression string, tokens []antlr.Token) *ParserContext {
+	input := antlr.NewInputStream(expression)
+	stream := antlr.NewCommonTokenStream(NewTokenListSource(input, tokens), antlr.TokenDefaultChannel)
+
+	return &ParserContext{
+		Input:  input,
+		Stream: stream,
+		Parser: parser.NewExpressionParser(stream),
+	}
+}
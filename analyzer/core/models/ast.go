@@ -0,0 +1,403 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Span is the position of a node in the source, as code point offsets like ParseTreeNode.
+// Nodes built in code have a zero Span.
+type Span struct {
+	Start int `json:"start"` // Start position in the input
+	End   int `json:"end"`   // End position in the input (exclusive)
+}
+
+// Position returns the span; it lets every node embedding a Span implement Expr
+func (s Span) Position() Span {
+	return s
+}
+
+// Expr is a node of the abstract syntax tree of an expression.
+// The implementations are *Literal, *ColumnRef, *Call, *UnaryExpr, *BinaryExpr and *ParenExpr.
+type Expr interface {
+	// Position returns the span of the node in the source it was parsed from
+	Position() Span
+	// AsMap converts the node and its descendants to a map for JSON serialization
+	AsMap() map[string]any
+	exprNode()
+}
+
+// LiteralKind is the kind of value a literal denotes
+type LiteralKind string
+
+const (
+	LiteralNumber  LiteralKind = "number"  // Value is a float64
+	LiteralString  LiteralKind = "string"  // Value is a string, without quotes or escapes
+	LiteralBoolean LiteralKind = "boolean" // Value is a bool
+)
+
+// Operator is a unary or binary operator, written as in expressions
+type Operator string
+
+const (
+	OpAdd Operator = "+"
+	OpSub Operator = "-" // Subtraction, or negation in a UnaryExpr
+	OpMul Operator = "*"
+	OpDiv Operator = "/"
+	OpPow Operator = "^"
+	OpLt  Operator = "<"
+	OpLe  Operator = "<="
+	OpGt  Operator = ">"
+	OpGe  Operator = ">="
+	OpEq  Operator = "=="
+	OpNeq Operator = "!="
+	OpAnd Operator = "&&"
+	OpOr  Operator = "||"
+)
+
+// Operator precedence, from loosest to tightest binding
+const (
+	PrecedenceOr = iota + 1
+	PrecedenceAnd
+	PrecedenceComparison
+	PrecedenceAdditive
+	PrecedenceMultiplicative
+	PrecedencePower
+	PrecedenceUnary
+	PrecedencePrimary
+)
+
+// Precedence returns the binding strength of a binary operator, or 0 if the operator is unknown
+func (op Operator) Precedence() int {
+	switch op {
+	case OpOr:
+		return PrecedenceOr
+	case OpAnd:
+		return PrecedenceAnd
+	case OpLt, OpLe, OpGt, OpGe, OpEq, OpNeq:
+		return PrecedenceComparison
+	case OpAdd, OpSub:
+		return PrecedenceAdditive
+	case OpMul, OpDiv:
+		return PrecedenceMultiplicative
+	case OpPow:
+		return PrecedencePower
+	default:
+		return 0
+	}
+}
+
+// Literal is a number, string or boolean literal
+type Literal struct {
+	Span
+	Kind  LiteralKind `json:"kind"`
+	Value any         `json:"value"` // float64, string or bool according to Kind
+}
+
+// ColumnRef is a column reference such as [price]
+type ColumnRef struct {
+	Span
+	Name string `json:"name"` // Column name without brackets
+}
+
+// Call is a function call
+type Call struct {
+	Span
+	Name string `json:"name"`
+	Args []Expr `json:"args"`
+}
+
+// UnaryExpr is a negation; OpSub is the only unary operator
+type UnaryExpr struct {
+	Span
+	Op      Operator `json:"op"`
+	Operand Expr     `json:"operand"`
+}
+
+// BinaryExpr is an arithmetic, comparison or logical operation
+type BinaryExpr struct {
+	Span
+	Op    Operator `json:"op"`
+	Left  Expr     `json:"left"`
+	Right Expr     `json:"right"`
+}
+
+// ParenExpr is an explicitly parenthesized expression.
+// Trees built in code do not need it: Print adds the parentheses precedence requires.
+type ParenExpr struct {
+	Span
+	Inner Expr `json:"inner"`
+}
+
+func (*Literal) exprNode()    {}
+func (*ColumnRef) exprNode()  {}
+func (*Call) exprNode()       {}
+func (*UnaryExpr) exprNode()  {}
+func (*BinaryExpr) exprNode() {}
+func (*ParenExpr) exprNode()  {}
+
+// AsMap converts Literal to a map for JSON serialization
+func (e *Literal) AsMap() map[string]any {
+	return map[string]any{"node": "literal", "kind": string(e.Kind), "value": e.Value, "start": e.Start, "end": e.End}
+}
+
+// AsMap converts ColumnRef to a map for JSON serialization
+func (e *ColumnRef) AsMap() map[string]any {
+	return map[string]any{"node": "column", "name": e.Name, "start": e.Start, "end": e.End}
+}
+
+// AsMap converts Call to a map for JSON serialization
+func (e *Call) AsMap() map[string]any {
+	args := make([]any, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = exprAsMap(arg)
+	}
+	return map[string]any{"node": "call", "name": e.Name, "args": args, "start": e.Start, "end": e.End}
+}
+
+// AsMap converts UnaryExpr to a map for JSON serialization
+func (e *UnaryExpr) AsMap() map[string]any {
+	return map[string]any{"node": "unary", "op": string(e.Op), "operand": exprAsMap(e.Operand), "start": e.Start, "end": e.End}
+}
+
+// AsMap converts BinaryExpr to a map for JSON serialization
+func (e *BinaryExpr) AsMap() map[string]any {
+	return map[string]any{
+		"node":  "binary",
+		"op":    string(e.Op),
+		"left":  exprAsMap(e.Left),
+		"right": exprAsMap(e.Right),
+		"start": e.Start,
+		"end":   e.End,
+	}
+}
+
+// AsMap converts ParenExpr to a map for JSON serialization
+func (e *ParenExpr) AsMap() map[string]any {
+	return map[string]any{"node": "paren", "inner": exprAsMap(e.Inner), "start": e.Start, "end": e.End}
+}
+
+// exprAsMap converts a possibly missing child node to a map, nil if missing
+func exprAsMap(e Expr) any {
+	if e == nil {
+		return nil
+	}
+	return e.AsMap()
+}
+
+// NumberLiteral creates a number literal
+func NumberLiteral(n float64) *Literal {
+	return &Literal{Kind: LiteralNumber, Value: n}
+}
+
+// StringLiteral creates a string literal; s is the text without quotes or escapes
+func StringLiteral(s string) *Literal {
+	return &Literal{Kind: LiteralString, Value: s}
+}
+
+// BooleanLiteral creates a boolean literal
+func BooleanLiteral(b bool) *Literal {
+	return &Literal{Kind: LiteralBoolean, Value: b}
+}
+
+// ColumnReference creates a column reference; name is given without brackets
+func ColumnReference(name string) *ColumnRef {
+	return &ColumnRef{Name: name}
+}
+
+// FunctionCall creates a function call
+func FunctionCall(name string, args ...Expr) *Call {
+	return &Call{Name: name, Args: append([]Expr{}, args...)}
+}
+
+// Binary creates a binary operation
+func Binary(op Operator, left, right Expr) *BinaryExpr {
+	return &BinaryExpr{Op: op, Left: left, Right: right}
+}
+
+// Negate creates a negation
+func Negate(operand Expr) *UnaryExpr {
+	return &UnaryExpr{Op: OpSub, Operand: operand}
+}
+
+// AllOf combines conditions with &&; a single condition is returned as is
+func AllOf(first Expr, rest ...Expr) Expr {
+	return chain(OpAnd, first, rest)
+}
+
+// AnyOf combines conditions with ||; a single condition is returned as is
+func AnyOf(first Expr, rest ...Expr) Expr {
+	return chain(OpOr, first, rest)
+}
+
+func chain(op Operator, first Expr, rest []Expr) Expr {
+	result := first
+	for _, operand := range rest {
+		result = Binary(op, result, operand)
+	}
+	return result
+}
+
+// Print turns an abstract syntax tree into expression source.
+// Parentheses are added where the precedence of operators requires them, so the source parses back
+// into the same tree apart from ParenExpr nodes, positions and negative numbers, which parse as a
+// negation. Operands are separated by single spaces; use the Formatter for other layouts. Print fails
+// on trees that have no source form, such as a missing operand, an unknown operator, a column name
+// containing brackets or whitespace, or a non-finite number.
+func Print(expr Expr) (string, error) {
+	var builder strings.Builder
+	if _, err := printExpr(&builder, expr); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// printExpr writes the source of expr and returns the precedence of its outermost construct
+func printExpr(builder *strings.Builder, expr Expr) (int, error) {
+	switch e := expr.(type) {
+	case *Literal:
+		return printLiteral(builder, e)
+
+	case *ColumnRef:
+		if !IsColumnName(e.Name) {
+			return 0, fmt.Errorf("invalid column name %q: must be non-empty without brackets or whitespace", e.Name)
+		}
+		builder.WriteString("[" + e.Name + "]")
+		return PrecedencePrimary, nil
+
+	case *Call:
+		if !IsFunctionName(e.Name) {
+			return 0, fmt.Errorf("invalid function name %q: %s", e.Name, FunctionNameRule)
+		}
+		builder.WriteString(e.Name + "(")
+		for i, arg := range e.Args {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			if _, err := printExpr(builder, arg); err != nil {
+				return 0, err
+			}
+		}
+		builder.WriteString(")")
+		return PrecedencePrimary, nil
+
+	case *UnaryExpr:
+		if e.Op != OpSub {
+			return 0, fmt.Errorf("invalid unary operator %q", e.Op)
+		}
+		builder.WriteString("-")
+		if err := printOperand(builder, e.Operand, PrecedenceUnary); err != nil {
+			return 0, err
+		}
+		return PrecedenceUnary, nil
+
+	case *BinaryExpr:
+		precedence := e.Op.Precedence()
+		if precedence == 0 {
+			return 0, fmt.Errorf("invalid binary operator %q", e.Op)
+		}
+		// ^ is right-associative, every other operator left-associative
+		leftMin, rightMin := precedence, precedence+1
+		if e.Op == OpPow {
+			leftMin, rightMin = precedence+1, precedence
+		}
+		if err := printOperand(builder, e.Left, leftMin); err != nil {
+			return 0, err
+		}
+		builder.WriteString(" " + string(e.Op) + " ")
+		if err := printOperand(builder, e.Right, rightMin); err != nil {
+			return 0, err
+		}
+		return precedence, nil
+
+	case *ParenExpr:
+		builder.WriteString("(")
+		if _, err := printExpr(builder, e.Inner); err != nil {
+			return 0, err
+		}
+		builder.WriteString(")")
+		return PrecedencePrimary, nil
+
+	default:
+		if expr == nil {
+			return 0, fmt.Errorf("missing expression")
+		}
+		return 0, fmt.Errorf("unsupported node %T", expr)
+	}
+}
+
+// printOperand writes an operand, parenthesized if it binds more loosely than minPrecedence
+func printOperand(builder *strings.Builder, operand Expr, minPrecedence int) error {
+	var inner strings.Builder
+	precedence, err := printExpr(&inner, operand)
+	if err != nil {
+		return err
+	}
+	if precedence < minPrecedence {
+		builder.WriteString("(" + inner.String() + ")")
+	} else {
+		builder.WriteString(inner.String())
+	}
+	return nil
+}
+
+// printLiteral writes a literal; negative numbers are written as a negation
+func printLiteral(builder *strings.Builder, literal *Literal) (int, error) {
+	switch value := literal.Value.(type) {
+	case float64:
+		if literal.Kind != LiteralNumber {
+			break
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return 0, fmt.Errorf("number %v has no literal form", value)
+		}
+		builder.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+		if value < 0 {
+			return PrecedenceUnary, nil
+		}
+		return PrecedencePrimary, nil
+	case string:
+		if literal.Kind != LiteralString {
+			break
+		}
+		builder.WriteString(QuoteString(value))
+		return PrecedencePrimary, nil
+	case bool:
+		if literal.Kind != LiteralBoolean {
+			break
+		}
+		builder.WriteString(strconv.FormatBool(value))
+		return PrecedencePrimary, nil
+	}
+	return 0, fmt.Errorf("invalid %s literal value %v (%T)", literal.Kind, literal.Value, literal.Value)
+}
+
+// QuoteString writes s as a double-quoted string literal, escaping backslashes, quotes and line breaks
+func QuoteString(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + replacer.Replace(s) + `"`
+}
+
+// IsColumnName reports whether name can be written as a column reference: it must be non-empty
+// without brackets or whitespace, like the text between the brackets of the COLUMN_REF lexer rule
+func IsColumnName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "[] \t\r\n")
+}
+
+// FunctionNameRule describes the names IsFunctionName accepts, for error messages
+const FunctionNameRule = "must start with an uppercase letter followed by uppercase letters, digits or underscores"
+
+// IsFunctionName reports whether name matches the FUNCTION_NAME lexer rule
+func IsFunctionName(name string) bool {
+	for i, c := range name {
+		switch {
+		case c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '_'):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
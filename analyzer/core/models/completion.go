@@ -0,0 +1,32 @@
+package models
+
+// CompletionKind classifies a completion item
+type CompletionKind string
+
+const (
+	CompletionColumn      CompletionKind = "column"      // Column reference
+	CompletionFunction    CompletionKind = "function"    // Function call
+	CompletionLiteral     CompletionKind = "literal"     // Boolean literal
+	CompletionPunctuation CompletionKind = "punctuation" // Parenthesis or argument separator
+	CompletionOperator    CompletionKind = "operator"    // Binary or unary operator
+)
+
+// CompletionItem is a suggestion for the text at a cursor position
+type CompletionItem struct {
+	Label         string         `json:"label"`         // Text shown in the completion list
+	Kind          CompletionKind `json:"kind"`          // What the suggestion inserts
+	Detail        string         `json:"detail"`        // Function syntax, column type or operator name
+	Documentation string         `json:"documentation"` // Function or column description
+	Edit          TextEdit       `json:"edit"`          // Replaces the text being typed with the suggestion
+}
+
+// AsMap converts CompletionItem to a map for JSON serialization
+func (c *CompletionItem) AsMap() map[string]any {
+	return map[string]any{
+		"label":         c.Label,
+		"kind":          string(c.Kind),
+		"detail":        c.Detail,
+		"documentation": c.Documentation,
+		"edit":          c.Edit.AsMap(),
+	}
+}
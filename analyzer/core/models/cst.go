@@ -0,0 +1,108 @@
+package models
+
+import "strings"
+
+// TriviaKind classifies source text of a concrete syntax tree that is not a token of the tree
+type TriviaKind string
+
+const (
+	TriviaWhitespace TriviaKind = "whitespace" // Spaces, tabs and line breaks
+	TriviaInvalid    TriviaKind = "invalid"    // Character the lexer cannot match
+	TriviaSkipped    TriviaKind = "skipped"    // Token the parser could not place in the tree
+)
+
+// Trivia is source text attached to a token of a concrete syntax tree
+type Trivia struct {
+	Kind  TriviaKind `json:"kind"`
+	Text  string     `json:"text"`
+	Start int        `json:"start"`
+	End   int        `json:"end"`
+}
+
+// AsMap converts Trivia to a map for JSON serialization
+func (t Trivia) AsMap() map[string]any {
+	return map[string]any{
+		"kind":  string(t.Kind),
+		"text":  t.Text,
+		"start": t.Start,
+		"end":   t.End,
+	}
+}
+
+// CSTNode represents a node of a concrete syntax tree, which keeps every character of the source.
+// Rule nodes have children; token nodes have text and the trivia around them. A token owns the trivia
+// that follows it up to and including the end of its line; all other trivia leads the next token.
+// Positions are code point offsets and exclude trivia.
+type CSTNode struct {
+	Type     NodeType  `json:"type"`     // Node type
+	Text     string    `json:"text"`     // Token text, empty for rule nodes
+	Start    int       `json:"start"`    // Start position in the input
+	End      int       `json:"end"`      // End position in the input
+	Leading  []Trivia  `json:"leading"`  // Trivia before the token
+	Trailing []Trivia  `json:"trailing"` // Trivia after the token
+	Children []CSTNode `json:"children"` // Child nodes, empty for tokens
+}
+
+// IsToken reports whether the node is a token rather than a rule node
+func (n *CSTNode) IsToken() bool {
+	return len(n.Children) == 0
+}
+
+// Tokens returns the tokens of the tree in source order
+func (n *CSTNode) Tokens() []*CSTNode {
+	var tokens []*CSTNode
+	n.collectTokens(&tokens)
+	return tokens
+}
+
+func (n *CSTNode) collectTokens(tokens *[]*CSTNode) {
+	if n.IsToken() {
+		*tokens = append(*tokens, n)
+		return
+	}
+	for i := range n.Children {
+		n.Children[i].collectTokens(tokens)
+	}
+}
+
+// String returns the source of the node including its trivia.
+// For the root of a tree this is exactly the parsed expression.
+func (n *CSTNode) String() string {
+	var builder strings.Builder
+	for _, token := range n.Tokens() {
+		for _, trivia := range token.Leading {
+			builder.WriteString(trivia.Text)
+		}
+		builder.WriteString(token.Text)
+		for _, trivia := range token.Trailing {
+			builder.WriteString(trivia.Text)
+		}
+	}
+	return builder.String()
+}
+
+// AsMap converts CSTNode to a map for JSON serialization
+func (n *CSTNode) AsMap() map[string]any {
+	leading := make([]any, len(n.Leading))
+	for i, trivia := range n.Leading {
+		leading[i] = trivia.AsMap()
+	}
+	trailing := make([]any, len(n.Trailing))
+	for i, trivia := range n.Trailing {
+		trailing[i] = trivia.AsMap()
+	}
+	children := make([]any, len(n.Children))
+	for i, child := range n.Children {
+		children[i] = child.AsMap()
+	}
+
+	return map[string]any{
+		"type":     int(n.Type),
+		"text":     n.Text,
+		"start":    n.Start,
+		"end":      n.End,
+		"leading":  leading,
+		"trailing": trailing,
+		"children": children,
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import "sort"
+
+// TextEdit replaces a range of an expression with new text.
+// Positions are code point offsets like those of tokens.
+type TextEdit struct {
+	Start   int    `json:"start"`   // Start position of the replaced range
+	End     int    `json:"end"`     // End position of the replaced range (exclusive)
+	NewText string `json:"newText"` // Replacement text
+}
+
+// AsMap converts TextEdit to a map for JSON serialization
+func (e *TextEdit) AsMap() map[string]any {
+	return map[string]any{
+		"start":   e.Start,
+		"end":     e.End,
+		"newText": e.NewText,
+	}
+}
+
+// ApplyEdits returns text with the edits applied. The edits must not overlap; their positions refer to the
+// original text, so they may be given in any order. Positions outside the text are clamped to it.
+func ApplyEdits(text string, edits []TextEdit) string {
+	if len(edits) == 0 {
+		return text
+	}
+
+	sorted := append([]TextEdit(nil), edits...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	runes := []rune(text)
+	clamp := func(position int) int {
+		return max(0, min(position, len(runes)))
+	}
+
+	result := make([]rune, 0, len(runes))
+	position := 0
+	for _, edit := range sorted {
+		start, end := max(clamp(edit.Start), position), clamp(edit.End)
+		result = append(result, runes[position:start]...)
+		result = append(result, []rune(edit.NewText)...)
+		position = max(start, end)
+	}
+	result = append(result, runes[position:]...)
+	return string(result)
+}
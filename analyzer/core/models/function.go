@@ -0,0 +1,108 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parameter describes a single parameter of a function
+type Parameter struct {
+	Name        string   `json:"name"`        // Parameter name
+	Type        DataType `json:"type"`        // Expected argument type
+	Optional    bool     `json:"optional"`    // Whether the argument may be omitted
+	Description string   `json:"description"` // Human readable description
+}
+
+// AsMap converts Parameter to a map for JSON serialization
+func (p *Parameter) AsMap() map[string]any {
+	return map[string]any{
+		"name":        p.Name,
+		"type":        string(p.Type),
+		"optional":    p.Optional,
+		"description": p.Description,
+	}
+}
+
+// FunctionSignature describes the parameters and return type of a function
+type FunctionSignature struct {
+	Name        string      `json:"name"`        // Function name (uppercase)
+	Description string      `json:"description"` // Human readable description
+	Examples    []string    `json:"examples"`    // Usage examples
+	Parameters  []Parameter `json:"parameters"`  // Fixed parameters; optional ones must come last
+	Variadic    *Parameter  `json:"variadic"`    // Repeated tail parameter, nil if not variadic
+	ReturnType  DataType    `json:"returnType"`  // Type of the returned value
+}
+
+// MinArgs returns the minimum number of arguments the function accepts
+func (s *FunctionSignature) MinArgs() int {
+	count := 0
+	for _, param := range s.Parameters {
+		if !param.Optional {
+			count++
+		}
+	}
+	return count
+}
+
+// MaxArgs returns the maximum number of arguments the function accepts, or -1 if unbounded
+func (s *FunctionSignature) MaxArgs() int {
+	if s.Variadic != nil {
+		return -1
+	}
+	return len(s.Parameters)
+}
+
+// ParameterAt returns the parameter receiving the argument at the given index, or nil if there is none
+func (s *FunctionSignature) ParameterAt(index int) *Parameter {
+	if index < 0 {
+		return nil
+	}
+	if index < len(s.Parameters) {
+		return &s.Parameters[index]
+	}
+	return s.Variadic
+}
+
+// Syntax returns a human readable signature such as "SUBSTRING(text, start, length)"
+func (s *FunctionSignature) Syntax() string {
+	params := make([]string, 0, len(s.Parameters)+1)
+	for _, param := range s.Parameters {
+		if param.Optional {
+			params = append(params, param.Name+"?")
+		} else {
+			params = append(params, param.Name)
+		}
+	}
+	if s.Variadic != nil {
+		params = append(params, s.Variadic.Name+", ...")
+	}
+	return fmt.Sprintf("%s(%s)", s.Name, strings.Join(params, ", "))
+}
+
+// AsMap converts FunctionSignature to a map for JSON serialization
+func (s *FunctionSignature) AsMap() map[string]any {
+	params := make([]any, len(s.Parameters))
+	for i, param := range s.Parameters {
+		params[i] = param.AsMap()
+	}
+
+	examples := make([]any, len(s.Examples))
+	for i, example := range s.Examples {
+		examples[i] = example
+	}
+
+	var variadic any
+	if s.Variadic != nil {
+		variadic = s.Variadic.AsMap()
+	}
+
+	return map[string]any{
+		"name":        s.Name,
+		"description": s.Description,
+		"examples":    examples,
+		"parameters":  params,
+		"variadic":    variadic,
+		"returnType":  string(s.ReturnType),
+		"syntax":      s.Syntax(),
+	}
+}
@@ -1,5 +1,7 @@
 package models
 
+import "fmt"
+
 type NodeType int
 
 const (
@@ -62,8 +64,66 @@ const (
 	NodeTypeErrorChar NodeType = 41
 	NodeTypeTerminal  NodeType = 42
 	NodeTypeError     NodeType = 43
+	NodeTypeEOF       NodeType = 44 // End of input in a concrete syntax tree, holds the trailing trivia
 )
 
+// nodeTypeNames holds the name of each node type, as used by the NodeType constants of the editor app
+var nodeTypeNames = map[NodeType]string{
+	NodeTypeExpression:       "Expression",
+	NodeTypeLiteralExpr:      "LiteralExpr",
+	NodeTypeColumnRefExpr:    "ColumnRefExpr",
+	NodeTypeFunctionCallExpr: "FunctionCallExpr",
+	NodeTypeParenExpr:        "ParenExpr",
+	NodeTypeUnaryMinusExpr:   "UnaryMinusExpr",
+	NodeTypePowerExpr:        "PowerExpr",
+	NodeTypeMulDivExpr:       "MulDivExpr",
+	NodeTypeAddSubExpr:       "AddSubExpr",
+	NodeTypeComparisonExpr:   "ComparisonExpr",
+	NodeTypeAndExpr:          "AndExpr",
+	NodeTypeOrExpr:           "OrExpr",
+	NodeTypeLiteral:          "Literal",
+	NodeTypeColumnReference:  "ColumnReference",
+	NodeTypeFunctionCall:     "FunctionCall",
+	NodeTypeArgumentList:     "ArgumentList",
+	NodeTypeAdd:              "Add",
+	NodeTypeSub:              "Sub",
+	NodeTypeMul:              "Mul",
+	NodeTypeDiv:              "Div",
+	NodeTypePow:              "Pow",
+	NodeTypeLt:               "Lt",
+	NodeTypeLe:               "Le",
+	NodeTypeGt:               "Gt",
+	NodeTypeGe:               "Ge",
+	NodeTypeEq:               "Eq",
+	NodeTypeNeq:              "Neq",
+	NodeTypeOr:               "Or",
+	NodeTypeAnd:              "And",
+	NodeTypeLParen:           "LParen",
+	NodeTypeRParen:           "RParen",
+	NodeTypeLBracket:         "LBracket",
+	NodeTypeRBracket:         "RBracket",
+	NodeTypeComma:            "Comma",
+	NodeTypeBooleanLiteral:   "BooleanLiteral",
+	NodeTypeFloatLiteral:     "FloatLiteral",
+	NodeTypeIntegerLiteral:   "IntegerLiteral",
+	NodeTypeStringLiteral:    "StringLiteral",
+	NodeTypeFunctionName:     "FunctionName",
+	NodeTypeColumnRef:        "ColumnRef",
+	NodeTypeWS:               "WS",
+	NodeTypeErrorChar:        "ErrorChar",
+	NodeTypeTerminal:         "Terminal",
+	NodeTypeError:            "Error",
+	NodeTypeEOF:              "EOF",
+}
+
+// String returns the name of the node type, e.g. "AddSubExpr"
+func (t NodeType) String() string {
+	if name, ok := nodeTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("NodeType(%d)", int(t))
+}
+
 // ParseTreeNode represents a node in the parse tree hierarchy
 type ParseTreeNode struct {
 	Type     NodeType        `json:"type"`     // Node type
@@ -88,3 +148,21 @@ func (n *ParseTreeNode) AsMap() map[string]any {
 		"children": children,
 	}
 }
+
+// NodeInfo identifies a node of the parse tree by its type and span, without its children
+type NodeInfo struct {
+	Type  NodeType `json:"type"`  // Node type
+	Text  string   `json:"text"`  // Text content of this node
+	Start int      `json:"start"` // Start position in the input
+	End   int      `json:"end"`   // End position in the input
+}
+
+// AsMap converts NodeInfo to a map for JSON serialization
+func (n *NodeInfo) AsMap() map[string]any {
+	return map[string]any{
+		"type":  int(n.Type),
+		"text":  n.Text,
+		"start": n.Start,
+		"end":   n.End,
+	}
+}
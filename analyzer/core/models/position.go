@@ -0,0 +1,203 @@
+package models
+
+import (
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// PositionEncoding selects the unit in which positions in an expression are counted.
+// Lines are always 1-based line numbers; columns are counted in the same unit as offsets.
+type PositionEncoding string
+
+const (
+	PositionEncodingRune  PositionEncoding = "rune"   // Unicode code points, as used by ANTLR and Python strings (default)
+	PositionEncodingUTF16 PositionEncoding = "utf-16" // UTF-16 code units, as used by JavaScript strings and CodeMirror
+	PositionEncodingByte  PositionEncoding = "byte"   // Bytes of the UTF-8 encoding, as used by Go and C strings
+)
+
+// IsValid reports whether e is one of the defined position encodings
+func (e PositionEncoding) IsValid() bool {
+	switch e {
+	case PositionEncodingRune, PositionEncodingUTF16, PositionEncodingByte:
+		return true
+	}
+	return false
+}
+
+// PositionMap converts the code point positions of a text to another encoding and back.
+// A nil map converts nothing; NewPositionMap returns nil when every code point of the text is one unit.
+type PositionMap struct {
+	offsets    []int // Encoded offset of each code point, followed by the encoded length of the text
+	lineStarts []int // Code point offset of the start of each line
+}
+
+// NewPositionMap creates the map of text for the encoding, or nil if positions are the same in both encodings
+func NewPositionMap(text string, encoding PositionEncoding) *PositionMap {
+	if encoding == "" || encoding == PositionEncodingRune || !needsMapping(text, encoding) {
+		return nil
+	}
+
+	m := &PositionMap{offsets: make([]int, 0, len(text)+1), lineStarts: []int{0}}
+	offset := 0
+	for i, r := range []rune(text) {
+		m.offsets = append(m.offsets, offset)
+		if encoding == PositionEncodingByte {
+			offset += utf8.RuneLen(r)
+		} else {
+			offset += len(utf16.Encode([]rune{r}))
+		}
+		if r == '\n' {
+			m.lineStarts = append(m.lineStarts, i+1)
+		}
+	}
+	m.offsets = append(m.offsets, offset)
+	return m
+}
+
+// needsMapping reports whether text has a code point taking more than one unit of the encoding
+func needsMapping(text string, encoding PositionEncoding) bool {
+	for _, r := range text {
+		if (encoding == PositionEncodingByte && r >= utf8.RuneSelf) || (encoding == PositionEncodingUTF16 && r > 0xFFFF) {
+			return true
+		}
+	}
+	return false
+}
+
+// Offset converts a code point offset to the encoding. Negative offsets, which mark missing positions,
+// are kept; offsets past the end of the text count one unit per code point past it.
+func (m *PositionMap) Offset(codePoint int) int {
+	if m == nil || codePoint < 0 {
+		return codePoint
+	}
+	last := len(m.offsets) - 1
+	if codePoint > last {
+		return m.offsets[last] + codePoint - last
+	}
+	return m.offsets[codePoint]
+}
+
+// CodePoint converts an offset in the encoding to a code point offset, the inverse of Offset.
+// An offset inside the encoding of a code point is moved to the start of that code point.
+func (m *PositionMap) CodePoint(offset int) int {
+	if m == nil || offset < 0 {
+		return offset
+	}
+	last := len(m.offsets) - 1
+	if offset > m.offsets[last] {
+		return last + offset - m.offsets[last]
+	}
+	// The first code point starting after offset follows the one containing it
+	return sort.Search(len(m.offsets), func(i int) bool { return m.offsets[i] > offset }) - 1
+}
+
+// Column converts the 0-based code point column of a position on a 1-based line to the encoding
+func (m *PositionMap) Column(line, column int) int {
+	if m == nil || line < 1 || line > len(m.lineStarts) || column < 0 {
+		return column
+	}
+	start := m.lineStarts[line-1]
+	return m.Offset(start+column) - m.Offset(start)
+}
+
+// Error converts the positions of an error
+func (m *PositionMap) Error(err ErrorInfo) ErrorInfo {
+	if m == nil {
+		return err
+	}
+	err.Column = m.Column(err.Line, err.Column)
+	err.Start, err.End = m.Offset(err.Start), m.Offset(err.End)
+	return err
+}
+
+// Errors converts the positions of errors in place and returns them
+func (m *PositionMap) Errors(errors []ErrorInfo) []ErrorInfo {
+	if m == nil {
+		return errors
+	}
+	for i := range errors {
+		errors[i] = m.Error(errors[i])
+	}
+	return errors
+}
+
+// Tokens converts the positions of tokens in place and returns them
+func (m *PositionMap) Tokens(tokens []TokenInfo) []TokenInfo {
+	if m == nil {
+		return tokens
+	}
+	for i := range tokens {
+		tokens[i].Column = m.Column(tokens[i].Line, tokens[i].Column)
+		tokens[i].Start, tokens[i].End = m.Offset(tokens[i].Start), m.Offset(tokens[i].End)
+	}
+	return tokens
+}
+
+// Edit converts the range of an edit
+func (m *PositionMap) Edit(edit TextEdit) TextEdit {
+	edit.Start, edit.End = m.Offset(edit.Start), m.Offset(edit.End)
+	return edit
+}
+
+// Span converts a span
+func (m *PositionMap) Span(span Span) Span {
+	return Span{Start: m.Offset(span.Start), End: m.Offset(span.End)}
+}
+
+// Node converts the span of a node
+func (m *PositionMap) Node(node NodeInfo) NodeInfo {
+	node.Start, node.End = m.Offset(node.Start), m.Offset(node.End)
+	return node
+}
+
+// ParseTree converts the positions of a parse tree in place
+func (m *PositionMap) ParseTree(node *ParseTreeNode) {
+	if m == nil || node == nil {
+		return
+	}
+	node.Start, node.End = m.Offset(node.Start), m.Offset(node.End)
+	for i := range node.Children {
+		m.ParseTree(&node.Children[i])
+	}
+}
+
+// CST converts the positions of a concrete syntax tree and its trivia in place
+func (m *PositionMap) CST(node *CSTNode) {
+	if m == nil || node == nil {
+		return
+	}
+	node.Start, node.End = m.Offset(node.Start), m.Offset(node.End)
+	for _, trivia := range [][]Trivia{node.Leading, node.Trailing} {
+		for i := range trivia {
+			trivia[i].Start, trivia[i].End = m.Offset(trivia[i].Start), m.Offset(trivia[i].End)
+		}
+	}
+	for i := range node.Children {
+		m.CST(&node.Children[i])
+	}
+}
+
+// AST converts the spans of an abstract syntax tree in place
+func (m *PositionMap) AST(expr Expr) {
+	if m == nil || expr == nil {
+		return
+	}
+	Inspect(expr, func(node Expr) bool {
+		switch node := node.(type) {
+		case *Literal:
+			node.Span = m.Span(node.Span)
+		case *ColumnRef:
+			node.Span = m.Span(node.Span)
+		case *Call:
+			node.Span = m.Span(node.Span)
+		case *UnaryExpr:
+			node.Span = m.Span(node.Span)
+		case *BinaryExpr:
+			node.Span = m.Span(node.Span)
+		case *ParenExpr:
+			node.Span = m.Span(node.Span)
+		}
+		return true
+	})
+}
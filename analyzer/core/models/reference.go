@@ -0,0 +1,19 @@
+package models
+
+// Reference is a column or function used by an expression, with every place it occurs
+type Reference struct {
+	Name        string `json:"name"`        // Column name without brackets, or function name
+	Occurrences []Span `json:"occurrences"` // Spans of the name in the expression, in source order
+}
+
+// AsMap converts Reference to a map for JSON serialization
+func (r *Reference) AsMap() map[string]any {
+	occurrences := make([]any, len(r.Occurrences))
+	for i, span := range r.Occurrences {
+		occurrences[i] = map[string]any{"start": span.Start, "end": span.End}
+	}
+	return map[string]any{
+		"name":        r.Name,
+		"occurrences": occurrences,
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+// Column describes a column that expressions may reference
+type Column struct {
+	Name        string   `json:"name"`        // Column name without brackets
+	Type        DataType `json:"type"`        // Declared column type, any if unknown
+	Description string   `json:"description"` // Human readable description
+}
+
+// AsMap converts Column to a map for JSON serialization
+func (c *Column) AsMap() map[string]any {
+	return map[string]any{
+		"name":        c.Name,
+		"type":        string(c.Type),
+		"description": c.Description,
+	}
+}
+
+// Schema is the set of columns available to expressions
+type Schema struct {
+	columns []Column
+	index   map[string]int
+}
+
+// NewSchema creates a schema from the given columns.
+// Columns without a type are treated as any; later duplicates replace earlier ones.
+func NewSchema(columns []Column) *Schema {
+	schema := &Schema{
+		columns: make([]Column, 0, len(columns)),
+		index:   make(map[string]int, len(columns)),
+	}
+	for _, column := range columns {
+		if column.Type == "" {
+			column.Type = DataTypeAny
+		}
+		if i, ok := schema.index[column.Name]; ok {
+			schema.columns[i] = column
+			continue
+		}
+		schema.index[column.Name] = len(schema.columns)
+		schema.columns = append(schema.columns, column)
+	}
+	return schema
+}
+
+// Lookup returns the column with the given name
+func (s *Schema) Lookup(name string) (*Column, bool) {
+	i, ok := s.index[name]
+	if !ok {
+		return nil, false
+	}
+	return &s.columns[i], true
+}
+
+// Columns returns the columns in declaration order
+func (s *Schema) Columns() []Column {
+	return s.columns
+}
@@ -0,0 +1,91 @@
+package models
+
+// Rewriter is called by Rewrite for every node of a tree, after the children of the node have been rewritten.
+// It returns the node to use in its place: the node itself to keep it, another node to replace it,
+// a node containing it to wrap it, or nil to delete it. The node is a copy that may be modified in place.
+type Rewriter func(node Expr) Expr
+
+// Rewrite returns a copy of the tree transformed bottom-up by rewrite; the input tree is not modified.
+// The node returned by rewrite is not visited again, so wrapping a node does not recurse into the wrapper.
+// Deleting a node removes it from the enclosing construct:
+//   - a deleted function argument is dropped from the argument list
+//   - a binary operation with a deleted operand is replaced by the other operand,
+//     so deleting one condition of an && or || chain keeps the others
+//   - a negation or parenthesized expression whose operand is deleted is deleted as well
+//
+// Rewrite returns nil if the root itself is deleted.
+func Rewrite(expr Expr, rewrite Rewriter) Expr {
+	if expr == nil {
+		return nil
+	}
+
+	switch e := expr.(type) {
+	case *Literal:
+		copied := *e
+		return rewrite(&copied)
+
+	case *ColumnRef:
+		copied := *e
+		return rewrite(&copied)
+
+	case *Call:
+		copied := *e
+		copied.Args = make([]Expr, 0, len(e.Args))
+		for _, arg := range e.Args {
+			if rewritten := Rewrite(arg, rewrite); rewritten != nil {
+				copied.Args = append(copied.Args, rewritten)
+			}
+		}
+		return rewrite(&copied)
+
+	case *UnaryExpr:
+		copied := *e
+		if copied.Operand = Rewrite(e.Operand, rewrite); copied.Operand == nil {
+			return nil
+		}
+		return rewrite(&copied)
+
+	case *BinaryExpr:
+		copied := *e
+		copied.Left, copied.Right = Rewrite(e.Left, rewrite), Rewrite(e.Right, rewrite)
+		switch {
+		case copied.Left == nil:
+			return copied.Right
+		case copied.Right == nil:
+			return copied.Left
+		}
+		return rewrite(&copied)
+
+	case *ParenExpr:
+		copied := *e
+		if copied.Inner = Rewrite(e.Inner, rewrite); copied.Inner == nil {
+			return nil
+		}
+		return rewrite(&copied)
+
+	default:
+		return rewrite(expr)
+	}
+}
+
+// Inspect traverses a tree depth-first, calling visit for every node before its children.
+// The children of a node are skipped when visit returns false.
+func Inspect(expr Expr, visit func(node Expr) bool) {
+	if expr == nil || !visit(expr) {
+		return
+	}
+
+	switch e := expr.(type) {
+	case *Call:
+		for _, arg := range e.Args {
+			Inspect(arg, visit)
+		}
+	case *UnaryExpr:
+		Inspect(e.Operand, visit)
+	case *BinaryExpr:
+		Inspect(e.Left, visit)
+		Inspect(e.Right, visit)
+	case *ParenExpr:
+		Inspect(e.Inner, visit)
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+// DataType represents the static type of an expression
+type DataType string
+
+const (
+	DataTypeNumber   DataType = "number"   // Integer and float values
+	DataTypeString   DataType = "string"   // String values
+	DataTypeBoolean  DataType = "boolean"  // Boolean values
+	DataTypeDateTime DataType = "datetime" // Date and time values, only produced by functions
+	DataTypeAny      DataType = "any"      // Type unknown at analysis time, compatible with every type
+)
+
+// IsAssignableTo reports whether a value of this type can be used where the target type is expected
+func (t DataType) IsAssignableTo(target DataType) bool {
+	return t == DataTypeAny || target == DataTypeAny || t == target
+}
+
+// IsValid reports whether the type is one of the known data types
+func (t DataType) IsValid() bool {
+	switch t {
+	case DataTypeNumber, DataTypeString, DataTypeBoolean, DataTypeDateTime, DataTypeAny:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Value is a typed runtime value produced by evaluating an expression
+type Value struct {
+	typ      DataType
+	null     bool
+	number   float64
+	text     string
+	boolean  bool
+	dateTime time.Time
+}
+
+// NullValue returns the null value
+func NullValue() Value {
+	return Value{typ: DataTypeAny, null: true}
+}
+
+// NumberValue returns a number value
+func NumberValue(n float64) Value {
+	return Value{typ: DataTypeNumber, number: n}
+}
+
+// StringValue returns a string value
+func StringValue(s string) Value {
+	return Value{typ: DataTypeString, text: s}
+}
+
+// BooleanValue returns a boolean value
+func BooleanValue(b bool) Value {
+	return Value{typ: DataTypeBoolean, boolean: b}
+}
+
+// DateTimeValue returns a datetime value
+func DateTimeValue(t time.Time) Value {
+	return Value{typ: DataTypeDateTime, dateTime: t}
+}
+
+// NewValue converts a Go value to a Value.
+// Supported inputs are nil, bool, string, all integer and float kinds, time.Time and Value itself.
+func NewValue(v any) (Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return NullValue(), nil
+	case Value:
+		return v, nil
+	case bool:
+		return BooleanValue(v), nil
+	case string:
+		return StringValue(v), nil
+	case float64:
+		return NumberValue(v), nil
+	case float32:
+		return NumberValue(float64(v)), nil
+	case int:
+		return NumberValue(float64(v)), nil
+	case int8:
+		return NumberValue(float64(v)), nil
+	case int16:
+		return NumberValue(float64(v)), nil
+	case int32:
+		return NumberValue(float64(v)), nil
+	case int64:
+		return NumberValue(float64(v)), nil
+	case uint:
+		return NumberValue(float64(v)), nil
+	case uint8:
+		return NumberValue(float64(v)), nil
+	case uint16:
+		return NumberValue(float64(v)), nil
+	case uint32:
+		return NumberValue(float64(v)), nil
+	case uint64:
+		return NumberValue(float64(v)), nil
+	case time.Time:
+		return DateTimeValue(v), nil
+	default:
+		return NullValue(), fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+// Type returns the data type of the value, any for null
+func (v Value) Type() DataType {
+	return v.typ
+}
+
+// IsNull reports whether the value is null
+func (v Value) IsNull() bool {
+	return v.null
+}
+
+// Number returns the number held by a number value
+func (v Value) Number() float64 {
+	return v.number
+}
+
+// Str returns the string held by a string value
+func (v Value) Str() string {
+	return v.text
+}
+
+// Boolean returns the boolean held by a boolean value
+func (v Value) Boolean() bool {
+	return v.boolean
+}
+
+// DateTime returns the time held by a datetime value
+func (v Value) DateTime() time.Time {
+	return v.dateTime
+}
+
+// Interface returns the value as a plain Go value: nil, float64, string, bool or time.Time
+func (v Value) Interface() any {
+	if v.null {
+		return nil
+	}
+	switch v.typ {
+	case DataTypeNumber:
+		return v.number
+	case DataTypeString:
+		return v.text
+	case DataTypeBoolean:
+		return v.boolean
+	case DataTypeDateTime:
+		return v.dateTime
+	default:
+		return nil
+	}
+}
+
+// String returns a human readable representation of the value
+func (v Value) String() string {
+	if v.null {
+		return "null"
+	}
+	switch v.typ {
+	case DataTypeNumber:
+		return strconv.FormatFloat(v.number, 'g', -1, 64)
+	case DataTypeString:
+		return strconv.Quote(v.text)
+	case DataTypeBoolean:
+		return strconv.FormatBool(v.boolean)
+	case DataTypeDateTime:
+		return v.dateTime.Format(time.RFC3339Nano)
+	default:
+		return "null"
+	}
+}
+
+// Equal reports whether two values have the same type and content
+func (v Value) Equal(other Value) bool {
+	if v.null || other.null {
+		return v.null == other.null
+	}
+	if v.typ != other.typ {
+		return false
+	}
+	switch v.typ {
+	case DataTypeNumber:
+		return v.number == other.number
+	case DataTypeString:
+		return v.text == other.text
+	case DataTypeBoolean:
+		return v.boolean == other.boolean
+	case DataTypeDateTime:
+		return v.dateTime.Equal(other.dateTime)
+	default:
+		return true
+	}
+}
+
+// AsMap converts Value to a map for JSON serialization.
+// Datetimes are serialized as RFC 3339 strings and null as a nil value with type "null".
+func (v Value) AsMap() map[string]any {
+	if v.null {
+		return map[string]any{
+			"type":  "null",
+			"value": nil,
+		}
+	}
+	value := v.Interface()
+	if v.typ == DataTypeDateTime {
+		value = v.dateTime.Format(time.RFC3339Nano)
+	}
+	return map[string]any{
+		"type":  string(v.typ),
+		"value": value,
+	}
+}
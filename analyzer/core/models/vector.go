@@ -0,0 +1,282 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Vector is a column of values for batch evaluation.
+// Typed vectors store their values in a plain Go slice; vectors of type any store a Value per row.
+// A nil null mask means no row is null.
+type Vector struct {
+	typ       DataType
+	length    int
+	numbers   []float64
+	strings   []string
+	booleans  []bool
+	dateTimes []time.Time
+	values    []Value
+	nulls     []bool
+}
+
+// NumberVector returns a number column; nulls may be nil or must have the same length as values
+func NumberVector(values []float64, nulls []bool) *Vector {
+	return &Vector{typ: DataTypeNumber, length: len(values), numbers: values, nulls: nulls}
+}
+
+// StringVector returns a string column; nulls may be nil or must have the same length as values
+func StringVector(values []string, nulls []bool) *Vector {
+	return &Vector{typ: DataTypeString, length: len(values), strings: values, nulls: nulls}
+}
+
+// BooleanVector returns a boolean column; nulls may be nil or must have the same length as values
+func BooleanVector(values []bool, nulls []bool) *Vector {
+	return &Vector{typ: DataTypeBoolean, length: len(values), booleans: values, nulls: nulls}
+}
+
+// DateTimeVector returns a datetime column; nulls may be nil or must have the same length as values
+func DateTimeVector(values []time.Time, nulls []bool) *Vector {
+	return &Vector{typ: DataTypeDateTime, length: len(values), dateTimes: values, nulls: nulls}
+}
+
+// ValueVector returns a column of type any whose rows may hold values of different types
+func ValueVector(values []Value) *Vector {
+	return &Vector{typ: DataTypeAny, length: len(values), values: values}
+}
+
+// NewVector converts a Go slice to a Vector.
+// Supported inputs are []float64, []float32, []int, []int32, []int64, []string, []bool, []time.Time,
+// []Value, []any holding values accepted by NewValue, and *Vector itself.
+func NewVector(column any) (*Vector, error) {
+	switch column := column.(type) {
+	case *Vector:
+		if column == nil {
+			return nil, fmt.Errorf("nil vector")
+		}
+		return column, nil
+	case []float64:
+		return NumberVector(column, nil), nil
+	case []float32:
+		return NumberVector(convertNumbers(column), nil), nil
+	case []int:
+		return NumberVector(convertNumbers(column), nil), nil
+	case []int32:
+		return NumberVector(convertNumbers(column), nil), nil
+	case []int64:
+		return NumberVector(convertNumbers(column), nil), nil
+	case []string:
+		return StringVector(column, nil), nil
+	case []bool:
+		return BooleanVector(column, nil), nil
+	case []time.Time:
+		return DateTimeVector(column, nil), nil
+	case []Value:
+		return ValueVector(column), nil
+	case []any:
+		values := make([]Value, len(column))
+		for i, v := range column {
+			value, err := NewValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i, err)
+			}
+			values[i] = value
+		}
+		return ValueVector(values), nil
+	default:
+		return nil, fmt.Errorf("unsupported column type %T", column)
+	}
+}
+
+// convertNumbers converts a slice of any number kind to float64
+func convertNumbers[T float32 | int | int32 | int64](column []T) []float64 {
+	numbers := make([]float64, len(column))
+	for i, n := range column {
+		numbers[i] = float64(n)
+	}
+	return numbers
+}
+
+// Type returns the data type of the column, any if rows may differ in type
+func (v *Vector) Type() DataType {
+	return v.typ
+}
+
+// Len returns the number of rows
+func (v *Vector) Len() int {
+	return v.length
+}
+
+// IsNull reports whether the given row is null
+func (v *Vector) IsNull(row int) bool {
+	if v.typ == DataTypeAny {
+		return v.values[row].IsNull()
+	}
+	return v.nulls != nil && v.nulls[row]
+}
+
+// At returns the value of the given row
+func (v *Vector) At(row int) Value {
+	if v.IsNull(row) {
+		return NullValue()
+	}
+	switch v.typ {
+	case DataTypeNumber:
+		return NumberValue(v.numbers[row])
+	case DataTypeString:
+		return StringValue(v.strings[row])
+	case DataTypeBoolean:
+		return BooleanValue(v.booleans[row])
+	case DataTypeDateTime:
+		return DateTimeValue(v.dateTimes[row])
+	default:
+		return v.values[row]
+	}
+}
+
+// Numbers returns the values of a number column; null rows hold 0
+func (v *Vector) Numbers() []float64 {
+	return v.numbers
+}
+
+// Strings returns the values of a string column; null rows hold ""
+func (v *Vector) Strings() []string {
+	return v.strings
+}
+
+// Booleans returns the values of a boolean column; null rows hold false
+func (v *Vector) Booleans() []bool {
+	return v.booleans
+}
+
+// DateTimes returns the values of a datetime column; null rows hold the zero time
+func (v *Vector) DateTimes() []time.Time {
+	return v.dateTimes
+}
+
+// Values returns the values of a column of type any
+func (v *Vector) Values() []Value {
+	return v.values
+}
+
+// Nulls returns the null mask of a typed column, nil if no row is null
+func (v *Vector) Nulls() []bool {
+	return v.nulls
+}
+
+// VectorBuilder builds a Vector row by row.
+// The vector takes the type of the first non-null value and falls back to any
+// when a later value has a different type.
+type VectorBuilder struct {
+	vector   Vector
+	capacity int
+}
+
+// NewVectorBuilder returns a builder expecting about capacity rows
+func NewVectorBuilder(capacity int) *VectorBuilder {
+	// The type stays empty while only nulls have been appended
+	return &VectorBuilder{capacity: capacity}
+}
+
+// Append adds a row
+func (b *VectorBuilder) Append(value Value) {
+	v := &b.vector
+	row := v.length
+	v.length++
+
+	switch {
+	case value.IsNull():
+		b.appendNull(row)
+		return
+	case v.typ == "":
+		v.typ = value.Type()
+		if row > 0 {
+			v.nulls = make([]bool, row, b.capacity)
+			for i := range v.nulls {
+				v.nulls[i] = true
+			}
+			for i := 0; i < row; i++ {
+				b.appendTyped(Value{typ: v.typ})
+			}
+		}
+	case v.typ == DataTypeAny:
+		v.values = append(v.values, value)
+		return
+	case value.Type() != v.typ:
+		b.convertToValues(row)
+		v.values = append(v.values, value)
+		return
+	}
+
+	if v.nulls != nil {
+		v.nulls = append(v.nulls, false)
+	}
+	b.appendTyped(value)
+}
+
+// appendNull adds a null row
+func (b *VectorBuilder) appendNull(row int) {
+	v := &b.vector
+	switch v.typ {
+	case "":
+		// Counted by length only until the type is known
+	case DataTypeAny:
+		v.values = append(v.values, NullValue())
+	default:
+		if v.nulls == nil {
+			v.nulls = make([]bool, row, b.capacity)
+		}
+		v.nulls = append(v.nulls, true)
+		b.appendTyped(Value{typ: v.typ})
+	}
+}
+
+// appendTyped appends a value to the slice of a typed column
+func (b *VectorBuilder) appendTyped(value Value) {
+	v := &b.vector
+	switch v.typ {
+	case DataTypeNumber:
+		if v.numbers == nil {
+			v.numbers = make([]float64, 0, b.capacity)
+		}
+		v.numbers = append(v.numbers, value.number)
+	case DataTypeString:
+		if v.strings == nil {
+			v.strings = make([]string, 0, b.capacity)
+		}
+		v.strings = append(v.strings, value.text)
+	case DataTypeBoolean:
+		if v.booleans == nil {
+			v.booleans = make([]bool, 0, b.capacity)
+		}
+		v.booleans = append(v.booleans, value.boolean)
+	case DataTypeDateTime:
+		if v.dateTimes == nil {
+			v.dateTimes = make([]time.Time, 0, b.capacity)
+		}
+		v.dateTimes = append(v.dateTimes, value.dateTime)
+	}
+}
+
+// convertToValues turns the first rows of a typed column into a column of type any
+func (b *VectorBuilder) convertToValues(rows int) {
+	v := &b.vector
+	values := make([]Value, rows, b.capacity)
+	for i := range values {
+		values[i] = v.At(i)
+	}
+	*v = Vector{typ: DataTypeAny, length: v.length, values: values}
+}
+
+// Build returns the vector built so far.
+// A vector of only nulls has type any.
+func (b *VectorBuilder) Build() *Vector {
+	vector := b.vector
+	if vector.typ == "" {
+		vector.typ = DataTypeAny
+		vector.values = make([]Value, vector.length)
+		for i := range vector.values {
+			vector.values[i] = NullValue()
+		}
+	}
+	return &vector
+}
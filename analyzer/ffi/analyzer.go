@@ -6,13 +6,19 @@ package main
 
 #include "struct/analyzer.h"
 #include "struct/error.h"
+#include "struct/schema.h"
 #include "struct/token.h"
+#include "struct/value.h"
 */
 import "C"
 import (
+	"encoding/json"
+	"fmt"
+	"time"
 	"unsafe"
 
 	"antlr-editor/analyzer/core/app"
+	"antlr-editor/analyzer/core/app/sqlgen"
 	"antlr-editor/analyzer/core/models"
 )
 
@@ -72,6 +78,66 @@ func ToCErrorInfo(err models.ErrorInfo) C.CErrorInfo {
 	}
 }
 
+// Free allocated C strings in CValue
+func freeCValue(value *C.CValue) {
+	if value.string != nil {
+		C.free(unsafe.Pointer(value.string))
+	}
+}
+
+// Convert Go Value to C struct
+func ToCValue(value models.Value) C.CValue {
+	cValue := C.CValue{value_type: C.VALUE_TYPE_NULL}
+	if value.IsNull() {
+		return cValue
+	}
+	switch value.Type() {
+	case models.DataTypeNumber:
+		cValue.value_type = C.VALUE_TYPE_NUMBER
+		cValue.number = C.double(value.Number())
+	case models.DataTypeString:
+		cValue.value_type = C.VALUE_TYPE_STRING
+		cValue.string = C.CString(value.Str())
+	case models.DataTypeBoolean:
+		cValue.value_type = C.VALUE_TYPE_BOOLEAN
+		if value.Boolean() {
+			cValue.boolean = 1
+		}
+	case models.DataTypeDateTime:
+		cValue.value_type = C.VALUE_TYPE_DATETIME
+		cValue.string = C.CString(value.DateTime().Format(time.RFC3339Nano))
+	}
+	return cValue
+}
+
+// Convert C struct to Go Value
+func FromCValue(value C.CValue) (models.Value, error) {
+	switch value.value_type {
+	case C.VALUE_TYPE_NULL:
+		return models.NullValue(), nil
+	case C.VALUE_TYPE_NUMBER:
+		return models.NumberValue(float64(value.number)), nil
+	case C.VALUE_TYPE_STRING:
+		if value.string == nil {
+			return models.NullValue(), fmt.Errorf("string value is NULL")
+		}
+		return models.StringValue(C.GoString(value.string)), nil
+	case C.VALUE_TYPE_BOOLEAN:
+		return models.BooleanValue(value.boolean != 0), nil
+	case C.VALUE_TYPE_DATETIME:
+		if value.string == nil {
+			return models.NullValue(), fmt.Errorf("datetime value is NULL")
+		}
+		t, err := time.Parse(time.RFC3339Nano, C.GoString(value.string))
+		if err != nil {
+			return models.NullValue(), fmt.Errorf("invalid datetime %q, expected RFC 3339", C.GoString(value.string))
+		}
+		return models.DateTimeValue(t), nil
+	default:
+		return models.NullValue(), fmt.Errorf("unknown value type %d", int(value.value_type))
+	}
+}
+
 // Global instances for FFI usage
 var analyzer = app.NewApp()
 
@@ -264,6 +330,716 @@ func FormatFFIString(expression *C.char) *C.char {
 	return C.CString(formatted)
 }
 
+// newCEvaluateResult allocates a C evaluate result holding the given value and errors
+func newCEvaluateResult(value models.Value, errs []models.ErrorInfo) *C.CEvaluateResult {
+	cResult := (*C.CEvaluateResult)(C.malloc(C.sizeof_CEvaluateResult))
+	if cResult == nil {
+		return nil
+	}
+
+	cResult.value = ToCValue(value)
+
+	// Convert errors
+	if len(errs) > 0 {
+		cResult.error_count = C.int32_t(len(errs))
+		cResult.errors = (*C.CErrorInfo)(C.malloc(C.size_t(len(errs)) * C.sizeof_CErrorInfo))
+
+		// Copy each error
+		errors := (*[1 << 30]C.CErrorInfo)(unsafe.Pointer(cResult.errors))[:len(errs):len(errs)]
+		for i, err := range errs {
+			errors[i] = ToCErrorInfo(err)
+		}
+	} else {
+		cResult.error_count = 0
+		cResult.errors = nil
+	}
+
+	return cResult
+}
+
+// EvaluateFFI evaluates expression against a row of column values and returns EvaluateResult struct
+// Datetime values are passed as RFC 3339 strings in both directions
+// The caller is responsible for freeing the returned struct using FreeEvaluateResult
+//
+//export EvaluateFFI
+func EvaluateFFI(expression *C.char, length C.int, row *C.CColumnValue, columnCount C.int) *C.CEvaluateResult {
+	if expression == nil {
+		return nil
+	}
+
+	// Convert C string to Go string
+	expressionStr := C.GoStringN(expression, length)
+
+	// Convert row values
+	rowValues := make(map[string]any)
+	if row != nil && columnCount > 0 {
+		cColumns := (*[1 << 30]C.CColumnValue)(unsafe.Pointer(row))[:columnCount:columnCount]
+		for _, cColumn := range cColumns {
+			if cColumn.name == nil {
+				continue
+			}
+			name := C.GoString(cColumn.name)
+			value, err := FromCValue(cColumn.value)
+			if err != nil {
+				return newCEvaluateResult(models.NullValue(), []models.ErrorInfo{{
+					Message: fmt.Sprintf("Invalid value for column [%s]: %s", name, err),
+					Line:    -1,
+					Column:  -1,
+					Start:   -1,
+					End:     -1,
+				}})
+			}
+			rowValues[name] = value
+		}
+	}
+
+	result := analyzer.Evaluate(expressionStr, rowValues)
+	return newCEvaluateResult(result.Value, result.Errors)
+}
+
+// FreeEvaluateResult frees the memory allocated by EvaluateFFI
+//
+//export FreeEvaluateResult
+func FreeEvaluateResult(result *C.CEvaluateResult) {
+	if result == nil {
+		return
+	}
+
+	freeCValue(&result.value)
+
+	// Free errors
+	if result.errors != nil && result.error_count > 0 {
+		errors := (*[1 << 30]C.CErrorInfo)(unsafe.Pointer(result.errors))[:result.error_count:result.error_count]
+		for i := range errors {
+			freeCErrorInfo(&errors[i])
+		}
+		C.free(unsafe.Pointer(result.errors))
+	}
+
+	// Free the result struct itself
+	C.free(unsafe.Pointer(result))
+}
+
+// fromCColumnBuffer converts a C column buffer of rows rows to a Vector
+// Number buffers are used in place, so the vector must not outlive the call that received the buffer
+func fromCColumnBuffer(buffer *C.CColumnBuffer, rows int) (*models.Vector, error) {
+	var nulls []bool
+	if buffer.nulls != nil {
+		nulls = make([]bool, rows)
+		for i, null := range (*[1 << 30]C.uint8_t)(unsafe.Pointer(buffer.nulls))[:rows:rows] {
+			nulls[i] = null != 0
+		}
+	}
+	isNull := func(row int) bool {
+		return nulls != nil && nulls[row]
+	}
+
+	switch buffer.value_type {
+	case C.VALUE_TYPE_NULL:
+		values := make([]models.Value, rows)
+		for i := range values {
+			values[i] = models.NullValue()
+		}
+		return models.ValueVector(values), nil
+	case C.VALUE_TYPE_NUMBER:
+		if buffer.numbers == nil {
+			return nil, fmt.Errorf("number buffer is NULL")
+		}
+		numbers := (*[1 << 30]float64)(unsafe.Pointer(buffer.numbers))[:rows:rows]
+		return models.NumberVector(numbers, nulls), nil
+	case C.VALUE_TYPE_BOOLEAN:
+		if buffer.booleans == nil {
+			return nil, fmt.Errorf("boolean buffer is NULL")
+		}
+		booleans := make([]bool, rows)
+		for i, b := range (*[1 << 30]C.uint8_t)(unsafe.Pointer(buffer.booleans))[:rows:rows] {
+			booleans[i] = b != 0
+		}
+		return models.BooleanVector(booleans, nulls), nil
+	case C.VALUE_TYPE_STRING, C.VALUE_TYPE_DATETIME:
+		if buffer.strings == nil {
+			return nil, fmt.Errorf("string buffer is NULL")
+		}
+		cStrings := (*[1 << 30]*C.char)(unsafe.Pointer(buffer.strings))[:rows:rows]
+		if buffer.value_type == C.VALUE_TYPE_STRING {
+			strings := make([]string, rows)
+			for i, cString := range cStrings {
+				if cString != nil && !isNull(i) {
+					strings[i] = C.GoString(cString)
+				}
+			}
+			return models.StringVector(strings, nulls), nil
+		}
+		dateTimes := make([]time.Time, rows)
+		for i, cString := range cStrings {
+			if cString == nil || isNull(i) {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339Nano, C.GoString(cString))
+			if err != nil {
+				return nil, fmt.Errorf("row %d: invalid datetime %q, expected RFC 3339", i, C.GoString(cString))
+			}
+			dateTimes[i] = t
+		}
+		return models.DateTimeVector(dateTimes, nulls), nil
+	case C.VALUE_TYPE_MIXED:
+		if buffer.values == nil {
+			return nil, fmt.Errorf("value buffer is NULL")
+		}
+		values := make([]models.Value, rows)
+		for i, cValue := range (*[1 << 30]C.CValue)(unsafe.Pointer(buffer.values))[:rows:rows] {
+			if isNull(i) {
+				values[i] = models.NullValue()
+				continue
+			}
+			value, err := FromCValue(cValue)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i, err)
+			}
+			values[i] = value
+		}
+		return models.ValueVector(values), nil
+	default:
+		return nil, fmt.Errorf("unknown value type %d", int(buffer.value_type))
+	}
+}
+
+// toCColumnBuffer converts a result Vector to a C column buffer with a null mask
+// A vector of type any becomes a MIXED buffer, or a NULL buffer if every row is null
+func toCColumnBuffer(vector *models.Vector) C.CColumnBuffer {
+	rows := vector.Len()
+	buffer := C.CColumnBuffer{value_type: C.VALUE_TYPE_NULL}
+	if rows == 0 {
+		return buffer
+	}
+
+	buffer.nulls = (*C.uint8_t)(C.malloc(C.size_t(rows)))
+	nulls := (*[1 << 30]C.uint8_t)(unsafe.Pointer(buffer.nulls))[:rows:rows]
+	allNull := true
+	for i := range nulls {
+		nulls[i] = 0
+		if vector.IsNull(i) {
+			nulls[i] = 1
+		} else {
+			allNull = false
+		}
+	}
+	if allNull {
+		return buffer
+	}
+
+	switch vector.Type() {
+	case models.DataTypeNumber:
+		buffer.value_type = C.VALUE_TYPE_NUMBER
+		buffer.numbers = (*C.double)(C.malloc(C.size_t(rows) * C.sizeof_double))
+		copy((*[1 << 30]float64)(unsafe.Pointer(buffer.numbers))[:rows:rows], vector.Numbers())
+	case models.DataTypeBoolean:
+		buffer.value_type = C.VALUE_TYPE_BOOLEAN
+		buffer.booleans = (*C.uint8_t)(C.malloc(C.size_t(rows)))
+		booleans := (*[1 << 30]C.uint8_t)(unsafe.Pointer(buffer.booleans))[:rows:rows]
+		for i, b := range vector.Booleans() {
+			booleans[i] = 0
+			if b {
+				booleans[i] = 1
+			}
+		}
+	case models.DataTypeString, models.DataTypeDateTime:
+		buffer.value_type = C.VALUE_TYPE_STRING
+		if vector.Type() == models.DataTypeDateTime {
+			buffer.value_type = C.VALUE_TYPE_DATETIME
+		}
+		buffer.strings = (**C.char)(C.malloc(C.size_t(rows) * C.size_t(unsafe.Sizeof(uintptr(0)))))
+		strings := (*[1 << 30]*C.char)(unsafe.Pointer(buffer.strings))[:rows:rows]
+		for i := range strings {
+			strings[i] = nil
+			if vector.IsNull(i) {
+				continue
+			}
+			value := vector.At(i)
+			if value.Type() == models.DataTypeDateTime {
+				strings[i] = C.CString(value.DateTime().Format(time.RFC3339Nano))
+			} else {
+				strings[i] = C.CString(value.Str())
+			}
+		}
+	default:
+		buffer.value_type = C.VALUE_TYPE_MIXED
+		buffer.values = (*C.CValue)(C.malloc(C.size_t(rows) * C.sizeof_CValue))
+		values := (*[1 << 30]C.CValue)(unsafe.Pointer(buffer.values))[:rows:rows]
+		for i := range values {
+			values[i] = ToCValue(vector.At(i))
+		}
+	}
+	return buffer
+}
+
+// freeCColumnBuffer frees the buffers of a result column
+func freeCColumnBuffer(buffer *C.CColumnBuffer, rows int) {
+	if buffer.strings != nil {
+		for _, s := range (*[1 << 30]*C.char)(unsafe.Pointer(buffer.strings))[:rows:rows] {
+			if s != nil {
+				C.free(unsafe.Pointer(s))
+			}
+		}
+		C.free(unsafe.Pointer(buffer.strings))
+	}
+	if buffer.values != nil {
+		values := (*[1 << 30]C.CValue)(unsafe.Pointer(buffer.values))[:rows:rows]
+		for i := range values {
+			freeCValue(&values[i])
+		}
+		C.free(unsafe.Pointer(buffer.values))
+	}
+	if buffer.numbers != nil {
+		C.free(unsafe.Pointer(buffer.numbers))
+	}
+	if buffer.booleans != nil {
+		C.free(unsafe.Pointer(buffer.booleans))
+	}
+	if buffer.nulls != nil {
+		C.free(unsafe.Pointer(buffer.nulls))
+	}
+}
+
+// batchFailure creates a batch result for errors that prevented evaluating any row
+func batchFailure(errs []models.ErrorInfo) *C.CBatchResult {
+	rowErrors := make([]app.RowError, len(errs))
+	for i, err := range errs {
+		rowErrors[i] = app.RowError{Row: -1, ErrorInfo: err}
+	}
+	return newCBatchResult(nil, rowErrors)
+}
+
+// newCBatchResult allocates a C batch result; result is nil when no row was evaluated
+func newCBatchResult(result *app.BatchResult, rowErrors []app.RowError) *C.CBatchResult {
+	cResult := (*C.CBatchResult)(C.malloc(C.sizeof_CBatchResult))
+	if cResult == nil {
+		return nil
+	}
+	*cResult = C.CBatchResult{column: C.CColumnBuffer{value_type: C.VALUE_TYPE_NULL}}
+
+	if result != nil {
+		rows := result.Values.Len()
+		cResult.row_count = C.int32_t(rows)
+		cResult.column = toCColumnBuffer(result.Values)
+		if rows > 0 {
+			cResult.error_mask = (*C.uint8_t)(C.malloc(C.size_t(rows)))
+			mask := (*[1 << 30]C.uint8_t)(unsafe.Pointer(cResult.error_mask))[:rows:rows]
+			for i, failed := range result.Failed {
+				mask[i] = 0
+				if failed {
+					mask[i] = 1
+				}
+			}
+		}
+		rowErrors = result.Errors
+	}
+
+	if len(rowErrors) > 0 {
+		cResult.error_count = C.int32_t(len(rowErrors))
+		cResult.errors = (*C.CErrorInfo)(C.malloc(C.size_t(len(rowErrors)) * C.sizeof_CErrorInfo))
+		cResult.error_rows = (*C.int32_t)(C.malloc(C.size_t(len(rowErrors)) * C.sizeof_int32_t))
+		errors := (*[1 << 30]C.CErrorInfo)(unsafe.Pointer(cResult.errors))[:len(rowErrors):len(rowErrors)]
+		errorRows := (*[1 << 30]C.int32_t)(unsafe.Pointer(cResult.error_rows))[:len(rowErrors):len(rowErrors)]
+		for i, err := range rowErrors {
+			errors[i] = ToCErrorInfo(err.ErrorInfo)
+			errorRows[i] = C.int32_t(err.Row)
+		}
+	}
+
+	return cResult
+}
+
+// EvaluateBatchFFI evaluates expression for rowCount rows given as column buffers and returns BatchResult struct
+// The expression is compiled once; every input buffer must hold rowCount rows
+// Row errors are reported in the error mask and error list; errors that prevent evaluating any row,
+// such as syntax errors or malformed buffers, are reported with row -1 and a row count of 0
+// The caller is responsible for freeing the returned struct using FreeBatchResult
+//
+//export EvaluateBatchFFI
+func EvaluateBatchFFI(expression *C.char, length C.int, columns *C.CColumnBuffer, columnCount C.int, rowCount C.int) *C.CBatchResult {
+	if expression == nil || rowCount < 0 {
+		return nil
+	}
+
+	// Convert C string to Go string
+	expressionStr := C.GoStringN(expression, length)
+	rows := int(rowCount)
+
+	// Convert column buffers
+	vectors := make(map[string]*models.Vector)
+	if columns != nil && columnCount > 0 {
+		cColumns := (*[1 << 30]C.CColumnBuffer)(unsafe.Pointer(columns))[:columnCount:columnCount]
+		for i := range cColumns {
+			if cColumns[i].name == nil {
+				continue
+			}
+			name := C.GoString(cColumns[i].name)
+			vector, err := fromCColumnBuffer(&cColumns[i], rows)
+			if err != nil {
+				return batchFailure([]models.ErrorInfo{{
+					Message: fmt.Sprintf("Invalid value for column [%s]: %s", name, err),
+					Line:    -1,
+					Column:  -1,
+					Start:   -1,
+					End:     -1,
+				}})
+			}
+			vectors[name] = vector
+		}
+	}
+
+	compiled, errs := analyzer.Compile(expressionStr)
+	if len(errs) > 0 {
+		return batchFailure(errs)
+	}
+
+	result, err := compiled.EvaluateVectors(vectors, rows)
+	if err != nil {
+		return batchFailure([]models.ErrorInfo{{Message: err.Error(), Line: -1, Column: -1, Start: -1, End: -1}})
+	}
+	return newCBatchResult(result, nil)
+}
+
+// FreeBatchResult frees the memory allocated by EvaluateBatchFFI
+//
+//export FreeBatchResult
+func FreeBatchResult(result *C.CBatchResult) {
+	if result == nil {
+		return
+	}
+
+	freeCColumnBuffer(&result.column, int(result.row_count))
+	if result.error_mask != nil {
+		C.free(unsafe.Pointer(result.error_mask))
+	}
+
+	// Free errors
+	if result.errors != nil && result.error_count > 0 {
+		errors := (*[1 << 30]C.CErrorInfo)(unsafe.Pointer(result.errors))[:result.error_count:result.error_count]
+		for i := range errors {
+			freeCErrorInfo(&errors[i])
+		}
+		C.free(unsafe.Pointer(result.errors))
+	}
+	if result.error_rows != nil {
+		C.free(unsafe.Pointer(result.error_rows))
+	}
+
+	// Free the result struct itself
+	C.free(unsafe.Pointer(result))
+}
+
+// newCTranspileResult allocates a C transpile result holding the given code and errors
+func newCTranspileResult(result *app.TranspileResult) *C.CTranspileResult {
+	cResult := (*C.CTranspileResult)(C.malloc(C.sizeof_CTranspileResult))
+	if cResult == nil {
+		return nil
+	}
+
+	cResult.code = C.CString(result.Code)
+
+	// Convert errors
+	if len(result.Errors) > 0 {
+		cResult.error_count = C.int32_t(len(result.Errors))
+		cResult.errors = (*C.CErrorInfo)(C.malloc(C.size_t(len(result.Errors)) * C.sizeof_CErrorInfo))
+
+		// Copy each error
+		errors := (*[1 << 30]C.CErrorInfo)(unsafe.Pointer(cResult.errors))[:len(result.Errors):len(result.Errors)]
+		for i, err := range result.Errors {
+			errors[i] = ToCErrorInfo(err)
+		}
+	} else {
+		cResult.error_count = 0
+		cResult.errors = nil
+	}
+
+	return cResult
+}
+
+// ToSQLFFI translates expression to SQL and returns TranspileResult struct
+// dialect names one of the built-in dialects ("sqlite", "postgresql" or "ansi"); NULL selects ANSI
+// The caller is responsible for freeing the returned struct using FreeTranspileResult
+//
+//export ToSQLFFI
+func ToSQLFFI(expression *C.char, length C.int, dialect *C.char) *C.CTranspileResult {
+	if expression == nil {
+		return nil
+	}
+
+	// Convert C string to Go string
+	expressionStr := C.GoStringN(expression, length)
+
+	sqlDialect := sqlgen.ANSI
+	if dialect != nil {
+		name := C.GoString(dialect)
+		var ok bool
+		if sqlDialect, ok = sqlgen.LookupDialect(name); !ok {
+			return newCTranspileResult(&app.TranspileResult{Errors: []models.ErrorInfo{{
+				Message: fmt.Sprintf("Unknown SQL dialect: %s", name),
+				Line:    -1,
+				Column:  -1,
+				Start:   -1,
+				End:     -1,
+			}}})
+		}
+	}
+
+	return newCTranspileResult(analyzer.ToSQL(expressionStr, sqlDialect))
+}
+
+// ToPandasFFI translates expression to a vectorized pandas expression over a DataFrame named df
+// and returns TranspileResult struct
+// The caller is responsible for freeing the returned struct using FreeTranspileResult
+//
+//export ToPandasFFI
+func ToPandasFFI(expression *C.char, length C.int) *C.CTranspileResult {
+	if expression == nil {
+		return nil
+	}
+
+	// Convert C string to Go string
+	expressionStr := C.GoStringN(expression, length)
+
+	return newCTranspileResult(analyzer.ToPandas(expressionStr))
+}
+
+// SourceFromJSONFFI regenerates canonical expression source from a parse tree or AST serialized as JSON
+// and returns TranspileResult struct whose code is the formatted source
+// The caller is responsible for freeing the returned struct using FreeTranspileResult
+//
+//export SourceFromJSONFFI
+func SourceFromJSONFFI(document *C.char, length C.int) *C.CTranspileResult {
+	if document == nil {
+		return nil
+	}
+
+	return newCTranspileResult(analyzer.SourceFromJSON([]byte(C.GoStringN(document, length))))
+}
+
+// FreeTranspileResult frees the memory allocated by ToSQLFFI, ToPandasFFI and SourceFromJSONFFI
+//
+//export FreeTranspileResult
+func FreeTranspileResult(result *C.CTranspileResult) {
+	if result == nil {
+		return
+	}
+
+	if result.code != nil {
+		C.free(unsafe.Pointer(result.code))
+	}
+
+	// Free errors
+	if result.errors != nil && result.error_count > 0 {
+		errors := (*[1 << 30]C.CErrorInfo)(unsafe.Pointer(result.errors))[:result.error_count:result.error_count]
+		for i := range errors {
+			freeCErrorInfo(&errors[i])
+		}
+		C.free(unsafe.Pointer(result.errors))
+	}
+
+	// Free the result struct itself
+	C.free(unsafe.Pointer(result))
+}
+
+// RenameColumnFFI renames the references to column oldName in expression to newName and returns RenameResult struct
+// Whitespace, layout and string literals are left untouched; edit positions are code point offsets
+// The caller is responsible for freeing the returned struct using FreeRenameResult
+//
+//export RenameColumnFFI
+func RenameColumnFFI(expression *C.char, length C.int, oldName *C.char, newName *C.char) *C.CRenameResult {
+	if expression == nil || oldName == nil || newName == nil {
+		return nil
+	}
+
+	// Convert C strings to Go strings
+	expressionStr := C.GoStringN(expression, length)
+	result := analyzer.RenameColumn(expressionStr, C.GoString(oldName), C.GoString(newName))
+
+	cResult := (*C.CRenameResult)(C.malloc(C.sizeof_CRenameResult))
+	if cResult == nil {
+		return nil
+	}
+
+	cResult.expression = C.CString(result.Expression)
+
+	// Convert edits
+	if len(result.Edits) > 0 {
+		cResult.edit_count = C.int32_t(len(result.Edits))
+		cResult.edits = (*C.CTextEdit)(C.malloc(C.size_t(len(result.Edits)) * C.sizeof_CTextEdit))
+
+		edits := (*[1 << 30]C.CTextEdit)(unsafe.Pointer(cResult.edits))[:len(result.Edits):len(result.Edits)]
+		for i, edit := range result.Edits {
+			edits[i] = C.CTextEdit{
+				start:    C.int32_t(edit.Start),
+				end:      C.int32_t(edit.End),
+				new_text: C.CString(edit.NewText),
+			}
+		}
+	} else {
+		cResult.edit_count = 0
+		cResult.edits = nil
+	}
+
+	// Convert errors
+	if len(result.Errors) > 0 {
+		cResult.error_count = C.int32_t(len(result.Errors))
+		cResult.errors = (*C.CErrorInfo)(C.malloc(C.size_t(len(result.Errors)) * C.sizeof_CErrorInfo))
+
+		errors := (*[1 << 30]C.CErrorInfo)(unsafe.Pointer(cResult.errors))[:len(result.Errors):len(result.Errors)]
+		for i, err := range result.Errors {
+			errors[i] = ToCErrorInfo(err)
+		}
+	} else {
+		cResult.error_count = 0
+		cResult.errors = nil
+	}
+
+	return cResult
+}
+
+// FreeRenameResult frees the memory allocated by RenameColumnFFI
+//
+//export FreeRenameResult
+func FreeRenameResult(result *C.CRenameResult) {
+	if result == nil {
+		return
+	}
+
+	if result.expression != nil {
+		C.free(unsafe.Pointer(result.expression))
+	}
+
+	// Free edits
+	if result.edits != nil && result.edit_count > 0 {
+		edits := (*[1 << 30]C.CTextEdit)(unsafe.Pointer(result.edits))[:result.edit_count:result.edit_count]
+		for i := range edits {
+			if edits[i].new_text != nil {
+				C.free(unsafe.Pointer(edits[i].new_text))
+			}
+		}
+		C.free(unsafe.Pointer(result.edits))
+	}
+
+	// Free errors
+	if result.errors != nil && result.error_count > 0 {
+		errors := (*[1 << 30]C.CErrorInfo)(unsafe.Pointer(result.errors))[:result.error_count:result.error_count]
+		for i := range errors {
+			freeCErrorInfo(&errors[i])
+		}
+		C.free(unsafe.Pointer(result.errors))
+	}
+
+	// Free the result struct itself
+	C.free(unsafe.Pointer(result))
+}
+
+// marshalCString encodes value as JSON into a C string (caller must free)
+func marshalCString(value map[string]any) *C.char {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(map[string]any{"error": err.Error()})
+	}
+	return C.CString(string(data))
+}
+
+// AnalyzeCatalogFFI analyzes a catalog of named expressions given as JSON {"expressions": [{"name", "expression"}]}
+// Returns JSON {"report": {...}, "error": null}, or {"report": null, "error": "..."} if the catalog cannot be loaded
+// The caller is responsible for freeing the returned string using FreeString
+//
+//export AnalyzeCatalogFFI
+func AnalyzeCatalogFFI(catalog *C.char, length C.int) *C.char {
+	if catalog == nil {
+		return nil
+	}
+
+	loaded, err := analyzer.LoadCatalog([]byte(C.GoStringN(catalog, length)))
+	if err != nil {
+		return marshalCString(map[string]any{"report": nil, "error": err.Error()})
+	}
+	return marshalCString(map[string]any{"report": loaded.Analyze().AsMap(), "error": nil})
+}
+
+// CatalogImpactFFI lists the named expressions of a JSON catalog that depend on column, in evaluation order
+// Returns JSON {"expressions": [...], "error": null}, or {"expressions": [], "error": "..."} if the catalog cannot be loaded
+// The caller is responsible for freeing the returned string using FreeString
+//
+//export CatalogImpactFFI
+func CatalogImpactFFI(catalog *C.char, length C.int, column *C.char) *C.char {
+	if catalog == nil || column == nil {
+		return nil
+	}
+
+	loaded, err := analyzer.LoadCatalog([]byte(C.GoStringN(catalog, length)))
+	if err != nil {
+		return marshalCString(map[string]any{"expressions": []string{}, "error": err.Error()})
+	}
+	return marshalCString(map[string]any{"expressions": loaded.Impact(C.GoString(column)), "error": nil})
+}
+
+// NodeAtFFI finds the parse tree node at an offset of the expression, in the position encoding
+// Returns JSON {"node", "ancestors", "type", "function", "column"}: the innermost node, the nodes enclosing it
+// innermost first, and the hover content for it; node is null if the offset is outside the expression
+// The caller is responsible for freeing the returned string using FreeString
+//
+//export NodeAtFFI
+func NodeAtFFI(expression *C.char, length C.int, offset C.int) *C.char {
+	if expression == nil {
+		return nil
+	}
+
+	expressionStr := C.GoStringN(expression, length)
+	return marshalCString(analyzer.NodeAt(expressionStr, int(offset)).AsMap())
+}
+
+// SetPositionEncodingFFI sets the unit of the positions of all results and of offsets passed in:
+// "rune" for code points (the default), "utf-16" for UTF-16 code units or "byte" for UTF-8 bytes
+// Returns 1 on success, 0 for NULL or an unknown encoding (the previous encoding is kept)
+//
+//export SetPositionEncodingFFI
+func SetPositionEncodingFFI(encoding *C.char) C.int {
+	if encoding == nil {
+		return 0
+	}
+	if err := analyzer.SetPositionEncoding(models.PositionEncoding(C.GoString(encoding))); err != nil {
+		return 0
+	}
+	return 1
+}
+
+// SetSchemaFFI sets the columns that expressions may reference
+// Passing NULL or a count of 0 clears the schema so that any column is accepted
+// Returns 1 on success, 0 if a column has no name or an unknown type (the previous schema is kept)
+//
+//export SetSchemaFFI
+func SetSchemaFFI(columns *C.CColumnInfo, count C.int) C.int {
+	if columns == nil || count <= 0 {
+		analyzer.SetSchema(nil)
+		return 1
+	}
+
+	cColumns := (*[1 << 30]C.CColumnInfo)(unsafe.Pointer(columns))[:count:count]
+	schemaColumns := make([]models.Column, len(cColumns))
+	for i, cColumn := range cColumns {
+		if cColumn.name == nil {
+			return 0
+		}
+		schemaColumns[i].Name = C.GoString(cColumn.name)
+		if cColumn._type != nil {
+			schemaColumns[i].Type = models.DataType(C.GoString(cColumn._type))
+			if !schemaColumns[i].Type.IsValid() {
+				return 0
+			}
+		}
+		if cColumn.description != nil {
+			schemaColumns[i].Description = C.GoString(cColumn.description)
+		}
+	}
+
+	analyzer.SetSchema(models.NewSchema(schemaColumns))
+	return 1
+}
+
 // main function required for FFI builds
 func main() {
 	// This is a no-op main function for FFI builds
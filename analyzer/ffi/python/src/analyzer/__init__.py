@@ -5,7 +5,30 @@ This module provides Python bindings for the ANTLR expression analyzer.
 """
 
 from .analyzer import Analyzer
-from .models import TokenizeResult, TokenInfo, ErrorInfo, TokenType
+from .models import BatchResult, CatalogExpression, CatalogReport, Column, EvaluateResult, FunctionSignature, MissingDependency, NodeAtResult, NodeInfo, NodeType, Parameter, RenameResult, RowError, TextEdit, TokenizeResult, TokenInfo, ErrorInfo, TokenType, TranspileResult, Value, ValueType
 
 __version__ = "0.1.0"
-__all__ = ["Analyzer", "TokenizeResult", "TokenInfo", "ErrorInfo", "TokenType"]
+__all__ = [
+    "Analyzer",
+    "BatchResult",
+    "CatalogExpression",
+    "CatalogReport",
+    "Column",
+    "EvaluateResult",
+    "FunctionSignature",
+    "MissingDependency",
+    "NodeAtResult",
+    "NodeInfo",
+    "NodeType",
+    "Parameter",
+    "RenameResult",
+    "RowError",
+    "TextEdit",
+    "TokenizeResult",
+    "TokenInfo",
+    "ErrorInfo",
+    "TokenType",
+    "TranspileResult",
+    "Value",
+    "ValueType",
+]
@@ -3,10 +3,13 @@ Python bindings for the ANTLR expression analyzer.
 """
 
 import ctypes
+import json
 import platform
+from datetime import datetime, timezone
 from pathlib import Path
+from typing import Mapping, Sequence
 
-from .models import TokenType, TokenInfo, ErrorInfo, TokenizeResult
+from .models import BatchResult, CatalogExpression, CatalogReport, Column, EvaluateResult, FunctionSignature, MissingDependency, NodeAtResult, NodeInfo, NodeType, Parameter, RenameResult, RowError, TextEdit, TokenType, TokenInfo, ErrorInfo, TokenizeResult, TranspileResult, Value, ValueType
 
 
 # C struct definitions
@@ -46,6 +49,105 @@ class CTokenizeResult(ctypes.Structure):
     ]
 
 
+class CValue(ctypes.Structure):
+    """C struct for a runtime value."""
+
+    _fields_ = [
+        ("value_type", ctypes.c_int),
+        ("number", ctypes.c_double),
+        ("string", ctypes.c_char_p),
+        ("boolean", ctypes.c_int32),
+    ]
+
+
+class CColumnValue(ctypes.Structure):
+    """C struct for a named column value."""
+
+    _fields_ = [
+        ("name", ctypes.c_char_p),
+        ("value", CValue),
+    ]
+
+
+class CEvaluateResult(ctypes.Structure):
+    """C struct for evaluate result."""
+
+    _fields_ = [
+        ("value", CValue),
+        ("errors", ctypes.POINTER(CErrorInfo)),
+        ("error_count", ctypes.c_int32),
+    ]
+
+
+class CColumnBuffer(ctypes.Structure):
+    """C struct for a column of values."""
+
+    _fields_ = [
+        ("name", ctypes.c_char_p),
+        ("value_type", ctypes.c_int),
+        ("numbers", ctypes.POINTER(ctypes.c_double)),
+        ("strings", ctypes.POINTER(ctypes.c_char_p)),
+        ("booleans", ctypes.POINTER(ctypes.c_uint8)),
+        ("values", ctypes.POINTER(CValue)),
+        ("nulls", ctypes.POINTER(ctypes.c_uint8)),
+    ]
+
+
+class CBatchResult(ctypes.Structure):
+    """C struct for batch evaluate result."""
+
+    _fields_ = [
+        ("column", CColumnBuffer),
+        ("row_count", ctypes.c_int32),
+        ("error_mask", ctypes.POINTER(ctypes.c_uint8)),
+        ("errors", ctypes.POINTER(CErrorInfo)),
+        ("error_rows", ctypes.POINTER(ctypes.c_int32)),
+        ("error_count", ctypes.c_int32),
+    ]
+
+
+class CTranspileResult(ctypes.Structure):
+    """C struct for transpile result."""
+
+    _fields_ = [
+        ("code", ctypes.c_char_p),
+        ("errors", ctypes.POINTER(CErrorInfo)),
+        ("error_count", ctypes.c_int32),
+    ]
+
+
+class CTextEdit(ctypes.Structure):
+    """C struct for text edit."""
+
+    _fields_ = [
+        ("start", ctypes.c_int32),
+        ("end", ctypes.c_int32),
+        ("new_text", ctypes.c_char_p),
+    ]
+
+
+class CRenameResult(ctypes.Structure):
+    """C struct for rename result."""
+
+    _fields_ = [
+        ("expression", ctypes.c_char_p),
+        ("edits", ctypes.POINTER(CTextEdit)),
+        ("edit_count", ctypes.c_int32),
+        ("errors", ctypes.POINTER(CErrorInfo)),
+        ("error_count", ctypes.c_int32),
+    ]
+
+
+class CColumnInfo(ctypes.Structure):
+    """C struct for column information."""
+
+    _fields_ = [
+        ("name", ctypes.c_char_p),
+        ("type", ctypes.c_char_p),
+        ("description", ctypes.c_char_p),
+    ]
+
+
 class Analyzer:
     """Python interface to the ANTLR expression analyzer."""
 
@@ -101,6 +203,66 @@ class Analyzer:
         self._lib.FormatFFI.argtypes = [ctypes.c_char_p, ctypes.c_int]
         self._lib.FormatFFI.restype = ctypes.c_char_p
 
+        # EvaluateFFI
+        self._lib.EvaluateFFI.argtypes = [ctypes.c_char_p, ctypes.c_int, ctypes.POINTER(CColumnValue), ctypes.c_int]
+        self._lib.EvaluateFFI.restype = ctypes.POINTER(CEvaluateResult)
+
+        # FreeEvaluateResult
+        self._lib.FreeEvaluateResult.argtypes = [ctypes.POINTER(CEvaluateResult)]
+        self._lib.FreeEvaluateResult.restype = None
+
+        # EvaluateBatchFFI
+        self._lib.EvaluateBatchFFI.argtypes = [
+            ctypes.c_char_p,
+            ctypes.c_int,
+            ctypes.POINTER(CColumnBuffer),
+            ctypes.c_int,
+            ctypes.c_int,
+        ]
+        self._lib.EvaluateBatchFFI.restype = ctypes.POINTER(CBatchResult)
+
+        # FreeBatchResult
+        self._lib.FreeBatchResult.argtypes = [ctypes.POINTER(CBatchResult)]
+        self._lib.FreeBatchResult.restype = None
+
+        # ToSQLFFI
+        self._lib.ToSQLFFI.argtypes = [ctypes.c_char_p, ctypes.c_int, ctypes.c_char_p]
+        self._lib.ToSQLFFI.restype = ctypes.POINTER(CTranspileResult)
+
+        # ToPandasFFI
+        self._lib.ToPandasFFI.argtypes = [ctypes.c_char_p, ctypes.c_int]
+        self._lib.ToPandasFFI.restype = ctypes.POINTER(CTranspileResult)
+
+        # SourceFromJSONFFI
+        self._lib.SourceFromJSONFFI.argtypes = [ctypes.c_char_p, ctypes.c_int]
+        self._lib.SourceFromJSONFFI.restype = ctypes.POINTER(CTranspileResult)
+
+        # FreeTranspileResult
+        self._lib.FreeTranspileResult.argtypes = [ctypes.POINTER(CTranspileResult)]
+        self._lib.FreeTranspileResult.restype = None
+
+        # SetSchemaFFI
+        self._lib.RenameColumnFFI.argtypes = [ctypes.c_char_p, ctypes.c_int, ctypes.c_char_p, ctypes.c_char_p]
+        self._lib.RenameColumnFFI.restype = ctypes.POINTER(CRenameResult)
+
+        self._lib.FreeRenameResult.argtypes = [ctypes.POINTER(CRenameResult)]
+        self._lib.FreeRenameResult.restype = None
+
+        self._lib.AnalyzeCatalogFFI.argtypes = [ctypes.c_char_p, ctypes.c_int]
+        self._lib.AnalyzeCatalogFFI.restype = ctypes.POINTER(ctypes.c_char)
+
+        self._lib.CatalogImpactFFI.argtypes = [ctypes.c_char_p, ctypes.c_int, ctypes.c_char_p]
+        self._lib.CatalogImpactFFI.restype = ctypes.POINTER(ctypes.c_char)
+
+        self._lib.NodeAtFFI.argtypes = [ctypes.c_char_p, ctypes.c_int, ctypes.c_int]
+        self._lib.NodeAtFFI.restype = ctypes.POINTER(ctypes.c_char)
+
+        self._lib.SetPositionEncodingFFI.argtypes = [ctypes.c_char_p]
+        self._lib.SetPositionEncodingFFI.restype = ctypes.c_int
+
+        self._lib.SetSchemaFFI.argtypes = [ctypes.POINTER(CColumnInfo), ctypes.c_int]
+        self._lib.SetSchemaFFI.restype = ctypes.c_int
+
         # FreeTokenizeResult
         self._lib.FreeTokenizeResult.argtypes = [ctypes.POINTER(CTokenizeResult)]
         self._lib.FreeTokenizeResult.restype = None
@@ -205,3 +367,512 @@ class Analyzer:
             # Free the C memory allocated for the formatted string
             self._lib.FreeString(result_ptr)
         return formatted
+
+    def evaluate(self, expression: str, row: Mapping[str, Value] | None = None) -> EvaluateResult:
+        """
+        Evaluate an expression against a row of column values.
+
+        Args:
+            expression: The expression to evaluate.
+            row: Column values keyed by column name (without brackets).
+                Values may be int, float, str, bool, datetime or None.
+
+        Returns:
+            EvaluateResult containing the value, or the errors that prevented evaluation.
+
+        Raises:
+            TypeError: If a row value has an unsupported type.
+        """
+        row = row or {}
+        c_row = (CColumnValue * len(row))()
+        for i, (name, value) in enumerate(row.items()):
+            c_row[i].name = name.encode("utf-8")
+            c_row[i].value = self._to_c_value(name, value)
+
+        expr_bytes = expression.encode("utf-8")
+        c_result_ptr = self._lib.EvaluateFFI(expr_bytes, len(expr_bytes), c_row, len(row))
+
+        if not c_result_ptr:
+            return EvaluateResult(value=None, errors=[])
+
+        try:
+            c_result = c_result_ptr.contents
+
+            # Convert errors
+            errors = []
+            for i in range(c_result.error_count):
+                c_error = c_result.errors[i]
+                error = ErrorInfo(
+                    message=c_error.message.decode("utf-8") if c_error.message else "",
+                    line=c_error.line,
+                    column=c_error.column,
+                    start=c_error.start,
+                    end=c_error.end,
+                )
+                errors.append(error)
+
+            return EvaluateResult(value=self._from_c_value(c_result.value), errors=errors)
+        finally:
+            # Free the C memory
+            self._lib.FreeEvaluateResult(c_result_ptr)
+
+    def evaluate_batch(self, expression: str, columns: Mapping[str, Sequence[Value]]) -> BatchResult:
+        """
+        Evaluate an expression for every row of the given columns in a single call.
+
+        The expression is compiled once and the columns cross the FFI boundary as buffers,
+        which is much faster than calling evaluate per row.
+
+        Args:
+            expression: The expression to evaluate.
+            columns: Column values keyed by column name (without brackets); all columns must have the same length.
+                A column whose non-None values are all floats may also be any buffer of doubles,
+                such as array.array("d"), which is passed without copying.
+
+        Returns:
+            BatchResult with one value per row, None where the row failed, and the error of each failed row.
+            Errors that prevent evaluating any row, such as syntax errors, have row -1 and no values.
+
+        Raises:
+            TypeError: If a column value has an unsupported type.
+            ValueError: If the columns have different lengths.
+        """
+        lengths = {len(column) for column in columns.values()}
+        if len(lengths) > 1:
+            raise ValueError(f"All columns must have the same length, got {sorted(lengths)}")
+        row_count = lengths.pop() if lengths else 0
+
+        c_columns = (CColumnBuffer * len(columns))()
+        keep_alive = []
+        for i, (name, column) in enumerate(columns.items()):
+            c_columns[i].name = name.encode("utf-8")
+            keep_alive.append(self._to_c_column(c_columns[i], name, column))
+
+        expr_bytes = expression.encode("utf-8")
+        c_result_ptr = self._lib.EvaluateBatchFFI(expr_bytes, len(expr_bytes), c_columns, len(columns), row_count)
+
+        if not c_result_ptr:
+            return BatchResult(values=[], failed=[], errors=[])
+
+        try:
+            c_result = c_result_ptr.contents
+            rows = c_result.row_count
+
+            # Convert errors
+            errors = []
+            for i in range(c_result.error_count):
+                c_error = c_result.errors[i]
+                error = ErrorInfo(
+                    message=c_error.message.decode("utf-8") if c_error.message else "",
+                    line=c_error.line,
+                    column=c_error.column,
+                    start=c_error.start,
+                    end=c_error.end,
+                )
+                errors.append(RowError(row=c_result.error_rows[i], error=error))
+
+            failed = [bool(c_result.error_mask[i]) for i in range(rows)] if rows else []
+            return BatchResult(values=self._from_c_column(c_result.column, rows), failed=failed, errors=errors)
+        finally:
+            # Free the C memory
+            self._lib.FreeBatchResult(c_result_ptr)
+
+    @classmethod
+    def _to_c_column(cls, buffer: CColumnBuffer, name: str, column: Sequence[Value]) -> object:
+        """Fill a C column buffer and return the objects that must stay alive while it is used."""
+        rows = len(column)
+
+        # Buffers of doubles are passed without copying
+        try:
+            view = memoryview(column)
+        except TypeError:
+            view = None
+        if view is not None and view.format == "d" and view.c_contiguous and not view.readonly:
+            buffer.value_type = ValueType.NUMBER
+            buffer.numbers = (ctypes.c_double * rows).from_buffer(view)
+            return view
+
+        types = {type(value) for value in column if value is not None}
+        nulls = (ctypes.c_uint8 * rows)(*(value is None for value in column))
+        buffer.nulls = nulls
+        if not types:
+            buffer.value_type = ValueType.NULL
+            return nulls
+        if types <= {int, float}:
+            buffer.value_type = ValueType.NUMBER
+            numbers = (ctypes.c_double * rows)(*(0.0 if value is None else float(value) for value in column))
+            buffer.numbers = numbers
+            return nulls, numbers
+        if types == {bool}:
+            buffer.value_type = ValueType.BOOLEAN
+            booleans = (ctypes.c_uint8 * rows)(*(bool(value) for value in column))
+            buffer.booleans = booleans
+            return nulls, booleans
+        if types == {str}:
+            buffer.value_type = ValueType.STRING
+            strings = (ctypes.c_char_p * rows)(*(None if value is None else value.encode("utf-8") for value in column))
+            buffer.strings = strings
+            return nulls, strings
+        buffer.value_type = ValueType.MIXED
+        values = (CValue * rows)(*(cls._to_c_value(name, value) for value in column))
+        buffer.values = values
+        return nulls, values
+
+    @classmethod
+    def _from_c_column(cls, buffer: CColumnBuffer, rows: int) -> list[Value]:
+        """Convert a C result column to a list of Python values."""
+        value_type = ValueType(buffer.value_type)
+        if value_type == ValueType.NULL:
+            return [None] * rows
+        if value_type == ValueType.NUMBER:
+            values = buffer.numbers[:rows]
+        elif value_type == ValueType.STRING:
+            values = [value.decode("utf-8") if value is not None else None for value in buffer.strings[:rows]]
+        elif value_type == ValueType.BOOLEAN:
+            values = [bool(value) for value in buffer.booleans[:rows]]
+        elif value_type == ValueType.DATETIME:
+            values = [
+                datetime.fromisoformat(value.decode("utf-8").replace("Z", "+00:00")) if value is not None else None
+                for value in buffer.strings[:rows]
+            ]
+        else:
+            values = [cls._from_c_value(buffer.values[i]) for i in range(rows)]
+        return [None if buffer.nulls[i] else value for i, value in enumerate(values)]
+
+    @staticmethod
+    def _to_c_value(name: str, value: Value) -> CValue:
+        """Convert a Python value to a C value."""
+        if value is None:
+            return CValue(value_type=ValueType.NULL)
+        # bool must be checked before int since bool is a subclass of int
+        if isinstance(value, bool):
+            return CValue(value_type=ValueType.BOOLEAN, boolean=int(value))
+        if isinstance(value, (int, float)):
+            return CValue(value_type=ValueType.NUMBER, number=float(value))
+        if isinstance(value, str):
+            return CValue(value_type=ValueType.STRING, string=value.encode("utf-8"))
+        if isinstance(value, datetime):
+            if value.tzinfo is None:
+                value = value.replace(tzinfo=timezone.utc)
+            return CValue(value_type=ValueType.DATETIME, string=value.isoformat().encode("utf-8"))
+        raise TypeError(f"Unsupported value for column [{name}]: {type(value).__name__}")
+
+    @staticmethod
+    def _from_c_value(value: CValue) -> Value:
+        """Convert a C value to a Python value."""
+        value_type = ValueType(value.value_type)
+        if value_type == ValueType.NUMBER:
+            return value.number
+        if value_type == ValueType.STRING:
+            return value.string.decode("utf-8") if value.string else ""
+        if value_type == ValueType.BOOLEAN:
+            return bool(value.boolean)
+        if value_type == ValueType.DATETIME:
+            return datetime.fromisoformat(value.string.decode("utf-8").replace("Z", "+00:00"))
+        return None
+
+    def to_sql(self, expression: str, dialect: str = "ansi") -> TranspileResult:
+        """
+        Translate an expression to a SQL expression.
+
+        Args:
+            expression: The expression to translate.
+            dialect: The SQL dialect: "sqlite", "postgresql" or "ansi".
+
+        Returns:
+            TranspileResult containing the SQL, or the errors that prevented translation
+            such as syntax errors or functions the dialect cannot express.
+        """
+        expr_bytes = expression.encode("utf-8")
+        return self._transpile_result(self._lib.ToSQLFFI(expr_bytes, len(expr_bytes), dialect.encode("utf-8")))
+
+    def to_pandas(self, expression: str) -> TranspileResult:
+        """
+        Translate an expression to a vectorized pandas expression.
+
+        The code reads columns from a DataFrame named ``df`` and uses ``pd`` (pandas) and ``np`` (numpy),
+        so it can be evaluated with ``eval(result.code, {"df": df, "pd": pd, "np": np})``.
+
+        Args:
+            expression: The expression to translate.
+
+        Returns:
+            TranspileResult containing the pandas expression, or the errors that prevented translation
+            such as syntax errors or functions without a pandas equivalent.
+        """
+        expr_bytes = expression.encode("utf-8")
+        return self._transpile_result(self._lib.ToPandasFFI(expr_bytes, len(expr_bytes)))
+
+    def source_from_json(self, tree: str | Mapping) -> TranspileResult:
+        """
+        Regenerate expression source from a serialized tree.
+
+        The tree is a parse tree node or an AST node as serialized by the analyzer, possibly edited,
+        either as a JSON string or as the decoded dict. It is validated and printed in canonical form
+        with the default formatting; the layout of the original expression is not kept.
+
+        Args:
+            tree: The tree as JSON text or a dict.
+
+        Returns:
+            TranspileResult containing the source, or an error naming the path of the first
+            malformed node, e.g. "tree.children[0]".
+        """
+        document = (tree if isinstance(tree, str) else json.dumps(tree)).encode("utf-8")
+        return self._transpile_result(self._lib.SourceFromJSONFFI(document, len(document)))
+
+    def _transpile_result(self, c_result_ptr) -> TranspileResult:
+        """Convert a C transpile result and free it."""
+        if not c_result_ptr:
+            return TranspileResult(code="", errors=[])
+
+        try:
+            c_result = c_result_ptr.contents
+
+            # Convert errors
+            errors = []
+            for i in range(c_result.error_count):
+                c_error = c_result.errors[i]
+                error = ErrorInfo(
+                    message=c_error.message.decode("utf-8") if c_error.message else "",
+                    line=c_error.line,
+                    column=c_error.column,
+                    start=c_error.start,
+                    end=c_error.end,
+                )
+                errors.append(error)
+
+            code = c_result.code.decode("utf-8") if c_result.code else ""
+            return TranspileResult(code=code, errors=errors)
+        finally:
+            # Free the C memory
+            self._lib.FreeTranspileResult(c_result_ptr)
+
+    def rename_column(self, expression: str, old_name: str, new_name: str) -> RenameResult:
+        """
+        Rename the references to a column in an expression.
+
+        Only column references are changed: whitespace, layout and string literals containing
+        the name are kept, so saved expressions can be migrated when a column is renamed.
+
+        Args:
+            expression: The expression to edit.
+            old_name: The current column name, without brackets.
+            new_name: The new column name, without brackets.
+
+        Returns:
+            RenameResult containing the edited expression and the edits applied, or an error
+            if a name cannot be written as a column reference.
+        """
+        expr_bytes = expression.encode("utf-8")
+        c_result_ptr = self._lib.RenameColumnFFI(
+            expr_bytes, len(expr_bytes), old_name.encode("utf-8"), new_name.encode("utf-8")
+        )
+        if not c_result_ptr:
+            return RenameResult(expression=expression, edits=[], errors=[])
+
+        try:
+            c_result = c_result_ptr.contents
+
+            # Convert edits
+            edits = []
+            for i in range(c_result.edit_count):
+                c_edit = c_result.edits[i]
+                edits.append(
+                    TextEdit(
+                        start=c_edit.start,
+                        end=c_edit.end,
+                        new_text=c_edit.new_text.decode("utf-8") if c_edit.new_text else "",
+                    )
+                )
+
+            # Convert errors
+            errors = []
+            for i in range(c_result.error_count):
+                c_error = c_result.errors[i]
+                error = ErrorInfo(
+                    message=c_error.message.decode("utf-8") if c_error.message else "",
+                    line=c_error.line,
+                    column=c_error.column,
+                    start=c_error.start,
+                    end=c_error.end,
+                )
+                errors.append(error)
+
+            edited = c_result.expression.decode("utf-8") if c_result.expression else ""
+            return RenameResult(expression=edited, edits=edits, errors=errors)
+        finally:
+            # Free the C memory
+            self._lib.FreeRenameResult(c_result_ptr)
+
+    def analyze_catalog(self, catalog: Mapping[str, str]) -> CatalogReport:
+        """
+        Analyze a catalog of named expressions that reference each other as columns.
+
+        Each expression is validated against the schema and the types inferred for the
+        expressions it references. The report lists dependency cycles, references to columns
+        that are neither in the schema nor in the catalog, and an evaluation order.
+
+        Args:
+            catalog: Expressions keyed by name, in catalog order.
+
+        Returns:
+            CatalogReport with the validation of each expression in catalog order.
+
+        Raises:
+            ValueError: If an expression name is empty or contains brackets or whitespace.
+        """
+        result = self._call_catalog(self._lib.AnalyzeCatalogFFI, catalog)
+        report = result["report"]
+        return CatalogReport(
+            expressions=[
+                CatalogExpression(
+                    name=expression["name"],
+                    type=expression["type"],
+                    dependencies=expression["dependencies"],
+                    columns=expression["columns"],
+                    errors=[ErrorInfo(**error) for error in expression["errors"]],
+                )
+                for expression in report["expressions"]
+            ],
+            cycles=report["cycles"],
+            missing=[MissingDependency(**missing) for missing in report["missing"]],
+            order=report["order"],
+        )
+
+    def catalog_impact(self, catalog: Mapping[str, str], column: str) -> list[str]:
+        """
+        List the named expressions of a catalog affected by a change to a column.
+
+        Args:
+            catalog: Expressions keyed by name, in catalog order.
+            column: The column, or named expression, that changes.
+
+        Returns:
+            The names of the expressions that depend on the column directly or transitively,
+            in evaluation order.
+
+        Raises:
+            ValueError: If an expression name is empty or contains brackets or whitespace.
+        """
+        result = self._call_catalog(self._lib.CatalogImpactFFI, catalog, column.encode("utf-8"))
+        return result["expressions"]
+
+    def _call_catalog(self, function, catalog: Mapping[str, str], *args) -> dict:
+        """Call a catalog FFI function with the catalog as JSON and decode its JSON result."""
+        data = json.dumps(
+            {"expressions": [{"name": name, "expression": expression} for name, expression in catalog.items()]}
+        ).encode("utf-8")
+        result_ptr = function(data, len(data), *args)
+        if not result_ptr:
+            raise ValueError("Invalid catalog")
+
+        try:
+            result = json.loads(ctypes.string_at(result_ptr).decode("utf-8"))
+        finally:
+            # Free the C memory allocated for the JSON result
+            self._lib.FreeString(result_ptr)
+
+        if result["error"]:
+            raise ValueError(result["error"])
+        return result
+
+    def node_at(self, expression: str, offset: int) -> NodeAtResult:
+        """
+        Find the parse tree node at an offset of an expression, e.g. to show a hover.
+
+        The node is the innermost one containing the character at the offset; operators, parentheses
+        and other tokens are nodes too. Partially parsed expressions are supported.
+
+        Args:
+            expression: The expression to inspect.
+            offset: Offset of the character, a code point offset that indexes Python strings
+                unless another encoding is set with set_position_encoding.
+
+        Returns:
+            NodeAtResult with the node, the nodes enclosing it innermost first, the type inferred for
+            the innermost expression containing it, and the documentation of the function or schema
+            column it names. The node is None if the offset is outside the expression.
+        """
+        expr_bytes = expression.encode("utf-8")
+        result_ptr = self._lib.NodeAtFFI(expr_bytes, len(expr_bytes), offset)
+        if not result_ptr:
+            return NodeAtResult(node=None, ancestors=[], type="", function=None, column=None)
+
+        try:
+            result = json.loads(ctypes.string_at(result_ptr).decode("utf-8"))
+        finally:
+            # Free the C memory allocated for the JSON result
+            self._lib.FreeString(result_ptr)
+
+        function = result["function"]
+        column = result["column"]
+        return NodeAtResult(
+            node=self._node_info(result["node"]) if result["node"] else None,
+            ancestors=[self._node_info(node) for node in result["ancestors"]],
+            type=result["type"],
+            function=self._function_signature(function) if function else None,
+            column=Column(column["name"], column["type"], column["description"]) if column else None,
+        )
+
+    @staticmethod
+    def _node_info(node: dict) -> NodeInfo:
+        """Convert a node decoded from JSON."""
+        return NodeInfo(node_type=NodeType(node["type"]), text=node["text"], start=node["start"], end=node["end"])
+
+    @staticmethod
+    def _function_signature(function: dict) -> FunctionSignature:
+        """Convert a function signature decoded from JSON."""
+        variadic = function["variadic"]
+        return FunctionSignature(
+            name=function["name"],
+            syntax=function["syntax"],
+            description=function["description"],
+            examples=function["examples"],
+            parameters=[Parameter(**param) for param in function["parameters"]],
+            variadic=Parameter(**variadic) if variadic else None,
+            return_type=function["returnType"],
+        )
+
+    def set_position_encoding(self, encoding: str) -> None:
+        """
+        Set the unit in which positions in expressions are counted.
+
+        The encoding applies to the positions of all results, such as tokens, errors and edits,
+        and to the offset passed to node_at.
+
+        Args:
+            encoding: "rune" for code points, the default, which index Python strings directly;
+                "utf-16" for UTF-16 code units, as used by JavaScript; or "byte" for offsets
+                into the UTF-8 encoded expression.
+
+        Raises:
+            ValueError: If the encoding is unknown; the previous encoding is kept.
+        """
+        if not self._lib.SetPositionEncodingFFI(encoding.encode("utf-8")):
+            raise ValueError(f"Unknown position encoding: {encoding!r}")
+
+    def set_schema(self, columns: list[Column] | None) -> None:
+        """
+        Set the columns that expressions may reference.
+
+        Once a schema is set, validate rejects unknown columns and uses the declared column types.
+
+        Args:
+            columns: The available columns, or None to accept any column.
+
+        Raises:
+            ValueError: If a column type is not one of number, string, boolean, datetime or any.
+        """
+        if not columns:
+            self._lib.SetSchemaFFI(None, 0)
+            return
+
+        c_columns = (CColumnInfo * len(columns))()
+        for i, column in enumerate(columns):
+            c_columns[i].name = column.name.encode("utf-8")
+            c_columns[i].type = column.type.encode("utf-8") if column.type else None
+            c_columns[i].description = column.description.encode("utf-8") if column.description else None
+
+        if not self._lib.SetSchemaFFI(c_columns, len(columns)):
+            raise ValueError("Invalid schema: column types must be number, string, boolean, datetime or any")
@@ -1,5 +1,31 @@
 from .error import ErrorInfo
-from .result import TokenizeResult
+from .function import FunctionSignature, Parameter
+from .node import NodeInfo, NodeType
+from .result import BatchResult, CatalogExpression, CatalogReport, EvaluateResult, MissingDependency, NodeAtResult, RenameResult, RowError, TextEdit, TokenizeResult, TranspileResult
+from .schema import Column
 from .token import TokenInfo, TokenType
+from .value import Value, ValueType
 
-__all__ = ["ErrorInfo", "TokenizeResult", "TokenInfo", "TokenType"]
+__all__ = [
+    "BatchResult",
+    "CatalogExpression",
+    "CatalogReport",
+    "Column",
+    "ErrorInfo",
+    "EvaluateResult",
+    "FunctionSignature",
+    "MissingDependency",
+    "NodeAtResult",
+    "NodeInfo",
+    "NodeType",
+    "Parameter",
+    "RenameResult",
+    "RowError",
+    "TextEdit",
+    "TokenizeResult",
+    "TokenInfo",
+    "TokenType",
+    "TranspileResult",
+    "Value",
+    "ValueType",
+]
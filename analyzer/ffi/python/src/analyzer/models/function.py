@@ -0,0 +1,24 @@
+from dataclasses import dataclass
+
+
+@dataclass(frozen=True)
+class Parameter:
+    """A parameter of a function."""
+
+    name: str
+    type: str
+    optional: bool
+    description: str
+
+
+@dataclass(frozen=True)
+class FunctionSignature:
+    """The parameters, return type and documentation of a function."""
+
+    name: str
+    syntax: str
+    description: str
+    examples: list[str]
+    parameters: list[Parameter]
+    variadic: Parameter | None
+    return_type: str
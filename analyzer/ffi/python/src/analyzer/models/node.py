@@ -0,0 +1,77 @@
+from dataclasses import dataclass
+from enum import IntEnum
+
+
+class NodeType(IntEnum):
+    """Parse tree node types, matching the analyzer's NodeType constants."""
+
+    # Root node
+    EXPRESSION = 0
+
+    # Parser rules - expression types
+    LITERAL_EXPR = 1
+    COLUMN_REF_EXPR = 2
+    FUNCTION_CALL_EXPR = 3
+    PAREN_EXPR = 4
+    UNARY_MINUS_EXPR = 5
+    POWER_EXPR = 6
+    MUL_DIV_EXPR = 7
+    ADD_SUB_EXPR = 8
+    COMPARISON_EXPR = 9
+    AND_EXPR = 10
+    OR_EXPR = 11
+
+    # Parser rules - components
+    LITERAL = 12
+    COLUMN_REFERENCE = 13
+    FUNCTION_CALL = 14
+    ARGUMENT_LIST = 15
+
+    # Lexer rules - operators
+    ADD = 16
+    SUB = 17
+    MUL = 18
+    DIV = 19
+    POW = 20
+    LT = 21
+    LE = 22
+    GT = 23
+    GE = 24
+    EQ = 25
+    NEQ = 26
+    OR = 27
+    AND = 28
+
+    # Lexer rules - delimiters
+    LPAREN = 29
+    RPAREN = 30
+    LBRACKET = 31
+    RBRACKET = 32
+    COMMA = 33
+
+    # Lexer rules - literals
+    BOOLEAN_LITERAL = 34
+    FLOAT_LITERAL = 35
+    INTEGER_LITERAL = 36
+    STRING_LITERAL = 37
+
+    # Lexer rules - identifiers
+    FUNCTION_NAME = 38
+    COLUMN_REF = 39
+
+    # Special
+    WS = 40
+    ERROR_CHAR = 41
+    TERMINAL = 42
+    ERROR = 43
+    EOF = 44
+
+
+@dataclass(frozen=True)
+class NodeInfo:
+    """A parse tree node without its children, in code point offsets."""
+
+    node_type: NodeType
+    text: str
+    start: int
+    end: int
@@ -2,6 +2,10 @@ from dataclasses import dataclass
 
 from .token import TokenInfo
 from .error import ErrorInfo
+from .function import FunctionSignature
+from .node import NodeInfo
+from .schema import Column
+from .value import Value
 
 
 @dataclass(frozen=True)
@@ -15,3 +19,124 @@ class TokenizeResult:
     def is_valid(self) -> bool:
         """Check if the expression is valid (no errors)."""
         return len(self.errors) == 0
+
+
+@dataclass(frozen=True)
+class EvaluateResult:
+    """Result of evaluating an expression against a row."""
+
+    value: Value
+    errors: list[ErrorInfo]
+
+    @property
+    def is_valid(self) -> bool:
+        """Check if the evaluation succeeded (no errors)."""
+        return len(self.errors) == 0
+
+
+@dataclass(frozen=True)
+class RowError:
+    """Error that stopped evaluation of one row of a batch."""
+
+    row: int
+    error: ErrorInfo
+
+
+@dataclass(frozen=True)
+class BatchResult:
+    """Result of evaluating an expression over columns of values."""
+
+    values: list[Value]
+    failed: list[bool]
+    errors: list[RowError]
+
+    @property
+    def is_valid(self) -> bool:
+        """Check if every row was evaluated (no errors)."""
+        return len(self.errors) == 0
+
+
+@dataclass(frozen=True)
+class TranspileResult:
+    """Result of translating an expression to another language."""
+
+    code: str
+    errors: list[ErrorInfo]
+
+    @property
+    def is_valid(self) -> bool:
+        """Check if the translation succeeded (no errors)."""
+        return len(self.errors) == 0
+
+
+@dataclass(frozen=True)
+class TextEdit:
+    """Replacement of a range of an expression, in code point offsets."""
+
+    start: int
+    end: int
+    new_text: str
+
+
+@dataclass(frozen=True)
+class RenameResult:
+    """Result of renaming a column in an expression."""
+
+    expression: str
+    edits: list[TextEdit]
+    errors: list[ErrorInfo]
+
+    @property
+    def is_valid(self) -> bool:
+        """Check if the rename succeeded (no errors)."""
+        return len(self.errors) == 0
+
+
+@dataclass(frozen=True)
+class CatalogExpression:
+    """Validation of one named expression of a catalog."""
+
+    name: str
+    type: str
+    dependencies: list[str]
+    columns: list[str]
+    errors: list[ErrorInfo]
+
+    @property
+    def is_valid(self) -> bool:
+        """Check if the expression is valid (no errors)."""
+        return len(self.errors) == 0
+
+
+@dataclass(frozen=True)
+class MissingDependency:
+    """Reference from a named expression to a column that is neither in the schema nor in the catalog."""
+
+    expression: str
+    column: str
+
+
+@dataclass(frozen=True)
+class CatalogReport:
+    """Result of analyzing a catalog of named expressions."""
+
+    expressions: list[CatalogExpression]
+    cycles: list[list[str]]
+    missing: list[MissingDependency]
+    order: list[str]
+
+    @property
+    def is_valid(self) -> bool:
+        """Check if every expression is valid and can be evaluated."""
+        return not self.cycles and not self.missing and all(e.is_valid for e in self.expressions)
+
+
+@dataclass(frozen=True)
+class NodeAtResult:
+    """Parse tree node at an offset of an expression, with the content of a hover for it."""
+
+    node: NodeInfo | None
+    ancestors: list[NodeInfo]
+    type: str
+    function: FunctionSignature | None
+    column: Column | None
@@ -0,0 +1,10 @@
+from dataclasses import dataclass
+
+
+@dataclass(frozen=True)
+class Column:
+    """A column that expressions may reference."""
+
+    name: str
+    type: str | None = None
+    description: str | None = None
@@ -0,0 +1,16 @@
+from datetime import datetime
+from enum import IntEnum
+
+
+class ValueType(IntEnum):
+    """Value type enumeration matching the C enum."""
+
+    NULL = 0
+    NUMBER = 1
+    STRING = 2
+    BOOLEAN = 3
+    DATETIME = 4
+    MIXED = 5
+
+
+Value = float | str | bool | datetime | None
@@ -5,6 +5,7 @@
 
 #include "error.h"
 #include "token.h"
+#include "value.h"
 
 typedef struct {
     CTokenInfo* tokens;  // Array of tokens
@@ -13,4 +14,39 @@ typedef struct {
     int32_t error_count; // Number of errors
 } CTokenizeResult;
 
+typedef struct {
+    CValue value;        // Resulting value, null if evaluation failed
+    CErrorInfo* errors;  // Array of errors
+    int32_t error_count; // Number of errors
+} CEvaluateResult;
+
+typedef struct {
+    CColumnBuffer column;  // Result column, null where evaluation failed
+    int32_t row_count;     // Number of rows
+    uint8_t* error_mask;   // Non-zero where evaluation of the row failed
+    CErrorInfo* errors;    // Array of errors
+    int32_t* error_rows;   // Row of each error, -1 if the error prevented evaluating any row
+    int32_t error_count;   // Number of errors
+} CBatchResult;
+
+typedef struct {
+    char* code;          // Generated code, empty if there are errors
+    CErrorInfo* errors;  // Array of errors
+    int32_t error_count; // Number of errors
+} CTranspileResult;
+
+typedef struct {
+    int32_t start;       // Start position of the replaced range
+    int32_t end;         // End position of the replaced range (exclusive)
+    char* new_text;      // Replacement text
+} CTextEdit;
+
+typedef struct {
+    char* expression;    // Expression with the edits applied
+    CTextEdit* edits;    // Array of edits, in source order
+    int32_t edit_count;  // Number of edits
+    CErrorInfo* errors;  // Array of errors
+    int32_t error_count; // Number of errors
+} CRenameResult;
+
 #endif // ANALYZER_H
@@ -0,0 +1,10 @@
+#ifndef SCHEMA_H
+#define SCHEMA_H
+
+typedef struct {
+    char* name;          // Column name without brackets
+    char* type;          // "number", "string", "boolean", "datetime", "any" or NULL for any
+    char* description;   // Column description, may be NULL
+} CColumnInfo;
+
+#endif // SCHEMA_H
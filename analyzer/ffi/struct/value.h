@@ -0,0 +1,37 @@
+#ifndef VALUE_H
+#define VALUE_H
+
+#include <stdint.h>
+
+enum ValueType {
+	VALUE_TYPE_NULL = 0,
+	VALUE_TYPE_NUMBER,
+	VALUE_TYPE_STRING,
+	VALUE_TYPE_BOOLEAN,
+	VALUE_TYPE_DATETIME,
+	VALUE_TYPE_MIXED     // Column buffers only: rows of different types, stored as CValue
+};
+
+typedef struct {
+    enum ValueType value_type;  // ValueType enum value
+    double number;              // Value of a number
+    char* string;               // Value of a string, or a datetime as RFC 3339 text
+    int32_t boolean;            // Value of a boolean (0 or 1)
+} CValue;
+
+typedef struct {
+    char* name;                 // Column name without brackets
+    CValue value;               // Column value
+} CColumnValue;
+
+typedef struct {
+    char* name;                 // Column name without brackets, NULL for a result column
+    enum ValueType value_type;  // Type of the non-null rows; a NULL column has no buffer
+    double* numbers;            // Row values of a NUMBER column
+    char** strings;             // Row values of a STRING column, or of a DATETIME column as RFC 3339 text
+    uint8_t* booleans;          // Row values of a BOOLEAN column (0 or 1)
+    CValue* values;             // Row values of a MIXED column
+    uint8_t* nulls;             // Null mask, non-zero where the row is null (may be NULL for input columns)
+} CColumnBuffer;
+
+#endif // VALUE_H
@@ -6,6 +6,7 @@ toolchain go1.24.6
 
 require (
 	github.com/antlr4-go/antlr/v4 v4.13.1
+	github.com/mattn/go-sqlite3 v1.14.33
 	github.com/stretchr/testify v1.10.0
 )
 
@@ -0,0 +1,142 @@
+package lsp
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// document is an open text document holding one expression per line or block.
+// A non-blank line starts a new expression unless it is indented or follows unclosed parentheses,
+// in which case it continues the expression above; blank lines always end an expression.
+// This keeps multi-line expressions written by the formatter together.
+type document struct {
+	uri     string
+	version int
+	lines   []string
+	blocks  []*block
+}
+
+// block is one expression of a document
+type block struct {
+	startLine int      // Document line of the first line
+	lines     []string // Lines of the expression without line terminators
+	text      string   // The expression, lines joined with "\n"
+}
+
+// newDocument splits the text of a document into expressions
+func newDocument(uri string, version int, text string) *document {
+	doc := &document{uri: uri, version: version, lines: splitLines(text)}
+
+	var current *block
+	depth := 0
+	for i, line := range doc.lines {
+		if strings.TrimSpace(line) == "" {
+			current, depth = nil, 0
+			continue
+		}
+		indented := line[0] == ' ' || line[0] == '\t'
+		if current == nil || (depth <= 0 && !indented) {
+			current, depth = &block{startLine: i}, 0
+			doc.blocks = append(doc.blocks, current)
+		}
+		current.lines = append(current.lines, line)
+		depth += parenDepth(line)
+	}
+
+	for _, b := range doc.blocks {
+		b.text = strings.Join(b.lines, "\n")
+	}
+	return doc
+}
+
+// splitLines splits text into lines, accepting \n and \r\n line terminators
+func splitLines(text string) []string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// parenDepth returns the number of parentheses a line opens minus the number it closes,
+// ignoring parentheses in string literals and column references
+func parenDepth(line string) int {
+	depth := 0
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '"', '\'':
+			for i++; i < len(line) && line[i] != c; i++ {
+				if line[i] == '\\' {
+					i++
+				}
+			}
+		case '[':
+			if end := strings.IndexAny(line[i+1:], "] \t"); end >= 0 && line[i+1+end] == ']' {
+				i += end + 1
+			}
+		}
+	}
+	return depth
+}
+
+// blockAt returns the expression containing a position and the code point offset of the position in it
+func (d *document) blockAt(pos Position) (*block, int, bool) {
+	for _, b := range d.blocks {
+		if pos.Line < b.startLine || pos.Line >= b.startLine+len(b.lines) {
+			continue
+		}
+		offset := 0
+		for _, line := range b.lines[:pos.Line-b.startLine] {
+			offset += len([]rune(line)) + 1
+		}
+		return b, offset + runeOffset(b.lines[pos.Line-b.startLine], pos.Character), true
+	}
+	return nil, 0, false
+}
+
+// position converts a code point offset in the expression to a document position
+func (b *block) position(offset int) Position {
+	pos := Position{Line: b.startLine}
+	for i, r := range []rune(b.text) {
+		if i >= offset {
+			break
+		}
+		if r == '\n' {
+			pos.Line++
+			pos.Character = 0
+			continue
+		}
+		pos.Character += len(utf16.Encode([]rune{r}))
+	}
+	return pos
+}
+
+// rangeOf converts a span of code point offsets in the expression to a document range
+func (b *block) rangeOf(start, end int) Range {
+	return Range{Start: b.position(start), End: b.position(end)}
+}
+
+// fullRange returns the range of the whole expression
+func (b *block) fullRange() Range {
+	last := b.lines[len(b.lines)-1]
+	return Range{
+		Start: Position{Line: b.startLine},
+		End:   Position{Line: b.startLine + len(b.lines) - 1, Character: len(utf16.Encode([]rune(last)))},
+	}
+}
+
+// runeOffset converts a UTF-16 character offset in a line to a code point offset, clamped to the line
+func runeOffset(line string, character int) int {
+	units := 0
+	for i, r := range []rune(line) {
+		if units >= character {
+			return i
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len([]rune(line))
+}
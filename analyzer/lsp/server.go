@@ -0,0 +1,468 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"antlr-editor/analyzer/core/app"
+	"antlr-editor/analyzer/core/app/formatter"
+	"antlr-editor/analyzer/core/models"
+)
+
+// diagnosticSource names the server in diagnostics
+const diagnosticSource = "analyzer"
+
+// semanticTokenTypes is the legend of the semantic tokens; tokens refer to types by index
+var semanticTokenTypes = []string{"function", "variable", "string", "number", "keyword", "operator"}
+
+// semanticTokenIndex maps analyzer token types to indexes in semanticTokenTypes
+var semanticTokenIndex = map[models.TokenType]int{
+	models.TokenFunction:        0,
+	models.TokenColumnReference: 1,
+	models.TokenString:          2,
+	models.TokenInteger:         3,
+	models.TokenFloat:           3,
+	models.TokenBoolean:         4,
+	models.TokenOperator:        5,
+}
+
+// completionKinds maps the kinds of App.Complete items to completion item kinds
+var completionKinds = map[models.CompletionKind]int{
+	models.CompletionColumn:      completionKindField,
+	models.CompletionFunction:    completionKindFunction,
+	models.CompletionLiteral:     completionKindKeyword,
+	models.CompletionPunctuation: completionKindOperator,
+	models.CompletionOperator:    completionKindOperator,
+}
+
+// errExit stops Serve when the client sends exit
+var errExit = errors.New("exit")
+
+// Server is a Language Server Protocol server for documents holding one expression per line or block.
+// It publishes Lint errors as diagnostics and provides formatting, semantic tokens, hover and completion.
+// Documents are synchronized in full on every change.
+type Server struct {
+	app         *app.App
+	conn        *conn
+	documents   map[string]*document
+	columns     []models.Column
+	initialized bool
+	shutdown    bool
+}
+
+// NewServer creates a server analyzing documents with the given App
+func NewServer(application *app.App) *Server {
+	return &Server{
+		app:       application,
+		documents: make(map[string]*document),
+	}
+}
+
+// Serve reads messages from r and writes responses and notifications to w until the client sends exit
+// or closes r. It returns nil on exit after shutdown, as the protocol requires for a zero exit status.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	for {
+		body, err := s.conn.read()
+		if err == io.EOF {
+			return fmt.Errorf("client closed the connection without exit")
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			if err := s.conn.write(&message{ID: json.RawMessage("null"), Error: &responseError{Code: codeParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.handle(&msg); err == errExit {
+			if !s.shutdown {
+				return fmt.Errorf("exit without shutdown")
+			}
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or notification; only write errors and exit are returned
+func (s *Server) handle(msg *message) error {
+	isRequest := len(msg.ID) > 0 && string(msg.ID) != "null"
+	if !isRequest {
+		return s.notify(msg)
+	}
+
+	result, respErr := s.request(msg)
+	response := &message{ID: msg.ID, Error: respErr}
+	if respErr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		response.Result = data
+	}
+	return s.conn.write(response)
+}
+
+// request handles a request and returns its result
+func (s *Server) request(msg *message) (any, *responseError) {
+	if msg.Method == "initialize" {
+		return s.initialize(msg.Params)
+	}
+	if !s.initialized {
+		return nil, &responseError{Code: codeServerNotInitialized, Message: "server not initialized"}
+	}
+	if s.shutdown {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "server is shutting down"}
+	}
+
+	switch msg.Method {
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/formatting":
+		var params DocumentFormattingParams
+		if err := decodeParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.formatting(params), nil
+	case "textDocument/semanticTokens/full":
+		var params SemanticTokensParams
+		if err := decodeParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.semanticTokens(params), nil
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := decodeParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params), nil
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := decodeParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(params), nil
+	case "textDocument/signatureHelp":
+		var params TextDocumentPositionParams
+		if err := decodeParams(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.signatureHelp(params), nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+// notify handles a notification; notifications before initialize are dropped, except exit
+func (s *Server) notify(msg *message) error {
+	if msg.Method == "exit" {
+		return errExit
+	}
+	if !s.initialized {
+		return nil
+	}
+
+	switch msg.Method {
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if decodeParams(msg.Params, &params) != nil {
+			return nil
+		}
+		item := params.TextDocument
+		return s.update(newDocument(item.URI, item.Version, item.Text))
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if decodeParams(msg.Params, &params) != nil || len(params.ContentChanges) == 0 {
+			return nil
+		}
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return s.update(newDocument(params.TextDocument.URI, params.TextDocument.Version, text))
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if decodeParams(msg.Params, &params) != nil {
+			return nil
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return s.publish(PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+	}
+	return nil
+}
+
+// initialize configures the server and returns its capabilities
+func (s *Server) initialize(raw json.RawMessage) (any, *responseError) {
+	var params InitializeParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	if options := params.InitializationOptions; options != nil && len(options.Columns) > 0 {
+		s.columns = make([]models.Column, len(options.Columns))
+		for i, column := range options.Columns {
+			columnType := models.DataType(column.Type)
+			if columnType == "" {
+				columnType = models.DataTypeAny
+			}
+			if !columnType.IsValid() {
+				return nil, &responseError{Code: codeInvalidParams, Message: fmt.Sprintf("column %q has invalid type %q", column.Name, column.Type)}
+			}
+			s.columns[i] = models.Column{Name: column.Name, Type: columnType, Description: column.Description}
+		}
+		s.app.SetSchema(models.NewSchema(s.columns))
+	}
+	s.initialized = true
+
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync":           1, // Full
+			"documentFormattingProvider": true,
+			"hoverProvider":              true,
+			"completionProvider":         map[string]any{"triggerCharacters": []string{"[", "(", ","}},
+			"signatureHelpProvider":      map[string]any{"triggerCharacters": []string{"(", ","}},
+			"semanticTokensProvider": map[string]any{
+				"legend": map[string]any{"tokenTypes": semanticTokenTypes, "tokenModifiers": []string{}},
+				"full":   true,
+			},
+		},
+		"serverInfo": map[string]any{"name": "analyzer-lsp"},
+	}, nil
+}
+
+// update stores a document and publishes its diagnostics
+func (s *Server) update(doc *document) error {
+	s.documents[doc.uri] = doc
+
+	diagnostics := []Diagnostic{}
+	for _, b := range doc.blocks {
+		for _, err := range s.app.Lint(b.text) {
+			start, end := max(err.Start, 0), max(err.End, err.Start, 0)
+			diagnostics = append(diagnostics, Diagnostic{
+				Range:    b.rangeOf(start, end),
+				Severity: severityError,
+				Source:   diagnosticSource,
+				Message:  err.Message,
+			})
+		}
+	}
+	return s.publish(PublishDiagnosticsParams{URI: doc.uri, Version: doc.version, Diagnostics: diagnostics})
+}
+
+// publish sends diagnostics to the client
+func (s *Server) publish(params PublishDiagnosticsParams) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.conn.write(&message{Method: "textDocument/publishDiagnostics", Params: data})
+}
+
+// formatting formats every expression without syntax errors, using the tab size of the editor as indent size
+func (s *Server) formatting(params DocumentFormattingParams) []TextEdit {
+	edits := []TextEdit{}
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return edits
+	}
+
+	options := formatter.DefaultFormatOptions()
+	if params.Options.TabSize > 0 {
+		options = options.WithIndentSize(params.Options.TabSize)
+	}
+	for _, b := range doc.blocks {
+		if len(s.app.ParseTree(b.text).Errors) > 0 {
+			continue
+		}
+		if formatted := s.app.FormatWithOptions(b.text, options); formatted != b.text {
+			edits = append(edits, TextEdit{Range: b.fullRange(), NewText: formatted})
+		}
+	}
+	return edits
+}
+
+// semanticTokens classifies the tokens of every expression; column references include their brackets
+func (s *Server) semanticTokens(params SemanticTokensParams) SemanticTokens {
+	result := SemanticTokens{Data: []int{}}
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return result
+	}
+
+	previous := Position{}
+	for _, b := range doc.blocks {
+		for _, token := range s.app.Tokenize(b.text).Tokens {
+			index, ok := semanticTokenIndex[token.Type]
+			if !ok {
+				continue
+			}
+			start, end := token.Start, token.End
+			if token.Type == models.TokenColumnReference {
+				start, end = start-1, end+1
+			}
+
+			pos := b.position(start)
+			deltaCharacter := pos.Character
+			if pos.Line == previous.Line {
+				deltaCharacter -= previous.Character
+			}
+			length := len(utf16.Encode([]rune(b.text)[start:end]))
+			result.Data = append(result.Data, pos.Line-previous.Line, deltaCharacter, length, index, 0)
+			previous = pos
+		}
+	}
+	return result
+}
+
+// hover documents the function or column under the cursor with App.NodeAt
+func (s *Server) hover(params TextDocumentPositionParams) *Hover {
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	b, offset, ok := doc.blockAt(params.Position)
+	if !ok {
+		return nil
+	}
+
+	result := s.app.NodeAt(b.text, offset)
+	var content string
+	switch {
+	case result.Function != nil:
+		content = functionDocumentation(*result.Function)
+	case result.Column != nil:
+		content = columnDocumentation(*result.Column)
+	default:
+		return nil
+	}
+	r := b.rangeOf(result.Node.Start, result.Node.End)
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: content}, Range: &r}
+}
+
+// completion suggests what the grammar allows at the cursor with App.Complete, keeping its ranking
+func (s *Server) completion(params TextDocumentPositionParams) CompletionList {
+	list := CompletionList{Items: []CompletionItem{}}
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return list
+	}
+
+	b, offset, ok := doc.blockAt(params.Position)
+	if !ok {
+		// A blank line starts a new expression
+		b = &block{startLine: params.Position.Line, lines: []string{""}}
+	}
+
+	for i, item := range s.app.Complete(b.text, offset).Items {
+		completion := CompletionItem{
+			Label:    item.Label,
+			Kind:     completionKinds[item.Kind],
+			Detail:   item.Detail,
+			SortText: fmt.Sprintf("%04d", i),
+			TextEdit: &TextEdit{Range: b.rangeOf(item.Edit.Start, item.Edit.End), NewText: item.Edit.NewText},
+		}
+		if item.Kind == models.CompletionColumn {
+			completion.Label = item.Edit.NewText
+		}
+		if item.Documentation != "" {
+			completion.Documentation = &MarkupContent{Kind: "markdown", Value: item.Documentation}
+		}
+		list.Items = append(list.Items, completion)
+	}
+	return list
+}
+
+// signatureHelp shows the signature of the function call around the cursor with App.SignatureHelp
+func (s *Server) signatureHelp(params TextDocumentPositionParams) *SignatureHelp {
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	b, offset, ok := doc.blockAt(params.Position)
+	if !ok {
+		return nil
+	}
+
+	help := s.app.SignatureHelp(b.text, offset)
+	if help.Signature == nil {
+		return nil
+	}
+	signature := help.Signature
+	information := SignatureInformation{
+		Label:         signature.Syntax(),
+		Documentation: &MarkupContent{Kind: "markdown", Value: signature.Description},
+		Parameters:    []ParameterInformation{},
+	}
+
+	// Parameter labels are UTF-16 offsets of the parameter names, found in order after the opening parenthesis
+	parameters := append([]models.Parameter{}, signature.Parameters...)
+	if signature.Variadic != nil {
+		parameters = append(parameters, *signature.Variadic)
+	}
+	position := strings.Index(information.Label, "(")
+	for _, param := range parameters {
+		index := strings.Index(information.Label[position:], param.Name)
+		if index < 0 {
+			break
+		}
+		start := position + index
+		position = start + len(param.Name)
+		information.Parameters = append(information.Parameters, ParameterInformation{
+			Label:         [2]int{utf16Length(information.Label[:start]), utf16Length(information.Label[:position])},
+			Documentation: &MarkupContent{Kind: "markdown", Value: fmt.Sprintf("`%s` (%s): %s", param.Name, param.Type, param.Description)},
+		})
+	}
+
+	active := help.ActiveParameter
+	if signature.Variadic != nil {
+		active = min(active, len(parameters)-1)
+	}
+	return &SignatureHelp{Signatures: []SignatureInformation{information}, ActiveParameter: active}
+}
+
+// functionDocumentation renders the hover text of a function
+func functionDocumentation(signature models.FunctionSignature) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "```\n%s -> %s\n```\n\n%s", signature.Syntax(), signature.ReturnType, signature.Description)
+	for _, param := range signature.Parameters {
+		fmt.Fprintf(&builder, "\n- `%s` (%s): %s", param.Name, param.Type, param.Description)
+	}
+	if len(signature.Examples) > 0 {
+		builder.WriteString("\n\nExamples:\n```\n" + strings.Join(signature.Examples, "\n") + "\n```")
+	}
+	return builder.String()
+}
+
+// columnDocumentation renders the hover text of a column
+func columnDocumentation(column models.Column) string {
+	content := fmt.Sprintf("**[%s]** `%s`", column.Name, column.Type)
+	if column.Description != "" {
+		content += "\n\n" + column.Description
+	}
+	return content
+}
+
+// utf16Length returns the number of UTF-16 code units of text
+func utf16Length(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+// decodeParams decodes the parameters of a message
+func decodeParams(raw json.RawMessage, params any) *responseError {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
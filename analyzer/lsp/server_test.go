@@ -0,0 +1,407 @@
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"antlr-editor/analyzer/core/app"
+)
+
+// testClient drives a server over in-memory pipes
+type testClient struct {
+	t        *testing.T
+	conn     *conn
+	messages chan *message
+	done     chan error
+	nextID   int
+}
+
+// newTestClient starts a server and returns a client connected to it
+func newTestClient(t *testing.T) *testClient {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &testClient{
+		t:        t,
+		conn:     newConn(clientIn, clientOut),
+		messages: make(chan *message, 64),
+		done:     make(chan error, 1),
+	}
+	go func() {
+		err := NewServer(app.NewApp()).Serve(serverIn, serverOut)
+		serverOut.Close()
+		c.done <- err
+	}()
+	go func() {
+		defer close(c.messages)
+		for {
+			body, err := c.conn.read()
+			if err != nil {
+				return
+			}
+			var msg message
+			if json.Unmarshal(body, &msg) == nil {
+				c.messages <- &msg
+			}
+		}
+	}()
+	t.Cleanup(func() { clientOut.Close() })
+	return c
+}
+
+// next returns the next message sent by the server
+func (c *testClient) next() *message {
+	c.t.Helper()
+	select {
+	case msg, ok := <-c.messages:
+		require.True(c.t, ok, "server closed the connection")
+		return msg
+	case <-time.After(5 * time.Second):
+		require.FailNow(c.t, "timed out waiting for the server")
+		return nil
+	}
+}
+
+// notify sends a notification
+func (c *testClient) notify(method string, params any) {
+	c.t.Helper()
+	data, err := json.Marshal(params)
+	require.NoError(c.t, err)
+	require.NoError(c.t, c.conn.write(&message{Method: method, Params: data}))
+}
+
+// call sends a request and decodes the result of its response into result
+func (c *testClient) call(method string, params any, result any) *responseError {
+	c.t.Helper()
+	c.nextID++
+	id, _ := json.Marshal(c.nextID)
+	data, err := json.Marshal(params)
+	require.NoError(c.t, err)
+	require.NoError(c.t, c.conn.write(&message{ID: id, Method: method, Params: data}))
+
+	for {
+		msg := c.next()
+		if string(msg.ID) != string(id) {
+			continue
+		}
+		if msg.Error == nil && result != nil {
+			require.NoError(c.t, json.Unmarshal(msg.Result, result))
+		}
+		return msg.Error
+	}
+}
+
+// diagnostics waits for the next diagnostics published for a document
+func (c *testClient) diagnostics(uri string) PublishDiagnosticsParams {
+	c.t.Helper()
+	for {
+		msg := c.next()
+		if msg.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		var params PublishDiagnosticsParams
+		require.NoError(c.t, json.Unmarshal(msg.Params, &params))
+		if params.URI == uri {
+			return params
+		}
+	}
+}
+
+// initialize performs the initialize handshake with the given options
+func (c *testClient) initialize(options *InitializationOptions) map[string]any {
+	c.t.Helper()
+	var result map[string]any
+	require.Nil(c.t, c.call("initialize", InitializeParams{InitializationOptions: options}, &result))
+	c.notify("initialized", struct{}{})
+	return result
+}
+
+// open opens a document and returns its diagnostics
+func (c *testClient) open(uri, text string) PublishDiagnosticsParams {
+	c.t.Helper()
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "expression", Version: 1, Text: text},
+	})
+	return c.diagnostics(uri)
+}
+
+// exit shuts the server down and returns the result of Serve
+func (c *testClient) exit() error {
+	c.t.Helper()
+	require.Nil(c.t, c.call("shutdown", nil, nil))
+	c.notify("exit", nil)
+	select {
+	case err := <-c.done:
+		return err
+	case <-time.After(5 * time.Second):
+		require.FailNow(c.t, "server did not exit")
+		return nil
+	}
+}
+
+var testColumns = &InitializationOptions{Columns: []ColumnOption{
+	{Name: "price", Type: "number", Description: "Unit price"},
+	{Name: "qty", Type: "number", Description: "Quantity"},
+	{Name: "name", Type: "string"},
+}}
+
+func TestServer_Initialize(t *testing.T) {
+	client := newTestClient(t)
+
+	result := client.initialize(testColumns)
+	capabilities := result["capabilities"].(map[string]any)
+	assert.Equal(t, float64(1), capabilities["textDocumentSync"])
+	assert.Equal(t, true, capabilities["documentFormattingProvider"])
+	assert.Equal(t, true, capabilities["hoverProvider"])
+	assert.Contains(t, capabilities, "completionProvider")
+	assert.Contains(t, capabilities, "semanticTokensProvider")
+
+	assert.NoError(t, client.exit())
+}
+
+func TestServer_Lifecycle(t *testing.T) {
+	t.Run("requests before initialize fail", func(t *testing.T) {
+		client := newTestClient(t)
+		err := client.call("textDocument/hover", TextDocumentPositionParams{}, nil)
+		require.NotNil(t, err)
+		assert.Equal(t, codeServerNotInitialized, err.Code)
+		client.initialize(nil)
+		assert.NoError(t, client.exit())
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		client := newTestClient(t)
+		client.initialize(nil)
+		err := client.call("workspace/symbol", struct{}{}, nil)
+		require.NotNil(t, err)
+		assert.Equal(t, codeMethodNotFound, err.Code)
+		assert.NoError(t, client.exit())
+	})
+
+	t.Run("invalid column type", func(t *testing.T) {
+		client := newTestClient(t)
+		options := &InitializationOptions{Columns: []ColumnOption{{Name: "a", Type: "decimal"}}}
+		err := client.call("initialize", InitializeParams{InitializationOptions: options}, nil)
+		require.NotNil(t, err)
+		assert.Equal(t, codeInvalidParams, err.Code)
+	})
+
+	t.Run("exit without shutdown", func(t *testing.T) {
+		client := newTestClient(t)
+		client.initialize(nil)
+		client.notify("exit", nil)
+		assert.Error(t, <-client.done)
+	})
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	client := newTestClient(t)
+	client.initialize(testColumns)
+
+	text := "[price] * [qty]\n\nUPPER([name]) +\n  [unknown]\n[qty] +"
+	published := client.open("file:///a.expr", text)
+	assert.Equal(t, 1, published.Version)
+
+	var lines []int
+	for _, diagnostic := range published.Diagnostics {
+		assert.Equal(t, severityError, diagnostic.Severity)
+		assert.Equal(t, diagnosticSource, diagnostic.Source)
+		lines = append(lines, diagnostic.Range.Start.Line)
+	}
+	assert.Contains(t, lines, 3, "unknown column on the continuation line")
+	assert.Contains(t, lines, 4, "incomplete expression on the last line")
+	assert.NotContains(t, lines, 0)
+
+	client.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: "file:///a.expr", Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "[price] * [qty]"}},
+	})
+	published = client.diagnostics("file:///a.expr")
+	assert.Equal(t, 2, published.Version)
+	assert.Empty(t, published.Diagnostics)
+
+	client.notify("textDocument/didClose", DidCloseTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: "file:///a.expr"}})
+	assert.Empty(t, client.diagnostics("file:///a.expr").Diagnostics)
+
+	assert.NoError(t, client.exit())
+}
+
+func TestServer_DiagnosticsUTF16(t *testing.T) {
+	client := newTestClient(t)
+	client.initialize(testColumns)
+
+	// The emoji takes two UTF-16 code units, so the unknown column after it starts at character 8
+	published := client.open("file:///u.expr", `"😀" == [x]`)
+	require.Len(t, published.Diagnostics, 1)
+	assert.Equal(t, Range{Start: Position{Character: 8}, End: Position{Character: 11}}, published.Diagnostics[0].Range)
+
+	assert.NoError(t, client.exit())
+}
+
+func TestServer_Formatting(t *testing.T) {
+	client := newTestClient(t)
+	client.initialize(nil)
+	client.open("file:///f.expr", "1+2\n[a]*[b]\n\n1 +")
+
+	var edits []TextEdit
+	require.Nil(t, client.call("textDocument/formatting", DocumentFormattingParams{
+		TextDocument: TextDocumentIdentifier{URI: "file:///f.expr"},
+		Options:      FormattingOptions{TabSize: 4, InsertSpaces: true},
+	}, &edits))
+
+	assert.Equal(t, []TextEdit{
+		{Range: Range{Start: Position{Line: 0}, End: Position{Line: 0, Character: 3}}, NewText: "1 + 2"},
+		{Range: Range{Start: Position{Line: 1}, End: Position{Line: 1, Character: 7}}, NewText: "[a] * [b]"},
+	}, edits, "expressions with syntax errors are left alone")
+
+	assert.NoError(t, client.exit())
+}
+
+func TestServer_SemanticTokens(t *testing.T) {
+	client := newTestClient(t)
+	client.initialize(nil)
+	client.open("file:///s.expr", "ROUND([a], 2)\n\n'x' == TRUE")
+
+	var tokens SemanticTokens
+	require.Nil(t, client.call("textDocument/semanticTokens/full", SemanticTokensParams{
+		TextDocument: TextDocumentIdentifier{URI: "file:///s.expr"},
+	}, &tokens))
+
+	assert.Equal(t, []int{
+		0, 0, 5, 0, 0, // ROUND
+		0, 6, 3, 1, 0, // [a]
+		0, 5, 1, 3, 0, // 2
+		2, 0, 3, 2, 0, // 'x'
+		0, 4, 2, 5, 0, // ==
+		0, 3, 4, 4, 0, // TRUE
+	}, tokens.Data)
+
+	assert.NoError(t, client.exit())
+}
+
+func TestServer_Hover(t *testing.T) {
+	client := newTestClient(t)
+	client.initialize(testColumns)
+	client.open("file:///h.expr", "ROUND([price], 2)")
+
+	uri := TextDocumentIdentifier{URI: "file:///h.expr"}
+
+	var hover *Hover
+	require.Nil(t, client.call("textDocument/hover", TextDocumentPositionParams{TextDocument: uri, Position: Position{Character: 2}}, &hover))
+	require.NotNil(t, hover)
+	assert.Equal(t, "markdown", hover.Contents.Kind)
+	assert.Contains(t, hover.Contents.Value, "ROUND(")
+	assert.Equal(t, &Range{Start: Position{}, End: Position{Character: 5}}, hover.Range)
+
+	hover = nil
+	require.Nil(t, client.call("textDocument/hover", TextDocumentPositionParams{TextDocument: uri, Position: Position{Character: 8}}, &hover))
+	require.NotNil(t, hover)
+	assert.Contains(t, hover.Contents.Value, "**[price]** `number`")
+	assert.Contains(t, hover.Contents.Value, "Unit price")
+
+	hover = nil
+	require.Nil(t, client.call("textDocument/hover", TextDocumentPositionParams{TextDocument: uri, Position: Position{Character: 15}}, &hover))
+	assert.Nil(t, hover, "literals have no hover")
+
+	assert.NoError(t, client.exit())
+}
+
+func TestServer_Completion(t *testing.T) {
+	client := newTestClient(t)
+	client.initialize(testColumns)
+	client.open("file:///c.expr", "RO\n[pr\n[other] + \n[qty] ")
+
+	uri := TextDocumentIdentifier{URI: "file:///c.expr"}
+	labels := func(list CompletionList) []string {
+		result := make([]string, len(list.Items))
+		for i, item := range list.Items {
+			result[i] = item.Label
+		}
+		return result
+	}
+
+	var list CompletionList
+	require.Nil(t, client.call("textDocument/completion", TextDocumentPositionParams{TextDocument: uri, Position: Position{Line: 0, Character: 2}}, &list))
+	assert.Contains(t, labels(list), "ROUND")
+	assert.NotContains(t, labels(list), "UPPER")
+	for _, item := range list.Items {
+		assert.Equal(t, Range{Start: Position{}, End: Position{Character: 2}}, item.TextEdit.Range)
+	}
+
+	list = CompletionList{}
+	require.Nil(t, client.call("textDocument/completion", TextDocumentPositionParams{TextDocument: uri, Position: Position{Line: 1, Character: 3}}, &list))
+	assert.Equal(t, []string{"[price]"}, labels(list))
+	assert.Equal(t, Range{Start: Position{Line: 1}, End: Position{Line: 1, Character: 3}}, list.Items[0].TextEdit.Range)
+	assert.Equal(t, completionKindField, list.Items[0].Kind)
+
+	list = CompletionList{}
+	require.Nil(t, client.call("textDocument/completion", TextDocumentPositionParams{TextDocument: uri, Position: Position{Line: 2, Character: 10}}, &list))
+	assert.Contains(t, labels(list), "ROUND")
+	assert.Contains(t, labels(list), "true")
+	assert.Contains(t, labels(list), "[qty]")
+	assert.NotContains(t, labels(list), "+", "an operand is expected after an operator")
+	assert.Equal(t, "0000", list.Items[0].SortText, "the ranking of App.Complete is kept")
+
+	list = CompletionList{}
+	require.Nil(t, client.call("textDocument/completion", TextDocumentPositionParams{TextDocument: uri, Position: Position{Line: 3, Character: 6}}, &list))
+	assert.Contains(t, labels(list), "+")
+	assert.NotContains(t, labels(list), "ROUND", "an operator is expected after an operand")
+
+	assert.NoError(t, client.exit())
+}
+
+func TestServer_SignatureHelp(t *testing.T) {
+	client := newTestClient(t)
+	client.initialize(nil)
+	client.open("file:///g.expr", "SUBSTRING([name],\n  1, \nCONCAT('a', 'b', 'c', \n\n[a] + 1")
+
+	uri := TextDocumentIdentifier{URI: "file:///g.expr"}
+
+	var help *SignatureHelp
+	require.Nil(t, client.call("textDocument/signatureHelp", TextDocumentPositionParams{TextDocument: uri, Position: Position{Line: 1, Character: 5}}, &help))
+	require.NotNil(t, help)
+	require.Len(t, help.Signatures, 1)
+	signature := help.Signatures[0]
+	assert.Equal(t, "SUBSTRING(text, start, length)", signature.Label)
+	assert.Equal(t, 2, help.ActiveParameter)
+	require.Len(t, signature.Parameters, 3)
+	assert.Equal(t, [2]int{10, 14}, signature.Parameters[0].Label)
+	assert.Equal(t, [2]int{23, 29}, signature.Parameters[2].Label)
+
+	help = nil
+	require.Nil(t, client.call("textDocument/signatureHelp", TextDocumentPositionParams{TextDocument: uri, Position: Position{Line: 2, Character: 22}}, &help))
+	require.NotNil(t, help)
+	assert.Equal(t, "CONCAT(text1, text, ...)", help.Signatures[0].Label)
+	assert.Equal(t, 1, help.ActiveParameter, "arguments past the fixed parameters highlight the variadic one")
+
+	help = nil
+	require.Nil(t, client.call("textDocument/signatureHelp", TextDocumentPositionParams{TextDocument: uri, Position: Position{Line: 4, Character: 3}}, &help))
+	assert.Nil(t, help, "no signature outside calls")
+
+	assert.NoError(t, client.exit())
+}
+
+func TestNewDocument(t *testing.T) {
+	doc := newDocument("file:///d.expr", 1, "1 + 2\r\nIF([a],\n[b],\n[c])\nSUM(\n  [x])\n  + 1\n\n[y]")
+
+	texts := make([]string, len(doc.blocks))
+	for i, b := range doc.blocks {
+		texts[i] = b.text
+	}
+	assert.Equal(t, []string{"1 + 2", "IF([a],\n[b],\n[c])", "SUM(\n  [x])\n  + 1", "[y]"}, texts)
+
+	b, offset, ok := doc.blockAt(Position{Line: 2, Character: 1})
+	require.True(t, ok)
+	assert.Equal(t, 1, b.startLine)
+	assert.Equal(t, 9, offset)
+	assert.Equal(t, Position{Line: 2, Character: 1}, b.position(offset))
+
+	_, _, ok = doc.blockAt(Position{Line: 7})
+	assert.False(t, ok, "blank lines belong to no expression")
+}
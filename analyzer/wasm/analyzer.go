@@ -4,15 +4,23 @@
 package main
 
 import (
+	"fmt"
 	"syscall/js"
+	"time"
 
 	"antlr-editor/analyzer/core/app"
 	"antlr-editor/analyzer/core/app/formatter"
+	"antlr-editor/analyzer/core/app/functions"
+	"antlr-editor/analyzer/core/app/sqlgen"
+	"antlr-editor/analyzer/core/models"
 )
 
 // Global instances for WASM usage
 var analyzer = app.NewApp()
 
+// session analyzes the expression last passed to analyze, nil until then and after the analyzer changes
+var session *app.Session
+
 
 // parseTree function exposed to JavaScript
 func parseTree(this js.Value, args []js.Value) any {
@@ -37,6 +45,29 @@ func parseTree(this js.Value, args []js.Value) any {
 	return js.ValueOf(result.AsMap())
 }
 
+// parseCST function exposed to JavaScript.
+// Returns {tree, errors} where tree is a lossless concrete syntax tree: tokens carry the surrounding whitespace
+// and unparsed text as leading and trailing trivia, so concatenating them reproduces the expression exactly.
+func parseCST(this js.Value, args []js.Value) any {
+	if len(args) != 1 {
+		return js.ValueOf(map[string]any{
+			"tree": nil,
+			"errors": []any{
+				map[string]any{
+					"message": "Invalid arguments",
+					"line":    -1,
+					"column":  -1,
+					"start":   -1,
+					"end":     -1,
+				},
+			},
+		})
+	}
+
+	result := analyzer.ParseCST(args[0].String())
+	return js.ValueOf(result.AsMap())
+}
+
 // validate function exposed to JavaScript
 func validate(this js.Value, args []js.Value) any {
 	if len(args) != 1 {
@@ -149,15 +180,489 @@ func formatWithOptions(this js.Value, args []js.Value) any {
 }
 
 
+// evaluate function exposed to JavaScript.
+// Takes the expression and an object mapping column names to numbers, strings, booleans, Dates or null.
+func evaluate(this js.Value, args []js.Value) any {
+	if len(args) < 1 || len(args) > 2 {
+		return js.ValueOf(map[string]any{
+			"value": map[string]any{"type": "null", "value": nil},
+			"errors": []any{
+				map[string]any{
+					"message": "Invalid arguments",
+					"line":    -1,
+					"column":  -1,
+					"start":   -1,
+					"end":     -1,
+				},
+			},
+		})
+	}
+
+	expression := args[0].String()
+	row := make(map[string]any)
+	if len(args) == 2 && args[1].Type() == js.TypeObject {
+		keys := js.Global().Get("Object").Call("keys", args[1])
+		for i := 0; i < keys.Length(); i++ {
+			key := keys.Index(i).String()
+			row[key] = jsToRowValue(args[1].Get(key))
+		}
+	}
+
+	result := analyzer.Evaluate(expression, row)
+	return js.ValueOf(result.AsMap())
+}
+
+// jsToRowValue converts a JavaScript cell value to the Go value expected by Evaluate.
+// Unsupported values are passed through as js.Value so that evaluation reports them on the column reference.
+func jsToRowValue(value js.Value) any {
+	switch value.Type() {
+	case js.TypeNull, js.TypeUndefined:
+		return nil
+	case js.TypeNumber:
+		return value.Float()
+	case js.TypeString:
+		return value.String()
+	case js.TypeBoolean:
+		return value.Bool()
+	case js.TypeObject:
+		if value.InstanceOf(js.Global().Get("Date")) {
+			return time.UnixMilli(int64(value.Call("getTime").Float())).UTC()
+		}
+	}
+	return value
+}
+
+// listFunctions function exposed to JavaScript as functions.
+// Returns the signature and documentation of every callable function so the editor does not duplicate them.
+func listFunctions(this js.Value, args []js.Value) any {
+	signatures := analyzer.Functions()
+	result := make([]any, len(signatures))
+	for i := range signatures {
+		result[i] = signatures[i].AsMap()
+	}
+	return js.ValueOf(result)
+}
+
+// safeInvoke calls a JavaScript function and captures any exception it throws,
+// returning {value} on success and {error} with the exception message otherwise
+var safeInvoke = js.Global().Get("Function").New("fn", "args", `
+	try {
+		return { value: fn.apply(null, args) };
+	} catch (e) {
+		return { error: String(e && e.message !== undefined ? e.message : e) };
+	}
+`)
+
+// registerFunction function exposed to JavaScript.
+// Takes a definition {name, description?, examples?, parameters?, variadic?, returnType?, nullAware?}
+// and an optional callback implementing the function. The callback receives the evaluated arguments
+// (numbers, strings, booleans, Dates or null) and returns the result; thrown exceptions become evaluation errors.
+// Returns null on success, or a message describing why the function was rejected.
+func registerFunction(this js.Value, args []js.Value) any {
+	if len(args) < 1 || len(args) > 2 || args[0].Type() != js.TypeObject {
+		return js.ValueOf("Invalid arguments")
+	}
+
+	fn, err := jsToFunction(args[0])
+	if err != nil {
+		return js.ValueOf(err.Error())
+	}
+	if len(args) == 2 && args[1].Type() == js.TypeFunction {
+		fn.Impl = jsImplementation(fn.Name, args[1])
+	} else if len(args) == 2 && !args[1].IsNull() && !args[1].IsUndefined() {
+		return js.ValueOf("Implementation must be a function")
+	}
+
+	if err := analyzer.RegisterFunction(fn); err != nil {
+		return js.ValueOf(err.Error())
+	}
+	session = nil
+	return js.Null()
+}
+
+// jsToFunction converts a JavaScript function definition to a functions.Function without implementation
+func jsToFunction(definition js.Value) (*functions.Function, error) {
+	name := definition.Get("name")
+	if name.Type() != js.TypeString {
+		return nil, fmt.Errorf("function name must be a string")
+	}
+
+	fn := &functions.Function{}
+	fn.Name = name.String()
+	fn.Description = jsOptionalString(definition.Get("description"))
+	fn.ReturnType = models.DataType(jsOptionalString(definition.Get("returnType")))
+	fn.NullAware = definition.Get("nullAware").Truthy()
+
+	if examples := definition.Get("examples"); examples.Type() == js.TypeObject {
+		for i := 0; i < examples.Length(); i++ {
+			fn.Examples = append(fn.Examples, examples.Index(i).String())
+		}
+	}
+	if params := definition.Get("parameters"); params.Type() == js.TypeObject {
+		for i := 0; i < params.Length(); i++ {
+			param, err := jsToParameter(params.Index(i))
+			if err != nil {
+				return nil, fmt.Errorf("function %s: %w", fn.Name, err)
+			}
+			fn.Parameters = append(fn.Parameters, param)
+		}
+	}
+	if variadic := definition.Get("variadic"); variadic.Type() == js.TypeObject {
+		param, err := jsToParameter(variadic)
+		if err != nil {
+			return nil, fmt.Errorf("function %s: %w", fn.Name, err)
+		}
+		fn.Variadic = &param
+	}
+	return fn, nil
+}
+
+// jsToParameter converts a JavaScript parameter definition {name, type?, optional?, description?}
+func jsToParameter(param js.Value) (models.Parameter, error) {
+	if param.Type() != js.TypeObject || param.Get("name").Type() != js.TypeString {
+		return models.Parameter{}, fmt.Errorf("parameter name must be a string")
+	}
+	return models.Parameter{
+		Name:        param.Get("name").String(),
+		Type:        models.DataType(jsOptionalString(param.Get("type"))),
+		Optional:    param.Get("optional").Truthy(),
+		Description: jsOptionalString(param.Get("description")),
+	}, nil
+}
+
+// jsOptionalString returns the string held by value, or "" if it is not a string
+func jsOptionalString(value js.Value) string {
+	if value.Type() != js.TypeString {
+		return ""
+	}
+	return value.String()
+}
+
+// jsImplementation wraps a JavaScript callback as a function implementation
+func jsImplementation(name string, callback js.Value) functions.Implementation {
+	return func(args functions.Arguments) (models.Value, error) {
+		jsArgs := js.Global().Get("Array").New(args.Len())
+		for i := 0; i < args.Len(); i++ {
+			value, err := args.At(i)
+			if err != nil {
+				return models.NullValue(), err
+			}
+			jsArgs.SetIndex(i, valueToJS(value))
+		}
+
+		outcome := safeInvoke.Invoke(callback, jsArgs)
+		if message := outcome.Get("error"); !message.IsUndefined() {
+			return models.NullValue(), fmt.Errorf("Function %s failed: %s", name, message.String())
+		}
+		result, err := models.NewValue(jsToRowValue(outcome.Get("value")))
+		if err != nil {
+			return models.NullValue(), fmt.Errorf("Function %s returned an unsupported value", name)
+		}
+		return result, nil
+	}
+}
+
+// valueToJS converts an evaluated value to the JavaScript value passed to callbacks
+func valueToJS(value models.Value) js.Value {
+	if value.IsNull() {
+		return js.Null()
+	}
+	if value.Type() == models.DataTypeDateTime {
+		return js.Global().Get("Date").New(float64(value.DateTime().UnixMilli()))
+	}
+	return js.ValueOf(value.Interface())
+}
+
+// setPositionEncoding function exposed to JavaScript.
+// Takes "rune", "utf-16" or "byte" and sets the unit of the positions of all results and of cursor offsets;
+// JavaScript strings and CodeMirror count UTF-16 code units. Returns false for an unknown encoding,
+// in which case the previous encoding is kept.
+func setPositionEncoding(this js.Value, args []js.Value) any {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return js.ValueOf(false)
+	}
+	session = nil
+	return js.ValueOf(analyzer.SetPositionEncoding(models.PositionEncoding(args[0].String())) == nil)
+}
+
+// setSchema function exposed to JavaScript.
+// Takes an array of {name, type?, description?} objects, or null to accept any column.
+// Returns false if the schema is malformed, in which case the previous schema is kept.
+func setSchema(this js.Value, args []js.Value) any {
+	if len(args) != 1 {
+		return js.ValueOf(false)
+	}
+
+	columnsJS := args[0]
+	if columnsJS.IsNull() || columnsJS.IsUndefined() {
+		session = nil
+		analyzer.SetSchema(nil)
+		return js.ValueOf(true)
+	}
+	if columnsJS.Type() != js.TypeObject || columnsJS.Get("length").Type() != js.TypeNumber {
+		return js.ValueOf(false)
+	}
+
+	columns := make([]models.Column, columnsJS.Length())
+	for i := range columns {
+		columnJS := columnsJS.Index(i)
+		if columnJS.Type() != js.TypeObject || columnJS.Get("name").Type() != js.TypeString {
+			return js.ValueOf(false)
+		}
+		columns[i].Name = columnJS.Get("name").String()
+		if dataType := columnJS.Get("type"); dataType.Type() == js.TypeString {
+			columns[i].Type = models.DataType(dataType.String())
+			if !columns[i].Type.IsValid() {
+				return js.ValueOf(false)
+			}
+		}
+		if description := columnJS.Get("description"); description.Type() == js.TypeString {
+			columns[i].Description = description.String()
+		}
+	}
+
+	session = nil
+	analyzer.SetSchema(models.NewSchema(columns))
+	return js.ValueOf(true)
+}
+
+// toJavaScript function exposed to JavaScript.
+// Returns {code, errors} where code is the source of a function `(row) => value` that evaluates
+// the expression without calling into WASM, e.g. to preview it over many rows.
+func toJavaScript(this js.Value, args []js.Value) any {
+	if len(args) != 1 {
+		return transpileFailure("Invalid arguments")
+	}
+
+	result := analyzer.ToJavaScript(args[0].String())
+	return js.ValueOf(result.AsMap())
+}
+
+// sourceFromJSON function exposed to JavaScript.
+// Takes a parse tree or AST serialized as JSON, e.g. JSON.stringify of an edited parseTree result,
+// and returns {code, errors} where code is the canonical formatted source of the tree.
+func sourceFromJSON(this js.Value, args []js.Value) any {
+	if len(args) != 1 {
+		return transpileFailure("Invalid arguments")
+	}
+
+	result := analyzer.SourceFromJSON([]byte(args[0].String()))
+	return js.ValueOf(result.AsMap())
+}
+
+// toSQL function exposed to JavaScript.
+// Takes an expression and an optional dialect name ("sqlite", "postgresql" or "ansi", the default)
+// and returns {code, errors} with the equivalent SQL expression.
+func toSQL(this js.Value, args []js.Value) any {
+	if len(args) < 1 || len(args) > 2 {
+		return transpileFailure("Invalid arguments")
+	}
+
+	dialect := sqlgen.ANSI
+	if len(args) == 2 && !args[1].IsNull() && !args[1].IsUndefined() {
+		var ok bool
+		if dialect, ok = sqlgen.LookupDialect(args[1].String()); !ok {
+			return transpileFailure(fmt.Sprintf("Unknown SQL dialect: %s", args[1].String()))
+		}
+	}
+
+	result := analyzer.ToSQL(args[0].String(), dialect)
+	return js.ValueOf(result.AsMap())
+}
+
+// renameColumn function exposed to JavaScript.
+// Takes an expression, the current column name and the new name, and returns {expression, edits, errors}
+// where edits lists {start, end, newText} replacements that keep the layout of the expression.
+func renameColumn(this js.Value, args []js.Value) any {
+	if len(args) != 3 {
+		return js.ValueOf(map[string]any{
+			"expression": "",
+			"edits":      []any{},
+			"errors": []any{
+				map[string]any{
+					"message": "Invalid arguments",
+					"line":    -1,
+					"column":  -1,
+					"start":   -1,
+					"end":     -1,
+				},
+			},
+		})
+	}
+
+	result := analyzer.RenameColumn(args[0].String(), args[1].String(), args[2].String())
+	return js.ValueOf(result.AsMap())
+}
+
+// analyzeCatalog function exposed to JavaScript.
+// Takes a catalog of named expressions as JSON {"expressions": [{name, expression}]} and returns {report, error}
+// where report holds the validation of each expression, cycles, missing dependencies and the evaluation order.
+func analyzeCatalog(this js.Value, args []js.Value) any {
+	if len(args) != 1 {
+		return js.ValueOf(map[string]any{"report": nil, "error": "Invalid arguments"})
+	}
+
+	catalog, err := analyzer.LoadCatalog([]byte(args[0].String()))
+	if err != nil {
+		return js.ValueOf(map[string]any{"report": nil, "error": err.Error()})
+	}
+	return js.ValueOf(map[string]any{"report": catalog.Analyze().AsMap(), "error": nil})
+}
+
+// catalogImpact function exposed to JavaScript.
+// Takes a catalog as JSON and a column name, and returns {expressions, error} where expressions lists
+// the named expressions that depend on the column, in evaluation order.
+func catalogImpact(this js.Value, args []js.Value) any {
+	if len(args) != 2 {
+		return js.ValueOf(map[string]any{"expressions": []any{}, "error": "Invalid arguments"})
+	}
+
+	catalog, err := analyzer.LoadCatalog([]byte(args[0].String()))
+	if err != nil {
+		return js.ValueOf(map[string]any{"expressions": []any{}, "error": err.Error()})
+	}
+
+	impact := catalog.Impact(args[1].String())
+	expressions := make([]any, len(impact))
+	for i, name := range impact {
+		expressions[i] = name
+	}
+	return js.ValueOf(map[string]any{"expressions": expressions, "error": nil})
+}
+
+// complete function exposed to JavaScript.
+// Takes an expression and a cursor offset and returns {items} where each item is
+// {label, kind, detail, documentation, edit} and edit replaces the text being typed; items are ranked best first.
+func complete(this js.Value, args []js.Value) any {
+	if len(args) != 2 || args[1].Type() != js.TypeNumber {
+		return js.ValueOf(map[string]any{"items": []any{}})
+	}
+
+	result := analyzer.Complete(args[0].String(), args[1].Int())
+	return js.ValueOf(result.AsMap())
+}
+
+// signatureHelp function exposed to JavaScript.
+// Takes an expression and a cursor offset and returns {name, signature, activeParameter, parameter}
+// describing the innermost function call around the cursor; name is empty and activeParameter -1 outside calls.
+func signatureHelp(this js.Value, args []js.Value) any {
+	if len(args) != 2 || args[1].Type() != js.TypeNumber {
+		return js.ValueOf(map[string]any{"name": "", "signature": nil, "activeParameter": -1, "parameter": nil})
+	}
+
+	result := analyzer.SignatureHelp(args[0].String(), args[1].Int())
+	return js.ValueOf(result.AsMap())
+}
+
+// nodeAt function exposed to JavaScript.
+// Takes an expression and a cursor offset and returns {node, ancestors, type, function, column}: the innermost
+// parse tree node at the offset, the nodes enclosing it, and the hover content for it.
+func nodeAt(this js.Value, args []js.Value) any {
+	if len(args) != 2 || args[1].Type() != js.TypeNumber {
+		return js.ValueOf(map[string]any{"node": nil, "ancestors": []any{}, "type": "", "function": nil, "column": nil})
+	}
+
+	result := analyzer.NodeAt(args[0].String(), args[1].Int())
+	return js.ValueOf(result.AsMap())
+}
+
+// analyze function exposed to JavaScript
+// Takes an expression and returns {tokens, errors, tree, formatted}: the results of tokenize, lint, parseTree
+// and format, computed from a single lex and parse of the expression. The expression is analyzed as an edit of
+// the one passed before, lexing again only around the change.
+func analyze(this js.Value, args []js.Value) any {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]any{
+			"tokens": []any{},
+			"errors": []any{
+				map[string]any{
+					"message": "Invalid arguments",
+					"line":    -1,
+					"column":  -1,
+					"start":   -1,
+					"end":     -1,
+				},
+			},
+			"tree":      nil,
+			"formatted": "",
+		})
+	}
+
+	if session == nil {
+		session = analyzer.NewSession(args[0].String())
+	} else {
+		session.Update(args[0].String())
+	}
+	return js.ValueOf(session.Analyze().AsMap())
+}
+
+// applyEdit function exposed to JavaScript
+// Takes the start and end offsets of a change of the expression last passed to analyze or edited, and the
+// inserted text, and returns {tokens, errors, changed, error}: the tokens and diagnostics of the edited expression
+// and the ranges where they changed, computed incrementally, or an error if there is no such expression or the
+// range is invalid.
+func applyEdit(this js.Value, args []js.Value) any {
+	failure := func(message string) js.Value {
+		return js.ValueOf(map[string]any{"tokens": []any{}, "errors": []any{}, "changed": []any{}, "error": message})
+	}
+	if len(args) != 3 || args[0].Type() != js.TypeNumber || args[1].Type() != js.TypeNumber || args[2].Type() != js.TypeString {
+		return failure("Invalid arguments")
+	}
+	if session == nil {
+		return failure("No expression to edit")
+	}
+
+	result, err := session.ApplyEdit(args[0].Int(), args[1].Int(), args[2].String())
+	if err != nil {
+		return failure(err.Error())
+	}
+	m := result.AsMap()
+	m["error"] = nil
+	return js.ValueOf(m)
+}
+
+// transpileFailure returns a transpile result holding a single error not tied to a position
+func transpileFailure(message string) js.Value {
+	return js.ValueOf(map[string]any{
+		"code": "",
+		"errors": []any{
+			map[string]any{
+				"message": message,
+				"line":    -1,
+				"column":  -1,
+				"start":   -1,
+				"end":     -1,
+			},
+		},
+	})
+}
+
 // main function registers WASM functions and keeps the program running
 func main() {
 	// Register functions
 	js.Global().Set("parseTree", js.FuncOf(parseTree))
+	js.Global().Set("parseCST", js.FuncOf(parseCST))
+	js.Global().Set("sourceFromJSON", js.FuncOf(sourceFromJSON))
 	js.Global().Set("lint", js.FuncOf(lint))
 	js.Global().Set("tokenize", js.FuncOf(tokenize))
 	js.Global().Set("validate", js.FuncOf(validate))
 	js.Global().Set("format", js.FuncOf(format))
 	js.Global().Set("formatWithOptions", js.FuncOf(formatWithOptions))
+	js.Global().Set("toJavaScript", js.FuncOf(toJavaScript))
+	js.Global().Set("setSchema", js.FuncOf(setSchema))
+	js.Global().Set("setPositionEncoding", js.FuncOf(setPositionEncoding))
+	js.Global().Set("evaluate", js.FuncOf(evaluate))
+	js.Global().Set("functions", js.FuncOf(listFunctions))
+	js.Global().Set("registerFunction", js.FuncOf(registerFunction))
+	js.Global().Set("toSQL", js.FuncOf(toSQL))
+	js.Global().Set("renameColumn", js.FuncOf(renameColumn))
+	js.Global().Set("analyzeCatalog", js.FuncOf(analyzeCatalog))
+	js.Global().Set("catalogImpact", js.FuncOf(catalogImpact))
+	js.Global().Set("complete", js.FuncOf(complete))
+	js.Global().Set("signatureHelp", js.FuncOf(signatureHelp))
+	js.Global().Set("nodeAt", js.FuncOf(nodeAt))
+	js.Global().Set("analyze", js.FuncOf(analyze))
+	js.Global().Set("applyEdit", js.FuncOf(applyEdit))
 	
 
 	// Keep the Go program running
@@ -4,8 +4,10 @@
 package main
 
 import (
+	"strings"
 	"syscall/js"
 	"testing"
+	"unicode/utf16"
 )
 
 func TestValidateExpression(t *testing.T) {
@@ -385,6 +387,572 @@ func TestFormatInvalidArguments(t *testing.T) {
 	})
 }
 
+func TestSetPositionEncoding(t *testing.T) {
+	defer setPositionEncoding(js.Value{}, []js.Value{js.ValueOf("rune")})
+
+	if got := setPositionEncoding(js.Value{}, []js.Value{js.ValueOf("utf-16")}).(js.Value).Bool(); !got {
+		t.Fatalf("setPositionEncoding(\"utf-16\") = %v, want true", got)
+	}
+
+	// JavaScript string offsets: the emoji is a surrogate pair
+	expression := "CONCAT('😀', [顧客名]) + 1"
+	tokens := tokenize(js.Value{}, []js.Value{js.ValueOf(expression)}).(js.Value).Get("tokens")
+	last := tokens.Index(tokens.Length() - 1)
+	if start, want := last.Get("start").Int(), len(utf16.Encode([]rune(expression))); start != want {
+		t.Errorf("tokenize() EOF token start = %d, want %d", start, want)
+	}
+
+	errors := lint(js.Value{}, []js.Value{js.ValueOf("'😀' + ")}).(js.Value)
+	if errors.Length() == 0 || errors.Index(0).Get("start").Int() != 7 {
+		t.Errorf("lint() error at EOF does not start at UTF-16 offset 7")
+	}
+
+	node := nodeAt(js.Value{}, []js.Value{js.ValueOf(expression), js.ValueOf(13)}).(js.Value).Get("node")
+	if text := node.Get("text").String(); text != "[顧客名]" {
+		t.Errorf("nodeAt() at UTF-16 offset 13 = %q, want %q", text, "[顧客名]")
+	}
+
+	for _, encoding := range []any{"utf-32", 16, nil} {
+		if setPositionEncoding(js.Value{}, []js.Value{js.ValueOf(encoding)}).(js.Value).Bool() {
+			t.Errorf("setPositionEncoding(%v) = true, want false", encoding)
+		}
+	}
+}
+
+func TestSetSchema(t *testing.T) {
+	defer setSchema(js.Value{}, []js.Value{js.Null()})
+
+	schema := js.ValueOf([]any{
+		map[string]any{"name": "price", "type": "number", "description": "Unit price"},
+		map[string]any{"name": "name", "type": "string"},
+		map[string]any{"name": "notes"},
+	})
+	if got := setSchema(js.Value{}, []js.Value{schema}).(js.Value).Bool(); !got {
+		t.Fatalf("setSchema() = %v, want true", got)
+	}
+
+	tests := []struct {
+		name        string
+		expression  string
+		wantMessage string
+	}{
+		{"known columns", "[price] > 1 && [notes] == 'x'", ""},
+		{"unknown column", "[pricee] > 1", "Unknown column: [pricee], did you mean [price]?"},
+		{"declared type", "[name] * 2", "Operator '*' expects number operands, got string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := lint(js.Value{}, []js.Value{js.ValueOf(tt.expression)}).(js.Value)
+			if tt.wantMessage == "" {
+				if errors.Length() != 0 {
+					t.Errorf("lint(%q) returned %d errors, want 0", tt.expression, errors.Length())
+				}
+				return
+			}
+			if errors.Length() != 1 {
+				t.Fatalf("lint(%q) returned %d errors, want 1", tt.expression, errors.Length())
+			}
+			if msg := errors.Index(0).Get("message").String(); msg != tt.wantMessage {
+				t.Errorf("lint(%q) message = %q, want %q", tt.expression, msg, tt.wantMessage)
+			}
+		})
+	}
+
+	t.Run("invalid column type keeps previous schema", func(t *testing.T) {
+		invalid := js.ValueOf([]any{map[string]any{"name": "price", "type": "money"}})
+		if got := setSchema(js.Value{}, []js.Value{invalid}).(js.Value).Bool(); got {
+			t.Errorf("setSchema() with invalid type = %v, want false", got)
+		}
+		if got := validate(js.Value{}, []js.Value{js.ValueOf("[pricee] > 1")}).(js.Value).Bool(); got {
+			t.Errorf("validate() after rejected schema = %v, want false", got)
+		}
+	})
+
+	t.Run("null clears the schema", func(t *testing.T) {
+		if got := setSchema(js.Value{}, []js.Value{js.Null()}).(js.Value).Bool(); !got {
+			t.Fatalf("setSchema(null) = %v, want true", got)
+		}
+		if got := validate(js.Value{}, []js.Value{js.ValueOf("[pricee] > 1")}).(js.Value).Bool(); !got {
+			t.Errorf("validate() without schema = %v, want true", got)
+		}
+	})
+}
+
+func TestEvaluate(t *testing.T) {
+	row := js.ValueOf(map[string]any{
+		"price":    19.5,
+		"quantity": 4,
+		"name":     "Widget",
+		"discount": nil,
+	})
+
+	tests := []struct {
+		name        string
+		expression  string
+		wantType    string
+		wantValue   any
+		wantMessage string
+	}{
+		{"arithmetic", "[price] * [quantity] - 2 ^ 3 ^ 0", "number", 76.0, ""},
+		{"string", "[name]", "string", "Widget", ""},
+		{"comparison", "[name] == 'Widget' && [quantity] > 3", "boolean", true, ""},
+		{"null", "[discount] + 1", "null", nil, ""},
+		{"runtime error", "[price] / 0", "null", nil, "Division by zero"},
+		{"missing column", "[tax]", "null", nil, "Unknown column: [tax]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := evaluate(js.Value{}, []js.Value{js.ValueOf(tt.expression), row}).(js.Value)
+			value := result.Get("value")
+			if got := value.Get("type").String(); got != tt.wantType {
+				t.Errorf("evaluate(%q) type = %q, want %q", tt.expression, got, tt.wantType)
+			}
+			switch want := tt.wantValue.(type) {
+			case float64:
+				if got := value.Get("value").Float(); got != want {
+					t.Errorf("evaluate(%q) value = %v, want %v", tt.expression, got, want)
+				}
+			case string:
+				if got := value.Get("value").String(); got != want {
+					t.Errorf("evaluate(%q) value = %q, want %q", tt.expression, got, want)
+				}
+			case bool:
+				if got := value.Get("value").Bool(); got != want {
+					t.Errorf("evaluate(%q) value = %v, want %v", tt.expression, got, want)
+				}
+			case nil:
+				if !value.Get("value").IsNull() {
+					t.Errorf("evaluate(%q) value = %v, want null", tt.expression, value.Get("value"))
+				}
+			}
+
+			errors := result.Get("errors")
+			if tt.wantMessage == "" {
+				if errors.Length() != 0 {
+					t.Errorf("evaluate(%q) returned %d errors, want 0", tt.expression, errors.Length())
+				}
+				return
+			}
+			if errors.Length() != 1 {
+				t.Fatalf("evaluate(%q) returned %d errors, want 1", tt.expression, errors.Length())
+			}
+			if msg := errors.Index(0).Get("message").String(); msg != tt.wantMessage {
+				t.Errorf("evaluate(%q) message = %q, want %q", tt.expression, msg, tt.wantMessage)
+			}
+		})
+	}
+
+	t.Run("Date values", func(t *testing.T) {
+		dateRow := js.Global().Get("Object").New()
+		dateRow.Set("created", js.Global().Get("Date").New("2024-03-01T12:00:00Z"))
+		result := evaluate(js.Value{}, []js.Value{js.ValueOf("[created]"), dateRow}).(js.Value)
+		value := result.Get("value")
+		if got := value.Get("type").String(); got != "datetime" {
+			t.Errorf("evaluate() type = %q, want datetime", got)
+		}
+		if got := value.Get("value").String(); got != "2024-03-01T12:00:00Z" {
+			t.Errorf("evaluate() value = %q, want 2024-03-01T12:00:00Z", got)
+		}
+	})
+}
+
+func TestFunctions(t *testing.T) {
+	result := listFunctions(js.Value{}, []js.Value{}).(js.Value)
+	if result.Length() == 0 {
+		t.Fatal("functions() returned no functions")
+	}
+
+	var substring js.Value
+	for i := 0; i < result.Length(); i++ {
+		if fn := result.Index(i); fn.Get("name").String() == "SUBSTRING" {
+			substring = fn
+		}
+	}
+	if substring.IsUndefined() {
+		t.Fatal("functions() did not return SUBSTRING")
+	}
+
+	if got := substring.Get("syntax").String(); got != "SUBSTRING(text, start, length)" {
+		t.Errorf("SUBSTRING syntax = %q, want %q", got, "SUBSTRING(text, start, length)")
+	}
+	if got := substring.Get("returnType").String(); got != "string" {
+		t.Errorf("SUBSTRING returnType = %q, want string", got)
+	}
+	if got := substring.Get("parameters").Length(); got != 3 {
+		t.Errorf("SUBSTRING has %d parameters, want 3", got)
+	}
+	if !substring.Get("variadic").IsNull() {
+		t.Errorf("SUBSTRING variadic = %v, want null", substring.Get("variadic"))
+	}
+}
+
+func TestRegisterFunction(t *testing.T) {
+	callback := js.FuncOf(func(this js.Value, args []js.Value) any {
+		return js.ValueOf(args[0].Float() * 2)
+	})
+	defer callback.Release()
+
+	definition := js.ValueOf(map[string]any{
+		"name":        "DOUBLE_IT",
+		"description": "Doubles a number.",
+		"parameters":  []any{map[string]any{"name": "value", "type": "number"}},
+		"returnType":  "number",
+	})
+	if got := registerFunction(js.Value{}, []js.Value{definition, callback.Value}); !got.(js.Value).IsNull() {
+		t.Fatalf("registerFunction() = %v, want null", got)
+	}
+
+	t.Run("calls are validated", func(t *testing.T) {
+		if got := validate(js.Value{}, []js.Value{js.ValueOf("DOUBLE_IT(2) > 3")}).(js.Value).Bool(); !got {
+			t.Errorf("validate() = %v, want true", got)
+		}
+		if got := validate(js.Value{}, []js.Value{js.ValueOf("DOUBLE_IT('a')")}).(js.Value).Bool(); got {
+			t.Errorf("validate() with string argument = %v, want false", got)
+		}
+	})
+
+	t.Run("callback implements the function", func(t *testing.T) {
+		result := evaluate(js.Value{}, []js.Value{js.ValueOf("DOUBLE_IT(21)")}).(js.Value)
+		if errors := result.Get("errors"); errors.Length() != 0 {
+			t.Fatalf("evaluate() returned error %q", errors.Index(0).Get("message").String())
+		}
+		if got := result.Get("value").Get("value").Float(); got != 42 {
+			t.Errorf("evaluate() = %v, want 42", got)
+		}
+	})
+
+	t.Run("exceptions become evaluation errors", func(t *testing.T) {
+		throwing := js.Global().Get("Function").New("throw new Error('boom')")
+		definition := js.ValueOf(map[string]any{"name": "EXPLODE"})
+		if got := registerFunction(js.Value{}, []js.Value{definition, throwing}); !got.(js.Value).IsNull() {
+			t.Fatalf("registerFunction() = %v, want null", got)
+		}
+
+		result := evaluate(js.Value{}, []js.Value{js.ValueOf("EXPLODE()")}).(js.Value)
+		errors := result.Get("errors")
+		if errors.Length() != 1 {
+			t.Fatalf("evaluate() returned %d errors, want 1", errors.Length())
+		}
+		if msg := errors.Index(0).Get("message").String(); msg != "Function EXPLODE failed: boom" {
+			t.Errorf("evaluate() message = %q, want %q", msg, "Function EXPLODE failed: boom")
+		}
+	})
+
+	t.Run("invalid definitions are rejected", func(t *testing.T) {
+		builtin := js.ValueOf(map[string]any{"name": "UPPER"})
+		got := registerFunction(js.Value{}, []js.Value{builtin}).(js.Value)
+		if got.Type() != js.TypeString || got.String() != "function UPPER is a built-in function and cannot be replaced" {
+			t.Errorf("registerFunction(UPPER) = %v, want built-in error", got)
+		}
+
+		noName := js.ValueOf(map[string]any{"description": "nameless"})
+		if got := registerFunction(js.Value{}, []js.Value{noName}).(js.Value); got.Type() != js.TypeString {
+			t.Errorf("registerFunction() without name = %v, want error message", got)
+		}
+	})
+}
+
+func TestToJavaScript(t *testing.T) {
+	result := toJavaScript(js.Value{}, []js.Value{js.ValueOf("IF([qty] > 0, ROUND([price] * [qty], 1), 2 ^ 3 ^ 2)")}).(js.Value)
+	if errors := result.Get("errors"); errors.Length() != 0 {
+		t.Fatalf("toJavaScript() returned error %q", errors.Index(0).Get("message").String())
+	}
+	fn := js.Global().Call("eval", result.Get("code"))
+	if fn.Type() != js.TypeFunction {
+		t.Fatalf("toJavaScript() code evaluates to %v, want a function", fn.Type())
+	}
+
+	tests := []struct {
+		row  map[string]any
+		want float64
+	}{
+		{map[string]any{"price": 2.25, "qty": 3}, 6.8},
+		{map[string]any{"price": 2.25, "qty": 0}, 512},
+	}
+	for _, tt := range tests {
+		if got := fn.Invoke(js.ValueOf(tt.row)).Float(); got != tt.want {
+			t.Errorf("function(%v) = %v, want %v", tt.row, got, tt.want)
+		}
+	}
+
+	t.Run("errors are thrown", func(t *testing.T) {
+		failing := toJavaScript(js.Value{}, []js.Value{js.ValueOf("[price] / [qty]")}).(js.Value)
+		fn := js.Global().Call("eval", failing.Get("code"))
+		outcome := safeInvoke.Invoke(fn, js.ValueOf([]any{map[string]any{"price": 1, "qty": 0}}))
+		if got := outcome.Get("error").String(); got != "Division by zero" {
+			t.Errorf("function() error = %q, want %q", got, "Division by zero")
+		}
+	})
+
+	t.Run("invalid expression", func(t *testing.T) {
+		result := toJavaScript(js.Value{}, []js.Value{js.ValueOf("1 +")}).(js.Value)
+		if got := result.Get("code").String(); got != "" {
+			t.Errorf("toJavaScript() code = %q, want empty", got)
+		}
+		if result.Get("errors").Length() == 0 {
+			t.Error("toJavaScript() returned no errors")
+		}
+	})
+}
+
+func TestToSQL(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []js.Value
+		wantCode    string
+		wantMessage string
+	}{
+		{"default dialect", []js.Value{js.ValueOf("[price] / 2 > 1")}, `CAST("price" AS DOUBLE PRECISION) / 2 > 1`, ""},
+		{"sqlite", []js.Value{js.ValueOf("LEN([name])"), js.ValueOf("SQLite")}, `LENGTH("name")`, ""},
+		{"unsupported function", []js.Value{js.ValueOf("ROUND([price], 1)"), js.ValueOf("ansi")}, "", "Function ROUND cannot be expressed in the ansi dialect"},
+		{"unknown dialect", []js.Value{js.ValueOf("1"), js.ValueOf("oracle")}, "", "Unknown SQL dialect: oracle"},
+		{"no arguments", []js.Value{}, "", "Invalid arguments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := toSQL(js.Value{}, tt.args).(js.Value)
+			if got := result.Get("code").String(); got != tt.wantCode {
+				t.Errorf("toSQL() code = %q, want %q", got, tt.wantCode)
+			}
+			errors := result.Get("errors")
+			if tt.wantMessage == "" {
+				if errors.Length() != 0 {
+					t.Errorf("toSQL() returned error %q", errors.Index(0).Get("message").String())
+				}
+				return
+			}
+			if errors.Length() != 1 {
+				t.Fatalf("toSQL() returned %d errors, want 1", errors.Length())
+			}
+			if msg := errors.Index(0).Get("message").String(); msg != tt.wantMessage {
+				t.Errorf("toSQL() message = %q, want %q", msg, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestRenameColumn(t *testing.T) {
+	result := renameColumn(js.Value{}, []js.Value{js.ValueOf("[a]  +  '[a]' * [a]"), js.ValueOf("a"), js.ValueOf("amount")}).(js.Value)
+	if got := result.Get("expression").String(); got != "[amount]  +  '[a]' * [amount]" {
+		t.Errorf("renameColumn() expression = %q", got)
+	}
+	edits := result.Get("edits")
+	if edits.Length() != 2 {
+		t.Fatalf("renameColumn() returned %d edits, want 2", edits.Length())
+	}
+	if start, end, text := edits.Index(1).Get("start").Int(), edits.Index(1).Get("end").Int(), edits.Index(1).Get("newText").String(); start != 17 || end != 18 || text != "amount" {
+		t.Errorf("renameColumn() edit = {%d, %d, %q}, want {17, 18, \"amount\"}", start, end, text)
+	}
+
+	result = renameColumn(js.Value{}, []js.Value{js.ValueOf("[a]"), js.ValueOf("a"), js.ValueOf("unit price")}).(js.Value)
+	if result.Get("errors").Length() != 1 {
+		t.Errorf("renameColumn() with an invalid name returned %d errors, want 1", result.Get("errors").Length())
+	}
+
+	result = renameColumn(js.Value{}, []js.Value{js.ValueOf("[a]")}).(js.Value)
+	if msg := result.Get("errors").Index(0).Get("message").String(); msg != "Invalid arguments" {
+		t.Errorf("renameColumn() message = %q, want %q", msg, "Invalid arguments")
+	}
+}
+
+func TestAnalyzeCatalog(t *testing.T) {
+	catalog := `{"expressions": [
+		{"name": "margin", "expression": "[revenue] - [cost]"},
+		{"name": "revenue", "expression": "[price] * [qty]"},
+		{"name": "cost", "expression": "[unit_cost] * [qty] + [cost]"}
+	]}`
+
+	result := analyzeCatalog(js.Value{}, []js.Value{js.ValueOf(catalog)}).(js.Value)
+	if !result.Get("error").IsNull() {
+		t.Fatalf("analyzeCatalog() error = %q", result.Get("error").String())
+	}
+	report := result.Get("report")
+	if order := report.Get("order"); order.Length() != 1 || order.Index(0).String() != "revenue" {
+		t.Errorf("analyzeCatalog() order has %d entries, want [revenue]", order.Length())
+	}
+	if cycles := report.Get("cycles"); cycles.Length() != 1 || cycles.Index(0).Index(0).String() != "cost" {
+		t.Errorf("analyzeCatalog() cycles has %d entries, want [[cost]]", cycles.Length())
+	}
+	if deps := report.Get("expressions").Index(0).Get("dependencies"); deps.Length() != 2 {
+		t.Errorf("analyzeCatalog() margin has %d dependencies, want 2", deps.Length())
+	}
+
+	result = catalogImpact(js.Value{}, []js.Value{js.ValueOf(catalog), js.ValueOf("price")}).(js.Value)
+	expressions := result.Get("expressions")
+	if expressions.Length() != 2 || expressions.Index(0).String() != "revenue" || expressions.Index(1).String() != "margin" {
+		t.Errorf("catalogImpact() returned %d expressions, want [revenue margin]", expressions.Length())
+	}
+
+	result = analyzeCatalog(js.Value{}, []js.Value{js.ValueOf(`{"expressions": [{"name": "a b", "expression": "1"}]}`)}).(js.Value)
+	if !result.Get("report").IsNull() || result.Get("error").String() != `invalid catalog: invalid expression name "a b": must be non-empty without brackets or whitespace` {
+		t.Errorf("analyzeCatalog() with an invalid name returned error %q", result.Get("error").String())
+	}
+
+	result = catalogImpact(js.Value{}, []js.Value{js.ValueOf(catalog)}).(js.Value)
+	if result.Get("error").String() != "Invalid arguments" {
+		t.Errorf("catalogImpact() error = %q, want %q", result.Get("error").String(), "Invalid arguments")
+	}
+}
+
+func TestParseCST(t *testing.T) {
+	expression := "SUM( [a],\n  2 ) @"
+	result := parseCST(js.Value{}, []js.Value{js.ValueOf(expression)}).(js.Value)
+	if result.Get("errors").Length() != 1 {
+		t.Errorf("parseCST() returned %d errors, want 1", result.Get("errors").Length())
+	}
+
+	// Concatenating the trivia and text of the tokens reproduces the expression
+	var source strings.Builder
+	writeTrivia := func(trivia js.Value) {
+		for i := 0; i < trivia.Length(); i++ {
+			source.WriteString(trivia.Index(i).Get("text").String())
+		}
+	}
+	var collect func(node js.Value)
+	collect = func(node js.Value) {
+		children := node.Get("children")
+		if children.Length() == 0 {
+			writeTrivia(node.Get("leading"))
+			source.WriteString(node.Get("text").String())
+			writeTrivia(node.Get("trailing"))
+		}
+		for i := 0; i < children.Length(); i++ {
+			collect(children.Index(i))
+		}
+	}
+	collect(result.Get("tree"))
+	if source.String() != expression {
+		t.Errorf("parseCST() tree source = %q, want %q", source.String(), expression)
+	}
+
+	result = parseCST(js.Value{}, []js.Value{}).(js.Value)
+	if !result.Get("tree").IsNull() || result.Get("errors").Index(0).Get("message").String() != "Invalid arguments" {
+		t.Error("parseCST() with no arguments should return an Invalid arguments error")
+	}
+}
+
+func TestSourceFromJSON(t *testing.T) {
+	tree := `{"type": 0, "children": [{"type": 8, "text": "[a]+2", "start": 0, "end": 5, "children": [
+		{"type": 2, "text": "[a]", "start": 0, "end": 3},
+		{"type": 1, "text": "2", "start": 4, "end": 5, "children": [{"type": 36, "text": "2", "start": 4, "end": 5}]}
+	]}]}`
+	result := sourceFromJSON(js.Value{}, []js.Value{js.ValueOf(tree)}).(js.Value)
+	if result.Get("errors").Length() != 0 {
+		t.Fatalf("sourceFromJSON() error = %q", result.Get("errors").Index(0).Get("message").String())
+	}
+	if got := result.Get("code").String(); got != "[a] + 2" {
+		t.Errorf("sourceFromJSON() code = %q, want %q", got, "[a] + 2")
+	}
+
+	result = sourceFromJSON(js.Value{}, []js.Value{js.ValueOf(`{"type": 4, "children": []}`)}).(js.Value)
+	if msg := result.Get("errors").Index(0).Get("message").String(); msg != "Invalid tree: tree: ParenExpr must have 1 child, got 0" {
+		t.Errorf("sourceFromJSON() message = %q", msg)
+	}
+
+	result = sourceFromJSON(js.Value{}, []js.Value{}).(js.Value)
+	if msg := result.Get("errors").Index(0).Get("message").String(); msg != "Invalid arguments" {
+		t.Errorf("sourceFromJSON() message = %q, want %q", msg, "Invalid arguments")
+	}
+}
+
+func TestComplete(t *testing.T) {
+	result := complete(js.Value{}, []js.Value{js.ValueOf("[a] + RO"), js.ValueOf(8)}).(js.Value)
+	items := result.Get("items")
+	if items.Length() != 1 {
+		t.Fatalf("complete() returned %d items, want 1", items.Length())
+	}
+	item := items.Index(0)
+	if label, kind := item.Get("label").String(), item.Get("kind").String(); label != "ROUND" || kind != "function" {
+		t.Errorf("complete() item = {%q, %q}, want {\"ROUND\", \"function\"}", label, kind)
+	}
+	edit := item.Get("edit")
+	if start, end, text := edit.Get("start").Int(), edit.Get("end").Int(), edit.Get("newText").String(); start != 6 || end != 8 || text != "ROUND(" {
+		t.Errorf("complete() edit = {%d, %d, %q}, want {6, 8, \"ROUND(\"}", start, end, text)
+	}
+
+	result = complete(js.Value{}, []js.Value{js.ValueOf("[a]")}).(js.Value)
+	if result.Get("items").Length() != 0 {
+		t.Errorf("complete() with missing offset returned %d items, want 0", result.Get("items").Length())
+	}
+}
+
+func TestSignatureHelp(t *testing.T) {
+	result := signatureHelp(js.Value{}, []js.Value{js.ValueOf("SUBSTRING([name], "), js.ValueOf(18)}).(js.Value)
+	if name, active := result.Get("name").String(), result.Get("activeParameter").Int(); name != "SUBSTRING" || active != 1 {
+		t.Errorf("signatureHelp() = {%q, %d}, want {\"SUBSTRING\", 1}", name, active)
+	}
+	if params := result.Get("signature").Get("parameters"); params.Length() != 3 {
+		t.Errorf("signatureHelp() signature has %d parameters, want 3", params.Length())
+	}
+	if param := result.Get("parameter").Get("name").String(); param != "start" {
+		t.Errorf("signatureHelp() parameter = %q, want %q", param, "start")
+	}
+
+	result = signatureHelp(js.Value{}, []js.Value{js.ValueOf("[a] + 1"), js.ValueOf(3)}).(js.Value)
+	if !result.Get("signature").IsNull() || result.Get("activeParameter").Int() != -1 {
+		t.Errorf("signatureHelp() outside a call returned a signature")
+	}
+
+	result = signatureHelp(js.Value{}, []js.Value{js.ValueOf("ROUND(")}).(js.Value)
+	if result.Get("activeParameter").Int() != -1 {
+		t.Errorf("signatureHelp() with missing offset = %d, want -1", result.Get("activeParameter").Int())
+	}
+}
+
+func TestNodeAt(t *testing.T) {
+	result := nodeAt(js.Value{}, []js.Value{js.ValueOf("ROUND([a], 2)"), js.ValueOf(1)}).(js.Value)
+	if text := result.Get("node").Get("text").String(); text != "ROUND" {
+		t.Errorf("nodeAt() node text = %q, want %q", text, "ROUND")
+	}
+	if ancestors := result.Get("ancestors").Length(); ancestors != 3 {
+		t.Errorf("nodeAt() has %d ancestors, want 3", ancestors)
+	}
+	if name := result.Get("function").Get("name").String(); name != "ROUND" {
+		t.Errorf("nodeAt() function = %q, want %q", name, "ROUND")
+	}
+	if dataType := result.Get("type").String(); dataType != "number" {
+		t.Errorf("nodeAt() type = %q, want %q", dataType, "number")
+	}
+
+	result = nodeAt(js.Value{}, []js.Value{js.ValueOf("ROUND([a], 2)")}).(js.Value)
+	if !result.Get("node").IsNull() {
+		t.Errorf("nodeAt() with missing offset returned a node")
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	expression := "[a]*2+"
+	result := analyze(js.Value{}, []js.Value{js.ValueOf(expression)}).(js.Value)
+
+	tokens := tokenize(js.Value{}, []js.Value{js.ValueOf(expression)}).(js.Value).Get("tokens")
+	if got, want := result.Get("tokens").Length(), tokens.Length(); got != want {
+		t.Errorf("analyze() returned %d tokens, want %d", got, want)
+	}
+	errors := lint(js.Value{}, []js.Value{js.ValueOf(expression)}).(js.Value)
+	if got, want := result.Get("errors").Length(), errors.Length(); got != want || got == 0 {
+		t.Errorf("analyze() returned %d errors, want %d", got, want)
+	}
+	if result.Get("tree").Get("type").Int() != 0 {
+		t.Errorf("analyze() tree root is not an Expression node")
+	}
+	if formatted := result.Get("formatted").String(); formatted != expression {
+		t.Errorf("analyze() formatted = %q, want the expression unchanged", formatted)
+	}
+
+	result = analyze(js.Value{}, []js.Value{js.ValueOf("[a]*2")}).(js.Value)
+	if formatted := result.Get("formatted").String(); formatted != "[a] * 2" {
+		t.Errorf("analyze() formatted = %q, want %q", formatted, "[a] * 2")
+	}
+
+	result = analyze(js.Value{}, []js.Value{}).(js.Value)
+	if result.Get("errors").Length() != 1 || !result.Get("tree").IsNull() {
+		t.Errorf("analyze() with no args should return a single error and no tree")
+	}
+}
+
 func TestInvalidArguments(t *testing.T) {
 	t.Run("validate with no arguments", func(t *testing.T) {
 		args := []js.Value{}
@@ -412,3 +980,39 @@ func TestInvalidArguments(t *testing.T) {
 		}
 	})
 }
+
+func TestApplyEdit(t *testing.T) {
+	setSchema(js.Value{}, []js.Value{js.Null()})
+	result := applyEdit(js.Value{}, []js.Value{js.ValueOf(0), js.ValueOf(0), js.ValueOf("1")}).(js.Value)
+	if result.Get("error").String() != "No expression to edit" {
+		t.Errorf("applyEdit() before analyze error = %q", result.Get("error").String())
+	}
+
+	analyze(js.Value{}, []js.Value{js.ValueOf("[a] * 2")})
+	result = applyEdit(js.Value{}, []js.Value{js.ValueOf(6), js.ValueOf(7), js.ValueOf("(2")}).(js.Value)
+	if !result.Get("error").IsNull() {
+		t.Fatalf("applyEdit() error = %q", result.Get("error").String())
+	}
+	errors := lint(js.Value{}, []js.Value{js.ValueOf("[a] * (2")}).(js.Value)
+	if got, want := result.Get("errors").Length(), errors.Length(); got != want || got == 0 {
+		t.Errorf("applyEdit() returned %d errors, want %d", got, want)
+	}
+	if changed := result.Get("changed"); changed.Length() == 0 || changed.Index(0).Get("start").Int() != 6 {
+		t.Errorf("applyEdit() changed ranges do not start at the edit")
+	}
+
+	// analyze continues from the edited expression
+	tree := analyze(js.Value{}, []js.Value{js.ValueOf("[a] * (2)")}).(js.Value).Get("tree")
+	if tree.Get("text").String() != "[a] * (2)" {
+		t.Errorf("analyze() after applyEdit tree text = %q", tree.Get("text").String())
+	}
+
+	result = applyEdit(js.Value{}, []js.Value{js.ValueOf(5), js.ValueOf(100), js.ValueOf("")}).(js.Value)
+	if result.Get("error").IsNull() {
+		t.Errorf("applyEdit() past the end should return an error")
+	}
+	result = applyEdit(js.Value{}, []js.Value{js.ValueOf("0")}).(js.Value)
+	if result.Get("error").String() != "Invalid arguments" {
+		t.Errorf("applyEdit() with invalid args error = %q", result.Get("error").String())
+	}
+}
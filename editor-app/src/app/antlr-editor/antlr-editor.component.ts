@@ -9,13 +9,12 @@ import { basicSetup, EditorView } from 'codemirror';
 import { type Analyzer, loadAnalyzer } from '../../wasm/analyzer';
 import { expressionAutocompletion } from './extensions/completion/autocomplete';
 import { formatExpression, formatKeymap } from './extensions/format/formatter';
-import type { FunctionDescription } from './extensions/function';
 import { applyLintStyles } from './extensions/lint/lint-styles';
 import { expressionLinter } from './extensions/lint/linter';
+import { expressionSignatureHelp } from './extensions/signature-help/signature-help';
 import { expressionLanguageSupport } from './extensions/syntax-highlight/language';
 import { darkHighlightStyle } from './extensions/syntax-highlight/theme/dark';
 import { expressionHoverTooltip } from './extensions/tooltip/tooltip';
-import { functionDescriptions } from './function-descriptions';
 
 @Component({
   selector: 'antlr-editor',
@@ -28,7 +27,6 @@ export class AntlrEditorComponent implements OnInit, AfterViewInit {
   @ViewChild('editor', { static: true }) editorElement!: ElementRef<HTMLDivElement>;
   @Input() initialValue: string = '';
   @Input() theme: 'light' | 'dark' = 'dark';
-  @Input() functionDescriptions: Record<string, FunctionDescription> = functionDescriptions;
   @Output() valueChange = new EventEmitter<string>();
 
   private editorView!: EditorView;
@@ -59,8 +57,9 @@ export class AntlrEditorComponent implements OnInit, AfterViewInit {
       expressionLanguageSupport(this.analyzer), // Expression language with syntax highlighting
       syntaxHighlighting(darkHighlightStyle), // Dark theme optimized highlighting
       expressionLinter(this.analyzer), // Error highlighting with underlines
-      expressionAutocompletion(this.functionDescriptions), // Function name autocompletion
-      expressionHoverTooltip(this.functionDescriptions), // Function description tooltips
+      expressionAutocompletion(this.analyzer), // Grammar-aware autocompletion
+      expressionSignatureHelp(this.analyzer), // Function signature with the active parameter
+      expressionHoverTooltip(this.analyzer), // Function and column tooltips
       bracketMatching(),
       lineNumbers(),
       foldGutter(),
@@ -1,48 +1,43 @@
 import { autocompletion, type Completion, type CompletionContext, type CompletionResult } from '@codemirror/autocomplete';
-import type { FunctionDescription } from '../function';
+import type { Analyzer, CompletionKind } from '../../../../wasm/analyzer';
 
-// Completion function factory
-const createExpressionCompletions = (functionDescriptions: Record<string, FunctionDescription>) => {
+// CodeMirror completion types of the analyzer's completion kinds, which select the icon
+const completionTypes: Record<CompletionKind, string> = {
+  column: 'variable',
+  function: 'function',
+  literal: 'constant',
+  punctuation: 'keyword',
+  operator: 'keyword',
+};
+
+// Completion function factory; suggestions come from the analyzer, which knows what the grammar allows at the cursor
+const createExpressionCompletions = (analyzer: Analyzer) => {
   return (context: CompletionContext): CompletionResult | null => {
-    const word = context.matchBefore(/\w*/);
-    if (!word) {
+    const { items } = analyzer.complete(context.state.doc.toString(), context.pos);
+    if (items.length === 0) {
       return null;
     }
 
-    // Don't show completions in the middle of a word
-    if (word.from === word.to && !context.explicit) {
+    // Only open the list unprompted while a name is being typed
+    const { start, end } = items[0].edit;
+    if (start === context.pos && !context.explicit) {
       return null;
     }
 
-    // Convert function descriptions to completion options
-    const functionCompletions: Completion[] = Object.values(functionDescriptions).map((desc) => ({
-      label: desc.name,
-      type: desc.type || 'function',
-      detail: desc.detail || desc.syntax,
-      info: desc.info || desc.description,
-      apply: (view, completion, from, to) => {
-        let insert = completion.label;
-
-        // Add parentheses for functions
-        if (completion.type === 'function') {
-          insert += '()';
-          // Position cursor between parentheses
-          view.dispatch({
-            changes: { from, to, insert },
-            selection: { anchor: from + insert.length - 1 },
-          });
-        } else {
-          view.dispatch({
-            changes: { from, to, insert },
-          });
-        }
-      },
+    // Items arrive ranked best first; boost keeps that order among equally good matches
+    const options: Completion[] = items.map((item, index) => ({
+      label: item.label,
+      type: completionTypes[item.kind],
+      detail: item.detail,
+      info: item.documentation || undefined,
+      apply: item.edit.newText,
+      boost: Math.max(-99, -index),
     }));
 
     return {
-      from: word.from,
-      options: functionCompletions,
-      validFor: /^\w*$/,
+      from: start,
+      to: end,
+      options,
     };
   };
 };
@@ -97,6 +92,16 @@ const applyAutocompleteStyles = () => {
       border: 1px solid rgba(237, 137, 54, 0.3) !important;
     }
 
+    .cm-completionIcon-variable {
+      background: rgba(183, 148, 244, 0.2) !important;
+      color: #b794f4 !important;
+      border: 1px solid rgba(183, 148, 244, 0.3) !important;
+    }
+
+    .cm-completionIcon-variable::before {
+      content: "[" !important;
+    }
+
     .cm-completionIcon-function::before {
       content: "ƒ" !important;
     }
@@ -220,12 +225,12 @@ const applyAutocompleteStyles = () => {
 };
 
 // Create the autocompletion extension
-export const expressionAutocompletion = (functionDescriptions: Record<string, FunctionDescription>) => {
+export const expressionAutocompletion = (analyzer: Analyzer) => {
   // Apply custom styles
   applyAutocompleteStyles();
 
   return autocompletion({
-    override: [createExpressionCompletions(functionDescriptions)],
+    override: [createExpressionCompletions(analyzer)],
     defaultKeymap: true,
     closeOnBlur: true,
     icons: true,
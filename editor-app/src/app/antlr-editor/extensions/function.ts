@@ -1,9 +0,0 @@
-export interface FunctionDescription {
-  name: string;
-  description: string;
-  syntax: string;
-  examples?: string[];
-  type?: string;
-  detail?: string;
-  info?: string;
-}
@@ -38,8 +38,8 @@ export const expressionLinter = (analyzer: Analyzer) => {
         return [];
       }
 
-      // Parse the expression
-      const errors = analyzer.lint(text);
+      // Reuse the analysis the syntax highlighter made of the same text
+      const { errors } = analyzer.analyze(text);
 
       // Convert errors to diagnostics
       return errorsToDiagnostics(errors);
@@ -0,0 +1,86 @@
+import { type EditorState, StateField } from '@codemirror/state';
+import { showTooltip, type Tooltip } from '@codemirror/view';
+import type { Analyzer, SignatureHelpResult } from '../../../../wasm/analyzer';
+
+// Build the tooltip of the function call around the cursor, if any
+const signatureTooltip = (analyzer: Analyzer, state: EditorState): Tooltip | null => {
+  const selection = state.selection.main;
+  if (!selection.empty) {
+    return null;
+  }
+
+  const help = analyzer.signatureHelp(state.doc.toString(), selection.head);
+  if (!help.signature) {
+    return null;
+  }
+
+  return {
+    pos: selection.head,
+    above: true,
+    strictSide: true,
+    create: () => ({ dom: createSignatureDOM(help) }),
+  };
+};
+
+// Render the signature with the active parameter highlighted, followed by its description
+const createSignatureDOM = (help: SignatureHelpResult): HTMLElement => {
+  const signature = help.signature!;
+  const tooltip = document.createElement('div');
+  tooltip.className = 'cm-tooltip-signature';
+  tooltip.style.cssText = `
+    background: #2d3748;
+    border: 1px solid rgba(255, 255, 255, 0.1);
+    border-radius: 8px;
+    padding: 8px 12px;
+    max-width: 450px;
+    color: #e2e8f0;
+    font-family: 'SF Mono', Monaco, 'Cascadia Code', 'Roboto Mono', Consolas, 'Courier New', monospace;
+    font-size: 12px;
+    line-height: 1.5;
+  `;
+
+  const line = document.createElement('div');
+  line.append(`${signature.name}(`);
+
+  const parameters = signature.variadic ? [...signature.parameters, signature.variadic] : signature.parameters;
+  parameters.forEach((param, index) => {
+    if (index > 0) {
+      line.append(', ');
+    }
+    const name = document.createElement('span');
+    name.textContent = param === signature.variadic ? `${param.name}, ...` : param.optional ? `${param.name}?` : param.name;
+    if (param === help.parameter) {
+      name.style.cssText = 'color: #63b3ed; font-weight: 600; text-decoration: underline;';
+    }
+    line.appendChild(name);
+  });
+  line.append(`) → ${signature.returnType}`);
+  tooltip.appendChild(line);
+
+  if (help.parameter?.description) {
+    const description = document.createElement('div');
+    description.textContent = `${help.parameter.name}: ${help.parameter.description}`;
+    description.style.cssText = `
+      margin-top: 4px;
+      font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', 'Roboto', sans-serif;
+      opacity: 0.8;
+    `;
+    tooltip.appendChild(description);
+  }
+
+  return tooltip;
+};
+
+// Create the signature help extension, showing the signature of the call around the cursor while typing arguments
+export const expressionSignatureHelp = (analyzer: Analyzer) => {
+  return StateField.define<Tooltip | null>({
+    create: (state) => signatureTooltip(analyzer, state),
+    update: (tooltip, transaction) => {
+      if (!transaction.docChanged && !transaction.selection) {
+        return tooltip;
+      }
+      return signatureTooltip(analyzer, transaction.state);
+    },
+    provide: (field) => showTooltip.from(field),
+  });
+};
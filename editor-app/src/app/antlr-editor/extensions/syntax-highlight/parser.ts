@@ -1,6 +1,6 @@
 import { type Input, NodeSet, NodeType, Parser, type PartialParse, Tree, type TreeFragment } from '@lezer/common';
 import { styleTags, tags } from '@lezer/highlight';
-import type { Analyzer, ParseTreeNode, ParseTreeResult } from '../../../../wasm/analyzer';
+import type { AnalyzeResult, Analyzer, ParseTreeNode } from '../../../../wasm/analyzer';
 
 // Define node types for our expression language (matching Go NodeType constants)
 const nodeTypeIds = {
@@ -179,8 +179,8 @@ class ExpressionPartialParse implements PartialParse {
     // Get the full text
     const text = this.input.read(0, this.input.length);
 
-    // Parse with analyzer
-    const result: ParseTreeResult = this.analyzer.parseTree(text);
+    // Parse with analyzer; the linter reuses the analysis of the same text
+    const result: AnalyzeResult = this.analyzer.analyze(text);
 
     // Build tree from parse result
     if (result.tree) {
@@ -1,41 +1,80 @@
-import { syntaxTree } from '@codemirror/language';
 import { hoverTooltip } from '@codemirror/view';
-import type { FunctionDescription } from '../function';
+import { type Analyzer, type FunctionSignature, type NodeAtResult, NodeType } from '../../../../wasm/analyzer';
 
-export const expressionHoverTooltip = (functionDescriptions: Record<string, FunctionDescription>) => {
+// Create the hover tooltip extension, documenting the function or column under the pointer with the analyzer.
+// Functions are documented by the function registry of the analyzer, the same documentation as signature help.
+export const expressionHoverTooltip = (analyzer: Analyzer) => {
   return hoverTooltip((view, pos, side) => {
-    // Get the syntax tree from the current state
-    const tree = syntaxTree(view.state);
-    if (!tree) {
+    // The analyzer looks up the character at an offset, which is the one before pos when hovering its right side
+    const offset = side < 0 ? pos - 1 : pos;
+    const result = analyzer.nodeAt(view.state.doc.toString(), offset);
+    const node = result.node;
+    if (!node) {
       return null;
     }
 
-    // Use cursor to find the node at the current position
-    const node = tree.resolveInner(pos, side);
-
-    // Check if the node is a FunctionName node
-    if (node && node.type.name === 'FunctionName') {
-      // Get the text of the function name from the document
-      const functionName = view.state.doc.sliceString(node.from, node.to).toUpperCase();
-      const description = functionDescriptions[functionName];
-
-      if (description) {
-        return {
-          pos: node.from,
-          end: node.to,
-          above: true,
-          create: () => ({
-            dom: createTooltipDOM(description),
-          }),
-        };
-      }
+    let dom: HTMLElement;
+    if (result.function) {
+      dom = createTooltipDOM(result.function);
+    } else if (node.type === NodeType.ColumnRef) {
+      dom = createColumnTooltipDOM(node.text, result);
+    } else {
+      return null;
     }
 
-    return null;
+    return {
+      pos: node.start,
+      end: node.end,
+      above: true,
+      create: () => ({ dom }),
+    };
   });
 };
 
-const createTooltipDOM = (description: FunctionDescription): HTMLElement => {
+// Render the column name with its type, inferred as any for columns missing from the schema, and its description
+const createColumnTooltipDOM = (reference: string, result: NodeAtResult): HTMLElement => {
+  const tooltip = document.createElement('div');
+  tooltip.className = 'cm-tooltip-hover';
+  tooltip.style.cssText = `
+    background: #2d3748;
+    border: 1px solid rgba(255, 255, 255, 0.1);
+    border-radius: 8px;
+    padding: 8px 12px;
+    max-width: 450px;
+    color: #e2e8f0;
+    font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', 'Roboto', sans-serif;
+    font-size: 13px;
+    line-height: 1.5;
+  `;
+
+  const header = document.createElement('div');
+  const name = document.createElement('strong');
+  name.textContent = reference;
+  name.style.cssText = 'color: #63b3ed; font-weight: 600;';
+  header.appendChild(name);
+
+  const type = document.createElement('code');
+  type.textContent = result.type;
+  type.style.cssText = `
+    margin-left: 8px;
+    color: #68d391;
+    font-family: 'SF Mono', Monaco, 'Cascadia Code', 'Roboto Mono', Consolas, 'Courier New', monospace;
+    font-size: 12px;
+  `;
+  header.appendChild(type);
+  tooltip.appendChild(header);
+
+  if (result.column?.description) {
+    const description = document.createElement('div');
+    description.textContent = result.column.description;
+    description.style.cssText = 'margin-top: 4px; opacity: 0.8;';
+    tooltip.appendChild(description);
+  }
+
+  return tooltip;
+};
+
+const createTooltipDOM = (description: FunctionSignature): HTMLElement => {
   const tooltip = document.createElement('div');
   tooltip.className = 'cm-tooltip-hover';
 
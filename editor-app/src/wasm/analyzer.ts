@@ -1,6 +1,57 @@
-import type { Error as AnalyzerError, FormatOptions, ParseTreeResult, TokenizeResult } from '@wasm-analyzer';
+import type {
+  Error as AnalyzerError,
+  AnalyzeCatalogResult,
+  AnalyzeResult,
+  CatalogImpactResult,
+  Column,
+  CompletionResult,
+  CSTResult,
+  EditResult,
+  EvaluateResult,
+  FormatOptions,
+  FunctionDefinition,
+  FunctionImplementation,
+  FunctionSignature,
+  NodeAtResult,
+  ParseTreeResult,
+  PositionEncoding,
+  RenameResult,
+  Row,
+  SignatureHelpResult,
+  SQLDialect,
+  TokenizeResult,
+  TranspileResult,
+} from '@wasm-analyzer';
 
-export type { Error, FormatOptions, ParseTreeNode, ParseTreeResult, Token, TokenizeResult, TokenType } from '@wasm-analyzer';
+export type {
+  AnalyzeResult,
+  CellValue,
+  ChangedRange,
+  Column,
+  CompletionItem,
+  CompletionKind,
+  CompletionResult,
+  DataType,
+  EditResult,
+  Error,
+  EvaluateResult,
+  FormatOptions,
+  FunctionDefinition,
+  FunctionImplementation,
+  FunctionSignature,
+  NodeAtResult,
+  NodeInfo,
+  Parameter,
+  ParseTreeNode,
+  ParseTreeResult,
+  PositionEncoding,
+  Row,
+  SignatureHelpResult,
+  Token,
+  TokenizeResult,
+  TokenType,
+  Value,
+} from '@wasm-analyzer';
 
 // NodeType constants matching Go analyzer/core/models/node.go
 export const NodeType = {
@@ -63,20 +114,56 @@ export const NodeType = {
   ErrorChar: 41,
   Terminal: 42,
   Error: 43,
+  EOF: 44,
 } as const;
 
 const wasmModuleUrl = '/analyzer.wasm';
 export interface Analyzer {
   parseTree: (expression: string) => ParseTreeResult;
+  parseCST: (expression: string) => CSTResult;
   lint: (expression: string) => AnalyzerError[];
   tokenize: (expression: string) => TokenizeResult;
   validate: (expression: string) => boolean;
   format: (expression: string) => string;
   formatWithOptions: (expression: string, options?: FormatOptions) => string;
+  toJavaScript: (expression: string) => TranspileResult;
+  sourceFromJSON: (tree: string) => TranspileResult;
+  setSchema: (columns: Column[] | null) => boolean;
+  evaluate: (expression: string, row?: Row) => EvaluateResult;
+  functions: () => FunctionSignature[];
+  registerFunction: (definition: FunctionDefinition, implementation?: FunctionImplementation) => string | null;
+  toSQL: (expression: string, dialect?: SQLDialect) => TranspileResult;
+  renameColumn: (expression: string, oldName: string, newName: string) => RenameResult;
+  analyzeCatalog: (catalog: string) => AnalyzeCatalogResult;
+  catalogImpact: (catalog: string, column: string) => CatalogImpactResult;
+  complete: (expression: string, offset: number) => CompletionResult;
+  signatureHelp: (expression: string, offset: number) => SignatureHelpResult;
+  nodeAt: (expression: string, offset: number) => NodeAtResult;
+  setPositionEncoding: (encoding: PositionEncoding) => boolean;
+  analyze: (expression: string) => AnalyzeResult;
+  applyEdit: (start: number, end: number, text: string) => EditResult;
 }
 
 let instance: Analyzer | null = null;
 
+// The syntax highlighter and the linter analyze the same text after each change, so the last result is reused
+// until the text or the functions, schema or position encoding it was computed with change
+let lastAnalysis: { expression: string; result: AnalyzeResult } | null = null;
+
+const analyze = (expression: string): AnalyzeResult => {
+  if (lastAnalysis?.expression !== expression) {
+    lastAnalysis = { expression, result: window.analyze(expression) };
+  }
+  return lastAnalysis.result;
+};
+
+const invalidatesAnalysis =
+  <Args extends unknown[], Result>(fn: (...args: Args) => Result) =>
+  (...args: Args): Result => {
+    lastAnalysis = null;
+    return fn(...args);
+  };
+
 export const loadAnalyzer = async (): Promise<Analyzer> => {
   if (instance) {
     return instance;
@@ -86,14 +173,33 @@ export const loadAnalyzer = async (): Promise<Analyzer> => {
   await WebAssembly.instantiateStreaming(fetch(wasmModuleUrl), go.importObject).then((result) => {
     go.run(result.instance);
   });
+  // JavaScript strings and CodeMirror positions count UTF-16 code units
+  window.setPositionEncoding('utf-16');
 
   instance = {
     parseTree: window.parseTree,
+    parseCST: window.parseCST,
     lint: window.lint,
     tokenize: window.tokenize,
     validate: window.validate,
     format: window.format,
     formatWithOptions: window.formatWithOptions,
+    toJavaScript: window.toJavaScript,
+    sourceFromJSON: window.sourceFromJSON,
+    setSchema: invalidatesAnalysis(window.setSchema),
+    evaluate: window.evaluate,
+    functions: window.functions,
+    registerFunction: invalidatesAnalysis(window.registerFunction),
+    toSQL: window.toSQL,
+    renameColumn: window.renameColumn,
+    analyzeCatalog: window.analyzeCatalog,
+    catalogImpact: window.catalogImpact,
+    complete: window.complete,
+    signatureHelp: window.signatureHelp,
+    nodeAt: window.nodeAt,
+    setPositionEncoding: invalidatesAnalysis(window.setPositionEncoding),
+    analyze,
+    applyEdit: invalidatesAnalysis(window.applyEdit),
   };
 
   return instance;
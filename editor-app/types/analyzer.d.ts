@@ -68,7 +68,8 @@ export type NodeType =
   | 40 // WS
   | 41 // ErrorChar
   | 42 // Terminal
-  | 43; // Error
+  | 43 // Error
+  | 44; // EOF
 
 export interface Token {
   readonly type: TokenType;
@@ -106,9 +107,204 @@ export interface ParseTreeResult {
   readonly errors: Error[];
 }
 
+// Results of tokenize, lint, parseTree and format computed from a single lex and parse
+export interface AnalyzeResult {
+  readonly tokens: Token[];
+  readonly errors: Error[]; // Diagnostics as returned by lint
+  readonly tree: ParseTreeNode | null;
+  readonly formatted: string; // The expression unchanged if it has syntax errors
+}
+
+export interface ChangedRange {
+  readonly start: number;
+  readonly end: number;
+}
+
+// Results of applyEdit, computed incrementally from the expression last analyzed or edited
+export interface EditResult {
+  readonly tokens: Token[];
+  readonly errors: Error[]; // Diagnostics as returned by lint
+  readonly changed: ChangedRange[]; // Sorted ranges whose tokens or diagnostics changed, not counting moves
+  readonly error: string | null; // Set if there is no expression to edit or the range is invalid
+}
+
+export type TriviaKind = 'whitespace' | 'invalid' | 'skipped';
+
+export interface Trivia {
+  readonly kind: TriviaKind;
+  readonly text: string;
+  readonly start: number;
+  readonly end: number;
+}
+
+export interface CSTNode {
+  readonly type: NodeType;
+  readonly text: string;
+  readonly start: number;
+  readonly end: number;
+  readonly leading: Trivia[];
+  readonly trailing: Trivia[];
+  readonly children: CSTNode[];
+}
+
+export interface CSTResult {
+  readonly tree: CSTNode | null;
+  readonly errors: Error[];
+}
+
 export interface FormatOptions {
   readonly indentSize?: number;
   readonly maxLineLength?: number;
   readonly spaceAroundOps?: boolean;
   readonly breakLongExpressions?: boolean;
 }
+
+export type DataType = 'number' | 'string' | 'boolean' | 'datetime' | 'any';
+
+export interface Column {
+  readonly name: string;
+  readonly type?: DataType;
+  readonly description?: string;
+}
+
+export type Value =
+  | { readonly type: 'number'; readonly value: number }
+  | { readonly type: 'string'; readonly value: string }
+  | { readonly type: 'boolean'; readonly value: boolean }
+  | { readonly type: 'datetime'; readonly value: string } // RFC 3339
+  | { readonly type: 'null'; readonly value: null };
+
+export type Row = Readonly<Record<string, CellValue>>;
+
+export interface EvaluateResult {
+  readonly value: Value;
+  readonly errors: Error[];
+}
+
+export type SQLDialect = 'sqlite' | 'postgresql' | 'ansi';
+
+export interface TranspileResult {
+  readonly code: string;
+  readonly errors: Error[];
+}
+
+export interface TextEdit {
+  readonly start: number;
+  readonly end: number;
+  readonly newText: string;
+}
+
+export type CompletionKind = 'column' | 'function' | 'literal' | 'punctuation' | 'operator';
+
+export interface CompletionItem {
+  readonly label: string;
+  readonly kind: CompletionKind;
+  readonly detail: string;
+  readonly documentation: string;
+  readonly edit: TextEdit;
+}
+
+export interface CompletionResult {
+  readonly items: CompletionItem[];
+}
+
+export interface RenameResult {
+  readonly expression: string;
+  readonly edits: TextEdit[];
+  readonly errors: Error[];
+}
+
+export interface CatalogEntry {
+  readonly name: string;
+  readonly expression: string;
+}
+
+export interface Catalog {
+  readonly expressions: CatalogEntry[];
+}
+
+export interface CatalogExpressionReport {
+  readonly name: string;
+  readonly type: DataType;
+  readonly dependencies: string[];
+  readonly columns: string[];
+  readonly errors: Error[];
+}
+
+export interface MissingDependency {
+  readonly expression: string;
+  readonly column: string;
+}
+
+export interface CatalogReport {
+  readonly expressions: CatalogExpressionReport[];
+  readonly cycles: string[][];
+  readonly missing: MissingDependency[];
+  readonly order: string[];
+}
+
+export interface AnalyzeCatalogResult {
+  readonly report: CatalogReport | null;
+  readonly error: string | null;
+}
+
+export interface CatalogImpactResult {
+  readonly expressions: string[];
+  readonly error: string | null;
+}
+
+export interface Parameter {
+  readonly name: string;
+  readonly type: DataType;
+  readonly optional: boolean;
+  readonly description: string;
+}
+
+export interface FunctionSignature {
+  readonly name: string;
+  readonly description: string;
+  readonly examples: string[];
+  readonly parameters: Parameter[];
+  readonly variadic: Parameter | null;
+  readonly returnType: DataType;
+  readonly syntax: string;
+}
+
+export interface FunctionDefinition {
+  readonly name: string;
+  readonly description?: string;
+  readonly examples?: string[];
+  readonly parameters?: Array<Partial<Parameter> & { readonly name: string }>;
+  readonly variadic?: (Partial<Parameter> & { readonly name: string }) | null;
+  readonly returnType?: DataType;
+  readonly nullAware?: boolean;
+}
+
+export interface SignatureHelpResult {
+  readonly name: string;
+  readonly signature: FunctionSignature | null;
+  readonly activeParameter: number;
+  readonly parameter: Parameter | null;
+}
+
+export interface NodeInfo {
+  readonly type: NodeType;
+  readonly text: string;
+  readonly start: number;
+  readonly end: number;
+}
+
+export interface NodeAtResult {
+  readonly node: NodeInfo | null;
+  readonly ancestors: NodeInfo[]; // Innermost first, ending with the root Expression
+  readonly type: DataType | ''; // Empty when node is null
+  readonly function: FunctionSignature | null;
+  readonly column: Column | null;
+}
+
+// Unit of positions in results and offsets passed in: code points (default), UTF-16 code units or UTF-8 bytes
+export type PositionEncoding = 'rune' | 'utf-16' | 'byte';
+
+export type CellValue = number | string | boolean | Date | null;
+
+export type FunctionImplementation = (...args: CellValue[]) => CellValue;
@@ -1,4 +1,4 @@
-import type { Error as AnalyzerError, TokenizeResult, ParseTreeResult, FormatOptions } from './analyzer';
+import type { Error as AnalyzerError, TokenizeResult, ParseTreeResult, CSTResult, FormatOptions, Column, EvaluateResult, Row, FunctionSignature, FunctionDefinition, FunctionImplementation, SQLDialect, TranspileResult, RenameResult, AnalyzeCatalogResult, CatalogImpactResult, CompletionResult, SignatureHelpResult, NodeAtResult, PositionEncoding, AnalyzeResult, EditResult } from './analyzer';
 
 declare global {
   // Go WASM runtime class
@@ -16,10 +16,27 @@ declare global {
     readonly Go: typeof Go;
 
     parseTree: (expression: string) => ParseTreeResult;
+    parseCST: (expression: string) => CSTResult;
     lint: (expression: string) => AnalyzerError[];
     tokenize: (expression: string) => TokenizeResult;
     validate: (expression: string) => boolean;
     format: (expression: string) => string;
     formatWithOptions: (expression: string, options?: FormatOptions) => string;
+    toJavaScript: (expression: string) => TranspileResult;
+    sourceFromJSON: (tree: string) => TranspileResult;
+    setSchema: (columns: Column[] | null) => boolean;
+    evaluate: (expression: string, row?: Row) => EvaluateResult;
+    functions: () => FunctionSignature[];
+    registerFunction: (definition: FunctionDefinition, implementation?: FunctionImplementation) => string | null;
+    toSQL: (expression: string, dialect?: SQLDialect) => TranspileResult;
+    renameColumn: (expression: string, oldName: string, newName: string) => RenameResult;
+    analyzeCatalog: (catalog: string) => AnalyzeCatalogResult;
+    catalogImpact: (catalog: string, column: string) => CatalogImpactResult;
+    complete: (expression: string, offset: number) => CompletionResult;
+    signatureHelp: (expression: string, offset: number) => SignatureHelpResult;
+    nodeAt: (expression: string, offset: number) => NodeAtResult;
+    setPositionEncoding: (encoding: PositionEncoding) => boolean;
+    analyze: (expression: string) => AnalyzeResult;
+    applyEdit: (start: number, end: number, text: string) => EditResult;
   }
 }
@@ -78,9 +78,9 @@ STRING_LITERAL
     | '"'  ( ~["\r\n\\] | '\\' . )* '"'
     ;
 
-// Function names (uppercase only) - must come before IDENTIFIER
+// Function names (uppercase letters, digits and underscores, starting with a letter) - must come before IDENTIFIER
 FUNCTION_NAME
-    : [A-Z]+
+    : [A-Z] [A-Z0-9_]*
     ;
 
 // Identifiers for column references (letters, digits, underscore)